@@ -25,9 +25,11 @@ import (
 	"github.com/adverax/crm/internal/platform/credential"
 	"github.com/adverax/crm/internal/platform/dml"
 	dmlengine "github.com/adverax/crm/internal/platform/dml/engine"
+	"github.com/adverax/crm/internal/platform/idempotency"
 	"github.com/adverax/crm/internal/platform/metadata"
 	"github.com/adverax/crm/internal/platform/metadata/ddl"
 	procengine "github.com/adverax/crm/internal/platform/procedure"
+	"github.com/adverax/crm/internal/platform/querylog"
 	"github.com/adverax/crm/internal/platform/security"
 	"github.com/adverax/crm/internal/platform/security/fls"
 	"github.com/adverax/crm/internal/platform/security/ols"
@@ -55,6 +57,18 @@ func main() {
 	defer pool.Close()
 	slog.Info("database connected", "host", cfg.DB.Host, "db", cfg.DB.Name)
 
+	// SOQL reads go to the replica when one is configured
+	var replica *pgxpool.Pool
+	if r := cfg.DB.Replica; r != nil {
+		replica, err = database.NewPool(ctx, r.DSN())
+		if err != nil {
+			slog.Error("failed to connect to read replica", "error", err)
+			os.Exit(1)
+		}
+		defer replica.Close()
+		slog.Info("read replica connected", "host", r.Host, "db", r.Name)
+	}
+
 	// --- Metadata cache (shared between router and outbox worker) ---
 	cacheLoader := metadata.NewPgCacheLoader(pool)
 	metadataCache := metadata.NewMetadataCache(cacheLoader)
@@ -62,12 +76,25 @@ func main() {
 		slog.Warn("metadata cache initial load failed (empty database?)", "error", err)
 	}
 
-	router := setupRouter(pool, metadataCache, cfg)
-
-	// Start outbox worker
 	workerCtx, workerCancel := context.WithCancel(ctx)
 	defer workerCancel()
-	startOutboxWorker(workerCtx, pool, metadataCache, cfg.DB.DSN(), logger)
+
+	// SOQL result cache (opt-in), invalidated by DML and the outbox worker
+	resultCache := newResultCache(workerCtx, metadataCache, cfg.SOQL)
+
+	// SOQL/DML query log (opt-in)
+	queryLog := startQueryLog(workerCtx, pool, cfg.QueryLog, logger)
+
+	// Recycle bin of deleted records, purged after the retention period
+	recycleBin := startRecycleBin(workerCtx, pool, metadataCache, cfg.RecycleBin, logger)
+
+	// Responses to Idempotency-Key requests, purged after the retention period
+	idempotencyKeys := startIdempotency(workerCtx, pool, cfg.Idempotency, logger)
+
+	router := setupRouter(pool, replica, metadataCache, resultCache, queryLog, recycleBin, idempotencyKeys, cfg)
+
+	// Start outbox worker
+	startOutboxWorker(workerCtx, pool, metadataCache, resultCache, cfg.DB.DSN(), logger)
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
@@ -103,7 +130,17 @@ func main() {
 	slog.Info("server stopped")
 }
 
-func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg config.Config) *gin.Engine {
+// setupRouter wires the services and routes. replica is the optional read
+// replica pool and queryLog the optional query log (nil when not configured).
+func setupRouter(
+	pool, replica *pgxpool.Pool,
+	metadataCache *metadata.MetadataCache,
+	resultCache *soql.ResultCache,
+	queryLog *querylog.Writer,
+	recycleBin *dml.RecycleBin,
+	idempotencyKeys *idempotency.Service,
+	cfg config.Config,
+) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 
@@ -120,7 +157,9 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 	ctx := context.Background()
 
 	objectService := metadata.NewObjectService(pool, objectRepo, fieldRepo, ddlExec, metadataCache)
-	fieldService := metadata.NewFieldService(pool, objectRepo, fieldRepo, polyRepo, ddlExec, metadataCache)
+	savedQueryRepo := metadata.NewPgSavedQueryRepository(pool)
+	fieldService := metadata.NewFieldService(pool, objectRepo, fieldRepo, polyRepo, ddlExec, metadataCache,
+		metadata.WithFieldDependencyChecker(metadata.NewSavedQueryFieldDependencies(savedQueryRepo)))
 
 	validationRuleRepo := metadata.NewPgValidationRuleRepository(pool)
 	validationRuleService := metadata.NewValidationRuleService(pool, validationRuleRepo, metadataCache)
@@ -148,6 +187,7 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 	memberRepo := security.NewPgGroupMemberRepository(pool)
 
 	sharingRuleRepo := security.NewPgSharingRuleRepository(pool)
+	orgSettingsRepo := security.NewPgOrgSettingsRepository(pool)
 
 	roleService := security.NewUserRoleService(pool, userRoleRepo, groupRepo, outboxRepo)
 	psService := security.NewPermissionSetService(pool, psRepo)
@@ -156,6 +196,7 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 	permissionService := security.NewPermissionService(pool, objPermRepo, fieldPermRepo, outboxRepo)
 	groupService := security.NewGroupService(pool, groupRepo, memberRepo, outboxRepo)
 	sharingRuleService := security.NewSharingRuleService(pool, sharingRuleRepo, groupRepo, outboxRepo)
+	orgSettingsService := security.NewOrgSettingsService(pool, orgSettingsRepo)
 
 	// --- Auth module ---
 	userAuthRepo := auth.NewPgUserAuthRepository(pool)
@@ -199,9 +240,13 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 	// Admin routes
 	adminGroup := router.Group("/api/v1/admin")
 	metadataHandler.RegisterRoutes(adminGroup)
-	secHandler := handler.NewSecurityHandler(roleService, psService, profileService, userService, permissionService, groupService, sharingRuleService, authService)
+	secHandler := handler.NewSecurityHandler(roleService, psService, profileService, userService, permissionService, groupService, sharingRuleService, orgSettingsService, authService)
 	secHandler.RegisterRoutes(adminGroup)
 
+	// Query log (top slow queries, queries by user)
+	queryLogHandler := handler.NewQueryLogHandler(querylog.NewService(querylog.NewPgRepository(pool)))
+	queryLogHandler.RegisterRoutes(adminGroup)
+
 	// App templates
 	templateRegistry := templates.BuildRegistry()
 	templateApplier := templates.NewApplier(objectService, fieldService, objectRepo, permissionService, metadataCache)
@@ -218,6 +263,7 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 	flsEnforcer := fls.NewEnforcer(effectivePermRepo)
 	rlsMetadataAdapter := security.NewCacheBackedMetadataLister(metadataCache)
 	rlsEnforcer := rls.NewEnforcer(rlsCacheRepo, rlsMetadataAdapter)
+	scopeFilter := rls.NewScopeFilter(rlsCacheRepo, rlsMetadataAdapter, newTerritoryResolver(pool))
 
 	// --- SOQL engine ---
 	soqlMetadataAdapter := soql.NewMetadataAdapter(metadataCache)
@@ -225,9 +271,53 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 	soqlEngine := soqlengine.NewEngine(
 		soqlengine.WithMetadata(soqlMetadataAdapter),
 		soqlengine.WithAccessController(soqlAccessAdapter),
+		soqlengine.WithLimits(soqlengine.DefaultLimits.Merge(&soqlengine.Limits{
+			MaxEstimatedCost: cfg.SOQL.MaxEstimatedCost,
+			MaxEstimatedRows: cfg.SOQL.MaxEstimatedRows,
+		})),
 	)
-	soqlExecutor := soql.NewExecutor(pool, metadataCache, rlsEnforcer)
-	soqlService := soql.NewQueryService(soqlEngine, soqlExecutor)
+	statementTimeouts := security.NewStatementTimeoutCache(profileRepo)
+	soqlExecutorOpts := []soql.ExecutorOption{
+		soql.WithOrgSettings(orgSettingsService),
+		soql.WithScopeFilter(scopeFilter),
+		soql.WithStatementTimeouts(statementTimeouts),
+	}
+	if replica != nil {
+		soqlExecutorOpts = append(soqlExecutorOpts, soql.WithReplica(replica))
+	}
+	soqlExecutor := soql.NewExecutor(pool, metadataCache, rlsEnforcer, soqlExecutorOpts...)
+	// Users who just wrote read from the primary until the replica catches up
+	recentWrites := database.NewWriteTracker(cfg.DB.ReplicaStickyWindow)
+	cursorSecret := cfg.SOQL.CursorSecret
+	if cursorSecret == "" {
+		cursorSecret = "soql-cursor:" + jwtSecret
+	}
+	soqlCursors := soqlengine.NewCursorManager(
+		soqlengine.NewStaticSecret(cursorSecret),
+		soqlengine.DefaultTieBreaker,
+		soqlengine.WithCursorTTL(cfg.SOQL.CursorTTL),
+	)
+	// Exports stream rows instead of materializing them, so they may return
+	// more records than a regular query; the cost ceiling does not apply.
+	exportLimits := soqlengine.DefaultLimits
+	exportLimits.MaxRecords = cfg.SOQL.ExportMaxRecords
+	soqlExportEngine := soqlengine.NewEngine(
+		soqlengine.WithMetadata(soqlMetadataAdapter),
+		soqlengine.WithAccessController(soqlAccessAdapter),
+		soqlengine.WithLimits(&exportLimits),
+	)
+	soqlServiceOpts := []soql.QueryServiceOption{
+		soql.WithCursorManager(soqlCursors),
+		soql.WithExportEngine(soqlExportEngine),
+		soql.WithWriteTracker(recentWrites),
+	}
+	if resultCache != nil {
+		soqlServiceOpts = append(soqlServiceOpts, soql.WithResultCache(resultCache))
+	}
+	if queryLog != nil {
+		soqlServiceOpts = append(soqlServiceOpts, soql.WithQueryLog(queryLog))
+	}
+	soqlService := soql.NewQueryService(soqlEngine, soqlExecutor, soqlServiceOpts...)
 
 	// --- DML engine ---
 	dmlMetadataAdapter := dml.NewMetadataAdapter(metadataCache)
@@ -258,7 +348,21 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 		dmlengine.WithRuleValidator(celRuleValidator),
 	)
 	dmlExecutor := dml.NewRLSExecutor(pool, metadataCache, rlsEnforcer)
-	dmlService := dml.NewDMLService(pool, dmlEngine, dmlExecutor)
+	recycleBin.SetDeleteAccess(olsEnforcer, flsEnforcer, rlsEnforcer)
+	dmlServiceOpts := []dml.DMLServiceOption{
+		dml.WithWriteRecorder(recentWrites),
+		dml.WithRecycleBin(recycleBin),
+		dml.WithMerger(dml.NewMerger(metadataCache)),
+		dml.WithReadAccessController(soqlAccessAdapter),
+		dml.WithRecordReader(soql.NewRecordReader(soqlService)),
+	}
+	if resultCache != nil {
+		dmlServiceOpts = append(dmlServiceOpts, dml.WithChangeListener(resultCache))
+	}
+	if queryLog != nil {
+		dmlServiceOpts = append(dmlServiceOpts, dml.WithQueryLog(queryLog))
+	}
+	dmlService := dml.NewDMLService(pool, dmlEngine, dmlExecutor, dmlServiceOpts...)
 
 	// CEL validation handler
 	celHandler := handler.NewCELHandler(metadataCache, fnRegistry)
@@ -268,11 +372,21 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 	soqlValidationEngine := soqlengine.NewEngine(
 		soqlengine.WithMetadata(soqlMetadataAdapter),
 	)
-	adminSoqlExecutor := soql.NewExecutor(pool, metadataCache, nil)
+	adminSoqlExecutor := soql.NewExecutor(pool, metadataCache, nil,
+		soql.WithOrgSettings(orgSettingsService),
+		soql.WithScopeFilter(scopeFilter),
+		soql.WithStatementTimeouts(statementTimeouts),
+	)
 	adminSoqlService := soql.NewQueryService(soqlValidationEngine, adminSoqlExecutor)
 	soqlHandler := handler.NewSOQLHandler(soqlValidationEngine, adminSoqlService, metadataCache)
 	soqlHandler.RegisterRoutes(adminGroup)
 
+	// SOQL run-as (sharing diagnostics, audited)
+	runAsService := soql.NewRunAsService(soqlEngine, soqlAccessAdapter, soqlExecutor,
+		userRepo, security.NewPgRunAsAuditRepository(pool))
+	runAsHandler := handler.NewSOQLRunAsHandler(runAsService)
+	runAsHandler.RegisterRoutes(adminGroup)
+
 	// DML validation handler (design-time, no access control)
 	adminDmlEngine := dmlengine.NewEngine(dmlengine.WithMetadata(dmlMetadataAdapter))
 	dmlHandler := handler.NewDMLHandler(adminDmlEngine, pool, metadataCache)
@@ -377,10 +491,25 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 	sharedLayoutHandler.RegisterRoutes(adminGroup)
 
 	// --- Query/Data API ---
-	queryHandler := handler.NewQueryHandler(soqlService, dmlService)
+	idempotent := middleware.Idempotency(idempotencyKeys)
+	queryHandler := handler.NewQueryHandler(soqlService, dmlService,
+		handler.WithExportTimeout(cfg.SOQL.ExportTimeout),
+		handler.WithDataIdempotency(idempotent),
+	)
 	apiGroup := router.Group("/api/v1")
 	queryHandler.RegisterRoutes(apiGroup)
 
+	// SOSL full-text search
+	searchService := soql.NewSearchService(soqlEngine, soqlExecutor)
+	searchHandler := handler.NewSearchHandler(searchService)
+	searchHandler.RegisterRoutes(apiGroup)
+
+	// Saved queries (named, parameterized SOQL)
+	savedQueryAnalyzer := soql.NewSavedQueryAnalyzer(metadataCache, soqlEngine.GetLimits())
+	savedQueryService := metadata.NewSavedQueryService(savedQueryRepo, savedQueryAnalyzer, metadataCache)
+	savedQueryHandler := handler.NewSavedQueryHandler(savedQueryService, soqlService)
+	savedQueryHandler.RegisterRoutes(adminGroup, apiGroup)
+
 	// Profile Navigation (ADR-0032)
 	navRepo := metadata.NewPgNavigationRepository(pool)
 	navService := metadata.NewProfileNavigationService(navRepo)
@@ -389,7 +518,7 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 
 	// --- Record CRUD API ---
 	recordService := service.NewRecordService(metadataCache, soqlService, dmlService)
-	recordHandler := handler.NewRecordHandler(recordService)
+	recordHandler := handler.NewRecordHandler(recordService, handler.WithRecordIdempotency(idempotent))
 	recordHandler.RegisterRoutes(apiGroup)
 
 	// --- View API ---
@@ -403,7 +532,72 @@ func setupRouter(pool *pgxpool.Pool, metadataCache *metadata.MetadataCache, cfg
 	return router
 }
 
-func startOutboxWorker(ctx context.Context, pool *pgxpool.Pool, metadataCache metadata.MetadataReader, dsn string, logger *slog.Logger) {
+// newResultCache creates the SOQL result cache, or returns nil when it is
+// disabled. Expired entries are collected until ctx is cancelled.
+func newResultCache(ctx context.Context, metadataCache metadata.MetadataReader, cfg config.SOQLConfig) *soql.ResultCache {
+	if cfg.ResultCacheTTL <= 0 {
+		return nil
+	}
+	backend := soqlengine.NewMemoryCache[string, *soql.CachedResult](cfg.ResultCacheTTL, cfg.ResultCacheMaxEntries)
+	backend.StartGarbageCollector(ctx, cfg.ResultCacheTTL)
+	slog.Info("soql result cache enabled", "ttl", cfg.ResultCacheTTL, "max_entries", cfg.ResultCacheMaxEntries)
+	return soql.NewResultCache(backend, metadataCache, soql.WithResultCacheMaxRows(cfg.ResultCacheMaxRows))
+}
+
+// startQueryLog starts the query log writer, or returns nil when the log is
+// disabled. Queued entries are written until ctx is cancelled.
+func startQueryLog(ctx context.Context, pool *pgxpool.Pool, cfg config.QueryLogConfig, logger *slog.Logger) *querylog.Writer {
+	if !cfg.Enabled {
+		return nil
+	}
+	writer := querylog.NewWriter(querylog.NewPgRepository(pool), logger,
+		querylog.WithMinDuration(cfg.MinDuration),
+		querylog.WithRetention(cfg.Retention),
+		querylog.WithBufferSize(cfg.BufferSize),
+	)
+	go func() {
+		if err := writer.Run(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("query log writer stopped with error", "error", err)
+		}
+	}()
+	slog.Info("query log enabled", "min_duration", cfg.MinDuration, "retention", cfg.Retention)
+	return writer
+}
+
+// startRecycleBin creates the recycle bin and starts its purge, which runs
+// until ctx is cancelled. A zero retention keeps deleted records.
+func startRecycleBin(ctx context.Context, pool *pgxpool.Pool, metadataCache metadata.MetadataReader, cfg config.RecycleBinConfig, logger *slog.Logger) *dml.RecycleBin {
+	bin := dml.NewRecycleBin(metadataCache, pool, logger, dml.WithRecycleBinRetention(cfg.Retention))
+	go func() {
+		if err := bin.Run(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("recycle bin purge stopped with error", "error", err)
+		}
+	}()
+	slog.Info("recycle bin enabled", "retention", cfg.Retention)
+	return bin
+}
+
+// startIdempotency creates the idempotency key service and starts its purge,
+// which runs until ctx is cancelled.
+func startIdempotency(ctx context.Context, pool *pgxpool.Pool, cfg config.IdempotencyConfig, logger *slog.Logger) *idempotency.Service {
+	svc := idempotency.NewService(idempotency.NewPgStore(pool), logger, idempotency.WithRetention(cfg.Retention))
+	go func() {
+		if err := svc.Run(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("idempotency key purge stopped with error", "error", err)
+		}
+	}()
+	slog.Info("idempotency keys enabled", "retention", cfg.Retention)
+	return svc
+}
+
+func startOutboxWorker(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	metadataCache metadata.MetadataReader,
+	resultCache *soql.ResultCache,
+	dsn string,
+	logger *slog.Logger,
+) {
 	connConfig, err := security.ParseConnConfig(dsn)
 	if err != nil {
 		slog.Error("failed to parse conn config for outbox worker", "error", err)
@@ -436,7 +630,11 @@ func startOutboxWorker(ctx context.Context, pool *pgxpool.Pool, metadataCache me
 		rlsCacheRepo, metadataLister,
 	)
 
-	worker := security.NewOutboxWorker(*connConfig, outboxRepo, computer, rlsComputer, logger)
+	var workerOpts []security.OutboxWorkerOption
+	if resultCache != nil {
+		workerOpts = append(workerOpts, security.WithRecomputeListener(resultCache))
+	}
+	worker := security.NewOutboxWorker(*connConfig, outboxRepo, computer, rlsComputer, logger, workerOpts...)
 	go func() {
 		if err := worker.Run(ctx); err != nil && ctx.Err() == nil {
 			slog.Error("outbox worker stopped with error", "error", err)
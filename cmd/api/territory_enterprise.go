@@ -7,8 +7,13 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/adverax/crm/ee/setup"
+	"github.com/adverax/crm/internal/platform/security/rls"
 )
 
 func registerTerritoryRoutes(pool *pgxpool.Pool, adminGroup *gin.RouterGroup) {
 	setup.RegisterTerritoryRoutes(pool, adminGroup)
 }
+
+func newTerritoryResolver(pool *pgxpool.Pool) rls.TerritoryResolver {
+	return setup.NewTerritoryResolver(pool)
+}
@@ -5,9 +5,15 @@ package main
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/adverax/crm/internal/platform/security/rls"
 )
 
 func registerTerritoryRoutes(_ *pgxpool.Pool, _ *gin.RouterGroup) {
 	// Territory management is an enterprise feature.
 	// Build with -tags enterprise to enable.
 }
+
+func newTerritoryResolver(_ *pgxpool.Pool) rls.TerritoryResolver {
+	return rls.NewTerritoryResolver()
+}
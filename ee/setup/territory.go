@@ -13,8 +13,15 @@ import (
 
 	"github.com/adverax/crm/ee/internal/handler"
 	"github.com/adverax/crm/ee/internal/platform/territory"
+	"github.com/adverax/crm/internal/platform/security/rls"
 )
 
+// NewTerritoryResolver returns the territory group resolver backed by the
+// effective territory caches.
+func NewTerritoryResolver(pool *pgxpool.Pool) rls.TerritoryResolver {
+	return territory.NewTerritoryResolver(territory.NewPgEffectiveRepository(pool))
+}
+
 // RegisterTerritoryRoutes instantiates all territory services and registers routes.
 func RegisterTerritoryRoutes(pool *pgxpool.Pool, adminGroup *gin.RouterGroup) {
 	modelRepo := territory.NewPgModelRepository(pool)
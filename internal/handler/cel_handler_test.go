@@ -72,6 +72,18 @@ func (l *testCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]metadata.Sh
 	return nil, nil
 }
 
+func (l *testCacheLoader) LoadAllSavedQueries(_ context.Context) ([]metadata.SavedQuery, error) {
+	return nil, nil
+}
+
+func (l *testCacheLoader) LoadAllPicklistValues(_ context.Context) ([]metadata.PicklistValueRow, error) {
+	return nil, nil
+}
+
+func (l *testCacheLoader) LoadAllTranslations(_ context.Context) ([]metadata.Translation, error) {
+	return nil, nil
+}
+
 func (l *testCacheLoader) RefreshMaterializedView(_ context.Context) error {
 	return nil
 }
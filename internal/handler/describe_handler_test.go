@@ -106,6 +106,18 @@ func (s *stubDescribeCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]met
 	return nil, nil
 }
 
+func (s *stubDescribeCacheLoader) LoadAllSavedQueries(_ context.Context) ([]metadata.SavedQuery, error) {
+	return nil, nil
+}
+
+func (s *stubDescribeCacheLoader) LoadAllPicklistValues(_ context.Context) ([]metadata.PicklistValueRow, error) {
+	return nil, nil
+}
+
+func (s *stubDescribeCacheLoader) LoadAllTranslations(_ context.Context) ([]metadata.Translation, error) {
+	return nil, nil
+}
+
 func buildDescribeTestCache(objID uuid.UUID, apiName, tableName string) *metadata.MetadataCache {
 	fieldID := uuid.New()
 	loader := &stubDescribeCacheLoader{
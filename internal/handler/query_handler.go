@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -10,34 +13,87 @@ import (
 	"github.com/adverax/crm/internal/platform/soql"
 )
 
+// DefaultExportTimeout is how long a streamed export may run.
+const DefaultExportTimeout = 10 * time.Minute
+
 // QueryHandler handles SOQL and DML API endpoints.
 type QueryHandler struct {
-	soqlService soql.QueryService
-	dmlService  dml.DMLService
+	soqlService   soql.QueryService
+	dmlService    dml.DMLService
+	exportTimeout time.Duration
+	idempotent    gin.HandlerFunc
+}
+
+// QueryHandlerOption configures a QueryHandler.
+type QueryHandlerOption func(*QueryHandler)
+
+// WithExportTimeout sets the time budget of a streamed export. It replaces
+// the server write timeout for export responses.
+func WithExportTimeout(timeout time.Duration) QueryHandlerOption {
+	return func(h *QueryHandler) {
+		if timeout > 0 {
+			h.exportTimeout = timeout
+		}
+	}
 }
 
+// WithDataIdempotency runs mw, the Idempotency-Key middleware, in front of
+// the DML endpoints.
+func WithDataIdempotency(mw gin.HandlerFunc) QueryHandlerOption {
+	return func(h *QueryHandler) {
+		h.idempotent = mw
+	}
+}
+
+// passThrough is the middleware of endpoints without idempotency keys.
+func passThrough(*gin.Context) {}
+
 // NewQueryHandler creates a new QueryHandler.
-func NewQueryHandler(soqlService soql.QueryService, dmlService dml.DMLService) *QueryHandler {
-	return &QueryHandler{
-		soqlService: soqlService,
-		dmlService:  dmlService,
+func NewQueryHandler(soqlService soql.QueryService, dmlService dml.DMLService, opts ...QueryHandlerOption) *QueryHandler {
+	h := &QueryHandler{
+		soqlService:   soqlService,
+		dmlService:    dmlService,
+		exportTimeout: DefaultExportTimeout,
+		idempotent:    passThrough,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // RegisterRoutes registers query/data routes on the given group.
 func (h *QueryHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.GET("/query", h.ExecuteQuery)
 	rg.POST("/query", h.ExecuteQueryPost)
-	rg.POST("/data", h.ExecuteDML)
+	rg.GET("/query/next/:cursor", h.ExecuteQueryNext)
+	rg.POST("/query/explain", h.ExplainQuery)
+	rg.GET("/query/export", h.ExportQuery)
+	rg.POST("/query/export", h.ExportQueryPost)
+	rg.POST("/data", h.idempotent, h.ExecuteDML)
+	rg.POST("/data/composite", h.idempotent, h.ExecuteComposite)
 }
 
 type queryRequest struct {
-	Query    string `json:"query" binding:"required"`
-	PageSize int    `json:"pageSize"`
+	Query    string         `json:"query" binding:"required"`
+	PageSize int            `json:"pageSize"`
+	Binds    map[string]any `json:"binds"`
+}
+
+type exportRequest struct {
+	Query  string         `json:"query" binding:"required"`
+	Format string         `json:"format"`
+	Binds  map[string]any `json:"binds"`
 }
 
 type dmlRequest struct {
-	Statement string `json:"statement" binding:"required"`
+	Statement  string   `json:"statement"`
+	Statements []string `json:"statements"`
+	AllOrNone  *bool    `json:"allOrNone"`
+}
+
+type compositeRequest struct {
+	Subrequests []dml.SubRequest `json:"subrequests" binding:"required"`
 }
 
 // ExecuteQuery handles GET /api/v1/query?q=SELECT...
@@ -66,7 +122,7 @@ func (h *QueryHandler) ExecuteQueryPost(c *gin.Context) {
 		return
 	}
 
-	params := &soql.QueryParams{PageSize: req.PageSize}
+	params := &soql.QueryParams{PageSize: req.PageSize, Binds: req.Binds}
 	result, err := h.soqlService.Execute(c.Request.Context(), req.Query, params)
 	if err != nil {
 		apperror.Respond(c, err)
@@ -76,19 +132,156 @@ func (h *QueryHandler) ExecuteQueryPost(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// ExecuteDML handles POST /api/v1/data
+// ExecuteQueryNext handles GET /api/v1/query/next/:cursor
+func (h *QueryHandler) ExecuteQueryNext(c *gin.Context) {
+	result, err := h.soqlService.ExecuteNext(c.Request.Context(), c.Param("cursor"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExplainQuery handles POST /api/v1/query/explain
+func (h *QueryHandler) ExplainQuery(c *gin.Context) {
+	var req queryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	params := &soql.QueryParams{PageSize: req.PageSize, Binds: req.Binds}
+	result, err := h.soqlService.Explain(c.Request.Context(), req.Query, params)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportQuery handles GET /api/v1/query/export?q=SELECT...&format=csv
+func (h *QueryHandler) ExportQuery(c *gin.Context) {
+	q := c.Query("q")
+	if q == "" {
+		apperror.Respond(c, apperror.BadRequest("query parameter 'q' is required"))
+		return
+	}
+
+	h.export(c, q, c.Query("format"), nil)
+}
+
+// ExportQueryPost handles POST /api/v1/query/export
+func (h *QueryHandler) ExportQueryPost(c *gin.Context) {
+	var req exportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	h.export(c, req.Query, req.Format, req.Binds)
+}
+
+// export streams all records of a query to the response. The export runs
+// under its own deadline instead of the server write timeout. Errors before
+// the first byte produce a regular error response; later errors truncate
+// the stream.
+func (h *QueryHandler) export(c *gin.Context, query, formatName string, binds map[string]any) {
+	format, err := soql.ParseExportFormat(formatName)
+	if err != nil {
+		apperror.Respond(c, apperror.BadRequest(err.Error()))
+		return
+	}
+
+	deadline := time.Now().Add(h.exportTimeout)
+	ctx, cancel := context.WithDeadline(c.Request.Context(), deadline)
+	defer cancel()
+	// Not every writer supports deadlines (e.g. test recorders).
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(deadline)
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="export.%s"`, format))
+	c.Header("Cache-Control", "no-store")
+
+	_, err = h.soqlService.Stream(ctx, query, binds, soql.NewRecordWriter(format, c.Writer))
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		apperror.Respond(c, err)
+		return
+	}
+	_ = c.Error(err)
+}
+
+// ExecuteDML handles POST /api/v1/data. A single statement run with the
+// default allOrNone=true returns its DML result; anything else returns the
+// per-row results of the batch.
 func (h *QueryHandler) ExecuteDML(c *gin.Context) {
 	var req dmlRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		apperror.Respond(c, apperror.BadRequest("invalid request body"))
 		return
 	}
+	if (req.Statement == "") == (len(req.Statements) == 0) {
+		apperror.Respond(c, apperror.BadRequest("exactly one of statement and statements is required"))
+		return
+	}
+	allOrNone := req.AllOrNone == nil || *req.AllOrNone
 
-	result, err := h.dmlService.Execute(c.Request.Context(), req.Statement)
+	if req.Statement != "" && allOrNone {
+		result, err := h.dmlService.Execute(c.Request.Context(), req.Statement)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	statements := req.Statements
+	if req.Statement != "" {
+		statements = []string{req.Statement}
+	}
+
+	if !allOrNone {
+		result, err := h.dmlService.ExecutePartial(c.Request.Context(), statements)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	results, err := h.dmlService.ExecuteBatch(c.Request.Context(), statements)
 	if err != nil {
 		apperror.Respond(c, err)
 		return
 	}
+	batch := &dml.BatchResult{Results: make([]dml.RowResult, len(results)), SuccessCount: len(results)}
+	for i, r := range results {
+		batch.Results[i] = dml.RowResult{Statement: i, Success: true, Result: r}
+	}
+	c.JSON(http.StatusOK, batch)
+}
 
-	c.JSON(http.StatusOK, result)
+// ExecuteComposite handles POST /api/v1/data/composite
+func (h *QueryHandler) ExecuteComposite(c *gin.Context) {
+	var req compositeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	results, err := h.dmlService.ExecuteComposite(c.Request.Context(), req.Subrequests)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
 }
@@ -0,0 +1,443 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/dml"
+	"github.com/adverax/crm/internal/platform/dml/engine"
+	"github.com/adverax/crm/internal/platform/soql"
+)
+
+func setupQueryRouter(t *testing.T, svc *mockSOQLService) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(contractValidationMiddleware(t))
+	api := r.Group("/api/v1")
+	h := NewQueryHandler(svc, nil)
+	h.RegisterRoutes(api)
+	return r
+}
+
+func TestQueryHandler_ExplainQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       interface{}
+		explainErr error
+		wantStatus int
+	}{
+		{
+			name: "returns plan",
+			body: map[string]any{
+				"query":    "SELECT Id FROM Account WHERE Industry = :industry",
+				"pageSize": 50,
+				"binds":    map[string]any{"industry": "Tech"},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing query returns 400",
+			body:       map[string]any{"pageSize": 50},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "service error is propagated",
+			body:       map[string]any{"query": "SELECT Id FROM Account"},
+			explainErr: apperror.Forbidden("access denied"),
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotQuery string
+			var gotParams *soql.QueryParams
+			svc := &mockSOQLService{
+				explainFn: func(_ context.Context, query string, params *soql.QueryParams) (*soql.ExplainResult, error) {
+					if tt.explainErr != nil {
+						return nil, tt.explainErr
+					}
+					gotQuery, gotParams = query, params
+					return &soql.ExplainResult{
+						Object: "Account",
+						Fields: []soql.FieldInfo{{Name: "Id", Type: "id"}},
+						SQL:    `SELECT t0."id" FROM "obj_account" t0 WHERE t0."industry" = $1 AND TRUE`,
+						RLS:    []soql.RLSPredicateInfo{{Object: "Account", Alias: "t0", Kind: "root", Predicate: "TRUE"}},
+						Plan: &soql.QueryPlan{
+							NodeType:  "Seq Scan",
+							TotalCost: 120000.5,
+							PlanRows:  40000,
+							Raw:       json.RawMessage(`{"Node Type":"Seq Scan"}`),
+						},
+						Rejected:     true,
+						RejectReason: "MaxEstimatedCost limit exceeded: 120001 (max: 100000)",
+					}, nil
+				},
+			}
+			r := setupQueryRouter(t, svc)
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/query/explain", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, "body: %s", w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "SELECT Id FROM Account WHERE Industry = :industry", gotQuery)
+			require.NotNil(t, gotParams)
+			assert.Equal(t, 50, gotParams.PageSize)
+			assert.Equal(t, "Tech", gotParams.Binds["industry"])
+
+			var resp soql.ExplainResult
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.NotNil(t, resp.Plan)
+			assert.Equal(t, "Seq Scan", resp.Plan.NodeType)
+			assert.True(t, resp.Rejected)
+			require.Len(t, resp.RLS, 1)
+			assert.Equal(t, "root", resp.RLS[0].Kind)
+		})
+	}
+}
+
+func TestQueryHandler_ExportQuery(t *testing.T) {
+	t.Parallel()
+
+	streamRecords := func(_ context.Context, _ string, _ map[string]any, w soql.RecordWriter) (int, error) {
+		if err := w.WriteHeader([]soql.FieldInfo{{Name: "Name", Type: "string"}}); err != nil {
+			return 0, err
+		}
+		for _, name := range []string{"Acme", "Globex"} {
+			if err := w.WriteRecord(map[string]any{"Name": name}); err != nil {
+				return 0, err
+			}
+		}
+		return 2, w.Flush()
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		target          string
+		body            interface{}
+		streamErr       error
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+		wantBinds       map[string]any
+	}{
+		{
+			name:            "GET streams CSV",
+			method:          http.MethodGet,
+			target:          "/api/v1/query/export?format=csv&q=SELECT+Name+FROM+Account",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "Name\r\nAcme\r\nGlobex\r\n",
+		},
+		{
+			name:   "POST streams NDJSON with binds",
+			method: http.MethodPost,
+			target: "/api/v1/query/export",
+			body: map[string]any{
+				"query": "SELECT Name FROM Account WHERE Industry = :industry",
+				"binds": map[string]any{"industry": "Tech"},
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/x-ndjson",
+			wantBody:        "{\"Name\":\"Acme\"}\n{\"Name\":\"Globex\"}\n",
+			wantBinds:       map[string]any{"industry": "Tech"},
+		},
+		{
+			name:       "GET without q returns 400",
+			method:     http.MethodGet,
+			target:     "/api/v1/query/export",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown format returns 400",
+			method:     http.MethodGet,
+			target:     "/api/v1/query/export?format=xlsx&q=SELECT+Name+FROM+Account",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "error before output returns JSON error",
+			method:          http.MethodPost,
+			target:          "/api/v1/query/export",
+			body:            map[string]any{"query": "SELECT Name FROM Account", "format": "csv"},
+			streamErr:       apperror.Forbidden("access denied"),
+			wantStatus:      http.StatusForbidden,
+			wantContentType: "application/json; charset=utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotBinds map[string]any
+			svc := &mockSOQLService{
+				streamFn: func(ctx context.Context, query string, binds map[string]any, w soql.RecordWriter) (int, error) {
+					if tt.streamErr != nil {
+						return 0, tt.streamErr
+					}
+					if _, ok := ctx.Deadline(); !ok {
+						t.Error("export must run with a deadline")
+					}
+					gotBinds = binds
+					return streamRecords(ctx, query, binds, w)
+				},
+			}
+			r := setupQueryRouter(t, svc)
+
+			var req *http.Request
+			if tt.body != nil {
+				body, _ := json.Marshal(tt.body)
+				req, _ = http.NewRequest(tt.method, tt.target, bytes.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+			} else {
+				req, _ = http.NewRequest(tt.method, tt.target, nil)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, "body: %s", w.Body.String())
+			if tt.wantContentType != "" {
+				assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+			}
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+			if tt.wantBinds != nil {
+				assert.Equal(t, tt.wantBinds, gotBinds)
+			}
+		})
+	}
+}
+
+type mockDMLService struct {
+	executeFn   func(ctx context.Context, statement string) (*dml.Result, error)
+	batchFn     func(ctx context.Context, statements []string) ([]*dml.Result, error)
+	partialFn   func(ctx context.Context, statements []string) (*dml.BatchResult, error)
+	compositeFn func(ctx context.Context, requests []dml.SubRequest) ([]dml.SubResult, error)
+}
+
+func (m *mockDMLService) Execute(ctx context.Context, statement string) (*dml.Result, error) {
+	return m.executeFn(ctx, statement)
+}
+
+func (m *mockDMLService) ExecuteBatch(ctx context.Context, statements []string) ([]*dml.Result, error) {
+	return m.batchFn(ctx, statements)
+}
+
+func (m *mockDMLService) ExecutePartial(ctx context.Context, statements []string) (*dml.BatchResult, error) {
+	return m.partialFn(ctx, statements)
+}
+
+func (m *mockDMLService) ExecuteComposite(ctx context.Context, requests []dml.SubRequest) ([]dml.SubResult, error) {
+	return m.compositeFn(ctx, requests)
+}
+
+func (m *mockDMLService) Prepare(_ context.Context, _ string) (*engine.CompiledDML, error) {
+	return nil, nil
+}
+
+func (m *mockDMLService) SetPostExecuteHook(_ dml.PostExecuteHook) {}
+
+func TestQueryHandler_ExecuteDML(t *testing.T) {
+	t.Parallel()
+
+	const insert = "INSERT INTO Account (Name) VALUES ('Acme'), ('Globex')"
+
+	tests := []struct {
+		name        string
+		body        map[string]any
+		wantStatus  int
+		wantCall    string
+		wantBatch   bool
+		wantSuccess int
+		wantFailure int
+	}{
+		{
+			name:       "single statement returns DML result",
+			body:       map[string]any{"statement": insert},
+			wantStatus: http.StatusOK,
+			wantCall:   "execute",
+		},
+		{
+			name:        "statements run as one batch",
+			body:        map[string]any{"statements": []string{insert, "DELETE FROM Task WHERE Status = 'Done'"}},
+			wantStatus:  http.StatusOK,
+			wantCall:    "batch",
+			wantBatch:   true,
+			wantSuccess: 2,
+		},
+		{
+			name:        "allOrNone=false runs a partial batch",
+			body:        map[string]any{"statement": insert, "allOrNone": false},
+			wantStatus:  http.StatusOK,
+			wantCall:    "partial",
+			wantBatch:   true,
+			wantSuccess: 1,
+			wantFailure: 1,
+		},
+		{
+			name:       "missing statement returns 400",
+			body:       map[string]any{"allOrNone": false},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "statement and statements returns 400",
+			body:       map[string]any{"statement": insert, "statements": []string{insert}},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotCall string
+			svc := &mockDMLService{
+				executeFn: func(_ context.Context, _ string) (*dml.Result, error) {
+					gotCall = "execute"
+					return &dml.Result{RowsAffected: 2}, nil
+				},
+				batchFn: func(_ context.Context, statements []string) ([]*dml.Result, error) {
+					gotCall = "batch"
+					results := make([]*dml.Result, len(statements))
+					for i := range statements {
+						results[i] = &dml.Result{RowsAffected: 1}
+					}
+					return results, nil
+				},
+				partialFn: func(_ context.Context, _ []string) (*dml.BatchResult, error) {
+					gotCall = "partial"
+					return &dml.BatchResult{
+						Results: []dml.RowResult{
+							{Statement: 0, Row: 0, Success: true, Result: &dml.Result{RowsAffected: 1}},
+							{Statement: 0, Row: 1, Error: &dml.RowError{Code: apperror.CodeValidation, Message: "Name is taken"}},
+						},
+						SuccessCount: 1,
+						FailureCount: 1,
+					}, nil
+				},
+			}
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(contractValidationMiddleware(t))
+			NewQueryHandler(nil, svc).RegisterRoutes(r.Group("/api/v1"))
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/data", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, "body: %s", w.Body.String())
+			assert.Equal(t, tt.wantCall, gotCall)
+			if !tt.wantBatch {
+				return
+			}
+			var resp dml.BatchResult
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantSuccess, resp.SuccessCount)
+			assert.Equal(t, tt.wantFailure, resp.FailureCount)
+			assert.Len(t, resp.Results, tt.wantSuccess+tt.wantFailure)
+		})
+	}
+}
+
+func TestQueryHandler_ExecuteComposite(t *testing.T) {
+	t.Parallel()
+
+	subrequests := []map[string]any{
+		{"referenceId": "newAccount", "statement": "INSERT INTO Account (Name) VALUES ('Acme')"},
+		{"referenceId": "newContact", "statement": "INSERT INTO Contact (AccountId, LastName) VALUES (@{newAccount.id}, 'Smith')"},
+	}
+
+	tests := []struct {
+		name       string
+		body       map[string]any
+		serviceErr error
+		wantStatus int
+		wantRefs   []string
+	}{
+		{
+			name:       "returns a result per sub-request",
+			body:       map[string]any{"subrequests": subrequests},
+			wantStatus: http.StatusOK,
+			wantRefs:   []string{"newAccount", "newContact"},
+		},
+		{
+			name:       "missing subrequests returns 400",
+			body:       map[string]any{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "failed sub-request is propagated",
+			body:       map[string]any{"subrequests": subrequests},
+			serviceErr: apperror.BadRequest("newContact: unknown field Contact.AccountId"),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []dml.SubRequest
+			svc := &mockDMLService{
+				compositeFn: func(_ context.Context, requests []dml.SubRequest) ([]dml.SubResult, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					got = requests
+					results := make([]dml.SubResult, len(requests))
+					for i, req := range requests {
+						results[i] = dml.SubResult{ReferenceID: req.ReferenceID, Result: &dml.Result{RowsAffected: 1}}
+					}
+					return results, nil
+				},
+			}
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(contractValidationMiddleware(t))
+			NewQueryHandler(nil, svc).RegisterRoutes(r.Group("/api/v1"))
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/data/composite", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, "body: %s", w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			require.Len(t, got, len(subrequests))
+			assert.Equal(t, subrequests[1]["statement"], got[1].Statement)
+
+			var resp struct {
+				Results []dml.SubResult `json:"results"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			refs := make([]string, len(resp.Results))
+			for i, r := range resp.Results {
+				refs[i] = r.ReferenceID
+			}
+			assert.Equal(t, tt.wantRefs, refs)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/querylog"
+)
+
+// QueryLogHandler serves the SOQL/DML query log to administrators.
+type QueryLogHandler struct {
+	service querylog.Service
+}
+
+// NewQueryLogHandler creates a new QueryLogHandler.
+func NewQueryLogHandler(service querylog.Service) *QueryLogHandler {
+	return &QueryLogHandler{service: service}
+}
+
+// RegisterRoutes registers query log routes on the admin group.
+func (h *QueryLogHandler) RegisterRoutes(admin *gin.RouterGroup) {
+	g := admin.Group("/query-log")
+	g.GET("/slow", h.TopSlow)
+	g.GET("/users/:userId", h.ListByUser)
+}
+
+// TopSlow handles GET /api/v1/admin/query-log/slow.
+func (h *QueryLogHandler) TopSlow(c *gin.Context) {
+	filter, err := parseQueryLogFilter(c)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	queries, err := h.service.TopSlow(c.Request.Context(), filter)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": queries})
+}
+
+// ListByUser handles GET /api/v1/admin/query-log/users/:userId.
+func (h *QueryLogHandler) ListByUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid user ID"))
+		return
+	}
+
+	filter, err := parseQueryLogFilter(c)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	entries, err := h.service.ListByUser(c.Request.Context(), userID, filter)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+// parseQueryLogFilter reads ?since=&kind=&object=&limit=. since is either a
+// duration back from now (24h) or an RFC 3339 time.
+func parseQueryLogFilter(c *gin.Context) (querylog.Filter, error) {
+	filter := querylog.Filter{
+		Kind:   querylog.Kind(c.Query("kind")),
+		Object: c.Query("object"),
+	}
+
+	if v := c.Query("since"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			filter.Since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+			filter.Since = t
+		} else {
+			return filter, apperror.BadRequest("invalid since: expected a duration (24h) or an RFC 3339 time")
+		}
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, apperror.BadRequest("invalid limit")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
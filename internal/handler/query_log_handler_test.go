@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adverax/crm/internal/platform/querylog"
+)
+
+type stubQueryLogService struct {
+	filter querylog.Filter
+	userID uuid.UUID
+}
+
+func (s *stubQueryLogService) TopSlow(_ context.Context, filter querylog.Filter) ([]querylog.SlowQuery, error) {
+	s.filter = filter
+	return []querylog.SlowQuery{{
+		QueryHash: "abc", Kind: querylog.KindSOQL, Object: "Deal", Source: "view:deals/query:main",
+		Executions: 12, AvgDurationMs: 840.5, MaxDurationMs: 2100, TotalRows: 240, LastSeenAt: time.Now(),
+	}}, nil
+}
+
+func (s *stubQueryLogService) ListByUser(_ context.Context, userID uuid.UUID, filter querylog.Filter) ([]querylog.Entry, error) {
+	s.userID = userID
+	s.filter = filter
+	return []querylog.Entry{{
+		ID: uuid.New(), Kind: querylog.KindDML, UserID: &userID, QueryHash: "def", Object: "Deal",
+		RowCount: 1, DurationMs: 4, RLSApplied: true, CreatedAt: time.Now(),
+	}}, nil
+}
+
+func setupQueryLogRouter(t *testing.T, svc querylog.Service) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(contractValidationMiddleware(t))
+	h := NewQueryLogHandler(svc)
+	h.RegisterRoutes(r.Group("/api/v1/admin"))
+	return r
+}
+
+func TestQueryLogHandler(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantKind   querylog.Kind
+		wantLimit  int
+		wantSince  time.Duration // back from now, 0 when not given
+		wantObject string
+		wantUser   uuid.UUID
+	}{
+		{
+			name:       "top slow queries",
+			url:        "/api/v1/admin/query-log/slow?kind=soql&limit=10&since=2h",
+			wantStatus: http.StatusOK,
+			wantKind:   querylog.KindSOQL,
+			wantLimit:  10,
+			wantSince:  2 * time.Hour,
+		},
+		{
+			name:       "queries by user",
+			url:        "/api/v1/admin/query-log/users/" + userID.String() + "?object=Deal",
+			wantStatus: http.StatusOK,
+			wantObject: "Deal",
+			wantUser:   userID,
+		},
+		{
+			name:       "invalid since",
+			url:        "/api/v1/admin/query-log/slow?since=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid user id",
+			url:        "/api/v1/admin/query-log/users/not-a-uuid",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &stubQueryLogService{}
+			router := setupQueryLogRouter(t, svc)
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.wantKind, svc.filter.Kind)
+			assert.Equal(t, tt.wantLimit, svc.filter.Limit)
+			assert.Equal(t, tt.wantObject, svc.filter.Object)
+			assert.Equal(t, tt.wantUser, svc.userID)
+			if tt.wantSince > 0 {
+				assert.WithinDuration(t, time.Now().Add(-tt.wantSince), svc.filter.Since, time.Minute)
+			} else {
+				assert.True(t, svc.filter.Since.IsZero())
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -13,18 +15,34 @@ import (
 // RecordHandler handles generic CRUD operations for any object.
 type RecordHandler struct {
 	recordService service.RecordService
+	idempotent    gin.HandlerFunc
+}
+
+// RecordHandlerOption configures a RecordHandler.
+type RecordHandlerOption func(*RecordHandler)
+
+// WithRecordIdempotency runs mw, the Idempotency-Key middleware, in front of
+// record creation.
+func WithRecordIdempotency(mw gin.HandlerFunc) RecordHandlerOption {
+	return func(h *RecordHandler) {
+		h.idempotent = mw
+	}
 }
 
 // NewRecordHandler creates a new RecordHandler.
-func NewRecordHandler(recordService service.RecordService) *RecordHandler {
-	return &RecordHandler{recordService: recordService}
+func NewRecordHandler(recordService service.RecordService, opts ...RecordHandlerOption) *RecordHandler {
+	h := &RecordHandler{recordService: recordService, idempotent: passThrough}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // RegisterRoutes registers record CRUD routes on the given group.
 func (h *RecordHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.GET("/records/:objectName", h.ListRecords)
 	rg.GET("/records/:objectName/:recordId", h.GetRecord)
-	rg.POST("/records/:objectName", h.CreateRecord)
+	rg.POST("/records/:objectName", h.idempotent, h.CreateRecord)
 	rg.PUT("/records/:objectName/:recordId", h.UpdateRecord)
 	rg.DELETE("/records/:objectName/:recordId", h.DeleteRecord)
 }
@@ -62,6 +80,9 @@ func (h *RecordHandler) GetRecord(c *gin.Context) {
 		return
 	}
 
+	if version, ok := record["SystemVersion"]; ok && version != nil {
+		c.Header("ETag", fmt.Sprintf(`"%v"`, version))
+	}
 	c.JSON(http.StatusOK, gin.H{"data": record})
 }
 
@@ -89,13 +110,19 @@ func (h *RecordHandler) UpdateRecord(c *gin.Context) {
 	objectName := c.Param("objectName")
 	recordID := c.Param("recordId")
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
 	var fields map[string]any
 	if err := c.ShouldBindJSON(&fields); err != nil {
 		apperror.Respond(c, apperror.BadRequest("invalid request body"))
 		return
 	}
 
-	if err := h.recordService.Update(c.Request.Context(), objectName, recordID, fields); err != nil {
+	if err := h.recordService.Update(c.Request.Context(), objectName, recordID, fields, version); err != nil {
 		apperror.Respond(c, err)
 		return
 	}
@@ -108,10 +135,30 @@ func (h *RecordHandler) DeleteRecord(c *gin.Context) {
 	objectName := c.Param("objectName")
 	recordID := c.Param("recordId")
 
-	if err := h.recordService.Delete(c.Request.Context(), objectName, recordID); err != nil {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	if err := h.recordService.Delete(c.Request.Context(), objectName, recordID, version); err != nil {
 		apperror.Respond(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// ifMatchVersion returns the record version of the If-Match header: the ETag
+// of GetRecord. It returns 0 when the header is absent or "*".
+func ifMatchVersion(c *gin.Context) (int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, apperror.BadRequest(fmt.Sprintf("invalid If-Match header: %s", header))
+	}
+	return version, nil
+}
@@ -4,13 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/adverax/crm/internal/middleware"
 	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/idempotency"
 	"github.com/adverax/crm/internal/service"
 )
 
@@ -18,8 +27,8 @@ type mockRecordService struct {
 	listFn    func(ctx context.Context, objectName string, params service.ListParams) (*service.RecordListResult, error)
 	getByIDFn func(ctx context.Context, objectName string, recordID string) (map[string]any, error)
 	createFn  func(ctx context.Context, objectName string, fields map[string]any) (*service.CreateResult, error)
-	updateFn  func(ctx context.Context, objectName string, recordID string, fields map[string]any) error
-	deleteFn  func(ctx context.Context, objectName string, recordID string) error
+	updateFn  func(ctx context.Context, objectName string, recordID string, fields map[string]any, version int64) error
+	deleteFn  func(ctx context.Context, objectName string, recordID string, version int64) error
 }
 
 func (m *mockRecordService) List(ctx context.Context, objectName string, params service.ListParams) (*service.RecordListResult, error) {
@@ -43,16 +52,16 @@ func (m *mockRecordService) Create(ctx context.Context, objectName string, field
 	return &service.CreateResult{ID: "new-id"}, nil
 }
 
-func (m *mockRecordService) Update(ctx context.Context, objectName string, recordID string, fields map[string]any) error {
+func (m *mockRecordService) Update(ctx context.Context, objectName string, recordID string, fields map[string]any, version int64) error {
 	if m.updateFn != nil {
-		return m.updateFn(ctx, objectName, recordID, fields)
+		return m.updateFn(ctx, objectName, recordID, fields, version)
 	}
 	return nil
 }
 
-func (m *mockRecordService) Delete(ctx context.Context, objectName string, recordID string) error {
+func (m *mockRecordService) Delete(ctx context.Context, objectName string, recordID string, version int64) error {
 	if m.deleteFn != nil {
-		return m.deleteFn(ctx, objectName, recordID)
+		return m.deleteFn(ctx, objectName, recordID, version)
 	}
 	return nil
 }
@@ -137,6 +146,7 @@ func TestRecordHandler_GetRecord(t *testing.T) {
 		url        string
 		setupSvc   func(*mockRecordService)
 		wantStatus int
+		wantETag   string
 	}{
 		{
 			name: "returns record successfully",
@@ -148,6 +158,17 @@ func TestRecordHandler_GetRecord(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name: "returns version as ETag",
+			url:  "/api/v1/records/Account/some-id",
+			setupSvc: func(m *mockRecordService) {
+				m.getByIDFn = func(_ context.Context, _ string, _ string) (map[string]any, error) {
+					return map[string]any{"Id": "some-id", "SystemVersion": int64(3)}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"3"`,
+		},
 		{
 			name: "returns 404 when not found",
 			url:  "/api/v1/records/Account/missing-id",
@@ -187,6 +208,9 @@ func TestRecordHandler_GetRecord(t *testing.T) {
 			if w.Code != tt.wantStatus {
 				t.Errorf("status = %d, want %d, body: %s", w.Code, tt.wantStatus, w.Body.String())
 			}
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
 		})
 	}
 }
@@ -250,12 +274,178 @@ func TestRecordHandler_CreateRecord(t *testing.T) {
 	}
 }
 
+// memoryIdempotencyStore is an in-memory idempotency.Store.
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]idempotency.Entry
+}
+
+func (s *memoryIdempotencyStore) Claim(_ context.Context, userID uuid.UUID, key string, claim idempotency.Entry, since, staleSince time.Time) (*idempotency.Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := userID.String() + ":" + key
+	if e, ok := s.entries[id]; ok && !e.CreatedAt.Before(since) &&
+		(e.Status == idempotency.StatusCompleted || !e.CreatedAt.Before(staleSince)) {
+		return &e, nil
+	}
+	s.entries[id] = claim
+	return nil, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, userID uuid.UUID, key string, claimedAt time.Time, resp idempotency.Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := userID.String() + ":" + key
+	if e, ok := s.entries[id]; ok && e.CreatedAt.Equal(claimedAt) {
+		e.Status = idempotency.StatusCompleted
+		e.Response = resp
+		s.entries[id] = e
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, userID uuid.UUID, key string, claimedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := userID.String() + ":" + key
+	if e, ok := s.entries[id]; ok && e.CreatedAt.Equal(claimedAt) && e.Status == idempotency.StatusInProgress {
+		delete(s.entries, id)
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Purge(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestRecordHandler_CreateRecordIdempotency(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		key        string
+		body       string
+		wantStatus int
+		wantBody   string
+		replayed   bool
+	}
+	tests := []struct {
+		name            string
+		inProgress      string
+		failAfterCommit bool
+		requests        []request
+		wantCalls       int
+	}{
+		{
+			name: "retry replays the first response",
+			requests: []request{
+				{key: "k1", body: `{"Name":"Acme"}`, wantStatus: http.StatusCreated, wantBody: `{"data":{"id":"id-1"}}`},
+				{key: "k1", body: `{"Name":"Acme"}`, wantStatus: http.StatusCreated, wantBody: `{"data":{"id":"id-1"}}`, replayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "different payload with the same key",
+			requests: []request{
+				{key: "k1", body: `{"Name":"Acme"}`, wantStatus: http.StatusCreated},
+				{key: "k1", body: `{"Name":"Globex"}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "different keys create twice",
+			requests: []request{
+				{key: "k1", body: `{"Name":"Acme"}`, wantStatus: http.StatusCreated, wantBody: `{"data":{"id":"id-1"}}`},
+				{key: "k2", body: `{"Name":"Acme"}`, wantStatus: http.StatusCreated, wantBody: `{"data":{"id":"id-2"}}`},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:       "duplicate of a request still in progress after the wait",
+			inProgress: `{"Name":"Acme"}`,
+			requests: []request{
+				{key: "k1", body: `{"Name":"Acme"}`, wantStatus: http.StatusConflict},
+			},
+			wantCalls: 0,
+		},
+		{
+			name:            "server error after the commit is replayed",
+			failAfterCommit: true,
+			requests: []request{
+				{key: "k1", body: `{"Name":"Acme"}`, wantStatus: http.StatusInternalServerError},
+				{key: "k1", body: `{"Name":"Acme"}`, wantStatus: http.StatusInternalServerError, replayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "no key creates twice",
+			requests: []request{
+				{body: `{"Name":"Acme"}`, wantStatus: http.StatusCreated},
+				{body: `{"Name":"Acme"}`, wantStatus: http.StatusCreated},
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			calls := 0
+			svc := &mockRecordService{
+				createFn: func(ctx context.Context, _ string, _ map[string]any) (*service.CreateResult, error) {
+					calls++
+					if tt.failAfterCommit {
+						idempotency.MarkCommitted(ctx)
+						return nil, errors.New("post-execute hook failed")
+					}
+					return &service.CreateResult{ID: fmt.Sprintf("id-%d", calls)}, nil
+				},
+			}
+			store := &memoryIdempotencyStore{entries: make(map[string]idempotency.Entry)}
+			if tt.inProgress != "" {
+				store.entries[uuid.Nil.String()+":k1"] = idempotency.Entry{
+					RequestHash: idempotency.RequestHash(http.MethodPost, "/api/v1/records/Account", []byte(tt.inProgress)),
+					Status:      idempotency.StatusInProgress,
+					CreatedAt:   time.Now(),
+				}
+			}
+			keys := idempotency.NewService(store, slog.New(slog.NewTextHandler(io.Discard, nil)),
+				idempotency.WithWaitTimeout(50*time.Millisecond))
+			h := NewRecordHandler(svc, WithRecordIdempotency(middleware.Idempotency(keys)))
+			r := setupRecordRouter(t, h)
+
+			for i, rq := range tt.requests {
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest(http.MethodPost, "/api/v1/records/Account", bytes.NewReader([]byte(rq.body)))
+				req.Header.Set("Content-Type", "application/json")
+				if rq.key != "" {
+					req.Header.Set(idempotency.Header, rq.key)
+				}
+				r.ServeHTTP(w, req)
+
+				if w.Code != rq.wantStatus {
+					t.Errorf("request %d: status = %d, want %d, body: %s", i, w.Code, rq.wantStatus, w.Body.String())
+				}
+				if rq.wantBody != "" && w.Body.String() != rq.wantBody {
+					t.Errorf("request %d: body = %s, want %s", i, w.Body.String(), rq.wantBody)
+				}
+				if got := w.Header().Get(idempotency.ReplayedHeader) == "true"; got != rq.replayed {
+					t.Errorf("request %d: replayed = %v, want %v", i, got, rq.replayed)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("service calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
 func TestRecordHandler_UpdateRecord(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name       string
 		body       interface{}
+		ifMatch    string
 		setupSvc   func(*mockRecordService)
 		wantStatus int
 	}{
@@ -263,7 +453,7 @@ func TestRecordHandler_UpdateRecord(t *testing.T) {
 			name: "updates record successfully",
 			body: map[string]any{"Name": "Updated"},
 			setupSvc: func(m *mockRecordService) {
-				m.updateFn = func(_ context.Context, _ string, _ string, _ map[string]any) error {
+				m.updateFn = func(_ context.Context, _ string, _ string, _ map[string]any, _ int64) error {
 					return nil
 				}
 			},
@@ -278,12 +468,46 @@ func TestRecordHandler_UpdateRecord(t *testing.T) {
 			name: "returns 404 from service",
 			body: map[string]any{"Name": "Updated"},
 			setupSvc: func(m *mockRecordService) {
-				m.updateFn = func(_ context.Context, _ string, _ string, _ map[string]any) error {
+				m.updateFn = func(_ context.Context, _ string, _ string, _ map[string]any, _ int64) error {
 					return apperror.NotFound("record", "id")
 				}
 			},
 			wantStatus: http.StatusNotFound,
 		},
+		{
+			name:    "passes If-Match version to service",
+			body:    map[string]any{"Name": "Updated"},
+			ifMatch: `"5"`,
+			setupSvc: func(m *mockRecordService) {
+				m.updateFn = func(_ context.Context, _ string, _ string, _ map[string]any, version int64) error {
+					if version != 5 {
+						return apperror.BadRequest("unexpected version")
+					}
+					return nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "returns 400 for invalid If-Match",
+			body:       map[string]any{"Name": "Updated"},
+			ifMatch:    `W/"5"`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "returns 409 with current values on version conflict",
+			body:    map[string]any{"Name": "Updated"},
+			ifMatch: `"5"`,
+			setupSvc: func(m *mockRecordService) {
+				m.updateFn = func(_ context.Context, _ string, _ string, _ map[string]any, _ int64) error {
+					return apperror.Conflict("changed").WithDetails(map[string]any{
+						"expected": 5,
+						"current":  map[string]any{"Id": "some-id", "SystemVersion": 6},
+					})
+				}
+			},
+			wantStatus: http.StatusConflict,
+		},
 	}
 
 	for _, tt := range tests {
@@ -300,6 +524,9 @@ func TestRecordHandler_UpdateRecord(t *testing.T) {
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest(http.MethodPut, "/api/v1/records/Account/some-id", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			r.ServeHTTP(w, req)
 
 			if w.Code != tt.wantStatus {
@@ -314,13 +541,14 @@ func TestRecordHandler_DeleteRecord(t *testing.T) {
 
 	tests := []struct {
 		name       string
+		ifMatch    string
 		setupSvc   func(*mockRecordService)
 		wantStatus int
 	}{
 		{
 			name: "deletes record successfully",
 			setupSvc: func(m *mockRecordService) {
-				m.deleteFn = func(_ context.Context, _ string, _ string) error {
+				m.deleteFn = func(_ context.Context, _ string, _ string, _ int64) error {
 					return nil
 				}
 			},
@@ -329,7 +557,7 @@ func TestRecordHandler_DeleteRecord(t *testing.T) {
 		{
 			name: "returns 404 from service",
 			setupSvc: func(m *mockRecordService) {
-				m.deleteFn = func(_ context.Context, _ string, _ string) error {
+				m.deleteFn = func(_ context.Context, _ string, _ string, _ int64) error {
 					return apperror.NotFound("record", "id")
 				}
 			},
@@ -338,12 +566,35 @@ func TestRecordHandler_DeleteRecord(t *testing.T) {
 		{
 			name: "returns 403 for forbidden",
 			setupSvc: func(m *mockRecordService) {
-				m.deleteFn = func(_ context.Context, _ string, _ string) error {
+				m.deleteFn = func(_ context.Context, _ string, _ string, _ int64) error {
 					return apperror.Forbidden("not deleteable")
 				}
 			},
 			wantStatus: http.StatusForbidden,
 		},
+		{
+			name:    "any version with If-Match *",
+			ifMatch: "*",
+			setupSvc: func(m *mockRecordService) {
+				m.deleteFn = func(_ context.Context, _ string, _ string, version int64) error {
+					if version != 0 {
+						return apperror.BadRequest("unexpected version")
+					}
+					return nil
+				}
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "returns 409 on version conflict",
+			ifMatch: `"2"`,
+			setupSvc: func(m *mockRecordService) {
+				m.deleteFn = func(_ context.Context, _ string, _ string, _ int64) error {
+					return apperror.Conflict("changed")
+				}
+			},
+			wantStatus: http.StatusConflict,
+		},
 	}
 
 	for _, tt := range tests {
@@ -358,6 +609,9 @@ func TestRecordHandler_DeleteRecord(t *testing.T) {
 
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/records/Account/some-id", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			r.ServeHTTP(w, req)
 
 			if w.Code != tt.wantStatus {
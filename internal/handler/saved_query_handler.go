@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/querylog"
+	"github.com/adverax/crm/internal/platform/soql"
+)
+
+// SavedQueryHandler handles admin CRUD for saved queries and their execution.
+type SavedQueryHandler struct {
+	service      metadata.SavedQueryService
+	queryService soql.QueryService
+}
+
+// NewSavedQueryHandler creates a new SavedQueryHandler.
+func NewSavedQueryHandler(service metadata.SavedQueryService, queryService soql.QueryService) *SavedQueryHandler {
+	return &SavedQueryHandler{service: service, queryService: queryService}
+}
+
+// RegisterRoutes registers saved query routes on admin and public groups.
+func (h *SavedQueryHandler) RegisterRoutes(adminGroup, apiGroup *gin.RouterGroup) {
+	adminGroup.POST("/saved-queries", h.Create)
+	adminGroup.GET("/saved-queries", h.List)
+	adminGroup.GET("/saved-queries/:queryId", h.Get)
+	adminGroup.PUT("/saved-queries/:queryId", h.Update)
+	adminGroup.DELETE("/saved-queries/:queryId", h.Delete)
+	adminGroup.GET("/saved-queries/:queryId/versions", h.ListVersions)
+
+	apiGroup.GET("/queries/:apiName", h.Execute)
+}
+
+type createSavedQueryRequest struct {
+	APIName     string                     `json:"api_name" binding:"required"`
+	Label       string                     `json:"label" binding:"required"`
+	Description string                     `json:"description"`
+	SOQL        string                     `json:"soql" binding:"required"`
+	Params      []metadata.SavedQueryParam `json:"params"`
+}
+
+type updateSavedQueryRequest struct {
+	Label         string                     `json:"label" binding:"required"`
+	Description   string                     `json:"description"`
+	SOQL          string                     `json:"soql" binding:"required"`
+	Params        []metadata.SavedQueryParam `json:"params"`
+	ChangeSummary string                     `json:"change_summary"`
+}
+
+func (h *SavedQueryHandler) Create(c *gin.Context) {
+	var req createSavedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	q, err := h.service.Create(c.Request.Context(), metadata.CreateSavedQueryInput{
+		APIName:     req.APIName,
+		Label:       req.Label,
+		Description: req.Description,
+		SOQL:        req.SOQL,
+		Params:      req.Params,
+	})
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": q})
+}
+
+func (h *SavedQueryHandler) List(c *gin.Context) {
+	queries, err := h.service.ListAll(c.Request.Context())
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": queries})
+}
+
+func (h *SavedQueryHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("queryId"))
+	if err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid saved query ID"))
+		return
+	}
+
+	q, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": q})
+}
+
+func (h *SavedQueryHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("queryId"))
+	if err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid saved query ID"))
+		return
+	}
+
+	var req updateSavedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	q, err := h.service.Update(c.Request.Context(), id, metadata.UpdateSavedQueryInput{
+		Label:         req.Label,
+		Description:   req.Description,
+		SOQL:          req.SOQL,
+		Params:        req.Params,
+		ChangeSummary: req.ChangeSummary,
+	})
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": q})
+}
+
+func (h *SavedQueryHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("queryId"))
+	if err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid saved query ID"))
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *SavedQueryHandler) ListVersions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("queryId"))
+	if err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid saved query ID"))
+		return
+	}
+
+	versions, err := h.service.ListVersions(c.Request.Context(), id)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": versions})
+}
+
+// Execute handles GET /api/v1/queries/:apiName?param=...
+// URL parameters supply the declared parameters of the query; the query
+// runs under the caller's security context like /query.
+func (h *SavedQueryHandler) Execute(c *gin.Context) {
+	q, err := h.service.GetByAPIName(c.Request.Context(), c.Param("apiName"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	binds, err := q.BindValues(c.Request.URL.Query())
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	ctx := querylog.WithSource(c.Request.Context(), "saved_query:"+q.APIName)
+	result, err := h.queryService.Execute(ctx, q.SOQL, &soql.QueryParams{Binds: binds})
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
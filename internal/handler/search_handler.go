@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/soql"
+)
+
+// SearchHandler handles the SOSL search API endpoint.
+type SearchHandler struct {
+	searchService soql.SearchService
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(searchService soql.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// RegisterRoutes registers search routes on the given group.
+func (h *SearchHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/search", h.Search)
+	rg.POST("/search", h.SearchPost)
+}
+
+type searchRequest struct {
+	Search string `json:"search" binding:"required"`
+}
+
+// Search handles GET /api/v1/search?q=FIND...
+func (h *SearchHandler) Search(c *gin.Context) {
+	q := c.Query("q")
+	if q == "" {
+		apperror.Respond(c, apperror.BadRequest("query parameter 'q' is required"))
+		return
+	}
+
+	result, err := h.searchService.Search(c.Request.Context(), q)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SearchPost handles POST /api/v1/search
+func (h *SearchHandler) SearchPost(c *gin.Context) {
+	var req searchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	result, err := h.searchService.Search(c.Request.Context(), req.Search)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/soql"
+)
+
+type mockSearchService struct {
+	searchFn func(ctx context.Context, search string) (*soql.SearchResult, error)
+}
+
+func (m *mockSearchService) Search(ctx context.Context, search string) (*soql.SearchResult, error) {
+	if m.searchFn != nil {
+		return m.searchFn(ctx, search)
+	}
+	return &soql.SearchResult{
+		TotalSize: 1,
+		Groups: []soql.SearchGroup{{
+			Object:    "Account",
+			Fields:    []soql.FieldInfo{{Name: "Name", Type: "string"}, {Name: "search_rank", Type: "float"}},
+			TotalSize: 1,
+			Records:   []map[string]any{{"Name": "Acme", "search_rank": 0.6}},
+		}},
+	}, nil
+}
+
+func setupSearchRouter(t *testing.T, svc *mockSearchService) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(contractValidationMiddleware(t))
+	api := r.Group("/api/v1")
+	h := NewSearchHandler(svc)
+	h.RegisterRoutes(api)
+	return r
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		body       interface{}
+		setupSvc   func(*mockSearchService)
+		wantStatus int
+		wantSearch string
+	}{
+		{
+			name:       "GET returns grouped records",
+			method:     http.MethodGet,
+			query:      "FIND {acme*} RETURNING Account(Name)",
+			wantStatus: http.StatusOK,
+			wantSearch: "FIND {acme*} RETURNING Account(Name)",
+		},
+		{
+			name:       "GET without q returns 400",
+			method:     http.MethodGet,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "POST returns grouped records",
+			method:     http.MethodPost,
+			body:       map[string]string{"search": "FIND {acme}"},
+			wantStatus: http.StatusOK,
+			wantSearch: "FIND {acme}",
+		},
+		{
+			name:       "POST without search returns 400",
+			method:     http.MethodPost,
+			body:       map[string]string{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "service error is propagated",
+			method: http.MethodGet,
+			query:  "FIND {acme}",
+			setupSvc: func(m *mockSearchService) {
+				m.searchFn = func(_ context.Context, _ string) (*soql.SearchResult, error) {
+					return nil, apperror.Forbidden("access denied")
+				}
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotSearch string
+			svc := &mockSearchService{}
+			if tt.setupSvc != nil {
+				tt.setupSvc(svc)
+			} else {
+				defaultSvc := &mockSearchService{}
+				svc.searchFn = func(ctx context.Context, search string) (*soql.SearchResult, error) {
+					gotSearch = search
+					return defaultSvc.Search(ctx, search)
+				}
+			}
+			r := setupSearchRouter(t, svc)
+
+			var req *http.Request
+			if tt.method == http.MethodGet {
+				target := "/api/v1/search"
+				if tt.query != "" {
+					target += "?q=" + url.QueryEscape(tt.query)
+				}
+				req, _ = http.NewRequest(http.MethodGet, target, nil)
+			} else {
+				body, _ := json.Marshal(tt.body)
+				req, _ = http.NewRequest(http.MethodPost, "/api/v1/search", bytes.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code, "body: %s", w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.wantSearch, gotSearch)
+
+			var resp soql.SearchResult
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Len(t, resp.Groups, 1)
+			assert.Equal(t, "Account", resp.Groups[0].Object)
+			assert.Equal(t, 1, resp.TotalSize)
+		})
+	}
+}
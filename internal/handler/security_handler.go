@@ -20,6 +20,7 @@ type SecurityHandler struct {
 	permissionService  security.PermissionService
 	groupService       security.GroupService
 	sharingRuleService security.SharingRuleService
+	orgSettingsService security.OrgSettingsService
 	authService        auth.Service
 }
 
@@ -32,6 +33,7 @@ func NewSecurityHandler(
 	permissionService security.PermissionService,
 	groupService security.GroupService,
 	sharingRuleService security.SharingRuleService,
+	orgSettingsService security.OrgSettingsService,
 	authService auth.Service,
 ) *SecurityHandler {
 	return &SecurityHandler{
@@ -42,6 +44,7 @@ func NewSecurityHandler(
 		permissionService:  permissionService,
 		groupService:       groupService,
 		sharingRuleService: sharingRuleService,
+		orgSettingsService: orgSettingsService,
 		authService:        authService,
 	}
 }
@@ -101,6 +104,9 @@ func (h *SecurityHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	sec.GET("/sharing-rules/:id", h.GetSharingRule)
 	sec.PUT("/sharing-rules/:id", h.UpdateSharingRule)
 	sec.DELETE("/sharing-rules/:id", h.DeleteSharingRule)
+
+	sec.GET("/org-settings", h.GetOrgSettings)
+	sec.PUT("/org-settings", h.UpdateOrgSettings)
 }
 
 // --- Roles ---
@@ -800,3 +806,28 @@ func paginationMeta(page, perPage int32, total int64) gin.H {
 		"total_pages": totalPages,
 	}
 }
+
+// --- Org Settings ---
+
+func (h *SecurityHandler) GetOrgSettings(c *gin.Context) {
+	settings, err := h.orgSettingsService.Get(c.Request.Context())
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+func (h *SecurityHandler) UpdateOrgSettings(c *gin.Context) {
+	var req security.UpdateOrgSettingsInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid request body"))
+		return
+	}
+	settings, err := h.orgSettingsService.Update(c.Request.Context(), req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
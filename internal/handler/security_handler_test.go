@@ -398,6 +398,21 @@ func (m *mockSharingRuleService) Delete(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
+type mockOrgSettingsService struct{}
+
+func (m *mockOrgSettingsService) Get(_ context.Context) (*security.OrgSettings, error) {
+	settings := security.DefaultOrgSettings
+	return &settings, nil
+}
+
+func (m *mockOrgSettingsService) Update(_ context.Context, input security.UpdateOrgSettingsInput) (*security.OrgSettings, error) {
+	return &security.OrgSettings{
+		DefaultTimeZone:      input.DefaultTimeZone,
+		DefaultLocale:        input.DefaultLocale,
+		FiscalYearStartMonth: input.FiscalYearStartMonth,
+	}, nil
+}
+
 // --- Helpers ---
 
 func setupSecurityRouter(t *testing.T, h *SecurityHandler) *gin.Engine {
@@ -440,7 +455,7 @@ func newSecurityHandler(
 	if sharing == nil {
 		sharing = &mockSharingRuleService{}
 	}
-	return NewSecurityHandler(roles, ps, profiles, users, perms, groups, sharing, &mockAuthService{})
+	return NewSecurityHandler(roles, ps, profiles, users, perms, groups, sharing, &mockOrgSettingsService{}, &mockAuthService{})
 }
 
 // --- Tests ---
@@ -972,3 +987,49 @@ func TestSecurityHandler_ListSharingRules(t *testing.T) {
 		})
 	}
 }
+
+func TestSecurityHandler_OrgSettings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "gets settings",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "updates settings",
+			method:     http.MethodPut,
+			body:       `{"default_time_zone":"America/Chicago","default_locale":"en_US","fiscal_year_start_month":7}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects malformed body",
+			method:     http.MethodPut,
+			body:       `{"fiscal_year_start_month":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := newSecurityHandler(nil, nil, nil, nil, nil, nil, nil)
+			r := setupSecurityRouter(t, h)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, "/api/v1/admin/security/org-settings", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -140,6 +140,18 @@ func (r *testMetadataReader) GetSharedLayoutByAPIName(_ string) (metadata.Shared
 	return metadata.SharedLayout{}, false
 }
 
+func (r *testMetadataReader) GetSavedQueryByAPIName(_ string) (metadata.SavedQuery, bool) {
+	return metadata.SavedQuery{}, false
+}
+
+func (r *testMetadataReader) GetPicklistValues(_ uuid.UUID) []metadata.PicklistValueRow {
+	return nil
+}
+
+func (r *testMetadataReader) GetTranslations(_ string, _ uuid.UUID, _ string) map[string]string {
+	return nil
+}
+
 var testAccountID = uuid.MustParse("11111111-1111-1111-1111-111111111111")
 
 func newTestCRMMetadata() *testMetadataReader {
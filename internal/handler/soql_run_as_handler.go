@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/middleware"
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/soql"
+)
+
+// SOQLRunAsHandler runs SOQL queries as another user for sharing diagnostics.
+type SOQLRunAsHandler struct {
+	service soql.RunAsService
+}
+
+// NewSOQLRunAsHandler creates a new SOQLRunAsHandler.
+func NewSOQLRunAsHandler(service soql.RunAsService) *SOQLRunAsHandler {
+	return &SOQLRunAsHandler{service: service}
+}
+
+// RegisterRoutes registers the run-as route on the admin group. Running
+// queries as another user exposes that user's data, so the route is limited
+// to system administrators.
+func (h *SOQLRunAsHandler) RegisterRoutes(admin *gin.RouterGroup) {
+	admin.POST("/soql/run-as", middleware.RequireProfile(security.SystemAdminProfileID), h.RunAs)
+}
+
+type soqlRunAsRequest struct {
+	UserID   string         `json:"user_id" binding:"required"`
+	Query    string         `json:"query" binding:"required"`
+	PageSize int            `json:"pageSize"`
+	Binds    map[string]any `json:"binds"`
+}
+
+// RunAs handles POST /api/v1/admin/soql/run-as.
+// Executes a SOQL query with the OLS, FLS and RLS of the given user.
+func (h *SOQLRunAsHandler) RunAs(c *gin.Context) {
+	var req soqlRunAsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid request body"))
+		return
+	}
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		apperror.Respond(c, apperror.BadRequest("invalid user ID"))
+		return
+	}
+
+	params := &soql.QueryParams{PageSize: req.PageSize, Binds: req.Binds}
+	result, err := h.service.RunAs(c.Request.Context(), userID, req.Query, params)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
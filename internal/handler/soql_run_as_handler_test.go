@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/middleware"
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/soql"
+)
+
+type stubRunAsService struct {
+	userID uuid.UUID
+	query  string
+	params *soql.QueryParams
+}
+
+func (s *stubRunAsService) RunAs(_ context.Context, userID uuid.UUID, query string, params *soql.QueryParams) (*soql.RunAsResult, error) {
+	s.userID, s.query, s.params = userID, query, params
+	if query == "SELECT Amount FROM Deal" {
+		return nil, apperror.Forbidden("user cannot run the query: access denied to field: Deal.Amount")
+	}
+	return &soql.RunAsResult{
+		QueryResult: soql.QueryResult{
+			TotalSize: 1,
+			Done:      true,
+			Records:   []map[string]any{{"Id": uuid.NewString(), "Name": "Acme"}},
+		},
+		UserID:          userID,
+		StrippedFields:  []string{"Deal.Margin"},
+		RLSFilteredRows: 4,
+	}, nil
+}
+
+func setupSOQLRunAsRouter(t *testing.T, svc soql.RunAsService, profileID uuid.UUID) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(contractValidationMiddleware(t))
+	r.Use(func(c *gin.Context) {
+		middleware.SetUserContext(c, security.UserContext{UserID: uuid.New(), ProfileID: profileID})
+		c.Next()
+	})
+	h := NewSOQLRunAsHandler(svc)
+	h.RegisterRoutes(r.Group("/api/v1/admin"))
+	return r
+}
+
+func TestSOQLRunAsHandler_RunAs(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		body       map[string]any
+		wantStatus int
+	}{
+		{
+			name:       "runs the query as the user",
+			body:       map[string]any{"user_id": userID.String(), "query": "SELECT FIELDS(ALL) FROM Deal", "pageSize": 10},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "field hidden from the user",
+			body:       map[string]any{"user_id": userID.String(), "query": "SELECT Amount FROM Deal"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid user id",
+			body:       map[string]any{"user_id": "anna", "query": "SELECT Id FROM Deal"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing query",
+			body:       map[string]any{"user_id": userID.String()},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &stubRunAsService{}
+			router := setupSOQLRunAsRouter(t, svc, security.SystemAdminProfileID)
+
+			body, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/soql/run-as", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, userID, svc.userID)
+			assert.Equal(t, 10, svc.params.PageSize)
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, []any{"Deal.Margin"}, resp["strippedFields"])
+			assert.Equal(t, float64(4), resp["rlsFilteredRows"])
+			assert.Equal(t, float64(1), resp["totalSize"])
+		})
+	}
+}
+
+func TestSOQLRunAsHandler_RunAsRequiresAdmin(t *testing.T) {
+	t.Parallel()
+
+	svc := &stubRunAsService{}
+	router := setupSOQLRunAsRouter(t, svc, uuid.New())
+
+	body, err := json.Marshal(map[string]any{"user_id": uuid.NewString(), "query": "SELECT Id FROM Deal"})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/soql/run-as", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	assert.Empty(t, svc.query)
+}
@@ -3,7 +3,6 @@ package handler
 import (
 	"fmt"
 	"net/http"
-	"regexp"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -11,11 +10,10 @@ import (
 	"github.com/adverax/crm/internal/pkg/apperror"
 	"github.com/adverax/crm/internal/platform/dml"
 	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/querylog"
 	"github.com/adverax/crm/internal/platform/soql"
 )
 
-var paramRegexp = regexp.MustCompile(`:(\w+)`)
-
 // ViewHandler serves resolved Object View configs, per-query data, and action execution.
 type ViewHandler struct {
 	cache       metadata.MetadataReader
@@ -76,9 +74,6 @@ func (h *ViewHandler) ExecuteQuery(c *gin.Context) {
 		return
 	}
 
-	// Substitute URL query params into SOQL :paramName placeholders
-	soqlText := substituteParams(query.SOQL, c)
-
 	// Parse pagination
 	perPage := 20
 	if pp := c.Query("per_page"); pp != "" {
@@ -87,8 +82,10 @@ func (h *ViewHandler) ExecuteQuery(c *gin.Context) {
 		}
 	}
 
-	result, err := h.soqlService.Execute(c.Request.Context(), soqlText, &soql.QueryParams{
+	ctx := querylog.WithSource(c.Request.Context(), "view:"+ovAPIName+"/query:"+queryName)
+	result, err := h.soqlService.Execute(ctx, query.SOQL, &soql.QueryParams{
 		PageSize: perPage,
+		Binds:    queryBinds(c),
 	})
 	if err != nil {
 		apperror.Respond(c, fmt.Errorf("viewHandler.ExecuteQuery: %w", err))
@@ -160,7 +157,8 @@ func (h *ViewHandler) ExecuteAction(c *gin.Context) {
 	}
 
 	// Execute DML batch
-	results, err := h.dmlService.ExecuteBatch(c.Request.Context(), action.Apply.DML)
+	ctx := querylog.WithSource(c.Request.Context(), "view:"+ovAPIName+"/action:"+actionKey)
+	results, err := h.dmlService.ExecuteBatch(ctx, action.Apply.DML)
 	if err != nil {
 		apperror.Respond(c, fmt.Errorf("viewHandler.ExecuteAction: %w", err))
 		return
@@ -188,13 +186,17 @@ func (h *ViewHandler) ExecuteAction(c *gin.Context) {
 	})
 }
 
-// substituteParams replaces :paramName in SOQL with URL query parameter values.
-func substituteParams(soqlText string, c *gin.Context) string {
-	return paramRegexp.ReplaceAllStringFunc(soqlText, func(match string) string {
-		paramName := match[1:] // strip leading ':'
-		if val := c.Query(paramName); val != "" {
-			return "'" + val + "'"
+// queryBinds collects URL query parameters as values for SOQL :paramName
+// bind variables. A repeated parameter (?ids=a&ids=b) becomes a list for IN :ids.
+func queryBinds(c *gin.Context) map[string]any {
+	values := c.Request.URL.Query()
+	binds := make(map[string]any, len(values))
+	for name, v := range values {
+		if len(v) == 1 {
+			binds[name] = v[0]
+		} else {
+			binds[name] = v
 		}
-		return match
-	})
+	}
+	return binds
 }
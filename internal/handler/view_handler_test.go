@@ -60,6 +60,15 @@ func (l *ovCacheLoaderForView) LoadAllLayouts(_ context.Context) ([]metadata.Lay
 func (l *ovCacheLoaderForView) LoadAllSharedLayouts(_ context.Context) ([]metadata.SharedLayout, error) {
 	return nil, nil
 }
+func (l *ovCacheLoaderForView) LoadAllSavedQueries(_ context.Context) ([]metadata.SavedQuery, error) {
+	return nil, nil
+}
+func (l *ovCacheLoaderForView) LoadAllPicklistValues(_ context.Context) ([]metadata.PicklistValueRow, error) {
+	return nil, nil
+}
+func (l *ovCacheLoaderForView) LoadAllTranslations(_ context.Context) ([]metadata.Translation, error) {
+	return nil, nil
+}
 func (l *ovCacheLoaderForView) RefreshMaterializedView(_ context.Context) error {
 	return nil
 }
@@ -147,6 +156,8 @@ func TestViewHandler_GetByAPIName(t *testing.T) {
 type mockSOQLService struct {
 	executeFn  func(ctx context.Context, query string, params *soql.QueryParams) (*soql.QueryResult, error)
 	describeFn func(ctx context.Context, query string) (*soql.DescribeResult, error)
+	explainFn  func(ctx context.Context, query string, params *soql.QueryParams) (*soql.ExplainResult, error)
+	streamFn   func(ctx context.Context, query string, binds map[string]any, w soql.RecordWriter) (int, error)
 }
 
 func (m *mockSOQLService) Execute(ctx context.Context, query string, params *soql.QueryParams) (*soql.QueryResult, error) {
@@ -156,6 +167,10 @@ func (m *mockSOQLService) Execute(ctx context.Context, query string, params *soq
 	return &soql.QueryResult{}, nil
 }
 
+func (m *mockSOQLService) ExecuteNext(_ context.Context, _ string) (*soql.QueryResult, error) {
+	return &soql.QueryResult{Done: true}, nil
+}
+
 func (m *mockSOQLService) Describe(ctx context.Context, query string) (*soql.DescribeResult, error) {
 	if m.describeFn != nil {
 		return m.describeFn(ctx, query)
@@ -163,6 +178,20 @@ func (m *mockSOQLService) Describe(ctx context.Context, query string) (*soql.Des
 	return &soql.DescribeResult{}, nil
 }
 
+func (m *mockSOQLService) Explain(ctx context.Context, query string, params *soql.QueryParams) (*soql.ExplainResult, error) {
+	if m.explainFn != nil {
+		return m.explainFn(ctx, query, params)
+	}
+	return &soql.ExplainResult{}, nil
+}
+
+func (m *mockSOQLService) Stream(ctx context.Context, query string, binds map[string]any, w soql.RecordWriter) (int, error) {
+	if m.streamFn != nil {
+		return m.streamFn(ctx, query, binds, w)
+	}
+	return 0, nil
+}
+
 func TestViewHandler_ExecuteQuery(t *testing.T) {
 	t.Parallel()
 
@@ -198,7 +227,8 @@ func TestViewHandler_ExecuteQuery(t *testing.T) {
 			ovs:       []metadata.ObjectView{testOV},
 			setupSOQL: func(m *mockSOQLService) {
 				m.executeFn = func(_ context.Context, query string, params *soql.QueryParams) (*soql.QueryResult, error) {
-					assert.Contains(t, query, "'abc-123'")
+					assert.Contains(t, query, "Id = :id", "URL values must not be spliced into SOQL")
+					assert.Equal(t, "abc-123", params.Binds["id"])
 					return &soql.QueryResult{
 						TotalSize: 1,
 						Done:      true,
@@ -226,6 +256,20 @@ func TestViewHandler_ExecuteQuery(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:      "repeated parameter binds a list",
+			ovAPIName: "account_view",
+			queryName: "contacts",
+			queryStr:  "id=a1&id=a2",
+			ovs:       []metadata.ObjectView{testOV},
+			setupSOQL: func(m *mockSOQLService) {
+				m.executeFn = func(_ context.Context, _ string, params *soql.QueryParams) (*soql.QueryResult, error) {
+					assert.Equal(t, []string{"a1", "a2"}, params.Binds["id"])
+					return &soql.QueryResult{Done: true}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
 		{
 			name:       "returns 404 for unknown OV",
 			ovAPIName:  "nonexistent",
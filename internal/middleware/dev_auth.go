@@ -50,6 +50,8 @@ func DevAuth(userRepo security.UserRepository, defaultUserID uuid.UUID) gin.Hand
 			UserID:    user.ID,
 			ProfileID: user.ProfileID,
 			RoleID:    user.RoleID,
+			TimeZone:  user.TimeZone,
+			Locale:    user.Locale,
 		}
 		SetUserContext(c, uc)
 
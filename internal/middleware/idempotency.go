@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/idempotency"
+)
+
+// Idempotency runs a request carrying an Idempotency-Key header at most once
+// per key and user, replaying the stored response to retries. Requests
+// without the header pass through.
+func Idempotency(svc *idempotency.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotency.Header)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if err := idempotency.ValidateKey(key); err != nil {
+			apperror.Respond(c, err)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apperror.Respond(c, apperror.BadRequest("invalid request body"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := idempotency.RequestHash(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		resp, replayed, err := svc.Do(c.Request.Context(), GetUserID(c), key, hash, func(ctx context.Context) idempotency.Response {
+			c.Request = c.Request.WithContext(ctx)
+			w := &recordingWriter{ResponseWriter: c.Writer}
+			c.Writer = w
+			c.Next()
+			c.Writer = w.ResponseWriter
+			return idempotency.Response{
+				StatusCode:  w.Status(),
+				ContentType: w.Header().Get("Content-Type"),
+				Body:        w.body.Bytes(),
+			}
+		})
+		if err != nil {
+			apperror.Respond(c, err)
+			c.Abort()
+			return
+		}
+		if replayed {
+			c.Header(idempotency.ReplayedHeader, "true")
+			if resp.ContentType != "" {
+				c.Header("Content-Type", resp.ContentType)
+			}
+			c.Status(resp.StatusCode)
+			_, _ = c.Writer.Write(resp.Body)
+			c.Abort()
+		}
+	}
+}
+
+// recordingWriter keeps a copy of the response body it writes.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
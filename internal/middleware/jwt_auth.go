@@ -73,10 +73,15 @@ func JWTAuth(secret []byte) gin.HandlerFunc {
 			}
 		}
 
+		timeZone, _ := claims["tz"].(string)
+		locale, _ := claims["loc"].(string)
+
 		uc := security.UserContext{
 			UserID:    userID,
 			ProfileID: profileID,
 			RoleID:    roleID,
+			TimeZone:  timeZone,
+			Locale:    locale,
 		}
 		SetUserContext(c, uc)
 		c.Request = c.Request.WithContext(security.ContextWithUser(c.Request.Context(), uc))
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+)
+
+// RequireProfile lets through only users with one of profileIDs. Other
+// users get 403, requests without a user 401.
+func RequireProfile(profileIDs ...uuid.UUID) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uc, ok := GetUserContext(c)
+		if !ok || uc.UserID == uuid.Nil {
+			apperror.Respond(c, apperror.Unauthorized("user context required"))
+			c.Abort()
+			return
+		}
+		if !slices.Contains(profileIDs, uc.ProfileID) {
+			apperror.Respond(c, apperror.Forbidden("requires the system administrator profile"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
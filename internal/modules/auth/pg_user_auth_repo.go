@@ -22,7 +22,7 @@ func NewPgUserAuthRepository(pool *pgxpool.Pool) *PgUserAuthRepository {
 func (r *PgUserAuthRepository) GetByUsername(ctx context.Context, username string) (*UserWithPassword, error) {
 	return r.scanUser(r.pool.QueryRow(ctx, `
 		SELECT id, username, email, first_name, last_name, profile_id, role_id,
-		       is_active, password_hash
+		       is_active, time_zone, locale, password_hash
 		FROM iam.users WHERE username = $1
 	`, username))
 }
@@ -30,7 +30,7 @@ func (r *PgUserAuthRepository) GetByUsername(ctx context.Context, username strin
 func (r *PgUserAuthRepository) GetByID(ctx context.Context, id uuid.UUID) (*UserWithPassword, error) {
 	return r.scanUser(r.pool.QueryRow(ctx, `
 		SELECT id, username, email, first_name, last_name, profile_id, role_id,
-		       is_active, password_hash
+		       is_active, time_zone, locale, password_hash
 		FROM iam.users WHERE id = $1
 	`, id))
 }
@@ -38,7 +38,7 @@ func (r *PgUserAuthRepository) GetByID(ctx context.Context, id uuid.UUID) (*User
 func (r *PgUserAuthRepository) GetByEmail(ctx context.Context, email string) (*UserWithPassword, error) {
 	return r.scanUser(r.pool.QueryRow(ctx, `
 		SELECT id, username, email, first_name, last_name, profile_id, role_id,
-		       is_active, password_hash
+		       is_active, time_zone, locale, password_hash
 		FROM iam.users WHERE email = $1
 	`, email))
 }
@@ -60,7 +60,7 @@ func (r *PgUserAuthRepository) scanUser(row pgx.Row) (*UserWithPassword, error)
 	var u UserWithPassword
 	err := row.Scan(
 		&u.ID, &u.Username, &u.Email, &u.FirstName, &u.LastName,
-		&u.ProfileID, &u.RoleID, &u.IsActive, &u.PasswordHash,
+		&u.ProfileID, &u.RoleID, &u.IsActive, &u.TimeZone, &u.Locale, &u.PasswordHash,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -155,6 +155,8 @@ func (s *ServiceImpl) Me(ctx context.Context, userID uuid.UUID) (*UserInfo, erro
 		ProfileID: user.ProfileID,
 		RoleID:    user.RoleID,
 		IsActive:  user.IsActive,
+		TimeZone:  user.TimeZone,
+		Locale:    user.Locale,
 	}, nil
 }
 
@@ -268,6 +270,12 @@ func (s *ServiceImpl) issueTokenPair(ctx context.Context, user *UserWithPassword
 	if user.RoleID != nil {
 		claims["rid"] = user.RoleID.String()
 	}
+	if user.TimeZone != "" {
+		claims["tz"] = user.TimeZone
+	}
+	if user.Locale != "" {
+		claims["loc"] = user.Locale
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	accessToken, err := token.SignedString(s.jwtSecret)
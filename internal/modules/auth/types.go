@@ -69,6 +69,8 @@ type UserInfo struct {
 	ProfileID uuid.UUID  `json:"profile_id"`
 	RoleID    *uuid.UUID `json:"role_id"`
 	IsActive  bool       `json:"is_active"`
+	TimeZone  string     `json:"time_zone"`
+	Locale    string     `json:"locale"`
 }
 
 // UserWithPassword holds user data including password hash for auth checks.
@@ -81,6 +83,8 @@ type UserWithPassword struct {
 	ProfileID    uuid.UUID
 	RoleID       *uuid.UUID
 	IsActive     bool
+	TimeZone     string
+	Locale       string
 	PasswordHash string
 }
 
@@ -89,4 +93,6 @@ type AccessTokenClaims struct {
 	Sub string `json:"sub"`
 	Pid string `json:"pid"`
 	Rid string `json:"rid,omitempty"`
+	Tz  string `json:"tz,omitempty"`
+	Loc string `json:"loc,omitempty"`
 }
@@ -11,18 +11,20 @@ import (
 type Code string
 
 const (
-	CodeNotFound     Code = "NOT_FOUND"
-	CodeBadRequest   Code = "BAD_REQUEST"
-	CodeForbidden    Code = "FORBIDDEN"
-	CodeUnauthorized Code = "UNAUTHORIZED"
-	CodeConflict     Code = "CONFLICT"
-	CodeInternal     Code = "INTERNAL"
-	CodeValidation   Code = "VALIDATION"
+	CodeNotFound      Code = "NOT_FOUND"
+	CodeBadRequest    Code = "BAD_REQUEST"
+	CodeForbidden     Code = "FORBIDDEN"
+	CodeUnauthorized  Code = "UNAUTHORIZED"
+	CodeConflict      Code = "CONFLICT"
+	CodeInternal      Code = "INTERNAL"
+	CodeValidation    Code = "VALIDATION"
+	CodeUnprocessable Code = "UNPROCESSABLE"
 )
 
 type AppError struct {
 	Code       Code   `json:"code"`
 	Message    string `json:"message"`
+	Details    any    `json:"details,omitempty"`
 	HTTPStatus int    `json:"-"`
 }
 
@@ -30,6 +32,13 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// WithDetails returns a copy of e whose response carries details.
+func (e *AppError) WithDetails(details any) *AppError {
+	withDetails := *e
+	withDetails.Details = details
+	return &withDetails
+}
+
 func NotFound(entity string, id string) *AppError {
 	return &AppError{
 		Code:       CodeNotFound,
@@ -86,6 +95,14 @@ func Validation(message string) *AppError {
 	}
 }
 
+func Unprocessable(message string) *AppError {
+	return &AppError{
+		Code:       CodeUnprocessable,
+		Message:    message,
+		HTTPStatus: http.StatusUnprocessableEntity,
+	}
+}
+
 type errorResponse struct {
 	Error errorBody `json:"error"`
 }
@@ -93,6 +110,7 @@ type errorResponse struct {
 type errorBody struct {
 	Code    Code   `json:"code"`
 	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
 }
 
 func Respond(c *gin.Context, err error) {
@@ -102,6 +120,7 @@ func Respond(c *gin.Context, err error) {
 			Error: errorBody{
 				Code:    appErr.Code,
 				Message: appErr.Message,
+				Details: appErr.Details,
 			},
 		})
 		return
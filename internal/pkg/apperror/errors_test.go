@@ -88,6 +88,12 @@ func TestErrorConstructors(t *testing.T) {
 			wantCode:   CodeValidation,
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "Unprocessable",
+			err:        Unprocessable("key reused"),
+			wantCode:   CodeUnprocessable,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -124,6 +130,12 @@ func TestRespond(t *testing.T) {
 			wantStatus: http.StatusNotFound,
 			wantBody:   `{"error":{"code":"NOT_FOUND","message":"Deal with id xyz not found"}}`,
 		},
+		{
+			name:       "details are included",
+			err:        Conflict("record changed").WithDetails(map[string]any{"version": 2}),
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":{"code":"CONFLICT","message":"record changed","details":{"version":2}}}`,
+		},
 		{
 			name:       "non-AppError returns 500",
 			err:        errors.New("unexpected"),
@@ -12,6 +12,10 @@ type Config struct {
 	DB                      DatabaseConfig
 	LogLevel                string
 	JWT                     JWTConfig
+	SOQL                    SOQLConfig
+	QueryLog                QueryLogConfig
+	RecycleBin              RecycleBinConfig
+	Idempotency             IdempotencyConfig
 	AdminInitialPassword    string
 	CredentialEncryptionKey string
 }
@@ -22,6 +26,57 @@ type JWTConfig struct {
 	RefreshTTL time.Duration
 }
 
+type SOQLConfig struct {
+	CursorSecret string
+	CursorTTL    time.Duration
+
+	// MaxEstimatedCost and MaxEstimatedRows reject queries whose planner
+	// estimates exceed them before they run. 0 disables the check.
+	MaxEstimatedCost float64
+	MaxEstimatedRows int
+
+	// ExportTimeout is the time budget of a streamed export and
+	// ExportMaxRecords caps the records it may return.
+	ExportTimeout    time.Duration
+	ExportMaxRecords int
+
+	// ResultCacheTTL enables the result cache for complete query results;
+	// 0 disables it. ResultCacheMaxEntries bounds the number of cached
+	// results and ResultCacheMaxRows the records of a cacheable one.
+	ResultCacheTTL        time.Duration
+	ResultCacheMaxEntries int
+	ResultCacheMaxRows    int
+}
+
+// QueryLogConfig controls the log of SOQL and DML executions.
+type QueryLogConfig struct {
+	Enabled bool
+
+	// MinDuration logs only statements that ran at least that long; 0 logs
+	// every statement. Failed statements are always logged.
+	MinDuration time.Duration
+
+	// Retention is how long entries are kept, rounded up to whole days.
+	Retention time.Duration
+
+	// BufferSize bounds the entries waiting to be written; when it is
+	// exceeded, new entries are dropped.
+	BufferSize int
+}
+
+// RecycleBinConfig controls how long deleted records are kept.
+type RecycleBinConfig struct {
+	// Retention is how long records stay in the recycle bin before they are
+	// purged; 0 keeps them until they are undeleted.
+	Retention time.Duration
+}
+
+// IdempotencyConfig controls how long responses to requests sent with an
+// Idempotency-Key are kept for replay.
+type IdempotencyConfig struct {
+	Retention time.Duration
+}
+
 type DatabaseConfig struct {
 	Host     string
 	Port     int
@@ -29,6 +84,13 @@ type DatabaseConfig struct {
 	Password string
 	Name     string
 	SSLMode  string
+
+	// Replica is an optional read replica serving SOQL queries; nil when
+	// not configured. ReplicaStickyWindow keeps a user's queries on the
+	// primary for that long after their last DML, so they see their own
+	// writes despite replication lag.
+	Replica             *DatabaseConfig
+	ReplicaStickyWindow time.Duration
 }
 
 func (d DatabaseConfig) DSN() string {
@@ -39,27 +101,75 @@ func (d DatabaseConfig) DSN() string {
 }
 
 func Load() Config {
+	db := DatabaseConfig{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnvInt("DB_PORT", 5432),
+		User:     getEnv("DB_USER", "crm"),
+		Password: getEnv("DB_PASSWORD", "crm_secret"),
+		Name:     getEnv("DB_NAME", "crm"),
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		ReplicaStickyWindow: getEnvDuration("DB_REPLICA_STICKY_WINDOW", 5*time.Second),
+	}
+	db.Replica = loadReplica(db)
+
 	return Config{
 		Port:     getEnvInt("PORT", 8080),
 		LogLevel: getEnv("LOG_LEVEL", "info"),
-		DB: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "crm"),
-			Password: getEnv("DB_PASSWORD", "crm_secret"),
-			Name:     getEnv("DB_NAME", "crm"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
-		},
+		DB:       db,
 		JWT: JWTConfig{
 			Secret:     getEnv("JWT_SECRET", ""),
 			AccessTTL:  getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
 			RefreshTTL: getEnvDuration("JWT_REFRESH_TTL", 168*time.Hour),
 		},
+		SOQL: SOQLConfig{
+			CursorSecret: getEnv("SOQL_CURSOR_SECRET", ""),
+			CursorTTL:    getEnvDuration("SOQL_CURSOR_TTL", 15*time.Minute),
+
+			MaxEstimatedCost: getEnvFloat("SOQL_MAX_ESTIMATED_COST", 0),
+			MaxEstimatedRows: getEnvInt("SOQL_MAX_ESTIMATED_ROWS", 0),
+
+			ExportTimeout:    getEnvDuration("SOQL_EXPORT_TIMEOUT", 10*time.Minute),
+			ExportMaxRecords: getEnvInt("SOQL_EXPORT_MAX_RECORDS", 1000000),
+
+			ResultCacheTTL:        getEnvDuration("SOQL_RESULT_CACHE_TTL", 0),
+			ResultCacheMaxEntries: getEnvInt("SOQL_RESULT_CACHE_MAX_ENTRIES", 10000),
+			ResultCacheMaxRows:    getEnvInt("SOQL_RESULT_CACHE_MAX_ROWS", 2000),
+		},
+		QueryLog: QueryLogConfig{
+			Enabled:     getEnvBool("QUERY_LOG_ENABLED", false),
+			MinDuration: getEnvDuration("QUERY_LOG_MIN_DURATION", 0),
+			Retention:   getEnvDuration("QUERY_LOG_RETENTION", 30*24*time.Hour),
+			BufferSize:  getEnvInt("QUERY_LOG_BUFFER_SIZE", 10000),
+		},
+		RecycleBin: RecycleBinConfig{
+			Retention: getEnvDuration("RECYCLE_BIN_RETENTION", 15*24*time.Hour),
+		},
+		Idempotency: IdempotencyConfig{
+			Retention: getEnvDuration("IDEMPOTENCY_KEY_RETENTION", 24*time.Hour),
+		},
 		AdminInitialPassword:    getEnv("ADMIN_INITIAL_PASSWORD", ""),
 		CredentialEncryptionKey: getEnv("CREDENTIAL_ENCRYPTION_KEY", ""),
 	}
 }
 
+// loadReplica reads the read replica settings. The replica is enabled by
+// DB_REPLICA_HOST or DB_REPLICA_NAME; other settings default to the
+// primary's, so a second database on the same server only needs a name.
+func loadReplica(primary DatabaseConfig) *DatabaseConfig {
+	if os.Getenv("DB_REPLICA_HOST") == "" && os.Getenv("DB_REPLICA_NAME") == "" {
+		return nil
+	}
+	return &DatabaseConfig{
+		Host:     getEnv("DB_REPLICA_HOST", primary.Host),
+		Port:     getEnvInt("DB_REPLICA_PORT", primary.Port),
+		User:     getEnv("DB_REPLICA_USER", primary.User),
+		Password: getEnv("DB_REPLICA_PASSWORD", primary.Password),
+		Name:     getEnv("DB_REPLICA_NAME", primary.Name),
+		SSLMode:  getEnv("DB_REPLICA_SSLMODE", primary.SSLMode),
+	}
+}
+
 func getEnv(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -90,3 +200,27 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return n
 }
+
+func getEnvBool(key string, fallback bool) bool {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
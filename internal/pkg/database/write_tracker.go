@@ -0,0 +1,62 @@
+package database
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WriteTracker remembers when each user last changed data, so their reads
+// can be kept on the primary until a read replica has caught up.
+// The state is per process; behind a load balancer it covers the requests
+// served by the instance that handled the write.
+type WriteTracker struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	writes map[uuid.UUID]time.Time
+}
+
+// NewWriteTracker creates a WriteTracker that reports writes younger than window.
+func NewWriteTracker(window time.Duration) *WriteTracker {
+	return &WriteTracker{
+		window: window,
+		now:    time.Now,
+		writes: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// RecordWrite marks that the user has just committed a change.
+func (t *WriteTracker) RecordWrite(userID uuid.UUID) {
+	if userID == uuid.Nil || t.window <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.writes[userID] = t.now()
+	t.prune()
+}
+
+// WroteRecently reports whether the user committed a change within the window.
+func (t *WriteTracker) WroteRecently(userID uuid.UUID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	at, ok := t.writes[userID]
+	return ok && t.now().Sub(at) < t.window
+}
+
+// prune drops expired writes once the map has grown, keeping memory
+// proportional to the users active within the window.
+func (t *WriteTracker) prune() {
+	if len(t.writes) < 1024 {
+		return
+	}
+	now := t.now()
+	for id, at := range t.writes {
+		if now.Sub(at) >= t.window {
+			delete(t.writes, id)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestWriteTracker_WroteRecently(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := now
+	tracker := NewWriteTracker(5 * time.Second)
+	tracker.now = func() time.Time { return clock }
+
+	writer := uuid.New()
+	tracker.RecordWrite(writer)
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		elapsed time.Duration
+		want    bool
+	}{
+		{name: "within the window", userID: writer, elapsed: 4 * time.Second, want: true},
+		{name: "after the window", userID: writer, elapsed: 5 * time.Second, want: false},
+		{name: "user without writes", userID: uuid.New(), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock = now.Add(tt.elapsed)
+			if got := tracker.WroteRecently(tt.userID); got != tt.want {
+				t.Errorf("WroteRecently() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("disabled without a window", func(t *testing.T) {
+		disabled := NewWriteTracker(0)
+		disabled.RecordWrite(writer)
+		if disabled.WroteRecently(writer) {
+			t.Error("WroteRecently() = true, want false")
+		}
+	})
+}
@@ -13,6 +13,11 @@ type UserContext struct {
 	UserID    uuid.UUID
 	ProfileID uuid.UUID
 	RoleID    *uuid.UUID
+
+	// TimeZone (IANA name) and Locale are the user's preferences.
+	// Empty means the organization default applies.
+	TimeZone string
+	Locale   string
 }
 
 type contextKey struct{}
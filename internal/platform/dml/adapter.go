@@ -8,6 +8,7 @@ import (
 
 	"github.com/adverax/crm/internal/platform/dml/engine"
 	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/metadata/ddl"
 	"github.com/adverax/crm/internal/platform/security"
 	"github.com/adverax/crm/internal/platform/security/fls"
 	"github.com/adverax/crm/internal/platform/security/ols"
@@ -35,7 +36,7 @@ func (a *MetadataAdapter) GetObject(_ context.Context, name string) (*engine.Obj
 }
 
 func (a *MetadataAdapter) buildObjectMeta(objDef metadata.ObjectDefinition, fields []metadata.FieldDefinition) *engine.ObjectMeta {
-	b := engine.NewObjectMeta(objDef.APIName, objDef.TableName)
+	b := engine.NewObjectMeta(objDef.APIName, objDef.TableName).SoftDelete().Versioned(ddl.VersionColumn)
 
 	// System fields.
 	b.FieldFull(&engine.FieldMeta{
@@ -56,6 +57,9 @@ func (a *MetadataAdapter) buildObjectMeta(objDef metadata.ObjectDefinition, fiel
 	b.FieldFull(&engine.FieldMeta{
 		Name: "UpdatedById", Column: "updated_by_id", Type: engine.FieldTypeID, Nullable: true, HasDefault: true,
 	})
+	b.FieldFull(&engine.FieldMeta{
+		Name: "SystemVersion", Column: ddl.VersionColumn, Type: engine.FieldTypeInteger, ReadOnly: true, HasDefault: true,
+	})
 
 	// User-defined fields.
 	for _, f := range fields {
@@ -152,13 +156,18 @@ func (ac *WriteAccessControllerAdapter) CanWriteObject(ctx context.Context, obje
 		err = ac.olsEnforcer.CanCreate(ctx, uc.UserID, objDef.ID)
 	case engine.OperationUpdate:
 		err = ac.olsEnforcer.CanUpdate(ctx, uc.UserID, objDef.ID)
-	case engine.OperationDelete:
+	case engine.OperationDelete, engine.OperationUndelete:
 		err = ac.olsEnforcer.CanDelete(ctx, uc.UserID, objDef.ID)
 	case engine.OperationUpsert:
 		// Upsert requires both create and update.
 		if err = ac.olsEnforcer.CanCreate(ctx, uc.UserID, objDef.ID); err == nil {
 			err = ac.olsEnforcer.CanUpdate(ctx, uc.UserID, objDef.ID)
 		}
+	case engine.OperationMerge:
+		// Merge updates the master and deletes the duplicates.
+		if err = ac.olsEnforcer.CanUpdate(ctx, uc.UserID, objDef.ID); err == nil {
+			err = ac.olsEnforcer.CanDelete(ctx, uc.UserID, objDef.ID)
+		}
 	}
 
 	if err != nil {
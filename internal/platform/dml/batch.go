@@ -0,0 +1,206 @@
+package dml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/dml/engine"
+)
+
+// BatchResult is the outcome of a batch executed with allOrNone=false.
+type BatchResult struct {
+	Results      []RowResult `json:"results"`
+	SuccessCount int         `json:"successCount"`
+	FailureCount int         `json:"failureCount"`
+}
+
+// RowResult is the outcome of one statement of a batch, or of one row of a
+// multi-row INSERT.
+type RowResult struct {
+	// Statement is the index of the statement in the batch.
+	Statement int `json:"statement"`
+	// Row is the index of the VALUES row of a multi-row INSERT, 0 otherwise.
+	Row     int       `json:"row"`
+	Success bool      `json:"success"`
+	Result  *Result   `json:"result,omitempty"`
+	Error   *RowError `json:"error,omitempty"`
+}
+
+// RowError describes why a row failed.
+type RowError struct {
+	Code    apperror.Code `json:"code"`
+	Message string        `json:"message"`
+}
+
+// partialRow is a row of a partial batch on its way through execution.
+type partialRow struct {
+	result   RowResult
+	text     string
+	compiled *engine.CompiledDML
+	ctx      context.Context
+	started  time.Time
+}
+
+// ExecutePartial executes statements in one transaction with allOrNone=false:
+// every statement, and every row of a multi-row INSERT, runs under its own
+// savepoint. Failed rows are rolled back and reported, the others commit.
+// Post-execute hooks fire for the committed rows only.
+func (s *dmlService) ExecutePartial(ctx context.Context, statements []string) (*BatchResult, error) {
+	// Phase 1: Prepare every row; rows that do not compile fail here.
+	var rows []*partialRow
+	for i, stmt := range statements {
+		ast, err := s.engine.Parse(stmt)
+		if err != nil {
+			stmtCtx, started := s.startLog(ctx)
+			s.logStatement(stmtCtx, stmt, nil, nil, started, err)
+			rows = append(rows, &partialRow{result: failedRow(i, 0, err)})
+			continue
+		}
+		for j, rowAST := range ast.SplitRows() {
+			row := &partialRow{result: RowResult{Statement: i, Row: j}, text: stmt}
+			row.ctx, row.started = s.startLog(ctx)
+			row.compiled, err = s.engine.PrepareAST(row.ctx, rowAST)
+			if err != nil {
+				s.logStatement(row.ctx, stmt, nil, nil, row.started, err)
+				row.result = failedRow(i, j, err)
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	// Phase 2: Execute the prepared rows, each under a savepoint.
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("dmlService.ExecutePartial: begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var committed []*engine.CompiledDML
+	var cascaded Cascaded
+	for _, row := range rows {
+		if row.compiled == nil {
+			continue
+		}
+		r, more, execErr := s.executeSavepoint(row.ctx, tx, row.compiled)
+		s.logStatement(row.ctx, row.text, row.compiled, r, row.started, execErr)
+		if execErr != nil {
+			if errors.Is(execErr, errSavepoint) {
+				return nil, fmt.Errorf("dmlService.ExecutePartial: statement[%d]: %w", row.result.Statement, execErr)
+			}
+			row.result = failedRow(row.result.Statement, row.result.Row, execErr)
+			row.compiled = nil
+			continue
+		}
+		row.result.Success = true
+		row.result.Result = r
+		committed = append(committed, row.compiled)
+		cascaded.add(more)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("dmlService.ExecutePartial: commit: %w", err)
+	}
+	if len(committed) > 0 {
+		s.recordWrite(ctx, cascaded.Objects, committed...)
+	}
+
+	result := &BatchResult{Results: make([]RowResult, len(rows))}
+	for i, row := range rows {
+		result.Results[i] = row.result
+		if row.result.Success {
+			result.SuccessCount++
+		} else {
+			result.FailureCount++
+		}
+	}
+
+	// Phase 3: Fire post-execute hooks for the committed rows.
+	if s.postExecHook != nil {
+		for _, row := range rows {
+			if row.compiled == nil {
+				continue
+			}
+			if hookErr := s.postExecHook.AfterDMLExecute(ctx, row.compiled, row.result.Result); hookErr != nil {
+				return nil, fmt.Errorf("dmlService.ExecutePartial: post-execute[%d]: %w", row.result.Statement, hookErr)
+			}
+		}
+		if hookErr := s.afterCascade(ctx, cascaded); hookErr != nil {
+			return nil, fmt.Errorf("dmlService.ExecutePartial: post-execute: %w", hookErr)
+		}
+	}
+
+	return result, nil
+}
+
+// errSavepoint marks a failure of the savepoint itself, which leaves the
+// transaction unusable.
+var errSavepoint = errors.New("savepoint failed")
+
+// executeSavepoint executes compiled under a savepoint of tx, rolling back
+// to the savepoint when it fails.
+func (s *dmlService) executeSavepoint(ctx context.Context, tx pgx.Tx, compiled *engine.CompiledDML) (*Result, Cascaded, error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, Cascaded{}, fmt.Errorf("%w: %w", errSavepoint, err)
+	}
+	result, cascaded, err := s.executeInTx(ctx, sp, s.executor.WithTx(sp), compiled)
+	if err != nil {
+		if rbErr := sp.Rollback(ctx); rbErr != nil {
+			return nil, Cascaded{}, fmt.Errorf("%w: %w", errSavepoint, rbErr)
+		}
+		return nil, Cascaded{}, err
+	}
+	if err := sp.Commit(ctx); err != nil {
+		return nil, Cascaded{}, fmt.Errorf("%w: %w", errSavepoint, err)
+	}
+	return result, cascaded, nil
+}
+
+// failedRow reports a row that failed with err.
+func failedRow(statement, row int, err error) RowResult {
+	return RowResult{Statement: statement, Row: row, Error: newRowError(err)}
+}
+
+// newRowError maps the error of a row to an error code: VALIDATION for
+// validation rules, BAD_REQUEST for statements that do not parse or
+// validate, FORBIDDEN for access errors and CONFLICT for constraint
+// violations. Other errors are reported without details, like the error
+// responses of the API.
+func newRowError(err error) *RowError {
+	var ruleErr *engine.RuleValidationError
+	if errors.As(err, &ruleErr) {
+		msg := "validation rule failed"
+		if len(ruleErr.Rules) > 0 {
+			msg = ruleErr.Rules[0].Message
+		}
+		return &RowError{Code: apperror.CodeValidation, Message: msg}
+	}
+
+	var parseErr *engine.ParseError
+	if errors.As(err, &parseErr) {
+		return &RowError{Code: apperror.CodeBadRequest, Message: parseErr.Error()}
+	}
+	var limitErr *engine.LimitError
+	if errors.As(err, &limitErr) {
+		return &RowError{Code: apperror.CodeBadRequest, Message: limitErr.Error()}
+	}
+
+	// Integrity constraint violations (class 23) are caused by the row.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && strings.HasPrefix(pgErr.Code, "23") {
+		return &RowError{Code: apperror.CodeConflict, Message: pgErr.Message}
+	}
+
+	var appErr *apperror.AppError
+	if errors.As(mapDMLError(err), &appErr) {
+		return &RowError{Code: appErr.Code, Message: appErr.Message}
+	}
+	return &RowError{Code: apperror.CodeInternal, Message: "internal server error"}
+}
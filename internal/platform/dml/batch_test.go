@@ -0,0 +1,78 @@
+package dml
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/dml/engine"
+)
+
+func TestNewRowError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    apperror.Code
+		wantMessage string
+	}{
+		{
+			name:        "validation rule",
+			err:         &engine.RuleValidationError{Rules: []engine.ValidationRuleError{{Message: "Amount must be positive", Severity: "error"}}},
+			wantCode:    apperror.CodeValidation,
+			wantMessage: "Amount must be positive",
+		},
+		{
+			name:        "validation error",
+			err:         engine.UnknownFieldError("Account", "Unknown"),
+			wantCode:    apperror.CodeBadRequest,
+			wantMessage: engine.UnknownFieldError("Account", "Unknown").Message,
+		},
+		{
+			name:     "access error",
+			err:      engine.NewWriteAccessError("Account", engine.OperationInsert),
+			wantCode: apperror.CodeForbidden,
+		},
+		{
+			name:     "parse error",
+			err:      engine.NewParseError(engine.Position{}, "INSERT", "garbage"),
+			wantCode: apperror.CodeBadRequest,
+		},
+		{
+			name: "unique violation",
+			err: engine.NewExecutionError("failed to execute DML",
+				&pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}),
+			wantCode:    apperror.CodeConflict,
+			wantMessage: "duplicate key value violates unique constraint",
+		},
+		{
+			name:        "merge record not found",
+			err:         fmt.Errorf("wrapped: %w", apperror.NotFound("Account", "a-1")),
+			wantCode:    apperror.CodeNotFound,
+			wantMessage: "Account with id a-1 not found",
+		},
+		{
+			name:        "other errors are not disclosed",
+			err:         engine.NewExecutionError("failed to execute DML", errors.New("connection reset")),
+			wantCode:    apperror.CodeInternal,
+			wantMessage: "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := newRowError(tt.err)
+			assert.Equal(t, tt.wantCode, got.Code)
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, got.Message)
+			}
+			assert.NotEmpty(t, got.Message)
+		})
+	}
+}
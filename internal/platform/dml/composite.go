@@ -0,0 +1,252 @@
+package dml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/dml/engine"
+)
+
+// MaxCompositeRequests is the maximum number of sub-requests of a composite request.
+const MaxCompositeRequests = 25
+
+// SubRequest is a statement of a composite request. Later statements refer
+// to the record it wrote as @{referenceId.field}.
+type SubRequest struct {
+	ReferenceID string `json:"referenceId"`
+	Statement   string `json:"statement"`
+}
+
+// SubResult is the result of a sub-request.
+type SubResult struct {
+	ReferenceID string  `json:"referenceId"`
+	Result      *Result `json:"result"`
+}
+
+// ReadAccessController checks read access to the fields that references
+// copy from one record to another.
+type ReadAccessController interface {
+	CanAccessObject(ctx context.Context, object string) error
+	CanAccessField(ctx context.Context, object, field string) error
+}
+
+var (
+	validReferenceID = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	referencePattern = regexp.MustCompile(`@\{([A-Za-z_][A-Za-z0-9_]*)\.([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+	// referenceOrQuoted matches a reference, or a string literal or quoted
+	// identifier, which is kept as written even if it spells a reference.
+	referenceOrQuoted = regexp.MustCompile(`'(?:''|[^'])*'|"(?:\\.|[^"])*"|` + referencePattern.String())
+)
+
+// executed is a sub-request that has run in the composite transaction.
+type executed struct {
+	compiled *engine.CompiledDML
+	result   *Result
+}
+
+// ExecuteComposite executes sub-requests in order in a single transaction.
+// Before a statement is prepared, each @{ref.field} in it is replaced by the
+// literal value of field of the record written by the earlier sub-request
+// ref. Like ExecuteBatch, the first failure rolls back every sub-request, and
+// post-execute hooks fire after the commit.
+func (s *dmlService) ExecuteComposite(ctx context.Context, requests []SubRequest) ([]SubResult, error) {
+	if err := validateSubRequests(requests); err != nil {
+		return nil, fmt.Errorf("dmlService.ExecuteComposite: %w", err)
+	}
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("dmlService.ExecuteComposite: begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	txExec := s.executor.WithTx(tx)
+	done := make(map[string]*executed, len(requests))
+	compiled := make([]*engine.CompiledDML, len(requests))
+	results := make([]SubResult, len(requests))
+	var cascaded Cascaded
+	for i, req := range requests {
+		stmtCtx, started := s.startLog(ctx)
+		statement, err := s.resolveReferences(stmtCtx, tx, req.Statement, done)
+		if err != nil {
+			s.logStatement(stmtCtx, req.Statement, nil, nil, started, err)
+			return nil, fmt.Errorf("dmlService.ExecuteComposite: %w", subRequestError(req.ReferenceID, err))
+		}
+
+		c, err := s.engine.Prepare(stmtCtx, statement)
+		if err != nil {
+			err = mapDMLError(err)
+			s.logStatement(stmtCtx, statement, nil, nil, started, err)
+			return nil, fmt.Errorf("dmlService.ExecuteComposite: %w", subRequestError(req.ReferenceID, err))
+		}
+
+		r, more, err := s.executeInTx(stmtCtx, tx, txExec, c)
+		s.logStatement(stmtCtx, statement, c, r, started, err)
+		if err != nil {
+			return nil, fmt.Errorf("dmlService.ExecuteComposite: %w", subRequestError(req.ReferenceID, err))
+		}
+		cascaded.add(more)
+		done[req.ReferenceID] = &executed{compiled: c, result: r}
+		compiled[i] = c
+		results[i] = SubResult{ReferenceID: req.ReferenceID, Result: r}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("dmlService.ExecuteComposite: commit: %w", err)
+	}
+	s.recordWrite(ctx, cascaded.Objects, compiled...)
+
+	if s.postExecHook != nil {
+		for i, c := range compiled {
+			if hookErr := s.postExecHook.AfterDMLExecute(ctx, c, results[i].Result); hookErr != nil {
+				return nil, fmt.Errorf("dmlService.ExecuteComposite: post-execute[%s]: %w", requests[i].ReferenceID, hookErr)
+			}
+		}
+		if hookErr := s.afterCascade(ctx, cascaded); hookErr != nil {
+			return nil, fmt.Errorf("dmlService.ExecuteComposite: post-execute: %w", hookErr)
+		}
+	}
+
+	return results, nil
+}
+
+// validateSubRequests checks the size of a composite request and its
+// reference ids.
+func validateSubRequests(requests []SubRequest) error {
+	if len(requests) == 0 {
+		return apperror.BadRequest("composite request has no sub-requests")
+	}
+	if len(requests) > MaxCompositeRequests {
+		return apperror.BadRequest(fmt.Sprintf("composite request has %d sub-requests (max: %d)", len(requests), MaxCompositeRequests))
+	}
+	seen := make(map[string]bool, len(requests))
+	for _, req := range requests {
+		if !validReferenceID.MatchString(req.ReferenceID) {
+			return apperror.BadRequest(fmt.Sprintf("invalid referenceId %q", req.ReferenceID))
+		}
+		if seen[req.ReferenceID] {
+			return apperror.BadRequest(fmt.Sprintf("referenceId %q is used twice", req.ReferenceID))
+		}
+		seen[req.ReferenceID] = true
+		if strings.TrimSpace(req.Statement) == "" {
+			return apperror.BadRequest(fmt.Sprintf("%s: statement is required", req.ReferenceID))
+		}
+	}
+	return nil
+}
+
+// resolveReferences replaces each @{ref.field} of statement with a literal.
+// References inside string literals and quoted identifiers are left alone.
+func (s *dmlService) resolveReferences(ctx context.Context, tx pgx.Tx, statement string, done map[string]*executed) (string, error) {
+	var resolveErr error
+	resolved := referenceOrQuoted.ReplaceAllStringFunc(statement, func(match string) string {
+		if resolveErr != nil || match[0] == '\'' || match[0] == '"' {
+			return match
+		}
+		parts := referencePattern.FindStringSubmatch(match)
+		literal, err := s.resolveReference(ctx, tx, parts[1], parts[2], done)
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return literal
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// resolveReference returns field of the record written by sub-request ref
+// as a DML literal. Fields other than Id are read in tx, so they reflect
+// defaults and automation applied on write, and need read access.
+func (s *dmlService) resolveReference(ctx context.Context, tx pgx.Tx, ref, field string, done map[string]*executed) (string, error) {
+	prev, ok := done[ref]
+	if !ok {
+		return "", apperror.BadRequest(fmt.Sprintf("@{%s.%s}: no earlier sub-request %s", ref, field, ref))
+	}
+	ids := recordIDs(prev.compiled.Operation, prev.result)
+	if len(ids) != 1 {
+		return "", apperror.BadRequest(fmt.Sprintf("@{%s.%s}: sub-request %s wrote %d records, not 1", ref, field, ref, len(ids)))
+	}
+	if strings.EqualFold(field, "id") {
+		return quoteLiteral(ids[0]), nil
+	}
+
+	obj, err := s.engine.GetMetadata().GetObject(ctx, prev.compiled.Object)
+	if err != nil {
+		return "", fmt.Errorf("@{%s.%s}: %w", ref, field, err)
+	}
+	meta := obj.GetField(field)
+	if meta == nil {
+		return "", apperror.BadRequest(fmt.Sprintf("@{%s.%s}: unknown field %s.%s", ref, field, prev.compiled.Object, field))
+	}
+	if s.reads == nil {
+		return "", apperror.BadRequest(fmt.Sprintf("@{%s.%s}: only Id can be referenced", ref, field))
+	}
+	if s.reads.CanAccessObject(ctx, prev.compiled.Object) != nil || s.reads.CanAccessField(ctx, prev.compiled.Object, meta.Name) != nil {
+		return "", apperror.Forbidden(fmt.Sprintf("no read access to %s.%s", prev.compiled.Object, meta.Name))
+	}
+
+	// to_json renders dates, timestamps, numbers and booleans the way DML
+	// literals spell them.
+	var value *string
+	err = tx.QueryRow(ctx,
+		fmt.Sprintf("SELECT to_json(%s) #>> '{}' FROM %s WHERE id = $1", pgx.Identifier{meta.Column}.Sanitize(), prev.compiled.Table),
+		ids[0]).Scan(&value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", apperror.BadRequest(fmt.Sprintf("@{%s.%s}: record %s no longer exists", ref, field, ids[0]))
+	}
+	if err != nil {
+		return "", fmt.Errorf("@{%s.%s}: %w", ref, field, err)
+	}
+	if value == nil {
+		return "NULL", nil
+	}
+	switch meta.Type {
+	case engine.FieldTypeInteger, engine.FieldTypeFloat, engine.FieldTypeBoolean, engine.FieldTypeDate, engine.FieldTypeDateTime:
+		return *value, nil
+	default:
+		return quoteLiteral(*value), nil
+	}
+}
+
+// recordIDs returns the records a statement wrote.
+func recordIDs(op engine.Operation, result *Result) []string {
+	switch op {
+	case engine.OperationInsert, engine.OperationUpsert:
+		return result.InsertedIds
+	case engine.OperationUpdate, engine.OperationMerge:
+		return result.UpdatedIds
+	case engine.OperationDelete:
+		return result.DeletedIds
+	case engine.OperationUndelete:
+		return result.UndeletedIds
+	default:
+		return nil
+	}
+}
+
+// quoteLiteral returns s as a DML string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// subRequestError prefixes the message of err with the reference id of the
+// failed sub-request.
+func subRequestError(ref string, err error) error {
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) {
+		prefixed := *appErr
+		prefixed.Message = ref + ": " + appErr.Message
+		return &prefixed
+	}
+	return fmt.Errorf("%s: %w", ref, err)
+}
@@ -0,0 +1,166 @@
+package dml
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/dml/engine"
+)
+
+func TestValidateSubRequests(t *testing.T) {
+	t.Parallel()
+
+	many := make([]SubRequest, MaxCompositeRequests+1)
+	for i := range many {
+		many[i] = SubRequest{ReferenceID: "r" + strings.Repeat("x", i), Statement: "DELETE FROM Task WHERE Status = 'Done'"}
+	}
+
+	tests := []struct {
+		name     string
+		requests []SubRequest
+		wantErr  bool
+	}{
+		{
+			name: "valid",
+			requests: []SubRequest{
+				{ReferenceID: "newAccount", Statement: "INSERT INTO Account (Name) VALUES ('Acme')"},
+				{ReferenceID: "new_contact1", Statement: "INSERT INTO Contact (AccountId) VALUES (@{newAccount.id})"},
+			},
+		},
+		{name: "empty", wantErr: true},
+		{name: "too many", requests: many, wantErr: true},
+		{
+			name:     "invalid reference id",
+			requests: []SubRequest{{ReferenceID: "new-account", Statement: "INSERT INTO Account (Name) VALUES ('Acme')"}},
+			wantErr:  true,
+		},
+		{
+			name: "reference id used twice",
+			requests: []SubRequest{
+				{ReferenceID: "acc", Statement: "INSERT INTO Account (Name) VALUES ('Acme')"},
+				{ReferenceID: "acc", Statement: "INSERT INTO Account (Name) VALUES ('Globex')"},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "missing statement",
+			requests: []SubRequest{{ReferenceID: "acc", Statement: " "}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateSubRequests(tt.requests)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var appErr *apperror.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, apperror.CodeBadRequest, appErr.Code)
+		})
+	}
+}
+
+func TestResolveReferences(t *testing.T) {
+	t.Parallel()
+
+	done := map[string]*executed{
+		"newAccount": {
+			compiled: &engine.CompiledDML{Operation: engine.OperationInsert, Object: "Account"},
+			result:   &Result{RowsAffected: 1, InsertedIds: []string{"a-1"}},
+		},
+		"closed": {
+			compiled: &engine.CompiledDML{Operation: engine.OperationUpdate, Object: "Deal"},
+			result:   &Result{RowsAffected: 2, UpdatedIds: []string{"d-1", "d-2"}},
+		},
+		"merged": {
+			compiled: &engine.CompiledDML{Operation: engine.OperationMerge, Object: "Account"},
+			result:   &Result{RowsAffected: 2, UpdatedIds: []string{"m-1"}, DeletedIds: []string{"x-1"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		statement string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "id of inserted record",
+			statement: "INSERT INTO Contact (AccountId, LastName) VALUES (@{newAccount.id}, 'Smith')",
+			want:      "INSERT INTO Contact (AccountId, LastName) VALUES ('a-1', 'Smith')",
+		},
+		{
+			name:      "Id is case insensitive and may repeat",
+			statement: "UPDATE Contact SET AccountId = @{newAccount.Id} WHERE AccountId = @{merged.id}",
+			want:      "UPDATE Contact SET AccountId = 'a-1' WHERE AccountId = 'm-1'",
+		},
+		{
+			name:      "references inside string literals are kept",
+			statement: "INSERT INTO Task (DealId, Subject) VALUES (@{newAccount.id}, 'see @{newAccount.id}, it''s @{later.id}')",
+			want:      "INSERT INTO Task (DealId, Subject) VALUES ('a-1', 'see @{newAccount.id}, it''s @{later.id}')",
+		},
+		{
+			name:      "reference after a literal with an escaped quote",
+			statement: "UPDATE Contact SET LastName = 'O''Brien' WHERE AccountId = @{newAccount.id}",
+			want:      "UPDATE Contact SET LastName = 'O''Brien' WHERE AccountId = 'a-1'",
+		},
+		{
+			name:      "statement without references is kept",
+			statement: "DELETE FROM Task WHERE Status = 'Done'",
+			want:      "DELETE FROM Task WHERE Status = 'Done'",
+		},
+		{
+			name:      "unknown reference",
+			statement: "INSERT INTO Contact (AccountId) VALUES (@{later.id})",
+			wantErr:   true,
+		},
+		{
+			name:      "reference to several records",
+			statement: "INSERT INTO Task (DealId) VALUES (@{closed.id})",
+			wantErr:   true,
+		},
+	}
+
+	s := &dmlService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := s.resolveReferences(context.Background(), nil, tt.statement, done)
+			if tt.wantErr {
+				var appErr *apperror.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, apperror.CodeBadRequest, appErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSubRequestError(t *testing.T) {
+	t.Parallel()
+
+	err := subRequestError("newContact", apperror.Forbidden("no access"))
+	var appErr *apperror.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperror.CodeForbidden, appErr.Code)
+	assert.Equal(t, "newContact: no access", appErr.Message)
+
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "'Acme'", quoteLiteral("Acme"))
+	assert.Equal(t, "'O''Brien'", quoteLiteral("O'Brien"))
+}
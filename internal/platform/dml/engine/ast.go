@@ -3,13 +3,15 @@ package engine
 import "github.com/alecthomas/participle/v2/lexer"
 
 // DMLStatement is the root AST node representing a DML statement.
-// Only one of Insert, Update, Delete, or Upsert will be non-nil.
+// Only one of Insert, Update, Delete, Upsert, Undelete, or Merge will be non-nil.
 type DMLStatement struct {
-	Pos    lexer.Position
-	Insert *InsertStatement `parser:"  @@"`
-	Update *UpdateStatement `parser:"| @@"`
-	Delete *DeleteStatement `parser:"| @@"`
-	Upsert *UpsertStatement `parser:"| @@"`
+	Pos      lexer.Position
+	Insert   *InsertStatement   `parser:"  @@"`
+	Update   *UpdateStatement   `parser:"| @@"`
+	Delete   *DeleteStatement   `parser:"| @@"`
+	Upsert   *UpsertStatement   `parser:"| @@"`
+	Undelete *UndeleteStatement `parser:"| @@"`
+	Merge    *MergeStatement    `parser:"| @@"`
 }
 
 // GetOperation returns the operation type of this statement.
@@ -23,6 +25,10 @@ func (s *DMLStatement) GetOperation() Operation {
 		return OperationDelete
 	case s.Upsert != nil:
 		return OperationUpsert
+	case s.Undelete != nil:
+		return OperationUndelete
+	case s.Merge != nil:
+		return OperationMerge
 	default:
 		return OperationInsert
 	}
@@ -39,11 +45,33 @@ func (s *DMLStatement) GetObject() string {
 		return s.Delete.Object
 	case s.Upsert != nil:
 		return s.Upsert.Object
+	case s.Undelete != nil:
+		return s.Undelete.Object
+	case s.Merge != nil:
+		return s.Merge.Object
 	default:
 		return ""
 	}
 }
 
+// SplitRows returns one statement per VALUES row of a multi-row INSERT, so
+// that each row can succeed or fail on its own. Any other statement is
+// returned as is.
+func (s *DMLStatement) SplitRows() []*DMLStatement {
+	if s.Insert == nil || len(s.Insert.Values) < 2 {
+		return []*DMLStatement{s}
+	}
+	rows := make([]*DMLStatement, len(s.Insert.Values))
+	for i, row := range s.Insert.Values {
+		ins := *s.Insert
+		// Defaults append to the field list, so each row gets its own copy.
+		ins.Fields = append([]string(nil), s.Insert.Fields...)
+		ins.Values = []*ValueList{row}
+		rows[i] = &DMLStatement{Pos: s.Pos, Insert: &ins}
+	}
+	return rows
+}
+
 // InsertStatement represents an INSERT statement.
 // Example: INSERT INTO Account (Name, Industry) VALUES ('Acme', 'Tech'), ('Globex', 'Finance')
 type InsertStatement struct {
@@ -95,6 +123,35 @@ type UpsertStatement struct {
 	ExternalIdField string       `parser:"'ON' @Ident"`
 }
 
+// UndeleteStatement represents an UNDELETE statement, which restores records
+// from the recycle bin.
+// Example: UNDELETE FROM Task WHERE Status = 'Completed'
+type UndeleteStatement struct {
+	Pos    lexer.Position
+	Object string      `parser:"'UNDELETE' 'FROM' @Ident"`
+	Where  *Expression `parser:"('WHERE' @@)?"`
+}
+
+// MergeStatement represents a MERGE statement, which folds duplicate records
+// into a master record and deletes the duplicates. SET picks the duplicate
+// whose value a field of the master takes.
+// Example: MERGE Account 'master-id' WITH ('dup-1', 'dup-2') SET Phone FROM 'dup-2'
+type MergeStatement struct {
+	Pos          lexer.Position
+	Object       string         `parser:"'MERGE' @Ident"`
+	MasterID     string         `parser:"@String"`
+	DuplicateIDs []string       `parser:"'WITH' '(' @String (',' @String)* ')'"`
+	Winners      []*FieldWinner `parser:"('SET' @@ (',' @@)*)?"`
+}
+
+// FieldWinner names the duplicate whose value a field of the master takes.
+// Example: Phone FROM 'dup-2'
+type FieldWinner struct {
+	Pos      lexer.Position
+	Field    string `parser:"@Ident 'FROM'"`
+	RecordID string `parser:"@String"`
+}
+
 // =============================================================================
 // WHERE clause expression AST (simplified from SOQL)
 // =============================================================================
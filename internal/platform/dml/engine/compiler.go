@@ -27,6 +27,47 @@ type CompiledDML struct {
 
 	// ReturningColumn is the column name to return (usually id)
 	ReturningColumn string
+
+	// SoftDelete reports that a DELETE moves the records to the recycle bin
+	// or that an UNDELETE restores them from it.
+	SoftDelete bool
+
+	// Merge is the plan of a MERGE. The statement itself locks the master
+	// record; the caller carries out the plan in the same transaction.
+	Merge *MergePlan
+
+	// Guard is the version check of an UPDATE or DELETE of a single record
+	// of a versioned object, nil when the statement has none.
+	Guard *VersionGuard
+}
+
+// VersionGuard is the optimistic lock of a statement whose WHERE clause
+// requires Id = '<id>' AND <version field> = <n>. When such a statement
+// affects no record, the record may have been changed since version n.
+type VersionGuard struct {
+	RecordID string
+	Version  int64
+}
+
+// MergePlan describes the work of a MERGE besides locking the master.
+type MergePlan struct {
+	// MasterID is the record the duplicates are merged into.
+	MasterID string
+
+	// DuplicateIDs are the records merged into the master.
+	DuplicateIDs []string
+
+	// Winners lists the master columns that take the value of a duplicate.
+	Winners []MergeWinner
+
+	// Delete deletes the duplicates once their references are moved to the master.
+	Delete *CompiledDML
+}
+
+// MergeWinner is a master column copied from a duplicate.
+type MergeWinner struct {
+	Column   string
+	RecordID string
 }
 
 // Compiler compiles validated DML statements to SQL.
@@ -77,6 +118,10 @@ func (c *Compiler) Compile(validated *ValidatedDML) (*CompiledDML, error) {
 		return c.compileDelete(validated)
 	case OperationUpsert:
 		return c.compileUpsert(validated)
+	case OperationUndelete:
+		return c.compileUndelete(validated)
+	case OperationMerge:
+		return c.compileMerge(validated)
 	default:
 		return nil, NewValidationError(ErrCodeInvalidExpression, "unknown operation")
 	}
@@ -156,16 +201,14 @@ func (c *Compiler) compileUpdate(validated *ValidatedDML) (*CompiledDML, error)
 		}
 		setParts[i] = fmt.Sprintf("%s = %s", assign.Field.Column, compiled)
 	}
+	if col := validated.Object.VersionColumn; col != "" {
+		setParts = append(setParts, fmt.Sprintf("%s = %s + 1", col, col))
+	}
 	sql.WriteString(strings.Join(setParts, ", "))
 
-	// WHERE ...
-	if upd.Where != nil {
-		whereSQL, err := c.compileExpression(ctx, validated.Object, upd.Where)
-		if err != nil {
-			return nil, err
-		}
-		sql.WriteString(" WHERE ")
-		sql.WriteString(whereSQL)
+	// WHERE ... (records in the recycle bin are not updated)
+	if err := c.writeWhere(ctx, &sql, validated.Object, upd.Where, validated.Object.SoftDelete, false); err != nil {
+		return nil, err
 	}
 
 	// RETURNING id
@@ -179,29 +222,25 @@ func (c *Compiler) compileUpdate(validated *ValidatedDML) (*CompiledDML, error)
 		Object:          validated.Object.Name,
 		Table:           validated.Object.Table(),
 		ReturningColumn: validated.Object.PrimaryKey,
+		Guard:           versionGuard(validated.Object, upd.Where),
 	}, nil
 }
 
 // compileDelete compiles a DELETE statement.
 // Generates: DELETE FROM table WHERE ... RETURNING id
+// For objects with a recycle bin the records are flagged instead:
+// UPDATE table SET is_deleted = true, deleted_at = now() WHERE is_deleted = false AND ... RETURNING id
 func (c *Compiler) compileDelete(validated *ValidatedDML) (*CompiledDML, error) {
 	ctx := newCompileContext(c, validated)
 	del := validated.AST.Delete
+	soft := validated.Object.SoftDelete
 
 	var sql strings.Builder
-
-	// DELETE FROM table
-	sql.WriteString("DELETE FROM ")
-	sql.WriteString(validated.Object.Table())
+	writeDeleteHead(&sql, validated.Object)
 
 	// WHERE ...
-	if del.Where != nil {
-		whereSQL, err := c.compileExpression(ctx, validated.Object, del.Where)
-		if err != nil {
-			return nil, err
-		}
-		sql.WriteString(" WHERE ")
-		sql.WriteString(whereSQL)
+	if err := c.writeWhere(ctx, &sql, validated.Object, del.Where, soft, false); err != nil {
+		return nil, err
 	}
 
 	// RETURNING id
@@ -215,9 +254,149 @@ func (c *Compiler) compileDelete(validated *ValidatedDML) (*CompiledDML, error)
 		Object:          validated.Object.Name,
 		Table:           validated.Object.Table(),
 		ReturningColumn: validated.Object.PrimaryKey,
+		SoftDelete:      soft,
+		Guard:           versionGuard(validated.Object, del.Where),
 	}, nil
 }
 
+// writeDeleteHead writes the statement deleting records of obj up to WHERE.
+func writeDeleteHead(sql *strings.Builder, obj *ObjectMeta) {
+	if obj.SoftDelete {
+		// UPDATE table SET is_deleted = true, deleted_at = now()
+		sql.WriteString("UPDATE ")
+		sql.WriteString(obj.Table())
+		sql.WriteString(" SET is_deleted = true, deleted_at = now()")
+	} else {
+		// DELETE FROM table
+		sql.WriteString("DELETE FROM ")
+		sql.WriteString(obj.Table())
+	}
+}
+
+// compileUndelete compiles an UNDELETE statement.
+// Generates: UPDATE table SET is_deleted = false WHERE is_deleted = true AND ... RETURNING id
+// deleted_at is kept so that the caller can restore the composition children
+// deleted together with each record.
+func (c *Compiler) compileUndelete(validated *ValidatedDML) (*CompiledDML, error) {
+	ctx := newCompileContext(c, validated)
+	und := validated.AST.Undelete
+
+	var sql strings.Builder
+
+	sql.WriteString("UPDATE ")
+	sql.WriteString(validated.Object.Table())
+	sql.WriteString(" SET is_deleted = false")
+
+	if err := c.writeWhere(ctx, &sql, validated.Object, und.Where, true, true); err != nil {
+		return nil, err
+	}
+
+	sql.WriteString(" RETURNING ")
+	sql.WriteString(validated.Object.PrimaryKey)
+
+	return &CompiledDML{
+		SQL:             sql.String(),
+		Params:          ctx.params,
+		Operation:       OperationUndelete,
+		Object:          validated.Object.Name,
+		Table:           validated.Object.Table(),
+		ReturningColumn: validated.Object.PrimaryKey,
+		SoftDelete:      true,
+	}, nil
+}
+
+// compileMerge compiles a MERGE statement.
+// Generates: SELECT id FROM table WHERE is_deleted = false AND id = $1 FOR UPDATE
+// and, in the plan, the DELETE of the duplicates:
+// UPDATE table SET is_deleted = true, deleted_at = now() WHERE is_deleted = false AND id IN ($1, $2) RETURNING id
+func (c *Compiler) compileMerge(validated *ValidatedDML) (*CompiledDML, error) {
+	ctx := newCompileContext(c, validated)
+	mrg := validated.AST.Merge
+	obj := validated.Object
+
+	var sql strings.Builder
+	sql.WriteString("SELECT ")
+	sql.WriteString(obj.PrimaryKey)
+	sql.WriteString(" FROM ")
+	sql.WriteString(obj.Table())
+	sql.WriteString(" WHERE ")
+	if obj.SoftDelete {
+		sql.WriteString("is_deleted = false AND ")
+	}
+	sql.WriteString(obj.PrimaryKey)
+	sql.WriteString(" = ")
+	sql.WriteString(ctx.addParam(mrg.MasterID))
+	sql.WriteString(" FOR UPDATE")
+
+	delCtx := newCompileContext(c, validated)
+	var del strings.Builder
+	writeDeleteHead(&del, obj)
+	del.WriteString(" WHERE ")
+	if obj.SoftDelete {
+		del.WriteString("is_deleted = false AND ")
+	}
+	placeholders := make([]string, len(mrg.DuplicateIDs))
+	for i, id := range mrg.DuplicateIDs {
+		placeholders[i] = delCtx.addParam(id)
+	}
+	del.WriteString(obj.PrimaryKey)
+	del.WriteString(" IN (")
+	del.WriteString(strings.Join(placeholders, ", "))
+	del.WriteString(") RETURNING ")
+	del.WriteString(obj.PrimaryKey)
+
+	winners := make([]MergeWinner, len(validated.Winners))
+	for i, w := range validated.Winners {
+		winners[i] = MergeWinner{Column: w.Field.Column, RecordID: w.RecordID}
+	}
+
+	return &CompiledDML{
+		SQL:             sql.String(),
+		Params:          ctx.params,
+		Operation:       OperationMerge,
+		Object:          obj.Name,
+		Table:           obj.Table(),
+		ReturningColumn: obj.PrimaryKey,
+		Merge: &MergePlan{
+			MasterID:     mrg.MasterID,
+			DuplicateIDs: mrg.DuplicateIDs,
+			Winners:      winners,
+			Delete: &CompiledDML{
+				SQL:             del.String(),
+				Params:          delCtx.params,
+				Operation:       OperationDelete,
+				Object:          obj.Name,
+				Table:           obj.Table(),
+				ReturningColumn: obj.PrimaryKey,
+				SoftDelete:      obj.SoftDelete,
+			},
+		},
+	}, nil
+}
+
+// writeWhere writes the WHERE clause of UPDATE, DELETE and UNDELETE. With
+// binned set, the statement only matches records whose is_deleted flag
+// equals deleted.
+func (c *Compiler) writeWhere(ctx *compileContext, sql *strings.Builder, obj *ObjectMeta, where *Expression, binned, deleted bool) error {
+	var parts []string
+	if binned {
+		parts = append(parts, fmt.Sprintf("is_deleted = %t", deleted))
+	}
+	if where != nil {
+		whereSQL, err := c.compileExpression(ctx, obj, where)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, whereSQL)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	sql.WriteString(" WHERE ")
+	sql.WriteString(strings.Join(parts, " AND "))
+	return nil
+}
+
 // compileUpsert compiles an UPSERT statement.
 // Generates: INSERT INTO table (col1, col2) VALUES ($1, $2)
 //
@@ -273,6 +452,16 @@ func (c *Compiler) compileUpsert(validated *ValidatedDML) (*CompiledDML, error)
 		}
 		updateParts = append(updateParts, fmt.Sprintf("%s = EXCLUDED.%s", f.Column, f.Column))
 	}
+	if col := validated.Object.VersionColumn; col != "" {
+		// Qualified: a bare column would be ambiguous with EXCLUDED.
+		updateParts = append(updateParts, fmt.Sprintf("%s = %s.%s + 1", col, validated.Object.Table(), col))
+	}
+	if validated.Object.SoftDelete {
+		// The unique external id also matches records in the recycle bin:
+		// upserting one restores it rather than updating a record nobody
+		// can see.
+		updateParts = append(updateParts, "is_deleted = false", "deleted_at = NULL", "deleted_by = NULL")
+	}
 	if len(updateParts) > 0 {
 		sql.WriteString(strings.Join(updateParts, ", "))
 	} else {
@@ -296,6 +485,68 @@ func (c *Compiler) compileUpsert(validated *ValidatedDML) (*CompiledDML, error)
 	}, nil
 }
 
+// versionGuard returns the version guard of where: the record id and the
+// version it is compared to when both are required by the top-level AND of
+// where. It returns nil when obj is not versioned or where has no guard.
+func versionGuard(obj *ObjectMeta, where *Expression) *VersionGuard {
+	if obj.VersionColumn == "" || where == nil || where.Or == nil || len(where.Or.And) != 1 {
+		return nil
+	}
+	var id *string
+	var version *int
+	for _, not := range where.Or.And[0].Not {
+		if not.Not || not.Compare == nil || not.Compare.Operator == nil || *not.Compare.Operator != OpEQ {
+			continue
+		}
+		field, value := fieldEquals(not.Compare)
+		if field == nil || value == nil {
+			continue
+		}
+		meta := obj.GetField(field.Name)
+		if meta == nil {
+			continue
+		}
+		switch {
+		case meta.Column == obj.PrimaryKey && value.String != nil:
+			id = value.String
+		case meta.Column == obj.VersionColumn && value.Integer != nil:
+			version = value.Integer
+		}
+	}
+	if id == nil || version == nil {
+		return nil
+	}
+	return &VersionGuard{RecordID: *id, Version: int64(*version)}
+}
+
+// fieldEquals returns the field and the constant of a comparison between
+// them, in either order, or nil when cmp compares anything else.
+func fieldEquals(cmp *CompareExpr) (*Field, *Const) {
+	left, right := primaryOf(cmp.Left), primaryOf(cmp.Right)
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	if left.Field != nil && right.Const != nil {
+		return left.Field, right.Const
+	}
+	if left.Const != nil && right.Field != nil {
+		return right.Field, left.Const
+	}
+	return nil, nil
+}
+
+// primaryOf returns the primary of an operand that is a bare field or
+// constant, or nil for IN, LIKE, IS and subexpressions.
+func primaryOf(in *InExpr) *Primary {
+	if in == nil || in.In || in.Left == nil || in.Left.Like || in.Left.Left == nil || in.Left.Left.Is {
+		return nil
+	}
+	if p := in.Left.Left.Left; p != nil && p.Subexpression == nil {
+		return p
+	}
+	return nil
+}
+
 // compileExpression compiles a WHERE expression to SQL.
 func (c *Compiler) compileExpression(ctx *compileContext, obj *ObjectMeta, expr *Expression) (string, error) {
 	if expr == nil || expr.Or == nil {
@@ -189,6 +189,241 @@ func TestCompileDelete(t *testing.T) {
 	})
 }
 
+func TestCompileRecycleBin(t *testing.T) {
+	metadata := NewStaticMetadataProvider(map[string]*ObjectMeta{
+		"Task": NewObjectMeta("Task", "tasks").
+			SoftDelete().
+			ExternalIdField("Code", "code", FieldTypeString).
+			Field("Status", "status", FieldTypeString).
+			Field("Priority", "priority", FieldTypeString).
+			Build(),
+	})
+	validator := NewValidator(metadata, nil, &NoLimits)
+	compiler := NewCompiler(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		statement  string
+		wantOp     Operation
+		wantSQL    string
+		wantParams []any
+		wantSoft   bool
+	}{
+		{
+			name:       "delete flags records",
+			statement:  "DELETE FROM Task WHERE Status = 'Completed'",
+			wantOp:     OperationDelete,
+			wantSQL:    "UPDATE public.tasks SET is_deleted = true, deleted_at = now() WHERE is_deleted = false AND status = $1 RETURNING id",
+			wantParams: []any{"Completed"},
+			wantSoft:   true,
+		},
+		{
+			name:       "delete with or condition",
+			statement:  "DELETE FROM Task WHERE Status = 'Completed' OR Priority = 'Low'",
+			wantOp:     OperationDelete,
+			wantSQL:    "UPDATE public.tasks SET is_deleted = true, deleted_at = now() WHERE is_deleted = false AND (status = $1 OR priority = $2) RETURNING id",
+			wantParams: []any{"Completed", "Low"},
+			wantSoft:   true,
+		},
+		{
+			name:       "update skips deleted records",
+			statement:  "UPDATE Task SET Status = 'Open'",
+			wantOp:     OperationUpdate,
+			wantSQL:    "UPDATE public.tasks SET status = $1 WHERE is_deleted = false RETURNING id",
+			wantParams: []any{"Open"},
+		},
+		{
+			name:       "undelete with where",
+			statement:  "UNDELETE FROM Task WHERE Priority = 'High'",
+			wantOp:     OperationUndelete,
+			wantSQL:    "UPDATE public.tasks SET is_deleted = false WHERE is_deleted = true AND priority = $1 RETURNING id",
+			wantParams: []any{"High"},
+			wantSoft:   true,
+		},
+		{
+			name:       "undelete everything",
+			statement:  "UNDELETE FROM Task",
+			wantOp:     OperationUndelete,
+			wantSQL:    "UPDATE public.tasks SET is_deleted = false WHERE is_deleted = true RETURNING id",
+			wantParams: []any{},
+			wantSoft:   true,
+		},
+		{
+			name:       "upsert restores binned records",
+			statement:  "UPSERT Task (Code, Status) VALUES ('T-1', 'Open') ON Code",
+			wantOp:     OperationUpsert,
+			wantSQL:    "INSERT INTO public.tasks (code, status) VALUES ($1, $2) ON CONFLICT (code) DO UPDATE SET status = EXCLUDED.status, is_deleted = false, deleted_at = NULL, deleted_by = NULL RETURNING id",
+			wantParams: []any{"T-1", "Open"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.statement)
+			require.NoError(t, err)
+
+			validated, err := validator.Validate(ctx, ast)
+			require.NoError(t, err)
+
+			compiled, err := compiler.Compile(validated)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantOp, compiled.Operation)
+			assert.Equal(t, tt.wantSQL, compiled.SQL)
+			assert.Equal(t, tt.wantParams, compiled.Params)
+			assert.Equal(t, tt.wantSoft, compiled.SoftDelete)
+		})
+	}
+}
+
+func TestCompileMerge(t *testing.T) {
+	const (
+		master = "6f1c1a4e-0000-4000-8000-000000000001"
+		dup1   = "6f1c1a4e-0000-4000-8000-000000000002"
+		dup2   = "6f1c1a4e-0000-4000-8000-000000000003"
+	)
+	metadata := NewStaticMetadataProvider(map[string]*ObjectMeta{
+		"Account": NewObjectMeta("Account", "accounts").
+			Field("Name", "name", FieldTypeString).
+			Field("Phone", "phone", FieldTypeString).
+			Build(),
+		"Task": NewObjectMeta("Task", "tasks").
+			SoftDelete().
+			Field("Status", "status", FieldTypeString).
+			Build(),
+	})
+	validator := NewValidator(metadata, nil, &NoLimits)
+	compiler := NewCompiler(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		statement     string
+		wantSQL       string
+		wantDeleteSQL string
+		wantSoft      bool
+		wantWinners   []MergeWinner
+	}{
+		{
+			name:          "hard delete of duplicates",
+			statement:     "MERGE Account '" + master + "' WITH ('" + dup1 + "', '" + dup2 + "') SET Phone FROM '" + dup2 + "'",
+			wantSQL:       "SELECT id FROM public.accounts WHERE id = $1 FOR UPDATE",
+			wantDeleteSQL: "DELETE FROM public.accounts WHERE id IN ($1, $2) RETURNING id",
+			wantWinners:   []MergeWinner{{Column: "phone", RecordID: dup2}},
+		},
+		{
+			name:          "duplicates go to the recycle bin",
+			statement:     "MERGE Task '" + master + "' WITH ('" + dup1 + "', '" + dup2 + "')",
+			wantSQL:       "SELECT id FROM public.tasks WHERE is_deleted = false AND id = $1 FOR UPDATE",
+			wantDeleteSQL: "UPDATE public.tasks SET is_deleted = true, deleted_at = now() WHERE is_deleted = false AND id IN ($1, $2) RETURNING id",
+			wantSoft:      true,
+			wantWinners:   []MergeWinner{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validated, err := validator.Validate(ctx, MustParse(tt.statement))
+			require.NoError(t, err)
+
+			compiled, err := compiler.Compile(validated)
+			require.NoError(t, err)
+
+			assert.Equal(t, OperationMerge, compiled.Operation)
+			assert.Equal(t, tt.wantSQL, compiled.SQL)
+			assert.Equal(t, []any{master}, compiled.Params)
+
+			require.NotNil(t, compiled.Merge)
+			assert.Equal(t, master, compiled.Merge.MasterID)
+			assert.Equal(t, []string{dup1, dup2}, compiled.Merge.DuplicateIDs)
+			assert.Equal(t, tt.wantWinners, compiled.Merge.Winners)
+
+			del := compiled.Merge.Delete
+			require.NotNil(t, del)
+			assert.Equal(t, OperationDelete, del.Operation)
+			assert.Equal(t, tt.wantDeleteSQL, del.SQL)
+			assert.Equal(t, []any{dup1, dup2}, del.Params)
+			assert.Equal(t, tt.wantSoft, del.SoftDelete)
+		})
+	}
+}
+
+func TestCompileVersion(t *testing.T) {
+	const id = "6f1c1a4e-0000-4000-8000-000000000001"
+	metadata := NewStaticMetadataProvider(map[string]*ObjectMeta{
+		"Deal": NewObjectMeta("Deal", "deals").
+			Versioned("system_version").
+			ReadOnlyField("Id", "id", FieldTypeID).
+			ReadOnlyField("SystemVersion", "system_version", FieldTypeInteger).
+			ExternalIdField("Code", "code", FieldTypeString).
+			Field("Stage", "stage", FieldTypeString).
+			Build(),
+	})
+	validator := NewValidator(metadata, nil, &NoLimits)
+	compiler := NewCompiler(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		statement string
+		wantSQL   string
+		wantGuard *VersionGuard
+	}{
+		{
+			name:      "update increments the version",
+			statement: "UPDATE Deal SET Stage = 'Won' WHERE Stage = 'Open'",
+			wantSQL:   "UPDATE public.deals SET stage = $1, system_version = system_version + 1 WHERE stage = $2 RETURNING id",
+		},
+		{
+			name:      "update guarded by version",
+			statement: "UPDATE Deal SET Stage = 'Won' WHERE Id = '" + id + "' AND SystemVersion = 3",
+			wantSQL:   "UPDATE public.deals SET stage = $1, system_version = system_version + 1 WHERE (id = $2 AND system_version = $3) RETURNING id",
+			wantGuard: &VersionGuard{RecordID: id, Version: 3},
+		},
+		{
+			name:      "guard operands in either order among further conditions",
+			statement: "UPDATE Deal SET Stage = 'Won' WHERE 3 = SystemVersion AND Stage = 'Open' AND '" + id + "' = Id",
+			wantSQL:   "UPDATE public.deals SET stage = $1, system_version = system_version + 1 WHERE ($2 = system_version AND stage = $3 AND $4 = id) RETURNING id",
+			wantGuard: &VersionGuard{RecordID: id, Version: 3},
+		},
+		{
+			name:      "no guard under OR",
+			statement: "UPDATE Deal SET Stage = 'Won' WHERE Id = '" + id + "' AND SystemVersion = 3 OR Stage = 'Open'",
+			wantSQL:   "UPDATE public.deals SET stage = $1, system_version = system_version + 1 WHERE ((id = $2 AND system_version = $3) OR stage = $4) RETURNING id",
+		},
+		{
+			name:      "no guard without version",
+			statement: "UPDATE Deal SET Stage = 'Won' WHERE Id = '" + id + "'",
+			wantSQL:   "UPDATE public.deals SET stage = $1, system_version = system_version + 1 WHERE id = $2 RETURNING id",
+		},
+		{
+			name:      "delete guarded by version",
+			statement: "DELETE FROM Deal WHERE Id = '" + id + "' AND SystemVersion = 7",
+			wantSQL:   "DELETE FROM public.deals WHERE (id = $1 AND system_version = $2) RETURNING id",
+			wantGuard: &VersionGuard{RecordID: id, Version: 7},
+		},
+		{
+			name:      "upsert increments the version of updated records",
+			statement: "UPSERT Deal (Code, Stage) VALUES ('D-1', 'Open') ON Code",
+			wantSQL:   "INSERT INTO public.deals (code, stage) VALUES ($1, $2) ON CONFLICT (code) DO UPDATE SET stage = EXCLUDED.stage, system_version = public.deals.system_version + 1 RETURNING id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validated, err := validator.Validate(ctx, MustParse(tt.statement))
+			require.NoError(t, err)
+
+			compiled, err := compiler.Compile(validated)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantSQL, compiled.SQL)
+			assert.Equal(t, tt.wantGuard, compiled.Guard)
+		})
+	}
+}
+
 func TestCompileUpsert(t *testing.T) {
 	metadata := newTestMetadata()
 	validator := NewValidator(metadata, nil, nil)
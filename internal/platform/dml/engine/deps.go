@@ -23,6 +23,8 @@ const (
 	OperationUpdate
 	OperationDelete
 	OperationUpsert
+	OperationUndelete
+	OperationMerge
 )
 
 func (op Operation) String() string {
@@ -35,6 +37,10 @@ func (op Operation) String() string {
 		return "DELETE"
 	case OperationUpsert:
 		return "UPSERT"
+	case OperationUndelete:
+		return "UNDELETE"
+	case OperationMerge:
+		return "MERGE"
 	default:
 		return "UNKNOWN"
 	}
@@ -136,6 +142,15 @@ type ObjectMeta struct {
 
 	// PrimaryKey is the name of the primary key column (usually "id").
 	PrimaryKey string
+
+	// SoftDelete reports that the table has the recycle bin columns
+	// (is_deleted, deleted_at, deleted_by): DELETE moves records to the
+	// recycle bin, UNDELETE restores them, and UPDATE skips deleted records.
+	SoftDelete bool
+
+	// VersionColumn is the integer column holding the version of a record,
+	// which UPDATE and UPSERT increment. Empty for unversioned objects.
+	VersionColumn string
 }
 
 // Table returns the fully qualified table name (schema.table).
@@ -296,6 +311,18 @@ func (b *ObjectMetaBuilder) PrimaryKey(pk string) *ObjectMetaBuilder {
 	return b
 }
 
+// SoftDelete marks the object as deleted into the recycle bin.
+func (b *ObjectMetaBuilder) SoftDelete() *ObjectMetaBuilder {
+	b.meta.SoftDelete = true
+	return b
+}
+
+// Versioned marks the object as versioned by the given integer column.
+func (b *ObjectMetaBuilder) Versioned(column string) *ObjectMetaBuilder {
+	b.meta.VersionColumn = column
+	return b
+}
+
 // Field adds a writable field to the object.
 func (b *ObjectMetaBuilder) Field(name, column string, typ FieldType) *ObjectMetaBuilder {
 	b.meta.Fields[name] = &FieldMeta{
@@ -422,11 +449,17 @@ type Result struct {
 	// InsertedIds contains the IDs of inserted records (for INSERT/UPSERT).
 	InsertedIds []string
 
-	// UpdatedIds contains the IDs of updated records (for UPDATE/UPSERT).
+	// UpdatedIds contains the IDs of updated records (for UPDATE/UPSERT), or
+	// the master record (for MERGE).
 	UpdatedIds []string
 
-	// DeletedIds contains the IDs of deleted records (for DELETE).
+	// DeletedIds contains the IDs of deleted records (for DELETE), or the
+	// duplicates merged into the master (for MERGE).
 	DeletedIds []string
+
+	// UndeletedIds contains the IDs of records restored from the recycle bin
+	// (for UNDELETE).
+	UndeletedIds []string
 }
 
 // DefaultExecutor is a simple executor that uses a DB interface.
@@ -459,13 +492,15 @@ func (e *DefaultExecutor) Execute(ctx context.Context, compiled *CompiledDML) (*
 		switch compiled.Operation {
 		case OperationInsert:
 			result.InsertedIds = append(result.InsertedIds, id)
-		case OperationUpdate:
+		case OperationUpdate, OperationMerge:
 			result.UpdatedIds = append(result.UpdatedIds, id)
 		case OperationDelete:
 			result.DeletedIds = append(result.DeletedIds, id)
 		case OperationUpsert:
 			// For UPSERT, we can't easily distinguish between insert and update
 			result.InsertedIds = append(result.InsertedIds, id)
+		case OperationUndelete:
+			result.UndeletedIds = append(result.UndeletedIds, id)
 		}
 	}
 
@@ -477,10 +512,12 @@ func (e *DefaultExecutor) Execute(ctx context.Context, compiled *CompiledDML) (*
 	switch compiled.Operation {
 	case OperationInsert, OperationUpsert:
 		result.RowsAffected = int64(len(result.InsertedIds))
-	case OperationUpdate:
+	case OperationUpdate, OperationMerge:
 		result.RowsAffected = int64(len(result.UpdatedIds))
 	case OperationDelete:
 		result.RowsAffected = int64(len(result.DeletedIds))
+	case OperationUndelete:
+		result.RowsAffected = int64(len(result.UndeletedIds))
 	}
 
 	return result, nil
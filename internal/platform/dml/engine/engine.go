@@ -145,7 +145,12 @@ func (e *Engine) Prepare(ctx context.Context, statement string) (*CompiledDML, e
 	if err != nil {
 		return nil, err
 	}
+	return e.PrepareAST(ctx, ast)
+}
 
+// PrepareAST validates and compiles a parsed DML statement.
+// Pipeline: applyDefaults → Validate → applyRuleValidation → Compile.
+func (e *Engine) PrepareAST(ctx context.Context, ast *DMLStatement) (*CompiledDML, error) {
 	// Stage 3: Apply defaults (before validation so defaults satisfy required checks)
 	if e.defaultResolver != nil {
 		if err := e.applyDefaults(ctx, ast); err != nil {
@@ -7,7 +7,7 @@ import (
 // Lexer defines tokens for DML statements
 var Lexer = lexer.MustSimple([]lexer.SimpleRule{
 	// DML Keywords and Functions (must come before Ident to take precedence)
-	{Name: "Keyword", Pattern: `\b(?i:INSERT|INTO|VALUES|UPDATE|SET|DELETE|FROM|WHERE|UPSERT|ON|AND|OR|NOT|IN|LIKE|IS|NULL|TRUE|FALSE|COALESCE|NULLIF|CONCAT|UPPER|LOWER|TRIM|LENGTH|LEN|SUBSTRING|SUBSTR|ABS|ROUND|FLOOR|CEIL|CEILING)\b`},
+	{Name: "Keyword", Pattern: `\b(?i:INSERT|INTO|VALUES|UPDATE|SET|DELETE|UNDELETE|MERGE|WITH|FROM|WHERE|UPSERT|ON|AND|OR|NOT|IN|LIKE|IS|NULL|TRUE|FALSE|COALESCE|NULLIF|CONCAT|UPPER|LOWER|TRIM|LENGTH|LEN|SUBSTRING|SUBSTR|ABS|ROUND|FLOOR|CEIL|CEILING)\b`},
 
 	// DateTime: 2024-01-15T10:30:00Z (must come before Date)
 	{Name: "DateTime", Pattern: `\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:\d{2})`},
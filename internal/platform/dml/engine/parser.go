@@ -97,3 +97,27 @@ func ParseUpsert(statement string) (*UpsertStatement, error) {
 	}
 	return ast.Upsert, nil
 }
+
+// ParseUndelete parses an UNDELETE statement.
+func ParseUndelete(statement string) (*UndeleteStatement, error) {
+	ast, err := Parse(statement)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Undelete == nil {
+		return nil, NewValidationError(ErrCodeInvalidExpression, "not an UNDELETE statement")
+	}
+	return ast.Undelete, nil
+}
+
+// ParseMerge parses a MERGE statement.
+func ParseMerge(statement string) (*MergeStatement, error) {
+	ast, err := Parse(statement)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Merge == nil {
+		return nil, NewValidationError(ErrCodeInvalidExpression, "not a MERGE statement")
+	}
+	return ast.Merge, nil
+}
@@ -258,6 +258,130 @@ func TestParseDelete(t *testing.T) {
 	}
 }
 
+func TestParseUndelete(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantObj      string
+		wantHasWhere bool
+		wantErr      bool
+	}{
+		{
+			name:    "simple undelete",
+			input:   "UNDELETE FROM Task",
+			wantObj: "Task",
+		},
+		{
+			name:         "undelete with where",
+			input:        "UNDELETE FROM Task WHERE Status = 'Completed'",
+			wantObj:      "Task",
+			wantHasWhere: true,
+		},
+		{
+			name:         "undelete case insensitive",
+			input:        "undelete from Task where Id = '123'",
+			wantObj:      "Task",
+			wantHasWhere: true,
+		},
+		{
+			name:    "invalid - missing from",
+			input:   "UNDELETE Task WHERE Id = '123'",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, ast.Undelete)
+
+			assert.Equal(t, tt.wantObj, ast.Undelete.Object)
+			assert.Equal(t, OperationUndelete, ast.GetOperation())
+			assert.Equal(t, tt.wantObj, ast.GetObject())
+			assert.Equal(t, tt.wantHasWhere, ast.Undelete.Where != nil)
+		})
+	}
+}
+
+func TestParseMerge(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantObj     string
+		wantMaster  string
+		wantDupes   []string
+		wantWinners map[string]string
+		wantErr     bool
+	}{
+		{
+			name:       "single duplicate",
+			input:      "MERGE Account 'm-1' WITH ('d-1')",
+			wantObj:    "Account",
+			wantMaster: "m-1",
+			wantDupes:  []string{"d-1"},
+		},
+		{
+			name:        "duplicates with winners",
+			input:       "MERGE Account 'm-1' WITH ('d-1', 'd-2') SET Phone FROM 'd-2', Industry FROM 'd-1'",
+			wantObj:     "Account",
+			wantMaster:  "m-1",
+			wantDupes:   []string{"d-1", "d-2"},
+			wantWinners: map[string]string{"Phone": "d-2", "Industry": "d-1"},
+		},
+		{
+			name:        "merge case insensitive",
+			input:       "merge Account 'm-1' with ('d-1') set Phone from 'd-1'",
+			wantObj:     "Account",
+			wantMaster:  "m-1",
+			wantDupes:   []string{"d-1"},
+			wantWinners: map[string]string{"Phone": "d-1"},
+		},
+		{
+			name:    "invalid - missing duplicates",
+			input:   "MERGE Account 'm-1'",
+			wantErr: true,
+		},
+		{
+			name:    "invalid - empty duplicates",
+			input:   "MERGE Account 'm-1' WITH ()",
+			wantErr: true,
+		},
+		{
+			name:    "invalid - winner without record",
+			input:   "MERGE Account 'm-1' WITH ('d-1') SET Phone",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, ast.Merge)
+
+			assert.Equal(t, OperationMerge, ast.GetOperation())
+			assert.Equal(t, tt.wantObj, ast.GetObject())
+			assert.Equal(t, tt.wantMaster, ast.Merge.MasterID)
+			assert.Equal(t, tt.wantDupes, ast.Merge.DuplicateIDs)
+			require.Len(t, ast.Merge.Winners, len(tt.wantWinners))
+			for _, w := range ast.Merge.Winners {
+				assert.Equal(t, tt.wantWinners[w.Field], w.RecordID)
+			}
+		})
+	}
+}
+
 func TestParseUpsert(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -585,6 +709,59 @@ func TestDMLStatementMethods(t *testing.T) {
 	})
 }
 
+func TestSplitRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantRows []string // first value of each row
+	}{
+		{
+			name:     "multi-row insert",
+			input:    "INSERT INTO Account (Name, Industry) VALUES ('Acme', 'Tech'), ('Globex', 'Finance'), ('Initech', 'IT')",
+			wantRows: []string{"Acme", "Globex", "Initech"},
+		},
+		{
+			name:     "single-row insert",
+			input:    "INSERT INTO Account (Name) VALUES ('Acme')",
+			wantRows: []string{"Acme"},
+		},
+		{
+			name:  "other statements are kept",
+			input: "DELETE FROM Task WHERE Status = 'Done'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.input)
+			require.NoError(t, err)
+
+			rows := ast.SplitRows()
+			if tt.wantRows == nil {
+				require.Len(t, rows, 1)
+				assert.Same(t, ast, rows[0])
+				return
+			}
+			require.Len(t, rows, len(tt.wantRows))
+			for i, row := range rows {
+				require.NotNil(t, row.Insert)
+				assert.Equal(t, ast.Insert.Fields, row.Insert.Fields)
+				require.Len(t, row.Insert.Values, 1)
+				assert.Equal(t, tt.wantRows[i], row.Insert.Values[0].Values[0].Value())
+			}
+
+			if len(rows) < 2 {
+				assert.Same(t, ast, rows[0])
+				return
+			}
+			// Fields appended by defaults stay with their row.
+			rows[0].Insert.Fields = append(rows[0].Insert.Fields, "OwnerId")
+			assert.NotContains(t, rows[1].Insert.Fields, "OwnerId")
+			assert.NotContains(t, ast.Insert.Fields, "OwnerId")
+		})
+	}
+}
+
 func TestParseFunctionCalls(t *testing.T) {
 	tests := []struct {
 		name     string
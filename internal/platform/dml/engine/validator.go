@@ -3,6 +3,8 @@ package engine
 import (
 	"context"
 	"fmt"
+
+	"github.com/google/uuid"
 )
 
 // ValidatedDML represents a validated DML statement with resolved metadata.
@@ -22,8 +24,11 @@ type ValidatedDML struct {
 	// For UPSERT
 	ExternalIdField *FieldMeta // The external ID field for conflict resolution
 
-	// For UPDATE/DELETE
+	// For UPDATE/DELETE/UNDELETE
 	HasWhere bool // Whether statement has WHERE clause
+
+	// For MERGE
+	Winners []*ValidatedWinner // Resolved field winners
 }
 
 // ValidatedAssignment represents a validated field assignment.
@@ -32,6 +37,12 @@ type ValidatedAssignment struct {
 	Value *Expr
 }
 
+// ValidatedWinner represents a validated MERGE field winner.
+type ValidatedWinner struct {
+	Field    *FieldMeta
+	RecordID string
+}
+
 // Validator validates DML statements against metadata and access rules.
 type Validator struct {
 	metadata MetadataProvider
@@ -65,6 +76,10 @@ func (v *Validator) Validate(ctx context.Context, ast *DMLStatement) (*Validated
 		return v.validateDelete(ctx, ast, ast.Delete)
 	case ast.Upsert != nil:
 		return v.validateUpsert(ctx, ast, ast.Upsert)
+	case ast.Undelete != nil:
+		return v.validateUndelete(ctx, ast, ast.Undelete)
+	case ast.Merge != nil:
+		return v.validateMerge(ctx, ast, ast.Merge)
 	default:
 		return nil, NewValidationError(ErrCodeInvalidExpression, "empty DML statement")
 	}
@@ -281,6 +296,122 @@ func (v *Validator) validateDelete(ctx context.Context, ast *DMLStatement, del *
 	}, nil
 }
 
+// validateUndelete validates an UNDELETE statement. Restoring records needs
+// the same object access as deleting them; unlike DELETE, WHERE is optional.
+func (v *Validator) validateUndelete(ctx context.Context, ast *DMLStatement, und *UndeleteStatement) (*ValidatedDML, error) {
+	obj, err := v.metadata.GetObject(ctx, und.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	if obj == nil {
+		return nil, UnknownObjectError(und.Object)
+	}
+	if !obj.SoftDelete {
+		return nil, NewValidationError(ErrCodeInvalidExpression,
+			fmt.Sprintf("object %s has no recycle bin", und.Object))
+	}
+
+	if err := v.access.CanWriteObject(ctx, und.Object, OperationUndelete); err != nil {
+		return nil, err
+	}
+
+	if und.Where != nil {
+		if err := v.validateExpression(ctx, obj, und.Where); err != nil {
+			return nil, fmt.Errorf("invalid WHERE clause: %w", err)
+		}
+	}
+
+	return &ValidatedDML{
+		AST:       ast,
+		Object:    obj,
+		Operation: OperationUndelete,
+		HasWhere:  und.Where != nil,
+	}, nil
+}
+
+// validateMerge validates a MERGE statement. Merging updates the master and
+// deletes the duplicates, so it needs both rights on the object.
+func (v *Validator) validateMerge(ctx context.Context, ast *DMLStatement, mrg *MergeStatement) (*ValidatedDML, error) {
+	obj, err := v.metadata.GetObject(ctx, mrg.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	if obj == nil {
+		return nil, UnknownObjectError(mrg.Object)
+	}
+
+	if err := v.access.CanWriteObject(ctx, mrg.Object, OperationMerge); err != nil {
+		return nil, err
+	}
+
+	if err := v.limits.CheckBatchSize(len(mrg.DuplicateIDs)); err != nil {
+		return nil, err
+	}
+
+	// Check record IDs
+	if _, err := uuid.Parse(mrg.MasterID); err != nil {
+		return nil, NewValidationError(ErrCodeInvalidValue,
+			fmt.Sprintf("invalid master record id %q", mrg.MasterID))
+	}
+	duplicates := make(map[string]bool, len(mrg.DuplicateIDs))
+	for _, id := range mrg.DuplicateIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, NewValidationError(ErrCodeInvalidValue,
+				fmt.Sprintf("invalid duplicate record id %q", id))
+		}
+		if id == mrg.MasterID {
+			return nil, NewValidationError(ErrCodeInvalidValue,
+				"MERGE master record cannot be one of its duplicates")
+		}
+		if duplicates[id] {
+			return nil, NewValidationError(ErrCodeInvalidValue,
+				fmt.Sprintf("duplicate record %q is listed twice", id))
+		}
+		duplicates[id] = true
+	}
+
+	// Check for duplicate fields in SET
+	seenFields := make(map[string]bool)
+	var fieldNames []string
+	for _, w := range mrg.Winners {
+		if seenFields[w.Field] {
+			return nil, DuplicateFieldError(mrg.Object, w.Field)
+		}
+		seenFields[w.Field] = true
+		fieldNames = append(fieldNames, w.Field)
+	}
+
+	// Check field write access
+	if len(fieldNames) > 0 {
+		if err := v.access.CheckWritableFields(ctx, mrg.Object, fieldNames); err != nil {
+			return nil, err
+		}
+	}
+
+	winners := make([]*ValidatedWinner, len(mrg.Winners))
+	for i, w := range mrg.Winners {
+		field := obj.GetField(w.Field)
+		if field == nil {
+			return nil, UnknownFieldError(mrg.Object, w.Field)
+		}
+		if field.ReadOnly || field.Calculated {
+			return nil, ReadOnlyFieldError(mrg.Object, w.Field)
+		}
+		if !duplicates[w.RecordID] {
+			return nil, NewValidationError(ErrCodeInvalidValue,
+				fmt.Sprintf("%s winner %q is not one of the duplicates", w.Field, w.RecordID))
+		}
+		winners[i] = &ValidatedWinner{Field: field, RecordID: w.RecordID}
+	}
+
+	return &ValidatedDML{
+		AST:       ast,
+		Object:    obj,
+		Operation: OperationMerge,
+		Winners:   winners,
+	}, nil
+}
+
 // validateUpsert validates an UPSERT statement.
 func (v *Validator) validateUpsert(ctx context.Context, ast *DMLStatement, ups *UpsertStatement) (*ValidatedDML, error) {
 	// Get object metadata
@@ -250,6 +250,165 @@ func TestValidateDelete(t *testing.T) {
 	})
 }
 
+func TestValidateUndelete(t *testing.T) {
+	metadata := NewStaticMetadataProvider(map[string]*ObjectMeta{
+		"Task": NewObjectMeta("Task", "tasks").
+			SoftDelete().
+			Field("Status", "status", FieldTypeString).
+			Build(),
+		"Account": NewObjectMeta("Account", "accounts").
+			Field("Name", "name", FieldTypeString).
+			Build(),
+	})
+	ctx := context.Background()
+
+	t.Run("undelete without where allowed with default limits", func(t *testing.T) {
+		validator := NewValidator(metadata, nil, &DefaultLimits)
+
+		validated, err := validator.Validate(ctx, MustParse("UNDELETE FROM Task"))
+		require.NoError(t, err)
+		assert.Equal(t, OperationUndelete, validated.Operation)
+		assert.False(t, validated.HasWhere)
+	})
+
+	t.Run("object without recycle bin error", func(t *testing.T) {
+		validator := NewValidator(metadata, nil, nil)
+
+		_, err := validator.Validate(ctx, MustParse("UNDELETE FROM Account WHERE Name = 'Acme'"))
+		require.Error(t, err)
+		assert.True(t, IsValidationError(err))
+	})
+
+	t.Run("access is checked for undelete", func(t *testing.T) {
+		var gotOp Operation
+		access := &FuncWriteAccessController{
+			ObjectFunc: func(_ context.Context, object string, op Operation) error {
+				gotOp = op
+				return NewWriteAccessError(object, op)
+			},
+		}
+		validator := NewValidator(metadata, access, nil)
+
+		_, err := validator.Validate(ctx, MustParse("UNDELETE FROM Task WHERE Status = 'Done'"))
+		require.Error(t, err)
+		assert.True(t, IsAccessError(err))
+		assert.Equal(t, OperationUndelete, gotOp)
+	})
+
+	t.Run("unknown field in WHERE error", func(t *testing.T) {
+		validator := NewValidator(metadata, nil, nil)
+
+		_, err := validator.Validate(ctx, MustParse("UNDELETE FROM Task WHERE Unknown = 'Test'"))
+		require.Error(t, err)
+		assert.True(t, IsValidationError(err))
+	})
+}
+
+func TestValidateMerge(t *testing.T) {
+	metadata := newTestMetadata()
+	ctx := context.Background()
+
+	const (
+		master = "6f1c1a4e-0000-4000-8000-000000000001"
+		dup1   = "6f1c1a4e-0000-4000-8000-000000000002"
+		dup2   = "6f1c1a4e-0000-4000-8000-000000000003"
+	)
+
+	t.Run("valid merge with winners", func(t *testing.T) {
+		validator := NewValidator(metadata, nil, nil)
+
+		validated, err := validator.Validate(ctx, MustParse(
+			"MERGE Account '"+master+"' WITH ('"+dup1+"', '"+dup2+"') SET Industry FROM '"+dup2+"'"))
+		require.NoError(t, err)
+		assert.Equal(t, OperationMerge, validated.Operation)
+		require.Len(t, validated.Winners, 1)
+		assert.Equal(t, "industry", validated.Winners[0].Field.Column)
+		assert.Equal(t, dup2, validated.Winners[0].RecordID)
+	})
+
+	tests := []struct {
+		name      string
+		statement string
+	}{
+		{
+			name:      "unknown object",
+			statement: "MERGE Unknown '" + master + "' WITH ('" + dup1 + "')",
+		},
+		{
+			name:      "invalid master id",
+			statement: "MERGE Account 'acc-1' WITH ('" + dup1 + "')",
+		},
+		{
+			name:      "invalid duplicate id",
+			statement: "MERGE Account '" + master + "' WITH ('acc-2')",
+		},
+		{
+			name:      "master among duplicates",
+			statement: "MERGE Account '" + master + "' WITH ('" + dup1 + "', '" + master + "')",
+		},
+		{
+			name:      "duplicate listed twice",
+			statement: "MERGE Account '" + master + "' WITH ('" + dup1 + "', '" + dup1 + "')",
+		},
+		{
+			name:      "unknown winner field",
+			statement: "MERGE Account '" + master + "' WITH ('" + dup1 + "') SET Unknown FROM '" + dup1 + "'",
+		},
+		{
+			name:      "read-only winner field",
+			statement: "MERGE Account '" + master + "' WITH ('" + dup1 + "') SET CreatedAt FROM '" + dup1 + "'",
+		},
+		{
+			name:      "winner field repeated",
+			statement: "MERGE Account '" + master + "' WITH ('" + dup1 + "', '" + dup2 + "') SET Industry FROM '" + dup1 + "', Industry FROM '" + dup2 + "'",
+		},
+		{
+			name:      "winner is not a duplicate",
+			statement: "MERGE Account '" + master + "' WITH ('" + dup1 + "') SET Industry FROM '" + dup2 + "'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewValidator(metadata, nil, nil)
+
+			_, err := validator.Validate(ctx, MustParse(tt.statement))
+			require.Error(t, err)
+			assert.True(t, IsValidationError(err))
+		})
+	}
+
+	t.Run("access is checked for merge", func(t *testing.T) {
+		var gotOp Operation
+		access := &FuncWriteAccessController{
+			ObjectFunc: func(_ context.Context, object string, op Operation) error {
+				gotOp = op
+				return NewWriteAccessError(object, op)
+			},
+		}
+		validator := NewValidator(metadata, access, nil)
+
+		_, err := validator.Validate(ctx, MustParse("MERGE Account '"+master+"' WITH ('"+dup1+"')"))
+		require.Error(t, err)
+		assert.True(t, IsAccessError(err))
+		assert.Equal(t, OperationMerge, gotOp)
+	})
+
+	t.Run("winner fields are checked for write access", func(t *testing.T) {
+		access := &FuncWriteAccessController{
+			FieldFunc: func(_ context.Context, object string, fields []string) error {
+				return NewFieldWriteAccessError(object, fields[0])
+			},
+		}
+		validator := NewValidator(metadata, access, nil)
+
+		_, err := validator.Validate(ctx, MustParse(
+			"MERGE Account '"+master+"' WITH ('"+dup1+"') SET Industry FROM '"+dup1+"'"))
+		require.Error(t, err)
+		assert.True(t, IsAccessError(err))
+	})
+}
+
 func TestValidateUpsert(t *testing.T) {
 	metadata := newTestMetadata()
 	validator := NewValidator(metadata, nil, nil)
@@ -10,12 +10,13 @@ import (
 
 	"github.com/adverax/crm/internal/platform/dml/engine"
 	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/querylog"
 	"github.com/adverax/crm/internal/platform/security"
 	"github.com/adverax/crm/internal/platform/security/rls"
 )
 
 // rlsExecutor wraps the default DML executor and injects RLS WHERE clauses
-// for UPDATE and DELETE operations. INSERT and UPSERT pass through unchanged.
+// for UPDATE, DELETE, UNDELETE and MERGE operations. INSERT and UPSERT pass through unchanged.
 type rlsExecutor struct {
 	inner       engine.Executor
 	pool        *pgxpool.Pool
@@ -35,7 +36,8 @@ func NewRLSExecutor(pool *pgxpool.Pool, cache metadata.MetadataReader, rlsEnforc
 
 // Execute implements engine.Executor.
 func (e *rlsExecutor) Execute(ctx context.Context, compiled *engine.CompiledDML) (*engine.Result, error) {
-	if compiled.Operation == engine.OperationUpdate || compiled.Operation == engine.OperationDelete {
+	switch compiled.Operation {
+	case engine.OperationUpdate, engine.OperationDelete, engine.OperationUndelete, engine.OperationMerge:
 		injected, err := e.injectRLS(ctx, compiled)
 		if err != nil {
 			return nil, err
@@ -67,6 +69,7 @@ func (e *rlsExecutor) injectRLS(ctx context.Context, compiled *engine.CompiledDM
 	}
 
 	sql, params := injectDMLRLSClause(compiled.SQL, compiled.Params, rlsClause, rlsParams)
+	querylog.MarkRLSApplied(ctx)
 
 	result := *compiled
 	result.SQL = sql
@@ -74,7 +77,8 @@ func (e *rlsExecutor) injectRLS(ctx context.Context, compiled *engine.CompiledDM
 	return &result, nil
 }
 
-// injectDMLRLSClause adds the RLS WHERE clause to a DML statement (UPDATE/DELETE).
+// injectDMLRLSClause adds the RLS WHERE clause to a DML statement (UPDATE/DELETE/UNDELETE)
+// or to the master lock of a MERGE.
 // It re-numbers the RLS parameters starting after existing params.
 func injectDMLRLSClause(sql string, params []any, rlsClause string, rlsParams []any) (string, []any) {
 	offset := len(params)
@@ -34,6 +34,24 @@ func TestInjectDMLRLSClause(t *testing.T) {
 			wantSQL:    "DELETE FROM public.obj_account WHERE owner_id = $2 AND id = $1 RETURNING id",
 			wantParams: []any{"abc-123", "user-1"},
 		},
+		{
+			name:       "DELETE into the recycle bin",
+			sql:        "UPDATE public.obj_account SET is_deleted = true, deleted_at = now() WHERE is_deleted = false AND id = $1 RETURNING id",
+			params:     []any{"abc-123"},
+			rlsClause:  "owner_id = $1",
+			rlsParams:  []any{"user-1"},
+			wantSQL:    "UPDATE public.obj_account SET is_deleted = true, deleted_at = now() WHERE owner_id = $2 AND is_deleted = false AND id = $1 RETURNING id",
+			wantParams: []any{"abc-123", "user-1"},
+		},
+		{
+			name:       "MERGE master lock",
+			sql:        "SELECT id FROM public.obj_account WHERE is_deleted = false AND id = $1 FOR UPDATE",
+			params:     []any{"abc-123"},
+			rlsClause:  "owner_id = $1",
+			rlsParams:  []any{"user-1"},
+			wantSQL:    "SELECT id FROM public.obj_account WHERE owner_id = $2 AND is_deleted = false AND id = $1 FOR UPDATE",
+			wantParams: []any{"abc-123", "user-1"},
+		},
 		{
 			name:       "DELETE without WHERE",
 			sql:        "DELETE FROM public.obj_account RETURNING id",
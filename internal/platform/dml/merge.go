@@ -0,0 +1,134 @@
+package dml
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/adverax/crm/internal/platform/dml/engine"
+	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/metadata/ddl"
+)
+
+// Merger carries out the plan of a MERGE: it copies the winning fields of the
+// duplicates to the master, moves every reference to a duplicate over to the
+// master and hands the sharing of the duplicates down to the master. The
+// duplicates themselves are deleted by the caller, through the DML pipeline.
+type Merger struct {
+	cache metadata.MetadataReader
+}
+
+// NewMerger creates a new Merger.
+func NewMerger(cache metadata.MetadataReader) *Merger {
+	return &Merger{cache: cache}
+}
+
+// Merge runs the plan of compiled on tx after the master has been locked. It
+// returns the objects whose records it reparented.
+func (m *Merger) Merge(ctx context.Context, tx engine.DB, compiled *engine.CompiledDML) ([]string, error) {
+	plan := compiled.Merge
+	obj, ok := m.cache.GetObjectByAPIName(compiled.Object)
+	if !ok || plan == nil {
+		return nil, fmt.Errorf("merger.Merge: unknown object %s", compiled.Object)
+	}
+
+	if err := m.copyWinners(ctx, tx, obj, plan); err != nil {
+		return nil, fmt.Errorf("merger.Merge: %w", err)
+	}
+	changed, err := m.reparent(ctx, tx, obj, plan)
+	if err != nil {
+		return nil, fmt.Errorf("merger.Merge: %w", err)
+	}
+	if err := m.mergeShares(ctx, tx, obj, plan); err != nil {
+		return nil, fmt.Errorf("merger.Merge: %w", err)
+	}
+	return changed, nil
+}
+
+// copyWinners copies the winning fields to the master, one statement per
+// duplicate.
+func (m *Merger) copyWinners(ctx context.Context, tx engine.DB, obj metadata.ObjectDefinition, plan *engine.MergePlan) error {
+	columns := make(map[string][]string)
+	var order []string
+	for _, w := range plan.Winners {
+		if _, ok := columns[w.RecordID]; !ok {
+			order = append(order, w.RecordID)
+		}
+		columns[w.RecordID] = append(columns[w.RecordID], w.Column)
+	}
+
+	table := quoteTable(obj.TableName)
+	for _, id := range order {
+		sets := make([]string, len(columns[id]))
+		for i, col := range columns[id] {
+			quoted := pgx.Identifier{col}.Sanitize()
+			sets[i] = fmt.Sprintf("%s = d.%s", quoted, quoted)
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(
+			"UPDATE %s m SET %s, %s = m.%s + 1 FROM %s d WHERE m.id = $1 AND d.id = $2",
+			table, strings.Join(sets, ", "), ddl.VersionColumn, ddl.VersionColumn, table),
+			plan.MasterID, id); err != nil {
+			return fmt.Errorf("copy fields from %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// reparent points the references to a duplicate at the master: lookups,
+// compositions and the polymorphic references whose object type is the
+// merged object. A self lookup of the master is left alone rather than made
+// to point at itself.
+func (m *Merger) reparent(ctx context.Context, tx engine.DB, obj metadata.ObjectDefinition, plan *engine.MergePlan) ([]string, error) {
+	var changed []string
+	for _, rel := range m.cache.GetReverseRelationships(obj.ID) {
+		child, ok := m.cache.GetObjectByID(rel.ChildObjectID)
+		if !ok {
+			continue
+		}
+		column := pgx.Identifier{rel.FieldAPIName}.Sanitize()
+		var typeFilter string
+		args := []any{plan.MasterID, plan.DuplicateIDs}
+		if rel.ReferenceSubtype == metadata.SubtypePolymorphic {
+			column = pgx.Identifier{rel.FieldAPIName + "_record_id"}.Sanitize()
+			typeFilter = fmt.Sprintf(" AND %s = $3", pgx.Identifier{rel.FieldAPIName + "_object_type"}.Sanitize())
+			args = append(args, obj.APIName)
+		}
+		tag, err := tx.Exec(ctx, fmt.Sprintf(
+			"UPDATE %s SET %s = $1, %s = %s + 1 WHERE %s = ANY($2::uuid[])%s AND id <> $1",
+			quoteTable(child.TableName), column, ddl.VersionColumn, ddl.VersionColumn, column, typeFilter),
+			args...)
+		if err != nil {
+			return nil, fmt.Errorf("reparent %s.%s: %w", child.APIName, rel.FieldAPIName, err)
+		}
+		if tag.RowsAffected() > 0 {
+			changed = append(changed, child.APIName)
+		}
+	}
+	return changed, nil
+}
+
+// mergeShares grants the master every share of the duplicates: manual shares
+// as well as those of sharing rules and territories. Where the master already
+// has a share for the same group and reason, the wider access level wins.
+func (m *Merger) mergeShares(ctx context.Context, tx engine.DB, obj metadata.ObjectDefinition, plan *engine.MergePlan) error {
+	if obj.Visibility == metadata.VisibilityPublicReadWrite {
+		return nil // no share table
+	}
+	share := quoteTable(ddl.ShareTableName(obj.TableName))
+	// 'read_write' sorts after 'read', so max() picks the wider level.
+	if _, err := tx.Exec(ctx, fmt.Sprintf(
+		`INSERT INTO %s (record_id, group_id, access_level, reason)
+		 SELECT $1, group_id, max(access_level), reason
+		   FROM %s
+		  WHERE record_id = ANY($2::uuid[])
+		  GROUP BY group_id, reason
+		 ON CONFLICT (record_id, group_id, reason)
+		 DO UPDATE SET access_level = GREATEST(%s.access_level, EXCLUDED.access_level)`,
+		share, share, share),
+		plan.MasterID, plan.DuplicateIDs); err != nil {
+		return fmt.Errorf("merge shares: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package dml
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/platform/dml/engine"
+	"github.com/adverax/crm/internal/platform/metadata"
+)
+
+// fakeMetadata serves objects and reverse relationships; other metadata is
+// not used by the code under test.
+type fakeMetadata struct {
+	metadata.MetadataReader
+	objects []metadata.ObjectDefinition
+	reverse map[uuid.UUID][]metadata.RelationshipInfo
+}
+
+func (m *fakeMetadata) GetObjectByID(id uuid.UUID) (metadata.ObjectDefinition, bool) {
+	for _, o := range m.objects {
+		if o.ID == id {
+			return o, true
+		}
+	}
+	return metadata.ObjectDefinition{}, false
+}
+
+func (m *fakeMetadata) GetObjectByAPIName(name string) (metadata.ObjectDefinition, bool) {
+	for _, o := range m.objects {
+		if o.APIName == name {
+			return o, true
+		}
+	}
+	return metadata.ObjectDefinition{}, false
+}
+
+func (m *fakeMetadata) GetReverseRelationships(id uuid.UUID) []metadata.RelationshipInfo {
+	return m.reverse[id]
+}
+
+// execCall is a statement run through fakeDB.
+type execCall struct {
+	sql  string
+	args []any
+}
+
+// fakeDB records the statements it runs. Exec reports rowsAffected rows and
+// QueryRow scans count into its single destination.
+type fakeDB struct {
+	execs        []execCall
+	queries      []execCall
+	rowsAffected int64
+	count        int
+}
+
+func (db *fakeDB) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	db.execs = append(db.execs, execCall{sql: sql, args: args})
+	return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", db.rowsAffected)), nil
+}
+
+func (db *fakeDB) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, fmt.Errorf("fakeDB: Query is not supported")
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	db.queries = append(db.queries, execCall{sql: sql, args: args})
+	return countRow(db.count)
+}
+
+type countRow int
+
+func (r countRow) Scan(dest ...any) error {
+	*dest[0].(*int) = int(r)
+	return nil
+}
+
+func TestMergerReparent(t *testing.T) {
+	t.Parallel()
+
+	account := metadata.ObjectDefinition{ID: uuid.New(), APIName: "Account", TableName: "obj_account"}
+	contact := metadata.ObjectDefinition{ID: uuid.New(), APIName: "Contact", TableName: "obj_contact"}
+	task := metadata.ObjectDefinition{ID: uuid.New(), APIName: "Task", TableName: "obj_task"}
+	plan := &engine.MergePlan{MasterID: "m", DuplicateIDs: []string{"d1", "d2"}}
+
+	tests := []struct {
+		name     string
+		rel      metadata.RelationshipInfo
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "lookup",
+			rel:      metadata.RelationshipInfo{FieldAPIName: "account_id", ChildObjectID: contact.ID, ReferenceSubtype: metadata.SubtypeAssociation},
+			wantSQL:  `UPDATE "obj_contact" SET "account_id" = $1, system_version = system_version + 1 WHERE "account_id" = ANY($2::uuid[]) AND id <> $1`,
+			wantArgs: []any{"m", []string{"d1", "d2"}},
+		},
+		{
+			name:     "polymorphic reference of the merged object type",
+			rel:      metadata.RelationshipInfo{FieldAPIName: "what", ChildObjectID: task.ID, ReferenceSubtype: metadata.SubtypePolymorphic},
+			wantSQL:  `UPDATE "obj_task" SET "what_record_id" = $1, system_version = system_version + 1 WHERE "what_record_id" = ANY($2::uuid[]) AND "what_object_type" = $3 AND id <> $1`,
+			wantArgs: []any{"m", []string{"d1", "d2"}, "Account"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cache := &fakeMetadata{
+				objects: []metadata.ObjectDefinition{account, contact, task},
+				reverse: map[uuid.UUID][]metadata.RelationshipInfo{account.ID: {tt.rel}},
+			}
+			db := &fakeDB{rowsAffected: 2}
+
+			changed, err := NewMerger(cache).reparent(context.Background(), db, account, plan)
+			require.NoError(t, err)
+			require.Len(t, db.execs, 1)
+			assert.Equal(t, tt.wantSQL, db.execs[0].sql)
+			assert.Equal(t, tt.wantArgs, db.execs[0].args)
+
+			child, _ := cache.GetObjectByID(tt.rel.ChildObjectID)
+			assert.Equal(t, []string{child.APIName}, changed)
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package dml
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/dml/engine"
+	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/metadata/ddl"
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/security/fls"
+	"github.com/adverax/crm/internal/platform/security/ols"
+	"github.com/adverax/crm/internal/platform/security/rls"
+)
+
+// RecycleBin carries DELETE and UNDELETE over to the records referencing the
+// deleted ones and purges the recycle bin. Records deleted together share
+// deleted_at, which is how UNDELETE finds the children that went into the bin
+// with a parent: children deleted on their own before stay there.
+type RecycleBin struct {
+	cache       metadata.MetadataReader
+	db          engine.DB
+	logger      *slog.Logger
+	retention   time.Duration
+	interval    time.Duration
+	now         func() time.Time
+	olsEnforcer ols.Enforcer
+	flsEnforcer fls.Enforcer
+	rlsEnforcer rls.Enforcer
+}
+
+// RecycleBinOption configures a RecycleBin.
+type RecycleBinOption func(*RecycleBin)
+
+// WithRecycleBinRetention purges deleted records older than d. 0 keeps them
+// until they are undeleted.
+func WithRecycleBinRetention(d time.Duration) RecycleBinOption {
+	return func(b *RecycleBin) {
+		b.retention = d
+	}
+}
+
+// NewRecycleBin creates a new RecycleBin. db is used by Purge only; the
+// cascades run on the transaction of the statement.
+func NewRecycleBin(cache metadata.MetadataReader, db engine.DB, logger *slog.Logger, opts ...RecycleBinOption) *RecycleBin {
+	b := &RecycleBin{
+		cache:     cache,
+		db:        db,
+		logger:    logger,
+		retention: 15 * 24 * time.Hour,
+		interval:  time.Hour,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// SetDeleteAccess makes a DELETE check that the user may change the children
+// it carries over to: OLS on the object of the children, FLS on the reference
+// a set_null clears and RLS on each child record. Without enforcers, or in
+// system context, the children are changed unchecked.
+func (b *RecycleBin) SetDeleteAccess(olsEnforcer ols.Enforcer, flsEnforcer fls.Enforcer, rlsEnforcer rls.Enforcer) {
+	b.olsEnforcer = olsEnforcer
+	b.flsEnforcer = flsEnforcer
+	b.rlsEnforcer = rlsEnforcer
+}
+
+// Cascaded is what a DELETE or UNDELETE did beyond its own records.
+type Cascaded struct {
+	// Objects lists the further objects whose records changed.
+	Objects []string
+
+	// Deletes lists the composition children a DELETE moved into the bin.
+	Deletes []CascadedDelete
+}
+
+// CascadedDelete is a set of composition children of one object deleted
+// together with their parents.
+type CascadedDelete struct {
+	Object string
+	IDs    []string
+}
+
+// add appends the cascade of another statement.
+func (c *Cascaded) add(other Cascaded) {
+	c.Objects = append(c.Objects, other.Objects...)
+	c.Deletes = append(c.Deletes, other.Deletes...)
+}
+
+// Cascade completes a soft DELETE or UNDELETE executed on tx. A DELETE stamps
+// the deleting user and applies the on_delete action of every relationship
+// referencing the records; an UNDELETE restores the composition children
+// deleted with them. Objects without the recycle bin are deleted permanently
+// and never get here.
+func (b *RecycleBin) Cascade(ctx context.Context, tx engine.DB, compiled *engine.CompiledDML, result *engine.Result) (Cascaded, error) {
+	var cascaded Cascaded
+	obj, ok := b.cache.GetObjectByAPIName(compiled.Object)
+	if !ok {
+		return cascaded, nil
+	}
+
+	switch compiled.Operation {
+	case engine.OperationDelete:
+		if len(result.DeletedIds) == 0 {
+			return cascaded, nil
+		}
+		deletedBy := currentUserID(ctx)
+		if _, err := tx.Exec(ctx,
+			fmt.Sprintf("UPDATE %s SET deleted_by = $1 WHERE id = ANY($2::uuid[])", quoteTable(obj.TableName)),
+			deletedBy, result.DeletedIds); err != nil {
+			return Cascaded{}, fmt.Errorf("recycleBin.Cascade: %w", err)
+		}
+		if err := b.deleteChildren(ctx, tx, obj.ID, result.DeletedIds, deletedBy, &cascaded); err != nil {
+			return Cascaded{}, fmt.Errorf("recycleBin.Cascade: %w", err)
+		}
+	case engine.OperationUndelete:
+		if len(result.UndeletedIds) == 0 {
+			return cascaded, nil
+		}
+		if err := b.undeleteChildren(ctx, tx, obj, result.UndeletedIds, &cascaded.Objects); err != nil {
+			return Cascaded{}, fmt.Errorf("recycleBin.Cascade: %w", err)
+		}
+	}
+	return cascaded, nil
+}
+
+// deleteChildren applies the on_delete action of each relationship
+// referencing the deleted records parentIDs, recursively: restrict fails
+// while a live record references one of them, set_null clears the reference
+// and cascade moves the children into the bin. Polymorphic references are not
+// followed.
+func (b *RecycleBin) deleteChildren(ctx context.Context, tx engine.DB, parentID uuid.UUID, parentIDs []string, deletedBy *uuid.UUID, cascaded *Cascaded) error {
+	for _, rel := range b.cache.GetReverseRelationships(parentID) {
+		child, ok := b.cache.GetObjectByID(rel.ChildObjectID)
+		if !ok {
+			continue
+		}
+		table := quoteTable(child.TableName)
+		column := pgx.Identifier{rel.FieldAPIName}.Sanitize()
+
+		switch onDeleteAction(rel) {
+		case "restrict":
+			var n int
+			if err := tx.QueryRow(ctx, fmt.Sprintf(
+				"SELECT count(*) FROM %s WHERE %s = ANY($1::uuid[]) AND NOT is_deleted", table, column),
+				parentIDs).Scan(&n); err != nil {
+				return fmt.Errorf("check %s.%s: %w", child.APIName, rel.FieldAPIName, err)
+			}
+			if n > 0 {
+				return apperror.Conflict(fmt.Sprintf("cannot delete: %s reference the records through %s",
+					countLabel(n, child), rel.FieldAPIName))
+			}
+
+		case "set_null":
+			if err := b.checkUpdateAccess(ctx, tx, child, rel.FieldID, column, parentIDs); err != nil {
+				return err
+			}
+			// The reference stays cleared when the records are undeleted.
+			tag, err := tx.Exec(ctx, fmt.Sprintf(
+				"UPDATE %s SET %s = NULL, %s = %s + 1 WHERE %s = ANY($1::uuid[])",
+				table, column, ddl.VersionColumn, ddl.VersionColumn, column),
+				parentIDs)
+			if err != nil {
+				return fmt.Errorf("clear %s.%s: %w", child.APIName, rel.FieldAPIName, err)
+			}
+			if tag.RowsAffected() > 0 {
+				cascaded.Objects = append(cascaded.Objects, child.APIName)
+			}
+
+		case "cascade":
+			if err := b.checkDeleteAccess(ctx, tx, child, column, parentIDs); err != nil {
+				return err
+			}
+			ids, err := queryIDs(ctx, tx, fmt.Sprintf(
+				`UPDATE %s SET is_deleted = true, deleted_at = now(), deleted_by = $1
+				  WHERE %s = ANY($2::uuid[]) AND NOT is_deleted
+				  RETURNING id`,
+				table, column),
+				deletedBy, parentIDs)
+			if err != nil {
+				return fmt.Errorf("delete %s: %w", child.APIName, err)
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			cascaded.Objects = append(cascaded.Objects, child.APIName)
+			cascaded.Deletes = append(cascaded.Deletes, CascadedDelete{Object: child.APIName, IDs: ids})
+			if err := b.deleteChildren(ctx, tx, child.ID, ids, deletedBy, cascaded); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkDeleteAccess fails when the user may not delete every live child of
+// parentIDs: when OLS denies deleting child records at all, or when RLS does
+// not let the user edit some of the children.
+func (b *RecycleBin) checkDeleteAccess(ctx context.Context, tx engine.DB, child metadata.ObjectDefinition, column string, parentIDs []string) error {
+	uc, ok := security.UserFromContext(ctx)
+	if !ok || uc.UserID == uuid.Nil || b.olsEnforcer == nil || b.rlsEnforcer == nil {
+		return nil
+	}
+	if err := b.olsEnforcer.CanDelete(ctx, uc.UserID, child.ID); err != nil {
+		return denyChildren(ctx, tx, child, column, parentIDs, "access")
+	}
+	return b.checkUneditableChildren(ctx, tx, uc.UserID, child, column, parentIDs, "access")
+}
+
+// checkUpdateAccess fails when the user may not clear the reference column,
+// field fieldID of child, on every live child of parentIDs: when OLS denies
+// updating child records, when FLS denies editing the reference, or when RLS
+// does not let the user edit some of the children.
+func (b *RecycleBin) checkUpdateAccess(ctx context.Context, tx engine.DB, child metadata.ObjectDefinition, fieldID uuid.UUID, column string, parentIDs []string) error {
+	uc, ok := security.UserFromContext(ctx)
+	if !ok || uc.UserID == uuid.Nil || b.olsEnforcer == nil || b.flsEnforcer == nil || b.rlsEnforcer == nil {
+		return nil
+	}
+	if err := b.olsEnforcer.CanUpdate(ctx, uc.UserID, child.ID); err != nil {
+		return denyChildren(ctx, tx, child, column, parentIDs, "edit")
+	}
+	if err := b.flsEnforcer.CanWriteField(ctx, uc.UserID, fieldID); err != nil {
+		return denyChildren(ctx, tx, child, column, parentIDs, "edit")
+	}
+	return b.checkUneditableChildren(ctx, tx, uc.UserID, child, column, parentIDs, "edit")
+}
+
+// denyChildren fails with Forbidden when parentIDs have live children of
+// child, which the user may not access in the way verb says.
+func denyChildren(ctx context.Context, tx engine.DB, child metadata.ObjectDefinition, column string, parentIDs []string, verb string) error {
+	var n int
+	if err := tx.QueryRow(ctx, fmt.Sprintf(
+		"SELECT count(*) FROM %s WHERE %s = ANY($1::uuid[]) AND NOT is_deleted", quoteTable(child.TableName), column),
+		parentIDs).Scan(&n); err != nil {
+		return fmt.Errorf("count %s: %w", child.APIName, err)
+	}
+	if n > 0 {
+		return apperror.Forbidden(fmt.Sprintf("cannot delete: %s you cannot %s", countLabel(n, child), verb))
+	}
+	return nil
+}
+
+// checkUneditableChildren fails with Forbidden when RLS does not let userID
+// edit or delete some of the live children of parentIDs, including children
+// the user can read but not edit.
+func (b *RecycleBin) checkUneditableChildren(ctx context.Context, tx engine.DB, userID uuid.UUID, child metadata.ObjectDefinition, column string, parentIDs []string, verb string) error {
+	clause, params, err := b.rlsEnforcer.BuildEditWhereClause(ctx, userID, child.ID)
+	if err != nil {
+		return fmt.Errorf("rls %s: %w", child.APIName, err)
+	}
+	if clause == "" || clause == "TRUE" {
+		return nil
+	}
+	var hidden int
+	if err := tx.QueryRow(ctx, fmt.Sprintf(
+		"SELECT count(*) FROM %s WHERE %s = ANY($%d::uuid[]) AND NOT is_deleted AND NOT COALESCE((%s), false)",
+		quoteTable(child.TableName), column, len(params)+1, clause),
+		append(params, parentIDs)...).Scan(&hidden); err != nil {
+		return fmt.Errorf("check access to %s: %w", child.APIName, err)
+	}
+	if hidden > 0 {
+		return apperror.Forbidden(fmt.Sprintf("cannot delete: %s you cannot %s", countLabel(hidden, child), verb))
+	}
+	return nil
+}
+
+// onDeleteAction returns what deleting a parent does to the records of rel:
+// "restrict", "set_null", "cascade", or "" for references not followed.
+// Relationships without an explicit action get the default of their subtype.
+func onDeleteAction(rel metadata.RelationshipInfo) string {
+	switch rel.ReferenceSubtype {
+	case metadata.SubtypePolymorphic:
+		return ""
+	case metadata.SubtypeComposition:
+		if rel.OnDelete == "" {
+			return "cascade"
+		}
+	case metadata.SubtypeAssociation:
+		if rel.OnDelete == "" {
+			return "set_null"
+		}
+	}
+	return rel.OnDelete
+}
+
+// countLabel spells n records of obj, as in "1 Contact" or "12 Contacts".
+func countLabel(n int, obj metadata.ObjectDefinition) string {
+	label := obj.PluralLabel
+	if n == 1 {
+		label = obj.Label
+	}
+	if label == "" {
+		label = obj.APIName
+	}
+	return fmt.Sprintf("%d %s", n, label)
+}
+
+// undeleteChildren restores the composition children deleted together with
+// the restored records ids of obj, recursively, and then clears the deletion
+// stamp of those records.
+func (b *RecycleBin) undeleteChildren(ctx context.Context, tx engine.DB, obj metadata.ObjectDefinition, ids []string, changed *[]string) error {
+	for _, rel := range b.compositions(obj.ID) {
+		child, ok := b.cache.GetObjectByID(rel.ChildObjectID)
+		if !ok {
+			continue
+		}
+		childIDs, err := queryIDs(ctx, tx, fmt.Sprintf(
+			`UPDATE %s c SET is_deleted = false
+			   FROM %s p
+			  WHERE p.id = ANY($1::uuid[]) AND c.%s = p.id
+			    AND c.is_deleted AND c.deleted_at = p.deleted_at
+			  RETURNING c.id`,
+			quoteTable(child.TableName), quoteTable(obj.TableName), pgx.Identifier{rel.FieldAPIName}.Sanitize()),
+			ids)
+		if err != nil {
+			return fmt.Errorf("undelete %s: %w", child.APIName, err)
+		}
+		if len(childIDs) == 0 {
+			continue
+		}
+		*changed = append(*changed, child.APIName)
+		if err := b.undeleteChildren(ctx, tx, child, childIDs, changed); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx,
+		fmt.Sprintf("UPDATE %s SET deleted_at = NULL, deleted_by = NULL WHERE id = ANY($1::uuid[])", quoteTable(obj.TableName)),
+		ids); err != nil {
+		return fmt.Errorf("clear %s: %w", obj.APIName, err)
+	}
+	return nil
+}
+
+// compositions returns the composition relationships whose children follow
+// parentID out of the bin.
+func (b *RecycleBin) compositions(parentID uuid.UUID) []metadata.RelationshipInfo {
+	var rels []metadata.RelationshipInfo
+	for _, rel := range b.cache.GetReverseRelationships(parentID) {
+		if rel.ReferenceSubtype == metadata.SubtypeComposition && onDeleteAction(rel) == "cascade" {
+			rels = append(rels, rel)
+		}
+	}
+	return rels
+}
+
+// Run purges the bin on start and then every interval until ctx is
+// cancelled. It does nothing when retention is 0.
+func (b *RecycleBin) Run(ctx context.Context) error {
+	if b.retention <= 0 {
+		return nil
+	}
+	b.Purge(ctx)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			b.Purge(ctx)
+		}
+	}
+}
+
+// Purge permanently deletes the records that have been in the bin longer
+// than the retention period. The foreign keys of the object tables delete
+// the composition children and clear the association references. An object
+// whose records cannot be deleted is logged and skipped.
+func (b *RecycleBin) Purge(ctx context.Context) {
+	cutoff := b.now().Add(-b.retention)
+	for _, name := range b.cache.ListObjectAPINames() {
+		obj, ok := b.cache.GetObjectByAPIName(name)
+		if !ok {
+			continue
+		}
+		tag, err := b.db.Exec(ctx,
+			fmt.Sprintf("DELETE FROM %s WHERE is_deleted AND deleted_at < $1", quoteTable(obj.TableName)),
+			cutoff)
+		if err != nil {
+			b.logger.Error("recycle bin: purge failed", "object", name, "error", err)
+			continue
+		}
+		if n := tag.RowsAffected(); n > 0 {
+			b.logger.Info("recycle bin: records purged", "object", name, "count", n)
+		}
+	}
+}
+
+// queryIDs runs a statement returning ids.
+func queryIDs(ctx context.Context, db engine.DB, sql string, args ...any) ([]string, error) {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// currentUserID returns the user of ctx, or nil in system context.
+func currentUserID(ctx context.Context) *uuid.UUID {
+	uc, ok := security.UserFromContext(ctx)
+	if !ok || uc.UserID == uuid.Nil {
+		return nil
+	}
+	return &uc.UserID
+}
+
+func quoteTable(tableName string) string {
+	return pgx.Identifier{tableName}.Sanitize()
+}
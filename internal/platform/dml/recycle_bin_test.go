@@ -0,0 +1,288 @@
+package dml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/dml/engine"
+	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/security/fls"
+	"github.com/adverax/crm/internal/platform/security/ols"
+	"github.com/adverax/crm/internal/platform/security/rls"
+)
+
+func TestOnDeleteAction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		rel  metadata.RelationshipInfo
+		want string
+	}{
+		{
+			name: "association defaults to set_null",
+			rel:  metadata.RelationshipInfo{ReferenceSubtype: metadata.SubtypeAssociation},
+			want: "set_null",
+		},
+		{
+			name: "association restrict",
+			rel:  metadata.RelationshipInfo{ReferenceSubtype: metadata.SubtypeAssociation, OnDelete: "restrict"},
+			want: "restrict",
+		},
+		{
+			name: "composition defaults to cascade",
+			rel:  metadata.RelationshipInfo{ReferenceSubtype: metadata.SubtypeComposition},
+			want: "cascade",
+		},
+		{
+			name: "composition cascade",
+			rel:  metadata.RelationshipInfo{ReferenceSubtype: metadata.SubtypeComposition, OnDelete: "cascade"},
+			want: "cascade",
+		},
+		{
+			name: "composition restrict",
+			rel:  metadata.RelationshipInfo{ReferenceSubtype: metadata.SubtypeComposition, OnDelete: "restrict"},
+			want: "restrict",
+		},
+		{
+			name: "polymorphic is not followed",
+			rel:  metadata.RelationshipInfo{ReferenceSubtype: metadata.SubtypePolymorphic, OnDelete: "set_null"},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, onDeleteAction(tt.rel))
+		})
+	}
+}
+
+func TestCountLabel(t *testing.T) {
+	t.Parallel()
+
+	contact := metadata.ObjectDefinition{APIName: "Contact", Label: "Contact", PluralLabel: "Contacts"}
+	tests := []struct {
+		name string
+		n    int
+		obj  metadata.ObjectDefinition
+		want string
+	}{
+		{name: "singular", n: 1, obj: contact, want: "1 Contact"},
+		{name: "plural", n: 12, obj: contact, want: "12 Contacts"},
+		{name: "no labels", n: 3, obj: metadata.ObjectDefinition{APIName: "Invoice_Line"}, want: "3 Invoice_Line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, countLabel(tt.n, tt.obj))
+		})
+	}
+}
+
+func TestCascadedAdd(t *testing.T) {
+	t.Parallel()
+
+	var c Cascaded
+	c.add(Cascaded{Objects: []string{"Contact"}, Deletes: []CascadedDelete{{Object: "Contact", IDs: []string{"a"}}}})
+	c.add(Cascaded{})
+	c.add(Cascaded{Objects: []string{"Task"}})
+
+	assert.Equal(t, []string{"Contact", "Task"}, c.Objects)
+	assert.Equal(t, []CascadedDelete{{Object: "Contact", IDs: []string{"a"}}}, c.Deletes)
+}
+
+// fakeOLS denies updating the objects in denyUpdate.
+type fakeOLS struct {
+	ols.Enforcer
+	denyUpdate map[uuid.UUID]bool
+}
+
+func (e *fakeOLS) CanUpdate(_ context.Context, _, objectID uuid.UUID) error {
+	if e.denyUpdate[objectID] {
+		return apperror.Forbidden("insufficient object permissions: update")
+	}
+	return nil
+}
+
+// fakeFLS denies writing the fields in denyWrite.
+type fakeFLS struct {
+	fls.Enforcer
+	denyWrite map[uuid.UUID]bool
+}
+
+func (e *fakeFLS) CanWriteField(_ context.Context, _, fieldID uuid.UUID) error {
+	if e.denyWrite[fieldID] {
+		return apperror.Forbidden("insufficient field-level permissions: write")
+	}
+	return nil
+}
+
+// fakeRLS restricts every object to clause.
+type fakeRLS struct {
+	rls.Enforcer
+	clause string
+}
+
+func (e *fakeRLS) BuildEditWhereClause(context.Context, uuid.UUID, uuid.UUID) (string, []interface{}, error) {
+	return e.clause, nil, nil
+}
+
+func TestRecycleBinSetNullAccess(t *testing.T) {
+	t.Parallel()
+
+	account := metadata.ObjectDefinition{ID: uuid.New(), APIName: "Account", TableName: "obj_account"}
+	contact := metadata.ObjectDefinition{
+		ID: uuid.New(), APIName: "Contact", TableName: "obj_contact",
+		Label: "Contact", PluralLabel: "Contacts",
+	}
+	accountField := uuid.New()
+	cache := &fakeMetadata{
+		objects: []metadata.ObjectDefinition{account, contact},
+		reverse: map[uuid.UUID][]metadata.RelationshipInfo{
+			account.ID: {{
+				FieldID: accountField, FieldAPIName: "account_id", ChildObjectID: contact.ID,
+				ReferenceSubtype: metadata.SubtypeAssociation,
+			}},
+		},
+	}
+	user := security.ContextWithUser(context.Background(), security.UserContext{UserID: uuid.New()})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		ols     *fakeOLS
+		fls     *fakeFLS
+		rls     *fakeRLS
+		count   int
+		wantErr string
+	}{
+		{
+			name:    "object not updatable",
+			ctx:     user,
+			ols:     &fakeOLS{denyUpdate: map[uuid.UUID]bool{contact.ID: true}},
+			count:   2,
+			wantErr: "cannot delete: 2 Contacts you cannot edit",
+		},
+		{
+			name:    "reference not writable",
+			ctx:     user,
+			fls:     &fakeFLS{denyWrite: map[uuid.UUID]bool{accountField: true}},
+			count:   1,
+			wantErr: "cannot delete: 1 Contact you cannot edit",
+		},
+		{
+			name:    "children hidden by RLS",
+			ctx:     user,
+			rls:     &fakeRLS{clause: "owner_id = $1"},
+			count:   3,
+			wantErr: "cannot delete: 3 Contacts you cannot edit",
+		},
+		{
+			name:    "children readable but not editable",
+			ctx:     user,
+			rls:     &fakeRLS{clause: "owner_id = $1 OR id IN (SELECT record_id FROM obj_contact__share WHERE access_level = 'read_write')"},
+			count:   1,
+			wantErr: "cannot delete: 1 Contact you cannot edit",
+		},
+		{
+			name: "object not updatable without live children",
+			ctx:  user,
+			ols:  &fakeOLS{denyUpdate: map[uuid.UUID]bool{contact.ID: true}},
+		},
+		{
+			name: "all children editable",
+			ctx:  user,
+			rls:  &fakeRLS{clause: "owner_id = $1"},
+		},
+		{
+			name:  "system context",
+			ctx:   context.Background(),
+			ols:   &fakeOLS{denyUpdate: map[uuid.UUID]bool{contact.ID: true}},
+			count: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tt.ols == nil {
+				tt.ols = &fakeOLS{}
+			}
+			if tt.fls == nil {
+				tt.fls = &fakeFLS{}
+			}
+			if tt.rls == nil {
+				tt.rls = &fakeRLS{clause: "TRUE"}
+			}
+			b := NewRecycleBin(cache, nil, nil)
+			b.SetDeleteAccess(tt.ols, tt.fls, tt.rls)
+			db := &fakeDB{count: tt.count, rowsAffected: 1}
+
+			var cascaded Cascaded
+			err := b.deleteChildren(tt.ctx, db, account.ID, []string{"a"}, nil, &cascaded)
+			if tt.wantErr != "" {
+				var appErr *apperror.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, apperror.CodeForbidden, appErr.Code)
+				assert.Equal(t, tt.wantErr, appErr.Message)
+				assert.Empty(t, db.execs)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, db.execs, 1)
+			assert.Contains(t, db.execs[0].sql, `SET "account_id" = NULL`)
+			assert.Equal(t, []string{"Contact"}, cascaded.Objects)
+		})
+	}
+}
+
+// stubExec returns result for every statement.
+type stubExec struct {
+	result *engine.Result
+}
+
+func (e *stubExec) Execute(context.Context, *engine.CompiledDML) (*engine.Result, error) {
+	return e.result, nil
+}
+
+// A hard DELETE runs on its own: the foreign keys apply on_delete, and the
+// recycle bin does not walk the children or check access to them.
+func TestHardDeleteSkipsOnDelete(t *testing.T) {
+	t.Parallel()
+
+	account := metadata.ObjectDefinition{ID: uuid.New(), APIName: "Account", TableName: "obj_account"}
+	contact := metadata.ObjectDefinition{ID: uuid.New(), APIName: "Contact", TableName: "obj_contact"}
+	cache := &fakeMetadata{
+		objects: []metadata.ObjectDefinition{account, contact},
+		reverse: map[uuid.UUID][]metadata.RelationshipInfo{
+			account.ID: {{
+				FieldID: uuid.New(), FieldAPIName: "account_id", ChildObjectID: contact.ID,
+				ReferenceSubtype: metadata.SubtypeAssociation,
+			}},
+		},
+	}
+	b := NewRecycleBin(cache, nil, nil)
+	b.SetDeleteAccess(&fakeOLS{denyUpdate: map[uuid.UUID]bool{contact.ID: true}}, &fakeFLS{}, &fakeRLS{clause: "TRUE"})
+	s := &dmlService{bin: b}
+	ctx := security.ContextWithUser(context.Background(), security.UserContext{UserID: uuid.New()})
+
+	compiled := &engine.CompiledDML{Operation: engine.OperationDelete, Object: "Account"}
+	assert.False(t, s.needsTx(compiled))
+	assert.True(t, s.needsTx(&engine.CompiledDML{Operation: engine.OperationDelete, Object: "Account", SoftDelete: true}))
+
+	deleted := &engine.Result{RowsAffected: 1, DeletedIds: []string{"a"}}
+	result, cascaded, err := s.executeInTx(ctx, nil, &stubExec{result: deleted}, compiled)
+	require.NoError(t, err)
+	assert.Equal(t, deleted, result)
+	assert.Equal(t, Cascaded{}, cascaded)
+}
@@ -59,6 +59,18 @@ func (m *mockCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]metadata.Sh
 	return nil, nil
 }
 
+func (m *mockCacheLoader) LoadAllSavedQueries(_ context.Context) ([]metadata.SavedQuery, error) {
+	return nil, nil
+}
+
+func (m *mockCacheLoader) LoadAllPicklistValues(_ context.Context) ([]metadata.PicklistValueRow, error) {
+	return nil, nil
+}
+
+func (m *mockCacheLoader) LoadAllTranslations(_ context.Context) ([]metadata.Translation, error) {
+	return nil, nil
+}
+
 func (m *mockCacheLoader) RefreshMaterializedView(_ context.Context) error {
 	return nil
 }
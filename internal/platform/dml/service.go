@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/adverax/crm/internal/pkg/apperror"
 	"github.com/adverax/crm/internal/platform/dml/engine"
+	"github.com/adverax/crm/internal/platform/idempotency"
+	"github.com/adverax/crm/internal/platform/querylog"
+	"github.com/adverax/crm/internal/platform/security"
 )
 
 // PostExecuteHook is called after a successful DML execute (Stage 8: Automation).
@@ -17,6 +22,18 @@ type PostExecuteHook interface {
 	AfterDMLExecute(ctx context.Context, compiled *engine.CompiledDML, result *engine.Result) error
 }
 
+// WriteRecorder is notified of the user whose DML has committed. SOQL uses it
+// to keep that user's queries on the primary while a read replica catches up.
+type WriteRecorder interface {
+	RecordWrite(userID uuid.UUID)
+}
+
+// ChangeListener is notified of the objects whose records committed
+// statements changed. The SOQL result cache uses it to drop stale results.
+type ChangeListener interface {
+	ObjectsChanged(ctx context.Context, objects []string)
+}
+
 // TxExecutor is an executor that supports transaction-scoped variants.
 type TxExecutor interface {
 	engine.Executor
@@ -27,6 +44,8 @@ type TxExecutor interface {
 type DMLService interface {
 	Execute(ctx context.Context, statement string) (*Result, error)
 	ExecuteBatch(ctx context.Context, statements []string) ([]*Result, error)
+	ExecutePartial(ctx context.Context, statements []string) (*BatchResult, error)
+	ExecuteComposite(ctx context.Context, requests []SubRequest) ([]SubResult, error)
 	Prepare(ctx context.Context, statement string) (*engine.CompiledDML, error)
 	SetPostExecuteHook(hook PostExecuteHook)
 }
@@ -36,15 +55,83 @@ type dmlService struct {
 	engine       *engine.Engine
 	executor     TxExecutor
 	postExecHook PostExecuteHook
+	writes       WriteRecorder
+	changes      ChangeListener
+	log          querylog.Recorder
+	bin          *RecycleBin
+	merger       *Merger
+	reads        ReadAccessController
+	records      RecordReader
+}
+
+// DMLServiceOption configures a DMLService.
+type DMLServiceOption func(*dmlService)
+
+// WithWriteRecorder reports every committed statement to recorder.
+func WithWriteRecorder(recorder WriteRecorder) DMLServiceOption {
+	return func(s *dmlService) {
+		s.writes = recorder
+	}
+}
+
+// WithChangeListener reports the target objects of committed statements to listener.
+func WithChangeListener(listener ChangeListener) DMLServiceOption {
+	return func(s *dmlService) {
+		s.changes = listener
+	}
+}
+
+// WithQueryLog reports every executed statement to recorder.
+func WithQueryLog(recorder querylog.Recorder) DMLServiceOption {
+	return func(s *dmlService) {
+		s.log = recorder
+	}
+}
+
+// WithRecycleBin cascades soft DELETE and UNDELETE to composition children
+// in the transaction of the statement.
+func WithRecycleBin(bin *RecycleBin) DMLServiceOption {
+	return func(s *dmlService) {
+		s.bin = bin
+	}
+}
+
+// WithMerger enables MERGE. Without a merger MERGE statements are rejected.
+func WithMerger(merger *Merger) DMLServiceOption {
+	return func(s *dmlService) {
+		s.merger = merger
+	}
+}
+
+// WithReadAccessController lets composite requests reference fields other
+// than Id, checking read access to them with reads.
+func WithReadAccessController(reads ReadAccessController) DMLServiceOption {
+	return func(s *dmlService) {
+		s.reads = reads
+	}
+}
+
+// WithRecordReader reports the current values of the record when a statement
+// guarded by a record version (WHERE Id = '...' AND SystemVersion = n) finds
+// the record changed. Without a reader such a statement affects no record
+// and does not fail.
+func WithRecordReader(records RecordReader) DMLServiceOption {
+	return func(s *dmlService) {
+		s.records = records
+	}
 }
 
 // NewDMLService creates a new DMLService.
-func NewDMLService(pool *pgxpool.Pool, eng *engine.Engine, executor TxExecutor) DMLService {
-	return &dmlService{
+func NewDMLService(pool *pgxpool.Pool, eng *engine.Engine, executor TxExecutor, opts ...DMLServiceOption) DMLService {
+	s := &dmlService{
 		pool:     pool,
 		engine:   eng,
 		executor: executor,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // SetPostExecuteHook sets the post-execute hook (automation rules).
@@ -64,21 +151,38 @@ func (s *dmlService) Prepare(ctx context.Context, statement string) (*engine.Com
 // Execute parses, validates, compiles, and executes a DML statement.
 // After successful execution, fires Stage 8 (post-execute / automation rules).
 func (s *dmlService) Execute(ctx context.Context, statement string) (*Result, error) {
+	ctx, started := s.startLog(ctx)
 	compiled, err := s.engine.Prepare(ctx, statement)
 	if err != nil {
-		return nil, fmt.Errorf("dmlService.Execute: %w", mapDMLError(err))
+		err = mapDMLError(err)
+		s.logStatement(ctx, statement, nil, nil, started, err)
+		return nil, fmt.Errorf("dmlService.Execute: %w", err)
 	}
 
-	result, err := s.executor.Execute(ctx, compiled)
+	var result *Result
+	var cascaded Cascaded
+	if s.needsTx(compiled) {
+		result, cascaded, err = s.executeAtomic(ctx, compiled)
+	} else {
+		result, err = s.executor.Execute(ctx, compiled)
+		if err == nil {
+			err = s.checkVersion(ctx, compiled, result)
+		}
+	}
+	s.logStatement(ctx, statement, compiled, result, started, err)
 	if err != nil {
 		return nil, fmt.Errorf("dmlService.Execute: %w", err)
 	}
+	s.recordWrite(ctx, cascaded.Objects, compiled)
 
 	// Stage 8: Post-execute hook (automation rules)
 	if s.postExecHook != nil {
 		if hookErr := s.postExecHook.AfterDMLExecute(ctx, compiled, result); hookErr != nil {
 			return nil, fmt.Errorf("dmlService.Execute: post-execute: %w", hookErr)
 		}
+		if hookErr := s.afterCascade(ctx, cascaded); hookErr != nil {
+			return nil, fmt.Errorf("dmlService.Execute: post-execute: %w", hookErr)
+		}
 	}
 
 	return result, nil
@@ -90,9 +194,12 @@ func (s *dmlService) ExecuteBatch(ctx context.Context, statements []string) ([]*
 	// Phase 1: Prepare all statements (validate before execute)
 	compiled := make([]*engine.CompiledDML, len(statements))
 	for i, stmt := range statements {
-		c, err := s.engine.Prepare(ctx, stmt)
+		stmtCtx, started := s.startLog(ctx)
+		c, err := s.engine.Prepare(stmtCtx, stmt)
 		if err != nil {
-			return nil, fmt.Errorf("dmlService.ExecuteBatch: statement[%d]: %w", i, mapDMLError(err))
+			err = mapDMLError(err)
+			s.logStatement(stmtCtx, stmt, nil, nil, started, err)
+			return nil, fmt.Errorf("dmlService.ExecuteBatch: statement[%d]: %w", i, err)
 		}
 		compiled[i] = c
 	}
@@ -107,8 +214,12 @@ func (s *dmlService) ExecuteBatch(ctx context.Context, statements []string) ([]*
 	txExec := s.executor.WithTx(tx)
 
 	results := make([]*Result, len(compiled))
+	var cascaded Cascaded
 	for i, c := range compiled {
-		r, execErr := txExec.Execute(ctx, c)
+		stmtCtx, started := s.startLog(ctx)
+		r, more, execErr := s.executeInTx(stmtCtx, tx, txExec, c)
+		cascaded.add(more)
+		s.logStatement(stmtCtx, statements[i], c, r, started, execErr)
 		if execErr != nil {
 			return nil, fmt.Errorf("dmlService.ExecuteBatch: statement[%d]: %w", i, execErr)
 		}
@@ -118,6 +229,7 @@ func (s *dmlService) ExecuteBatch(ctx context.Context, statements []string) ([]*
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("dmlService.ExecuteBatch: commit: %w", err)
 	}
+	s.recordWrite(ctx, cascaded.Objects, compiled...)
 
 	// Phase 3: Fire post-execute hooks after successful commit
 	if s.postExecHook != nil {
@@ -126,11 +238,168 @@ func (s *dmlService) ExecuteBatch(ctx context.Context, statements []string) ([]*
 				return nil, fmt.Errorf("dmlService.ExecuteBatch: post-execute[%d]: %w", i, hookErr)
 			}
 		}
+		if hookErr := s.afterCascade(ctx, cascaded); hookErr != nil {
+			return nil, fmt.Errorf("dmlService.ExecuteBatch: post-execute: %w", hookErr)
+		}
 	}
 
 	return results, nil
 }
 
+// needsTx reports whether compiled does more than its own statement: a soft
+// DELETE or UNDELETE cascading to the records referencing it, or a MERGE.
+func (s *dmlService) needsTx(compiled *engine.CompiledDML) bool {
+	return compiled.Merge != nil || (s.bin != nil && compiled.SoftDelete)
+}
+
+// executeAtomic executes compiled and the work that follows it in one
+// transaction. It returns what the statement did beyond its own records.
+func (s *dmlService) executeAtomic(ctx context.Context, compiled *engine.CompiledDML) (*Result, Cascaded, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, Cascaded{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, cascaded, err := s.executeInTx(ctx, tx, s.executor.WithTx(tx), compiled)
+	if err != nil {
+		return nil, Cascaded{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, Cascaded{}, fmt.Errorf("commit: %w", err)
+	}
+	return result, cascaded, nil
+}
+
+// executeInTx executes compiled on tx through exec, followed by the cascade
+// of a soft DELETE or UNDELETE, or by the plan of a MERGE. A hard DELETE is
+// left to the foreign keys: they restrict, clear or cascade to the children
+// without the access checks of the recycle bin.
+func (s *dmlService) executeInTx(ctx context.Context, tx pgx.Tx, exec engine.Executor, compiled *engine.CompiledDML) (*Result, Cascaded, error) {
+	if compiled.Merge != nil {
+		return s.merge(ctx, tx, exec, compiled)
+	}
+	result, err := exec.Execute(ctx, compiled)
+	if err != nil {
+		return nil, Cascaded{}, err
+	}
+	if err := s.checkVersion(ctx, compiled, result); err != nil {
+		return nil, Cascaded{}, err
+	}
+	if s.bin == nil || !compiled.SoftDelete {
+		return result, Cascaded{}, nil
+	}
+	cascaded, err := s.bin.Cascade(ctx, tx, compiled, result)
+	if err != nil {
+		return nil, Cascaded{}, err
+	}
+	return result, cascaded, nil
+}
+
+// merge locks the master, runs the merge plan and deletes the duplicates.
+// The master and every duplicate must be visible to the user: a record RLS
+// hides fails the whole merge.
+func (s *dmlService) merge(ctx context.Context, tx pgx.Tx, exec engine.Executor, compiled *engine.CompiledDML) (*Result, Cascaded, error) {
+	if s.merger == nil {
+		return nil, Cascaded{}, apperror.BadRequest("MERGE is not supported")
+	}
+	plan := compiled.Merge
+
+	master, err := exec.Execute(ctx, compiled)
+	if err != nil {
+		return nil, Cascaded{}, err
+	}
+	if len(master.UpdatedIds) == 0 {
+		return nil, Cascaded{}, apperror.NotFound(compiled.Object, plan.MasterID)
+	}
+
+	changed, err := s.merger.Merge(ctx, tx, compiled)
+	if err != nil {
+		return nil, Cascaded{}, err
+	}
+
+	deleted, cascaded, err := s.executeInTx(ctx, tx, exec, plan.Delete)
+	if err != nil {
+		return nil, Cascaded{}, err
+	}
+	if len(deleted.DeletedIds) != len(plan.DuplicateIDs) {
+		found := make(map[string]bool, len(deleted.DeletedIds))
+		for _, id := range deleted.DeletedIds {
+			found[id] = true
+		}
+		for _, id := range plan.DuplicateIDs {
+			if !found[id] {
+				return nil, Cascaded{}, apperror.NotFound(compiled.Object, id)
+			}
+		}
+	}
+
+	return &Result{
+		RowsAffected: int64(1 + len(deleted.DeletedIds)),
+		UpdatedIds:   master.UpdatedIds,
+		DeletedIds:   deleted.DeletedIds,
+	}, Cascaded{Objects: append(changed, cascaded.Objects...), Deletes: cascaded.Deletes}, nil
+}
+
+// afterCascade fires the post-execute hook for the composition children
+// that DELETE statements cascaded to, as if each set had been deleted by a
+// statement of its own.
+func (s *dmlService) afterCascade(ctx context.Context, cascaded Cascaded) error {
+	for _, d := range cascaded.Deletes {
+		compiled := &engine.CompiledDML{Operation: engine.OperationDelete, Object: d.Object, SoftDelete: true}
+		result := &Result{RowsAffected: int64(len(d.IDs)), DeletedIds: d.IDs}
+		if err := s.postExecHook.AfterDMLExecute(ctx, compiled, result); err != nil {
+			return fmt.Errorf("%s: %w", d.Object, err)
+		}
+	}
+	return nil
+}
+
+// recordWrite reports committed statements of the current user, and marks
+// the request committed for its idempotency key. cascaded lists further
+// objects the statements changed.
+func (s *dmlService) recordWrite(ctx context.Context, cascaded []string, statements ...*engine.CompiledDML) {
+	idempotency.MarkCommitted(ctx)
+	if s.writes != nil {
+		if uc, ok := security.UserFromContext(ctx); ok {
+			s.writes.RecordWrite(uc.UserID)
+		}
+	}
+	if s.changes != nil {
+		objects := make([]string, 0, len(statements)+len(cascaded))
+		for _, c := range statements {
+			objects = append(objects, c.Object)
+		}
+		objects = append(objects, cascaded...)
+		s.changes.ObjectsChanged(ctx, objects)
+	}
+}
+
+// startLog prepares ctx for an execution reported to the query log.
+func (s *dmlService) startLog(ctx context.Context) (context.Context, time.Time) {
+	if s.log == nil {
+		return ctx, time.Time{}
+	}
+	return querylog.Trace(ctx), time.Now()
+}
+
+// logStatement reports an execution started by startLog to the query log.
+// compiled is nil when the statement did not compile.
+func (s *dmlService) logStatement(ctx context.Context, statement string, compiled *engine.CompiledDML, result *Result, started time.Time, err error) {
+	if s.log == nil {
+		return
+	}
+	var object string
+	if compiled != nil {
+		object = compiled.Object
+	}
+	var rows int
+	if result != nil {
+		rows = int(result.RowsAffected)
+	}
+	s.log.Record(querylog.NewEntry(ctx, querylog.KindDML, statement, object, rows, started, err))
+}
+
 // mapDMLError maps engine errors to application errors.
 func mapDMLError(err error) error {
 	var ruleErr *engine.RuleValidationError
@@ -7,8 +7,8 @@ import (
 // DMLTargetInfo describes the target object and modified fields of a DML statement.
 type DMLTargetInfo struct {
 	Object    string   // target object API name
-	Fields    []string // modified fields (INSERT columns, UPDATE SET fields, UPSERT columns)
-	Operation string   // "insert", "update", "delete", "upsert"
+	Fields    []string // modified fields (INSERT columns, UPDATE SET fields, UPSERT columns, MERGE winners)
+	Operation string   // "insert", "update", "delete", "upsert", "undelete", "merge"
 }
 
 // ExtractTargets parses DML statements and extracts target objects with modified fields.
@@ -41,6 +41,10 @@ func operationString(op engine.Operation) string {
 		return "delete"
 	case engine.OperationUpsert:
 		return "upsert"
+	case engine.OperationUndelete:
+		return "undelete"
+	case engine.OperationMerge:
+		return "merge"
 	default:
 		return "unknown"
 	}
@@ -58,6 +62,12 @@ func extractFields(ast *engine.DMLStatement) []string {
 		return fields
 	case ast.Upsert != nil:
 		return ast.Upsert.Fields
+	case ast.Merge != nil:
+		fields := make([]string, len(ast.Merge.Winners))
+		for i, w := range ast.Merge.Winners {
+			fields[i] = w.Field
+		}
+		return fields
 	case ast.Delete != nil:
 		return nil
 	default:
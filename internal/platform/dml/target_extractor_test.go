@@ -42,6 +42,13 @@ func TestExtractTargets(t *testing.T) {
 				{Object: "Account", Fields: []string{"external_id", "Name", "Industry"}, Operation: "upsert"},
 			},
 		},
+		{
+			name:       "MERGE extracts object and winner fields",
+			statements: []string{"MERGE Account 'm-1' WITH ('d-1', 'd-2') SET Phone FROM 'd-2'"},
+			want: []DMLTargetInfo{
+				{Object: "Account", Fields: []string{"Phone"}, Operation: "merge"},
+			},
+		},
 		{
 			name: "multiple statements",
 			statements: []string{
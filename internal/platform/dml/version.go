@@ -0,0 +1,71 @@
+package dml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/dml/engine"
+)
+
+// RecordReader reads a record the way the user sees it. It returns nil when
+// the record does not exist or is hidden from the user.
+type RecordReader interface {
+	ReadRecord(ctx context.Context, object, id string) (map[string]any, error)
+}
+
+// VersionConflict details the error of a statement guarded by a record
+// version that is no longer current.
+type VersionConflict struct {
+	// Expected is the version the statement was guarded by.
+	Expected int64 `json:"expected"`
+
+	// Current is the record as it is now, SystemVersion included.
+	Current map[string]any `json:"current"`
+}
+
+// checkVersion fails when compiled is guarded by a record version and
+// affected no record: with a conflict carrying the current record when the
+// record has another version, and with not found when the record is gone or
+// hidden from the user.
+func (s *dmlService) checkVersion(ctx context.Context, compiled *engine.CompiledDML, result *Result) error {
+	guard := compiled.Guard
+	if guard == nil || s.records == nil || result.RowsAffected > 0 {
+		return nil
+	}
+	current, err := s.records.ReadRecord(ctx, compiled.Object, guard.RecordID)
+	if err != nil {
+		var appErr *apperror.AppError
+		if !errors.As(err, &appErr) || (appErr.Code != apperror.CodeNotFound && appErr.Code != apperror.CodeForbidden) {
+			return fmt.Errorf("read %s %s: %w", compiled.Object, guard.RecordID, err)
+		}
+		current = nil
+	}
+	if current == nil {
+		return apperror.NotFound(compiled.Object, guard.RecordID)
+	}
+	version, ok := recordVersion(current)
+	if !ok || version == guard.Version {
+		return nil
+	}
+	return apperror.Conflict(fmt.Sprintf("%s %s was changed by someone else: version %d, expected %d",
+		compiled.Object, guard.RecordID, version, guard.Version)).
+		WithDetails(VersionConflict{Expected: guard.Version, Current: current})
+}
+
+// recordVersion returns the SystemVersion of a record read through SOQL.
+func recordVersion(record map[string]any) (int64, bool) {
+	switch v := record["SystemVersion"].(type) {
+	case int64:
+		return v, true
+	case int32:
+		return int64(v), true
+	case int:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,181 @@
+package dml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/dml/engine"
+	"github.com/adverax/crm/internal/platform/soql"
+	soqlengine "github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+type stubRecordReader struct {
+	record map[string]any
+	err    error
+}
+
+func (r *stubRecordReader) ReadRecord(_ context.Context, _, _ string) (map[string]any, error) {
+	return r.record, r.err
+}
+
+func TestCheckVersion(t *testing.T) {
+	t.Parallel()
+
+	guarded := &engine.CompiledDML{
+		Operation: engine.OperationUpdate,
+		Object:    "Account",
+		Guard:     &engine.VersionGuard{RecordID: "a1", Version: 3},
+	}
+	tests := []struct {
+		name      string
+		compiled  *engine.CompiledDML
+		result    *Result
+		reader    *stubRecordReader
+		wantCode  apperror.Code
+		wantError bool
+	}{
+		{
+			name:     "unguarded statement",
+			compiled: &engine.CompiledDML{Operation: engine.OperationUpdate, Object: "Account"},
+			result:   &Result{},
+			reader:   &stubRecordReader{record: map[string]any{"SystemVersion": int64(4)}},
+		},
+		{
+			name:     "record affected",
+			compiled: guarded,
+			result:   &Result{RowsAffected: 1},
+			reader:   &stubRecordReader{record: map[string]any{"SystemVersion": int64(4)}},
+		},
+		{
+			name:     "record gone or hidden",
+			compiled: guarded,
+			result:   &Result{},
+			reader:   &stubRecordReader{},
+			wantCode: apperror.CodeNotFound,
+		},
+		{
+			name:     "object not readable",
+			compiled: guarded,
+			result:   &Result{},
+			reader:   &stubRecordReader{err: fmt.Errorf("read: %w", apperror.Forbidden("no access"))},
+			wantCode: apperror.CodeNotFound,
+		},
+		{
+			name:     "same version",
+			compiled: guarded,
+			result:   &Result{},
+			reader:   &stubRecordReader{record: map[string]any{"SystemVersion": int64(3)}},
+		},
+		{
+			name:     "newer version",
+			compiled: guarded,
+			result:   &Result{},
+			reader:   &stubRecordReader{record: map[string]any{"Id": "a1", "SystemVersion": int64(4)}},
+			wantCode: apperror.CodeConflict,
+		},
+		{
+			name:      "read fails",
+			compiled:  guarded,
+			result:    &Result{},
+			reader:    &stubRecordReader{err: errors.New("boom")},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := &dmlService{records: tt.reader}
+			err := s.checkVersion(context.Background(), tt.compiled, tt.result)
+			switch {
+			case tt.wantCode != "":
+				var appErr *apperror.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.wantCode, appErr.Code)
+				if tt.wantCode == apperror.CodeConflict {
+					assert.Equal(t, VersionConflict{Expected: 3, Current: tt.reader.record}, appErr.Details)
+				}
+			case tt.wantError:
+				var appErr *apperror.AppError
+				assert.Error(t, err)
+				assert.False(t, errors.As(err, &appErr))
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// recordBackend is a result cache backend holding one record for any query,
+// so the read of a conflict runs through the real query service without a
+// database.
+type recordBackend struct {
+	soql.ResultCacheBackend
+	record map[string]any
+}
+
+func (b *recordBackend) Get(context.Context, string) (*soql.CachedResult, bool) {
+	return &soql.CachedResult{Result: &soql.QueryResult{
+		TotalSize: 1, Done: true, Records: []map[string]any{b.record},
+	}}, true
+}
+
+func TestCheckVersion_ReadsThroughQueryService(t *testing.T) {
+	t.Parallel()
+
+	account := soqlengine.NewObjectMeta("Account", "public", "obj_account").
+		Field("Id", "id", soqlengine.FieldTypeID).
+		Field("Name", "name", soqlengine.FieldTypeString).
+		Field("SystemVersion", "system_version", soqlengine.FieldTypeInteger).
+		Build()
+	eng := soqlengine.NewEngine(soqlengine.WithMetadata(
+		soqlengine.NewStaticMetadataProvider(map[string]*soqlengine.ObjectMeta{"Account": account})))
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	current := map[string]any{"Id": id, "Name": "Acme Corp", "SystemVersion": int64(4)}
+	queries := soql.NewQueryService(eng, soql.NewExecutor(nil, nil, nil),
+		soql.WithResultCache(soql.NewResultCache(&recordBackend{record: current}, nil)))
+
+	s := &dmlService{records: soql.NewRecordReader(queries)}
+	err := s.checkVersion(context.Background(), &engine.CompiledDML{
+		Operation: engine.OperationUpdate,
+		Object:    "Account",
+		Guard:     &engine.VersionGuard{RecordID: id, Version: 3},
+	}, &Result{})
+
+	var appErr *apperror.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperror.CodeConflict, appErr.Code)
+	assert.Equal(t, VersionConflict{Expected: 3, Current: current}, appErr.Details)
+}
+
+func TestRecordVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		record map[string]any
+		want   int64
+		wantOK bool
+	}{
+		{name: "int64", record: map[string]any{"SystemVersion": int64(7)}, want: 7, wantOK: true},
+		{name: "int32", record: map[string]any{"SystemVersion": int32(7)}, want: 7, wantOK: true},
+		{name: "float64", record: map[string]any{"SystemVersion": float64(7)}, want: 7, wantOK: true},
+		{name: "missing", record: map[string]any{"Id": "a1"}},
+		{name: "null", record: map[string]any{"SystemVersion": nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := recordVersion(tt.record)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store keeps the responses of requests sent with an idempotency key.
+type Store interface {
+	// Claim stores claim, an entry in progress, under the key of userID
+	// unless the key has a live entry: a completed one created since, or
+	// one in progress created since staleSince. It returns nil when the
+	// key was claimed and the live entry otherwise.
+	Claim(ctx context.Context, userID uuid.UUID, key string, claim Entry, since, staleSince time.Time) (*Entry, error)
+
+	// Complete stores resp in the entry claimed at claimedAt, unless the
+	// claim was taken over since.
+	Complete(ctx context.Context, userID uuid.UUID, key string, claimedAt time.Time, resp Response) error
+
+	// Release removes the entry claimed at claimedAt while it is still in
+	// progress, so the key can be claimed again.
+	Release(ctx context.Context, userID uuid.UUID, key string, claimedAt time.Time) error
+
+	// Purge removes the entries created before cutoff.
+	Purge(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// PgStore is the PostgreSQL implementation of Store. Every call is a
+// statement of its own, so no connection is held while the request runs;
+// the primary key serializes duplicates sent at the same time across server
+// instances.
+type PgStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgStore creates a new PgStore.
+func NewPgStore(pool *pgxpool.Pool) *PgStore {
+	return &PgStore{pool: pool}
+}
+
+// Claim inserts the claim, takes over an expired or stale entry, or returns
+// the live one. A live entry may vanish between the statements when it is
+// purged or released; Claim then tries again.
+func (s *PgStore) Claim(ctx context.Context, userID uuid.UUID, key string, claim Entry, since, staleSince time.Time) (*Entry, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		tag, err := s.pool.Exec(ctx, `
+			INSERT INTO metadata.idempotency_keys (user_id, key, request_hash, status, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, key) DO NOTHING
+		`, userID, key, claim.RequestHash, claim.Status, claim.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("pgIdempotencyStore.Claim: insert: %w", err)
+		}
+		if tag.RowsAffected() == 1 {
+			return nil, nil
+		}
+
+		tag, err = s.pool.Exec(ctx, `
+			UPDATE metadata.idempotency_keys SET
+				request_hash = $3, status = $4, status_code = NULL,
+				content_type = '', body = NULL, created_at = $5
+			WHERE user_id = $1 AND key = $2
+			  AND (created_at < $6 OR (status = $7 AND created_at < $8))
+		`, userID, key, claim.RequestHash, claim.Status, claim.CreatedAt, since, StatusInProgress, staleSince)
+		if err != nil {
+			return nil, fmt.Errorf("pgIdempotencyStore.Claim: take over: %w", err)
+		}
+		if tag.RowsAffected() == 1 {
+			return nil, nil
+		}
+
+		var (
+			e          Entry
+			statusCode *int
+		)
+		err = s.pool.QueryRow(ctx, `
+			SELECT request_hash, status, status_code, content_type, body, created_at
+			FROM metadata.idempotency_keys
+			WHERE user_id = $1 AND key = $2
+		`, userID, key).Scan(
+			&e.RequestHash, &e.Status, &statusCode, &e.Response.ContentType, &e.Response.Body, &e.CreatedAt,
+		)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pgIdempotencyStore.Claim: select: %w", err)
+		}
+		if statusCode != nil {
+			e.Response.StatusCode = *statusCode
+		}
+		return &e, nil
+	}
+	return nil, fmt.Errorf("pgIdempotencyStore.Claim: key %q keeps changing", key)
+}
+
+// Complete stores resp in the claimed entry.
+func (s *PgStore) Complete(ctx context.Context, userID uuid.UUID, key string, claimedAt time.Time, resp Response) error {
+	body := resp.Body
+	if body == nil {
+		body = []byte{} // a completed entry has a body
+	}
+	_, err := s.pool.Exec(ctx, `
+		UPDATE metadata.idempotency_keys SET
+			status = $4, status_code = $5, content_type = $6, body = $7
+		WHERE user_id = $1 AND key = $2 AND created_at = $3 AND status = $8
+	`, userID, key, claimedAt, StatusCompleted, resp.StatusCode, resp.ContentType, body, StatusInProgress)
+	if err != nil {
+		return fmt.Errorf("pgIdempotencyStore.Complete: %w", err)
+	}
+	return nil
+}
+
+// Release removes the claimed entry.
+func (s *PgStore) Release(ctx context.Context, userID uuid.UUID, key string, claimedAt time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		DELETE FROM metadata.idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND created_at = $3 AND status = $4
+	`, userID, key, claimedAt, StatusInProgress)
+	if err != nil {
+		return fmt.Errorf("pgIdempotencyStore.Release: %w", err)
+	}
+	return nil
+}
+
+// Purge removes the entries created before cutoff.
+func (s *PgStore) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, "DELETE FROM metadata.idempotency_keys WHERE created_at < $1", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("pgIdempotencyStore.Purge: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
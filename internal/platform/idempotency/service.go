@@ -0,0 +1,189 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+)
+
+// Service makes requests sent with an idempotency key run once: a retry
+// within the retention period gets the stored response of the first request
+// instead of running again.
+type Service struct {
+	store        Store
+	logger       *slog.Logger
+	retention    time.Duration
+	claimTimeout time.Duration
+	waitTimeout  time.Duration
+	pollInterval time.Duration
+	interval     time.Duration
+	now          func() time.Time
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*Service)
+
+// WithRetention keeps responses for d.
+func WithRetention(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		if d > 0 {
+			s.retention = d
+		}
+	}
+}
+
+// WithClaimTimeout lets a retry take over a key whose request has been in
+// progress for d, as when the server running it went down.
+func WithClaimTimeout(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		if d > 0 {
+			s.claimTimeout = d
+		}
+	}
+}
+
+// WithWaitTimeout makes a duplicate give up waiting for the request in
+// progress after d.
+func WithWaitTimeout(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		if d > 0 {
+			s.waitTimeout = d
+		}
+	}
+}
+
+// NewService creates a new Service.
+func NewService(store Store, logger *slog.Logger, opts ...ServiceOption) *Service {
+	s := &Service{
+		store:        store,
+		logger:       logger,
+		retention:    24 * time.Hour,
+		claimTimeout: 5 * time.Minute,
+		waitTimeout:  30 * time.Second,
+		pollInterval: 100 * time.Millisecond,
+		interval:     time.Hour,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Do runs the request identified by requestHash once per key of userID.
+// The key is claimed before run is called and no store connection is held
+// while it runs. A duplicate sent meanwhile waits for the request to finish
+// and replays its response, or fails with 409 when it is still running after
+// the wait timeout. A key seen before replays its stored response, with
+// replayed set, unless it was used for another request, which fails with
+// 422. Otherwise run handles the request with a context for MarkCommitted;
+// its response is stored unless it is a server error returned before a
+// write was committed, in which case the key is released, so retrying after
+// such a failure runs the request again. Do returns an error only before run
+// is called.
+func (s *Service) Do(ctx context.Context, userID uuid.UUID, key, requestHash string, run func(ctx context.Context) Response) (resp Response, replayed bool, err error) {
+	claimedAt, entry, err := s.claim(ctx, userID, key, requestHash)
+	if err != nil {
+		return Response{}, false, err
+	}
+	if entry != nil {
+		return entry.Response, true, nil
+	}
+
+	// The response may be written by the time the client goes away; the
+	// key is completed or released regardless.
+	storeCtx := context.WithoutCancel(ctx)
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if relErr := s.store.Release(storeCtx, userID, key, claimedAt); relErr != nil {
+			s.logger.Error("idempotency: release failed", "key", key, "error", relErr)
+		}
+	}()
+
+	commits := &commitTrace{}
+	resp = run(context.WithValue(ctx, commitKey{}, commits))
+	if resp.StatusCode >= http.StatusInternalServerError && !commits.committed.Load() {
+		return resp, false, nil
+	}
+	completed = true
+	if err := s.store.Complete(storeCtx, userID, key, claimedAt, resp); err != nil {
+		s.logger.Error("idempotency: complete failed", "key", key, "error", err)
+	}
+	return resp, false, nil
+}
+
+// claim claims the key for the request, polling while a duplicate holds it.
+// It returns the time of the claim, or the completed entry to replay.
+func (s *Service) claim(ctx context.Context, userID uuid.UUID, key, requestHash string) (time.Time, *Entry, error) {
+	timeout := time.NewTimer(s.waitTimeout)
+	defer timeout.Stop()
+	poll := time.NewTicker(s.pollInterval)
+	defer poll.Stop()
+
+	for {
+		claimedAt := s.now()
+		entry, err := s.store.Claim(ctx, userID, key,
+			Entry{RequestHash: requestHash, Status: StatusInProgress, CreatedAt: claimedAt},
+			claimedAt.Add(-s.retention), claimedAt.Add(-s.claimTimeout))
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("idempotencyService.Do: %w", err)
+		}
+		if entry == nil {
+			return claimedAt, nil, nil
+		}
+		if entry.RequestHash != requestHash {
+			return time.Time{}, nil, apperror.Unprocessable(
+				fmt.Sprintf("%s %q was already used for a different request", Header, key))
+		}
+		if entry.Status == StatusCompleted {
+			return claimedAt, entry, nil
+		}
+
+		select {
+		case <-poll.C:
+		case <-timeout.C:
+			return time.Time{}, nil, apperror.Conflict(
+				fmt.Sprintf("a request with %s %q is still in progress", Header, key))
+		case <-ctx.Done():
+			return time.Time{}, nil, fmt.Errorf("idempotencyService.Do: %w", ctx.Err())
+		}
+	}
+}
+
+// Run purges expired entries on start and then every interval until ctx is
+// cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	s.Purge(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.Purge(ctx)
+		}
+	}
+}
+
+// Purge removes the entries older than the retention period.
+func (s *Service) Purge(ctx context.Context) {
+	n, err := s.store.Purge(ctx, s.now().Add(-s.retention))
+	if err != nil {
+		s.logger.Error("idempotency: purge failed", "error", err)
+		return
+	}
+	if n > 0 {
+		s.logger.Info("idempotency: keys purged", "count", n)
+	}
+}
@@ -0,0 +1,333 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// fakeStore keeps entries in memory. Each call takes one of the conns of
+// the store, as a call of PgStore takes a connection of its pool, and gives
+// it back when it returns.
+type fakeStore struct {
+	mu       sync.Mutex
+	entries  map[string]Entry
+	conns    chan struct{}
+	claimErr error
+	claims   int
+	purged   time.Time
+}
+
+func newFakeStore(poolSize int) *fakeStore {
+	return &fakeStore{entries: make(map[string]Entry), conns: make(chan struct{}, poolSize)}
+}
+
+// conn takes a connection and returns the function giving it back.
+func (s *fakeStore) conn(ctx context.Context) (func(), error) {
+	select {
+	case s.conns <- struct{}{}:
+		s.mu.Lock()
+		return func() {
+			s.mu.Unlock()
+			<-s.conns
+		}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *fakeStore) Claim(ctx context.Context, userID uuid.UUID, key string, claim Entry, since, staleSince time.Time) (*Entry, error) {
+	if s.claimErr != nil {
+		return nil, s.claimErr
+	}
+	release, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	s.claims++
+	id := userID.String() + ":" + key
+	if e, ok := s.entries[id]; ok && !e.CreatedAt.Before(since) &&
+		(e.Status == StatusCompleted || !e.CreatedAt.Before(staleSince)) {
+		return &e, nil
+	}
+	s.entries[id] = claim
+	return nil, nil
+}
+
+func (s *fakeStore) Complete(ctx context.Context, userID uuid.UUID, key string, claimedAt time.Time, resp Response) error {
+	release, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	id := userID.String() + ":" + key
+	if e, ok := s.entries[id]; ok && e.CreatedAt.Equal(claimedAt) && e.Status == StatusInProgress {
+		e.Status = StatusCompleted
+		e.Response = resp
+		s.entries[id] = e
+	}
+	return nil
+}
+
+func (s *fakeStore) Release(ctx context.Context, userID uuid.UUID, key string, claimedAt time.Time) error {
+	release, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	id := userID.String() + ":" + key
+	if e, ok := s.entries[id]; ok && e.CreatedAt.Equal(claimedAt) && e.Status == StatusInProgress {
+		delete(s.entries, id)
+	}
+	return nil
+}
+
+// claimCount returns the number of Claim calls so far.
+func (s *fakeStore) claimCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.claims
+}
+
+func (s *fakeStore) Purge(_ context.Context, cutoff time.Time) (int64, error) {
+	s.purged = cutoff
+	return 0, nil
+}
+
+func newTestService(store Store, now time.Time) *Service {
+	s := NewService(store, discardLogger, WithRetention(time.Hour), WithWaitTimeout(20*time.Millisecond))
+	s.now = func() time.Time { return now }
+	s.pollInterval = time.Millisecond
+	return s
+}
+
+func TestServiceDo(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	user := uuid.New()
+	created := Response{StatusCode: http.StatusCreated, ContentType: "application/json", Body: []byte(`{"id":"r1"}`)}
+
+	tests := []struct {
+		name         string
+		stored       *Entry
+		hash         string
+		response     Response
+		commit       bool
+		wantRun      bool
+		wantReplayed bool
+		wantResponse Response
+		wantCode     apperror.Code
+		wantStatus   Status
+	}{
+		{
+			name:         "first request runs and is stored",
+			hash:         "h1",
+			response:     created,
+			wantRun:      true,
+			wantResponse: created,
+			wantStatus:   StatusCompleted,
+		},
+		{
+			name:         "retry replays the stored response",
+			stored:       &Entry{RequestHash: "h1", Status: StatusCompleted, Response: created, CreatedAt: now.Add(-time.Minute)},
+			hash:         "h1",
+			wantReplayed: true,
+			wantResponse: created,
+			wantStatus:   StatusCompleted,
+		},
+		{
+			name:     "key reused for another request",
+			stored:   &Entry{RequestHash: "h1", Status: StatusCompleted, Response: created, CreatedAt: now.Add(-time.Minute)},
+			hash:     "h2",
+			wantCode: apperror.CodeUnprocessable,
+		},
+		{
+			name:         "expired entry runs again",
+			stored:       &Entry{RequestHash: "h1", Status: StatusCompleted, Response: created, CreatedAt: now.Add(-2 * time.Hour)},
+			hash:         "h2",
+			response:     created,
+			wantRun:      true,
+			wantResponse: created,
+			wantStatus:   StatusCompleted,
+		},
+		{
+			name:     "duplicate of a request still in progress after the wait",
+			stored:   &Entry{RequestHash: "h1", Status: StatusInProgress, CreatedAt: now.Add(-time.Minute)},
+			hash:     "h1",
+			wantCode: apperror.CodeConflict,
+		},
+		{
+			name:         "stale claim is taken over",
+			stored:       &Entry{RequestHash: "h1", Status: StatusInProgress, CreatedAt: now.Add(-10 * time.Minute)},
+			hash:         "h1",
+			response:     created,
+			wantRun:      true,
+			wantResponse: created,
+			wantStatus:   StatusCompleted,
+		},
+		{
+			name:         "client errors are stored",
+			hash:         "h1",
+			response:     Response{StatusCode: http.StatusBadRequest},
+			wantRun:      true,
+			wantResponse: Response{StatusCode: http.StatusBadRequest},
+			wantStatus:   StatusCompleted,
+		},
+		{
+			name:         "server errors are not stored",
+			hash:         "h1",
+			response:     Response{StatusCode: http.StatusInternalServerError},
+			wantRun:      true,
+			wantResponse: Response{StatusCode: http.StatusInternalServerError},
+		},
+		{
+			name:         "server errors after a commit are stored",
+			hash:         "h1",
+			response:     Response{StatusCode: http.StatusInternalServerError},
+			commit:       true,
+			wantRun:      true,
+			wantResponse: Response{StatusCode: http.StatusInternalServerError},
+			wantStatus:   StatusCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newFakeStore(1)
+			if tt.stored != nil {
+				store.entries[user.String()+":k"] = *tt.stored
+			}
+			svc := newTestService(store, now)
+
+			ran := false
+			resp, replayed, err := svc.Do(context.Background(), user, "k", tt.hash, func(ctx context.Context) Response {
+				ran = true
+				if tt.commit {
+					MarkCommitted(ctx)
+				}
+				return tt.response
+			})
+			if tt.wantCode != "" {
+				var appErr *apperror.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.wantCode, appErr.Code)
+				assert.False(t, ran)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRun, ran)
+			assert.Equal(t, tt.wantReplayed, replayed)
+			assert.Equal(t, tt.wantResponse, resp)
+
+			assert.Equal(t, tt.wantStatus, store.entries[user.String()+":k"].Status)
+		})
+	}
+}
+
+func TestServiceDo_ClaimFails(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore(1)
+	store.claimErr = errors.New("connection refused")
+	svc := newTestService(store, time.Now())
+
+	_, _, err := svc.Do(context.Background(), uuid.New(), "k", "h", func(context.Context) Response {
+		t.Fatal("run must not be called")
+		return Response{}
+	})
+	assert.Error(t, err)
+}
+
+// The request holds no connection while it runs: with a pool of one, the
+// duplicates sent meanwhile keep polling the key, and replay its response
+// once it finishes.
+func TestServiceDo_ConcurrentDuplicatesOnSmallPool(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore(1)
+	svc := newTestService(store, time.Now())
+	svc.waitTimeout = 5 * time.Second
+	user := uuid.New()
+	ok := Response{StatusCode: http.StatusOK, Body: []byte(`{}`)}
+
+	const duplicates = 4
+	var mu sync.Mutex
+	runs, replays := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < duplicates+1; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, replayed, err := svc.Do(context.Background(), user, "k", "h", func(context.Context) Response {
+				mu.Lock()
+				runs++
+				mu.Unlock()
+				// Every duplicate claims the key at least twice meanwhile.
+				deadline := time.Now().Add(5 * time.Second)
+				for store.claimCount() < 1+2*duplicates {
+					if time.Now().After(deadline) {
+						t.Error("duplicates did not poll while the request ran")
+						break
+					}
+					time.Sleep(time.Millisecond)
+				}
+				return ok
+			})
+			if assert.NoError(t, err) {
+				assert.Equal(t, ok, resp)
+			}
+			if replayed {
+				mu.Lock()
+				replays++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, runs)
+	assert.Equal(t, duplicates, replays)
+}
+
+func TestServiceDo_WaitCancelled(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	store := newFakeStore(1)
+	user := uuid.New()
+	store.entries[user.String()+":k"] = Entry{RequestHash: "h", Status: StatusInProgress, CreatedAt: now}
+	svc := newTestService(store, now)
+	svc.waitTimeout = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err := svc.Do(ctx, user, "k", "h", func(context.Context) Response {
+		t.Fatal("run must not be called")
+		return Response{}
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestServicePurge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newFakeStore(1)
+	newTestService(store, now).Purge(context.Background())
+	assert.Equal(t, now.Add(-time.Hour), store.purged)
+}
@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+)
+
+// Header is the request header carrying the idempotency key.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader marks a response replayed from the store.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// MaxKeyLength is the maximum length of an idempotency key.
+const MaxKeyLength = 255
+
+// Response is a response stored under an idempotency key.
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// Status is the state of an Entry.
+type Status string
+
+const (
+	// StatusInProgress marks a key claimed by a request still running.
+	StatusInProgress Status = "in_progress"
+	// StatusCompleted marks a key whose response is stored.
+	StatusCompleted Status = "completed"
+)
+
+// Entry is an idempotency key as used by a user: the hash of the request it
+// was first sent with and, once completed, the response to that request.
+type Entry struct {
+	RequestHash string
+	Status      Status
+	Response    Response
+	CreatedAt   time.Time
+}
+
+// ValidateKey checks that key is 1 to MaxKeyLength printable ASCII
+// characters.
+func ValidateKey(key string) error {
+	if key == "" || len(key) > MaxKeyLength {
+		return apperror.BadRequest(fmt.Sprintf("%s must be 1 to %d characters", Header, MaxKeyLength))
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return apperror.BadRequest(fmt.Sprintf("%s must be printable ASCII", Header))
+		}
+	}
+	return nil
+}
+
+// RequestHash identifies a request by its method, URI and body, so a key
+// reused for another request is told apart from a retry.
+func RequestHash(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(uri))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type commitKey struct{}
+
+// commitTrace records whether a request run by Service.Do committed a write.
+type commitTrace struct {
+	committed atomic.Bool
+}
+
+// MarkCommitted reports that the request handled with ctx committed a
+// write, so its response is stored even when it is a server error: a retry
+// must not repeat the write. It does nothing outside Service.Do.
+func MarkCommitted(ctx context.Context) {
+	if t, ok := ctx.Value(commitKey{}).(*commitTrace); ok {
+		t.committed.Store(true)
+	}
+}
@@ -0,0 +1,48 @@
+package idempotency
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "uuid", key: "0b6f3a3e-7d3c-4d4e-9a3e-2f0c1e5b8a11"},
+		{name: "max length", key: strings.Repeat("k", MaxKeyLength)},
+		{name: "empty", key: "", wantErr: true},
+		{name: "too long", key: strings.Repeat("k", MaxKeyLength+1), wantErr: true},
+		{name: "control character", key: "abc\n", wantErr: true},
+		{name: "non-ASCII", key: "ключ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateKey(tt.key)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	t.Parallel()
+
+	base := RequestHash("POST", "/api/v1/records/Account", []byte(`{"Name":"Acme"}`))
+	assert.Len(t, base, 64)
+	assert.Equal(t, base, RequestHash("POST", "/api/v1/records/Account", []byte(`{"Name":"Acme"}`)))
+	assert.NotEqual(t, base, RequestHash("POST", "/api/v1/records/Account", []byte(`{"Name":"Acme Corp"}`)))
+	assert.NotEqual(t, base, RequestHash("POST", "/api/v1/records/Contact", []byte(`{"Name":"Acme"}`)))
+	assert.NotEqual(t, base, RequestHash("PUT", "/api/v1/records/Account", []byte(`{"Name":"Acme"}`)))
+}
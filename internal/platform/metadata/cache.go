@@ -20,6 +20,9 @@ type CacheLoader interface {
 	LoadAllAutomationRules(ctx context.Context) ([]AutomationRule, error)
 	LoadAllLayouts(ctx context.Context) ([]Layout, error)
 	LoadAllSharedLayouts(ctx context.Context) ([]SharedLayout, error)
+	LoadAllSavedQueries(ctx context.Context) ([]SavedQuery, error)
+	LoadAllPicklistValues(ctx context.Context) ([]PicklistValueRow, error)
+	LoadAllTranslations(ctx context.Context) ([]Translation, error)
 	RefreshMaterializedView(ctx context.Context) error
 }
 
@@ -42,6 +45,9 @@ type MetadataReader interface {
 	GetAutomationRules(objectID uuid.UUID) []AutomationRule
 	GetLayoutsForOV(ovID uuid.UUID) []Layout
 	GetSharedLayoutByAPIName(apiName string) (SharedLayout, bool)
+	GetSavedQueryByAPIName(apiName string) (SavedQuery, bool)
+	GetPicklistValues(picklistID uuid.UUID) []PicklistValueRow
+	GetTranslations(resourceType string, resourceID uuid.UUID, fieldName string) map[string]string
 }
 
 // MetadataCache is an in-memory cache of metadata backed by a PostgreSQL materialized view
@@ -77,6 +83,13 @@ type MetadataCache struct {
 	layoutsByOVID      map[uuid.UUID][]Layout
 	sharedLayoutsByAPI map[string]SharedLayout
 
+	// Saved queries
+	savedQueriesByAPIName map[string]SavedQuery
+
+	// Global picklist values and translations of labels
+	picklistValuesByID map[uuid.UUID][]PicklistValueRow // picklist_definition_id → values
+	translations       map[translationKey]map[string]string
+
 	loader CacheLoader
 	loaded bool
 }
@@ -97,6 +110,9 @@ func NewMetadataCache(loader CacheLoader) *MetadataCache {
 		automationRulesByObjectID: make(map[uuid.UUID][]AutomationRule),
 		layoutsByOVID:             make(map[uuid.UUID][]Layout),
 		sharedLayoutsByAPI:        make(map[string]SharedLayout),
+		savedQueriesByAPIName:     make(map[string]SavedQuery),
+		picklistValuesByID:        make(map[uuid.UUID][]PicklistValueRow),
+		translations:              make(map[translationKey]map[string]string),
 		loader:                    loader,
 	}
 }
@@ -153,6 +169,21 @@ func (c *MetadataCache) Load(ctx context.Context) error {
 		return fmt.Errorf("metadataCache.Load: shared layouts: %w", err)
 	}
 
+	savedQueries, err := c.loader.LoadAllSavedQueries(ctx)
+	if err != nil {
+		return fmt.Errorf("metadataCache.Load: saved queries: %w", err)
+	}
+
+	picklistValues, err := c.loader.LoadAllPicklistValues(ctx)
+	if err != nil {
+		return fmt.Errorf("metadataCache.Load: picklist values: %w", err)
+	}
+
+	translations, err := c.loader.LoadAllTranslations(ctx)
+	if err != nil {
+		return fmt.Errorf("metadataCache.Load: translations: %w", err)
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -214,6 +245,25 @@ func (c *MetadataCache) Load(ctx context.Context) error {
 		c.sharedLayoutsByAPI[sl.APIName] = sl
 	}
 
+	c.savedQueriesByAPIName = make(map[string]SavedQuery, len(savedQueries))
+	for _, q := range savedQueries {
+		c.savedQueriesByAPIName[q.APIName] = q
+	}
+
+	c.picklistValuesByID = make(map[uuid.UUID][]PicklistValueRow)
+	for _, v := range picklistValues {
+		c.picklistValuesByID[v.PicklistDefinitionID] = append(c.picklistValuesByID[v.PicklistDefinitionID], v)
+	}
+
+	c.translations = make(map[translationKey]map[string]string)
+	for _, t := range translations {
+		key := translationKey{resourceType: t.ResourceType, resourceID: t.ResourceID, fieldName: t.FieldName}
+		if c.translations[key] == nil {
+			c.translations[key] = make(map[string]string)
+		}
+		c.translations[key][t.Locale] = t.Value
+	}
+
 	c.loaded = true
 	return nil
 }
@@ -476,6 +526,46 @@ func (c *MetadataCache) LoadSharedLayouts(ctx context.Context) error {
 	return nil
 }
 
+// GetSavedQueryByAPIName returns a saved query by API name from cache.
+func (c *MetadataCache) GetSavedQueryByAPIName(apiName string) (SavedQuery, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	q, ok := c.savedQueriesByAPIName[apiName]
+	return q, ok
+}
+
+// LoadSavedQueries reloads only saved queries into the cache.
+func (c *MetadataCache) LoadSavedQueries(ctx context.Context) error {
+	savedQueries, err := c.loader.LoadAllSavedQueries(ctx)
+	if err != nil {
+		return fmt.Errorf("metadataCache.LoadSavedQueries: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.savedQueriesByAPIName = make(map[string]SavedQuery, len(savedQueries))
+	for _, q := range savedQueries {
+		c.savedQueriesByAPIName[q.APIName] = q
+	}
+	return nil
+}
+
+// GetPicklistValues returns the values of a global picklist, in sort order.
+func (c *MetadataCache) GetPicklistValues(picklistID uuid.UUID) []PicklistValueRow {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.picklistValuesByID[picklistID]
+}
+
+// GetTranslations returns the translations of a metadata property keyed by
+// locale, or nil if it is not translated. The map must not be modified.
+func (c *MetadataCache) GetTranslations(resourceType string, resourceID uuid.UUID, fieldName string) map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.translations[translationKey{resourceType: resourceType, resourceID: resourceID, fieldName: fieldName}]
+}
+
 // IsLoaded returns whether the cache has been loaded.
 func (c *MetadataCache) IsLoaded() bool {
 	c.mu.RLock()
@@ -253,6 +253,72 @@ func (l *PgCacheLoader) LoadAllSharedLayouts(ctx context.Context) ([]SharedLayou
 	return scanSharedLayouts(rows)
 }
 
+// LoadAllSavedQueries loads all saved queries from the database.
+func (l *PgCacheLoader) LoadAllSavedQueries(ctx context.Context) ([]SavedQuery, error) {
+	rows, err := l.pool.Query(ctx, `
+		SELECT `+savedQueryColumns+`
+		FROM metadata.saved_queries
+		ORDER BY api_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("pgCacheLoader.LoadAllSavedQueries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSavedQueries(rows)
+}
+
+// LoadAllPicklistValues loads the values of all global picklists from the database.
+func (l *PgCacheLoader) LoadAllPicklistValues(ctx context.Context) ([]PicklistValueRow, error) {
+	rows, err := l.pool.Query(ctx, `
+		SELECT id, picklist_definition_id, value, label,
+			sort_order, is_default, is_active, created_at, updated_at
+		FROM metadata.picklist_values
+		ORDER BY picklist_definition_id, sort_order, value
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("pgCacheLoader.LoadAllPicklistValues: %w", err)
+	}
+	defer rows.Close()
+
+	var values []PicklistValueRow
+	for rows.Next() {
+		var v PicklistValueRow
+		if err := rows.Scan(
+			&v.ID, &v.PicklistDefinitionID, &v.Value, &v.Label,
+			&v.SortOrder, &v.IsDefault, &v.IsActive, &v.CreatedAt, &v.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("pgCacheLoader.LoadAllPicklistValues: scan: %w", err)
+		}
+		values = append(values, v)
+	}
+
+	return values, rows.Err()
+}
+
+// LoadAllTranslations loads all metadata translations from the database.
+func (l *PgCacheLoader) LoadAllTranslations(ctx context.Context) ([]Translation, error) {
+	rows, err := l.pool.Query(ctx, `
+		SELECT resource_type, resource_id, field_name, locale, value
+		FROM metadata.translations
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("pgCacheLoader.LoadAllTranslations: %w", err)
+	}
+	defer rows.Close()
+
+	var translations []Translation
+	for rows.Next() {
+		var t Translation
+		if err := rows.Scan(&t.ResourceType, &t.ResourceID, &t.FieldName, &t.Locale, &t.Value); err != nil {
+			return nil, fmt.Errorf("pgCacheLoader.LoadAllTranslations: scan: %w", err)
+		}
+		translations = append(translations, t)
+	}
+
+	return translations, rows.Err()
+}
+
 // RefreshMaterializedView refreshes the relationship_registry materialized view concurrently.
 func (l *PgCacheLoader) RefreshMaterializedView(ctx context.Context) error {
 	_, err := l.pool.Exec(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY metadata.relationship_registry")
@@ -14,6 +14,8 @@ type mockCacheLoader struct {
 	objects    []ObjectDefinition
 	fields     []FieldDefinition
 	rels       []RelationshipInfo
+	picklists  []PicklistValueRow
+	labels     []Translation
 	objectsErr error
 	fieldsErr  error
 	relsErr    error
@@ -75,6 +77,18 @@ func (m *mockCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]SharedLayou
 	return nil, nil
 }
 
+func (m *mockCacheLoader) LoadAllSavedQueries(_ context.Context) ([]SavedQuery, error) {
+	return nil, nil
+}
+
+func (m *mockCacheLoader) LoadAllPicklistValues(_ context.Context) ([]PicklistValueRow, error) {
+	return m.picklists, nil
+}
+
+func (m *mockCacheLoader) LoadAllTranslations(_ context.Context) ([]Translation, error) {
+	return m.labels, nil
+}
+
 func TestMetadataCacheLoad(t *testing.T) {
 	t.Parallel()
 
@@ -171,6 +185,42 @@ func TestMetadataCacheLoad(t *testing.T) {
 	}
 }
 
+func TestMetadataCacheLabels(t *testing.T) {
+	t.Parallel()
+
+	picklistID := uuid.New()
+	fieldID := uuid.New()
+	loader := &mockCacheLoader{
+		picklists: []PicklistValueRow{
+			{ID: uuid.New(), PicklistDefinitionID: picklistID, Value: "new", Label: "New"},
+			{ID: uuid.New(), PicklistDefinitionID: picklistID, Value: "won", Label: "Won"},
+		},
+		labels: []Translation{
+			{ResourceType: TranslationField, ResourceID: fieldID, FieldName: "label", Locale: "de", Value: "Phase"},
+			{ResourceType: TranslationField, ResourceID: fieldID, FieldName: "label", Locale: "fr", Value: "Étape"},
+		},
+	}
+	cache := NewMetadataCache(loader)
+	if err := cache.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if got := cache.GetPicklistValues(picklistID); len(got) != 2 || got[1].Value != "won" {
+		t.Errorf("GetPicklistValues() = %v, want new, won", got)
+	}
+	if got := cache.GetPicklistValues(uuid.New()); got != nil {
+		t.Errorf("GetPicklistValues(unknown) = %v, want nil", got)
+	}
+
+	labels := cache.GetTranslations(TranslationField, fieldID, "label")
+	if len(labels) != 2 || labels["de"] != "Phase" || labels["fr"] != "Étape" {
+		t.Errorf("GetTranslations() = %v", labels)
+	}
+	if got := cache.GetTranslations(TranslationObject, fieldID, "label"); got != nil {
+		t.Errorf("GetTranslations(other resource type) = %v, want nil", got)
+	}
+}
+
 func TestMetadataCacheLookups(t *testing.T) {
 	t.Parallel()
 
@@ -6,6 +6,8 @@ import (
 )
 
 // CreateObjectTable generates DDL to create a table for an object.
+// Records are soft-deleted into the recycle bin (see RecycleBinIndex) and
+// carry a version for optimistic locking (see VersionColumn).
 func CreateObjectTable(tableName string) string {
 	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
     id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -13,7 +15,11 @@ func CreateObjectTable(tableName string) string {
     created_by  UUID        NOT NULL,
     created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_by  UUID        NOT NULL,
-    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    system_version BIGINT   NOT NULL DEFAULT 1,
+    is_deleted  BOOLEAN     NOT NULL DEFAULT false,
+    deleted_at  TIMESTAMPTZ,
+    deleted_by  UUID
 )`, quoteIdent(tableName))
 }
 
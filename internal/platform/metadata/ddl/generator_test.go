@@ -20,6 +20,10 @@ func TestCreateObjectTable(t *testing.T) {
 		{"has created_at", "created_at"},
 		{"has updated_by", "updated_by"},
 		{"has updated_at", "updated_at"},
+		{"has system_version", "system_version BIGINT   NOT NULL DEFAULT 1"},
+		{"has is_deleted", "is_deleted  BOOLEAN     NOT NULL DEFAULT false"},
+		{"has deleted_at", "deleted_at"},
+		{"has deleted_by", "deleted_by"},
 		{"has gen_random_uuid", "gen_random_uuid()"},
 	}
 	for _, tt := range tests {
@@ -32,6 +36,15 @@ func TestCreateObjectTable(t *testing.T) {
 	}
 }
 
+func TestRecycleBinIndex(t *testing.T) {
+	t.Parallel()
+	sql := RecycleBinIndex("obj_invoice")
+	want := `CREATE INDEX IF NOT EXISTS "idx_obj_invoice_deleted_at" ON "obj_invoice" ("deleted_at") WHERE "is_deleted"`
+	if sql != want {
+		t.Errorf("RecycleBinIndex() = %q, want %q", sql, want)
+	}
+}
+
 func TestDropObjectTable(t *testing.T) {
 	t.Parallel()
 	sql := DropObjectTable("obj_invoice")
@@ -0,0 +1,19 @@
+package ddl
+
+import "fmt"
+
+// Soft-delete columns of object tables. A deleted record stays in its table,
+// in the recycle bin, until it is undeleted or purged.
+const (
+	IsDeletedColumn = "is_deleted"
+	DeletedAtColumn = "deleted_at"
+	DeletedByColumn = "deleted_by"
+)
+
+// RecycleBinIndex generates DDL for the partial index the recycle bin purge
+// scans: deleted records by deletion time.
+func RecycleBinIndex(tableName string) string {
+	idxName := fmt.Sprintf("idx_%s_%s", sanitizeForIndex(tableName), DeletedAtColumn)
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s) WHERE %s",
+		quoteIdent(idxName), quoteIdent(tableName), quoteIdent(DeletedAtColumn), quoteIdent(IsDeletedColumn))
+}
@@ -0,0 +1,72 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SearchVectorColumn is the generated tsvector column used by SOSL.
+const SearchVectorColumn = "search_vector"
+
+// Search weights partition the vector so that SOSL can restrict a search to
+// a field group (IN NAME FIELDS, IN EMAIL FIELDS, ...) with tsquery weight labels.
+const (
+	SearchWeightName  = "A"
+	SearchWeightEmail = "B"
+	SearchWeightPhone = "C"
+	SearchWeightOther = "D"
+)
+
+// SearchWeight returns the search weight of a field, or false when the field
+// is not indexed for full-text search. Only text fields are indexed; URLs are
+// skipped because they tokenize into noise.
+func SearchWeight(field FieldInfo) (string, bool) {
+	if field.FieldType != "text" {
+		return "", false
+	}
+	switch field.FieldSubtype {
+	case "email":
+		return SearchWeightEmail, true
+	case "phone":
+		return SearchWeightPhone, true
+	case "url":
+		return "", false
+	}
+	if field.APIName == "name" || strings.HasSuffix(field.APIName, "_name") {
+		return SearchWeightName, true
+	}
+	return SearchWeightOther, true
+}
+
+// AddSearchVector generates DDL for the generated search vector column and its
+// GIN index. Returns nil when none of the fields is searchable.
+func AddSearchVector(tableName string, fields []FieldInfo) []string {
+	var parts []string
+	for _, f := range fields {
+		weight, ok := SearchWeight(f)
+		if !ok {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("setweight(to_tsvector('simple', coalesce(%s, '')), '%s')",
+			quoteIdent(f.APIName), weight))
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+
+	idxName := fmt.Sprintf("idx_%s_%s", sanitizeForIndex(tableName), SearchVectorColumn)
+	return []string{
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s tsvector GENERATED ALWAYS AS (%s) STORED",
+			quoteIdent(tableName), quoteIdent(SearchVectorColumn), strings.Join(parts, " || ")),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (%s)",
+			quoteIdent(idxName), quoteIdent(tableName), quoteIdent(SearchVectorColumn)),
+	}
+}
+
+// DropSearchVector generates DDL to drop the search vector column (and its index).
+// A generated column cannot be altered, so changes to the set of searchable
+// fields drop the column and add it again.
+func DropSearchVector(tableName string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s",
+		quoteIdent(tableName), quoteIdent(SearchVectorColumn))
+}
@@ -0,0 +1,83 @@
+package ddl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchWeight(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		field      FieldInfo
+		wantWeight string
+		wantOK     bool
+	}{
+		{"name field", FieldInfo{APIName: "name", FieldType: "text", FieldSubtype: "plain"}, SearchWeightName, true},
+		{"suffixed name field", FieldInfo{APIName: "last_name", FieldType: "text", FieldSubtype: "plain"}, SearchWeightName, true},
+		{"email", FieldInfo{APIName: "email", FieldType: "text", FieldSubtype: "email"}, SearchWeightEmail, true},
+		{"phone", FieldInfo{APIName: "mobile", FieldType: "text", FieldSubtype: "phone"}, SearchWeightPhone, true},
+		{"long text", FieldInfo{APIName: "notes", FieldType: "text", FieldSubtype: "area"}, SearchWeightOther, true},
+		{"url is skipped", FieldInfo{APIName: "website", FieldType: "text", FieldSubtype: "url"}, "", false},
+		{"number is skipped", FieldInfo{APIName: "amount", FieldType: "number", FieldSubtype: "decimal"}, "", false},
+		{"picklist is skipped", FieldInfo{APIName: "stage_name", FieldType: "picklist", FieldSubtype: "single"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			weight, ok := SearchWeight(tt.field)
+			if weight != tt.wantWeight || ok != tt.wantOK {
+				t.Errorf("SearchWeight() = (%q, %v), want (%q, %v)", weight, ok, tt.wantWeight, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAddSearchVector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("builds weighted generated column and GIN index", func(t *testing.T) {
+		t.Parallel()
+		stmts := AddSearchVector("obj_contact", []FieldInfo{
+			{APIName: "last_name", FieldType: "text", FieldSubtype: "plain"},
+			{APIName: "email", FieldType: "text", FieldSubtype: "email"},
+			{APIName: "amount", FieldType: "number", FieldSubtype: "integer"},
+		})
+		if len(stmts) != 2 {
+			t.Fatalf("AddSearchVector() returned %d statements, want 2: %v", len(stmts), stmts)
+		}
+		for _, want := range []string{
+			`ADD COLUMN "search_vector" tsvector GENERATED ALWAYS AS`,
+			`setweight(to_tsvector('simple', coalesce("last_name", '')), 'A')`,
+			`setweight(to_tsvector('simple', coalesce("email", '')), 'B')`,
+			"STORED",
+		} {
+			if !strings.Contains(stmts[0], want) {
+				t.Errorf("AddSearchVector() column missing %q in: %s", want, stmts[0])
+			}
+		}
+		if strings.Contains(stmts[0], "amount") {
+			t.Errorf("AddSearchVector() indexed a number field: %s", stmts[0])
+		}
+		if !strings.Contains(stmts[1], `USING GIN ("search_vector")`) {
+			t.Errorf("AddSearchVector() index = %s, want GIN index", stmts[1])
+		}
+	})
+
+	t.Run("no searchable fields", func(t *testing.T) {
+		t.Parallel()
+		stmts := AddSearchVector("obj_contact", []FieldInfo{{APIName: "amount", FieldType: "number"}})
+		if stmts != nil {
+			t.Errorf("AddSearchVector() = %v, want nil", stmts)
+		}
+	})
+}
+
+func TestDropSearchVector(t *testing.T) {
+	t.Parallel()
+	sql := DropSearchVector("obj_contact")
+	if sql != `ALTER TABLE "obj_contact" DROP COLUMN IF EXISTS "search_vector"` {
+		t.Errorf("DropSearchVector() = %q", sql)
+	}
+}
@@ -0,0 +1,6 @@
+package ddl
+
+// VersionColumn is the record version of object tables. It starts at 1 and
+// every update of the record increments it, so that a client can tell whether
+// the record changed since it was read.
+const VersionColumn = "system_version"
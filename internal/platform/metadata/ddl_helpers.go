@@ -23,3 +23,18 @@ func ToFieldInfo(f FieldDefinition) ddl.FieldInfo {
 		DefaultValue: f.Config.DefaultValue,
 	}
 }
+
+// isSearchableField reports whether a field contributes to the object's search vector.
+func isSearchableField(f FieldDefinition) bool {
+	_, ok := ddl.SearchWeight(ToFieldInfo(f))
+	return ok
+}
+
+// searchVectorDDL recreates the search vector of a table from the given fields.
+func searchVectorDDL(tableName string, fields []FieldDefinition) []string {
+	infos := make([]ddl.FieldInfo, 0, len(fields))
+	for _, f := range fields {
+		infos = append(infos, ToFieldInfo(f))
+	}
+	return append([]string{ddl.DropSearchVector(tableName)}, ddl.AddSearchVector(tableName, infos)...)
+}
@@ -0,0 +1,43 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// FieldUsage describes metadata that references a field.
+type FieldUsage struct {
+	Entity string `json:"entity"` // "saved_query"
+	Name   string `json:"name"`
+	ID     string `json:"id"`
+}
+
+// FieldDependencyChecker finds the metadata referencing a field. A field
+// with usages cannot be deleted.
+type FieldDependencyChecker interface {
+	FieldUsages(ctx context.Context, fieldID uuid.UUID) ([]FieldUsage, error)
+}
+
+type savedQueryFieldDependencies struct {
+	repo SavedQueryRepository
+}
+
+// NewSavedQueryFieldDependencies creates a FieldDependencyChecker reporting
+// the saved queries that reference a field.
+func NewSavedQueryFieldDependencies(repo SavedQueryRepository) FieldDependencyChecker {
+	return &savedQueryFieldDependencies{repo: repo}
+}
+
+func (d *savedQueryFieldDependencies) FieldUsages(ctx context.Context, fieldID uuid.UUID) ([]FieldUsage, error) {
+	queries, err := d.repo.ListByFieldID(ctx, fieldID)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryFieldDependencies.FieldUsages: %w", err)
+	}
+	usages := make([]FieldUsage, len(queries))
+	for i, q := range queries {
+		usages[i] = FieldUsage{Entity: "saved_query", Name: q.APIName, ID: q.ID.String()}
+	}
+	return usages, nil
+}
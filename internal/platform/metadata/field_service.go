@@ -3,6 +3,7 @@ package metadata
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -18,6 +19,18 @@ type fieldServiceImpl struct {
 	polymorphicRepo PolymorphicTargetRepository
 	ddlExec         DDLExecutor
 	cache           CacheInvalidator
+	dependencies    FieldDependencyChecker
+}
+
+// FieldServiceOption configures a FieldService.
+type FieldServiceOption func(*fieldServiceImpl)
+
+// WithFieldDependencyChecker refuses to delete fields that checker reports
+// as used.
+func WithFieldDependencyChecker(checker FieldDependencyChecker) FieldServiceOption {
+	return func(s *fieldServiceImpl) {
+		s.dependencies = checker
+	}
 }
 
 // NewFieldService creates a new FieldService.
@@ -28,8 +41,9 @@ func NewFieldService(
 	polymorphicRepo PolymorphicTargetRepository,
 	ddlExec DDLExecutor,
 	cache CacheInvalidator,
+	opts ...FieldServiceOption,
 ) FieldService {
-	return &fieldServiceImpl{
+	s := &fieldServiceImpl{
 		txBeginner:      txBeginner,
 		objectRepo:      objectRepo,
 		fieldRepo:       fieldRepo,
@@ -37,6 +51,10 @@ func NewFieldService(
 		ddlExec:         ddlExec,
 		cache:           cache,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *fieldServiceImpl) Create(ctx context.Context, input CreateFieldInput) (*FieldDefinition, error) {
@@ -126,6 +144,14 @@ func (s *fieldServiceImpl) Create(ctx context.Context, input CreateFieldInput) (
 			return fmt.Errorf("fieldService.Create: generate DDL: %w", err)
 		}
 
+		if obj.IsSearchable && isSearchableField(*created) {
+			fields, err := s.fieldRepo.ListByObjectID(ctx, obj.ID)
+			if err != nil {
+				return fmt.Errorf("fieldService.Create: list fields: %w", err)
+			}
+			ddlStmts = append(ddlStmts, searchVectorDDL(obj.TableName, withField(fields, *created))...)
+		}
+
 		if err := s.ddlExec.ExecInTx(ctx, tx, ddlStmts); err != nil {
 			return fmt.Errorf("fieldService.Create: execute DDL: %w", err)
 		}
@@ -264,6 +290,21 @@ func (s *fieldServiceImpl) Delete(ctx context.Context, id uuid.UUID) error {
 			apperror.Forbidden("cannot delete platform-managed field"))
 	}
 
+	if s.dependencies != nil {
+		usages, err := s.dependencies.FieldUsages(ctx, id)
+		if err != nil {
+			return fmt.Errorf("fieldService.Delete: %w", err)
+		}
+		if len(usages) > 0 {
+			names := make([]string, len(usages))
+			for i, u := range usages {
+				names[i] = u.Entity + " " + u.Name
+			}
+			return fmt.Errorf("fieldService.Delete: %w",
+				apperror.Conflict(fmt.Sprintf("field %q is used by %s", existing.APIName, strings.Join(names, ", "))))
+		}
+	}
+
 	obj, err := s.objectRepo.GetByID(ctx, existing.ObjectID)
 	if err != nil {
 		return fmt.Errorf("fieldService.Delete: get object: %w", err)
@@ -271,6 +312,16 @@ func (s *fieldServiceImpl) Delete(ctx context.Context, id uuid.UUID) error {
 
 	err = withTx(ctx, s.txBeginner, func(tx pgx.Tx) error {
 		ddlStmts := ddl.DropColumn(obj.TableName, ToFieldInfo(*existing))
+		if obj.IsSearchable && isSearchableField(*existing) {
+			// The generated search vector depends on the column, so it is
+			// dropped first and rebuilt from the remaining fields.
+			fields, err := s.fieldRepo.ListByObjectID(ctx, obj.ID)
+			if err != nil {
+				return fmt.Errorf("fieldService.Delete: list fields: %w", err)
+			}
+			rebuild := searchVectorDDL(obj.TableName, withoutField(fields, existing.ID))
+			ddlStmts = append(append([]string{rebuild[0]}, ddlStmts...), rebuild[1:]...)
+		}
 		if err := s.ddlExec.ExecInTx(ctx, tx, ddlStmts); err != nil {
 			return fmt.Errorf("fieldService.Delete: DDL DROP COLUMN: %w", err)
 		}
@@ -305,3 +356,25 @@ func (s *fieldServiceImpl) loadObjectMap(ctx context.Context) (map[uuid.UUID]Obj
 	}
 	return result, nil
 }
+
+// withField returns fields with f added, unless a field with the same ID is
+// already listed (the repository may or may not see uncommitted rows).
+func withField(fields []FieldDefinition, f FieldDefinition) []FieldDefinition {
+	for _, existing := range fields {
+		if existing.ID == f.ID {
+			return fields
+		}
+	}
+	return append(fields, f)
+}
+
+// withoutField returns fields except the one with the given ID.
+func withoutField(fields []FieldDefinition, id uuid.UUID) []FieldDefinition {
+	result := make([]FieldDefinition, 0, len(fields))
+	for _, f := range fields {
+		if f.ID != id {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -3,6 +3,7 @@ package metadata
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -675,3 +676,180 @@ func TestFieldServiceDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestFieldServiceDeleteBlockedBySavedQuery(t *testing.T) {
+	t.Parallel()
+
+	objectID := uuid.New()
+	fieldID := uuid.New()
+	subtypePlain := SubtypePlain
+
+	objRepo := newMockObjectRepo()
+	objRepo.addObject(&ObjectDefinition{ID: objectID, APIName: "Contact", TableName: "obj_contact"})
+	fieldRepo := newMockFieldRepo()
+	fieldRepo.fields[fieldID] = &FieldDefinition{
+		ID:           fieldID,
+		ObjectID:     objectID,
+		APIName:      "first_name",
+		FieldType:    FieldTypeText,
+		FieldSubtype: &subtypePlain,
+	}
+
+	queryRepo := newMockSavedQueryRepo()
+	q, err := queryRepo.Create(context.Background(), CreateSavedQueryInput{
+		APIName: "contacts_by_name", SOQL: "SELECT Id FROM Contact WHERE first_name = :name",
+	}, []uuid.UUID{fieldID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ddlExec := newMockDDLExec()
+	svc := NewFieldService(
+		&mockTxBeginner{},
+		objRepo,
+		fieldRepo,
+		newMockPolymorphicRepo(),
+		ddlExec,
+		newMockCache(),
+		WithFieldDependencyChecker(NewSavedQueryFieldDependencies(queryRepo)),
+	)
+
+	err = svc.Delete(context.Background(), fieldID)
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || appErr.Code != apperror.CodeConflict {
+		t.Fatalf("error = %v, want conflict", err)
+	}
+	if !strings.Contains(err.Error(), "saved_query contacts_by_name") {
+		t.Errorf("error = %q, want the referencing saved query", err.Error())
+	}
+	if len(ddlExec.executed) != 0 {
+		t.Error("expected no DDL for a blocked delete")
+	}
+
+	if err := queryRepo.Delete(context.Background(), q.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Delete(context.Background(), fieldID); err != nil {
+		t.Fatalf("unexpected error after removing the saved query: %v", err)
+	}
+}
+
+func TestFieldServiceMaintainsSearchVector(t *testing.T) {
+	t.Parallel()
+
+	objectID := uuid.New()
+	nameID := uuid.New()
+	emailID := uuid.New()
+	subtypePlain := SubtypePlain
+	subtypeEmail := SubtypeEmail
+	subtypeInteger := SubtypeInteger
+	maxLen := 255
+
+	setup := func(searchable bool) (*mockObjectRepo, *mockFieldRepo) {
+		objRepo := newMockObjectRepo()
+		objRepo.addObject(&ObjectDefinition{
+			ID:           objectID,
+			APIName:      "Contact",
+			TableName:    "obj_contact",
+			IsSearchable: searchable,
+		})
+		fieldRepo := newMockFieldRepo()
+		fieldRepo.fields[nameID] = &FieldDefinition{
+			ID: nameID, ObjectID: objectID, APIName: "last_name",
+			FieldType: FieldTypeText, FieldSubtype: &subtypePlain,
+		}
+		fieldRepo.fields[emailID] = &FieldDefinition{
+			ID: emailID, ObjectID: objectID, APIName: "email",
+			FieldType: FieldTypeText, FieldSubtype: &subtypeEmail,
+		}
+		return objRepo, fieldRepo
+	}
+
+	executed := func(d *mockDDLExec) string {
+		var all []string
+		for _, stmts := range d.executed {
+			all = append(all, stmts...)
+		}
+		return strings.Join(all, "\n")
+	}
+
+	tests := []struct {
+		name       string
+		searchable bool
+		run        func(FieldService) error
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:       "create text field rebuilds vector",
+			searchable: true,
+			run: func(svc FieldService) error {
+				_, err := svc.Create(context.Background(), CreateFieldInput{
+					ObjectID: objectID, APIName: "notes", Label: "Notes",
+					FieldType: FieldTypeText, FieldSubtype: &subtypePlain,
+					Config: FieldConfig{MaxLength: &maxLen},
+				})
+				return err
+			},
+			want: []string{`DROP COLUMN IF EXISTS "search_vector"`, `coalesce("last_name", '')`, `coalesce("email", '')`, `coalesce("notes", '')`, "USING GIN"},
+		},
+		{
+			name:       "create number field leaves vector alone",
+			searchable: true,
+			run: func(svc FieldService) error {
+				_, err := svc.Create(context.Background(), CreateFieldInput{
+					ObjectID: objectID, APIName: "age", Label: "Age",
+					FieldType: FieldTypeNumber, FieldSubtype: &subtypeInteger,
+				})
+				return err
+			},
+			notWant: []string{"search_vector"},
+		},
+		{
+			name:       "object not searchable",
+			searchable: false,
+			run: func(svc FieldService) error {
+				_, err := svc.Create(context.Background(), CreateFieldInput{
+					ObjectID: objectID, APIName: "notes", Label: "Notes",
+					FieldType: FieldTypeText, FieldSubtype: &subtypePlain,
+					Config: FieldConfig{MaxLength: &maxLen},
+				})
+				return err
+			},
+			notWant: []string{"search_vector"},
+		},
+		{
+			name:       "delete text field drops vector before column",
+			searchable: true,
+			run: func(svc FieldService) error {
+				return svc.Delete(context.Background(), emailID)
+			},
+			want:    []string{"DROP COLUMN IF EXISTS \"search_vector\"\nALTER TABLE \"obj_contact\" DROP COLUMN IF EXISTS \"email\"", `coalesce("last_name", '')`},
+			notWant: []string{`coalesce("email", '')`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			objRepo, fieldRepo := setup(tt.searchable)
+			ddlExec := newMockDDLExec()
+			svc := newFieldService(objRepo, fieldRepo, newMockPolymorphicRepo(), ddlExec, newMockCache())
+
+			if err := tt.run(svc); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := executed(ddlExec)
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("DDL missing %q in:\n%s", w, got)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(got, nw) {
+					t.Errorf("DDL unexpectedly contains %q in:\n%s", nw, got)
+				}
+			}
+		})
+	}
+}
@@ -114,6 +114,15 @@ func (m *mockFnCacheLoader) LoadAllLayouts(_ context.Context) ([]Layout, error)
 func (m *mockFnCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]SharedLayout, error) {
 	return nil, nil
 }
+func (m *mockFnCacheLoader) LoadAllSavedQueries(_ context.Context) ([]SavedQuery, error) {
+	return nil, nil
+}
+func (m *mockFnCacheLoader) LoadAllPicklistValues(_ context.Context) ([]PicklistValueRow, error) {
+	return nil, nil
+}
+func (m *mockFnCacheLoader) LoadAllTranslations(_ context.Context) ([]Translation, error) {
+	return nil, nil
+}
 func (m *mockFnCacheLoader) RefreshMaterializedView(_ context.Context) error {
 	return nil
 }
@@ -137,6 +137,15 @@ func (m *mockLayoutCacheLoader) LoadAllLayouts(_ context.Context) ([]Layout, err
 func (m *mockLayoutCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]SharedLayout, error) {
 	return nil, nil
 }
+func (m *mockLayoutCacheLoader) LoadAllSavedQueries(_ context.Context) ([]SavedQuery, error) {
+	return nil, nil
+}
+func (m *mockLayoutCacheLoader) LoadAllPicklistValues(_ context.Context) ([]PicklistValueRow, error) {
+	return nil, nil
+}
+func (m *mockLayoutCacheLoader) LoadAllTranslations(_ context.Context) ([]Translation, error) {
+	return nil, nil
+}
 func (m *mockLayoutCacheLoader) RefreshMaterializedView(_ context.Context) error {
 	return nil
 }
@@ -86,8 +86,8 @@ func (s *objectServiceImpl) Create(ctx context.Context, input CreateObjectInput)
 			return fmt.Errorf("objectService.Create: insert metadata: %w", err)
 		}
 
-		createTableDDL := ddl.CreateObjectTable(tableName)
-		if err := s.ddlExec.ExecInTx(ctx, tx, []string{createTableDDL}); err != nil {
+		createTableDDL := []string{ddl.CreateObjectTable(tableName), ddl.RecycleBinIndex(tableName)}
+		if err := s.ddlExec.ExecInTx(ctx, tx, createTableDDL); err != nil {
 			return fmt.Errorf("objectService.Create: DDL CREATE TABLE: %w", err)
 		}
 
@@ -211,6 +211,21 @@ func (s *objectServiceImpl) Update(ctx context.Context, id uuid.UUID, input Upda
 			}
 		}
 
+		// Maintain the full-text search vector used by SOSL.
+		if input.IsSearchable != existing.IsSearchable {
+			stmts := []string{ddl.DropSearchVector(existing.TableName)}
+			if input.IsSearchable {
+				fields, err := s.fieldRepo.ListByObjectID(ctx, id)
+				if err != nil {
+					return fmt.Errorf("objectService.Update: list fields: %w", err)
+				}
+				stmts = searchVectorDDL(existing.TableName, fields)
+			}
+			if err := s.ddlExec.ExecInTx(ctx, tx, stmts); err != nil {
+				return fmt.Errorf("objectService.Update: DDL SEARCH VECTOR: %w", err)
+			}
+		}
+
 		result = updated
 		return nil
 	})
@@ -139,6 +139,18 @@ func (m *mockOVCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]SharedLay
 	return nil, nil
 }
 
+func (m *mockOVCacheLoader) LoadAllSavedQueries(_ context.Context) ([]SavedQuery, error) {
+	return nil, nil
+}
+
+func (m *mockOVCacheLoader) LoadAllPicklistValues(_ context.Context) ([]PicklistValueRow, error) {
+	return nil, nil
+}
+
+func (m *mockOVCacheLoader) LoadAllTranslations(_ context.Context) ([]Translation, error) {
+	return nil, nil
+}
+
 func (m *mockOVCacheLoader) RefreshMaterializedView(_ context.Context) error {
 	return nil
 }
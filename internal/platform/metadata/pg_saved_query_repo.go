@@ -0,0 +1,252 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgSavedQueryRepository is a PostgreSQL implementation of SavedQueryRepository.
+type PgSavedQueryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgSavedQueryRepository creates a new PgSavedQueryRepository.
+func NewPgSavedQueryRepository(pool *pgxpool.Pool) *PgSavedQueryRepository {
+	return &PgSavedQueryRepository{pool: pool}
+}
+
+const savedQueryColumns = `id, api_name, label, description, soql, params, version,
+			created_at, updated_at`
+
+func (r *PgSavedQueryRepository) Create(ctx context.Context, input CreateSavedQueryInput, fieldIDs []uuid.UUID) (*SavedQuery, error) {
+	paramsJSON, err := json.Marshal(input.Params)
+	if err != nil {
+		return nil, fmt.Errorf("pgSavedQueryRepo.Create: marshal params: %w", err)
+	}
+
+	var q *SavedQuery
+	err = withTx(ctx, r.pool, func(tx pgx.Tx) error {
+		q, err = scanSavedQuery(tx.QueryRow(ctx, `
+			INSERT INTO metadata.saved_queries
+				(api_name, label, description, soql, params)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING `+savedQueryColumns,
+			input.APIName, input.Label, input.Description, input.SOQL, paramsJSON,
+		))
+		if err != nil {
+			return err
+		}
+		if err := insertSavedQueryVersion(ctx, tx, q.ID, "Initial version"); err != nil {
+			return err
+		}
+		return replaceSavedQueryFields(ctx, tx, q.ID, fieldIDs)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgSavedQueryRepo.Create: %w", err)
+	}
+	return q, nil
+}
+
+func (r *PgSavedQueryRepository) GetByID(ctx context.Context, id uuid.UUID) (*SavedQuery, error) {
+	q, err := scanSavedQuery(r.pool.QueryRow(ctx, `
+		SELECT `+savedQueryColumns+`
+		FROM metadata.saved_queries
+		WHERE id = $1`, id,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("pgSavedQueryRepo.GetByID: %w", err)
+	}
+	return q, nil
+}
+
+func (r *PgSavedQueryRepository) GetByAPIName(ctx context.Context, apiName string) (*SavedQuery, error) {
+	q, err := scanSavedQuery(r.pool.QueryRow(ctx, `
+		SELECT `+savedQueryColumns+`
+		FROM metadata.saved_queries
+		WHERE api_name = $1`, apiName,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("pgSavedQueryRepo.GetByAPIName: %w", err)
+	}
+	return q, nil
+}
+
+func (r *PgSavedQueryRepository) ListAll(ctx context.Context) ([]SavedQuery, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+savedQueryColumns+`
+		FROM metadata.saved_queries
+		ORDER BY api_name`)
+	if err != nil {
+		return nil, fmt.Errorf("pgSavedQueryRepo.ListAll: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSavedQueries(rows)
+}
+
+func (r *PgSavedQueryRepository) Update(ctx context.Context, id uuid.UUID, input UpdateSavedQueryInput, fieldIDs []uuid.UUID) (*SavedQuery, error) {
+	paramsJSON, err := json.Marshal(input.Params)
+	if err != nil {
+		return nil, fmt.Errorf("pgSavedQueryRepo.Update: marshal params: %w", err)
+	}
+
+	var q *SavedQuery
+	err = withTx(ctx, r.pool, func(tx pgx.Tx) error {
+		q, err = scanSavedQuery(tx.QueryRow(ctx, `
+			UPDATE metadata.saved_queries SET
+				label = $2, description = $3, soql = $4, params = $5,
+				version = version + CASE
+					WHEN soql <> $4 OR params <> $5::jsonb THEN 1 ELSE 0 END,
+				updated_at = now()
+			WHERE id = $1
+			RETURNING `+savedQueryColumns,
+			id, input.Label, input.Description, input.SOQL, paramsJSON,
+		))
+		if err != nil {
+			return err
+		}
+		if err := insertSavedQueryVersion(ctx, tx, id, input.ChangeSummary); err != nil {
+			return err
+		}
+		return replaceSavedQueryFields(ctx, tx, id, fieldIDs)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("pgSavedQueryRepo.Update: %w", err)
+	}
+	return q, nil
+}
+
+func (r *PgSavedQueryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM metadata.saved_queries WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("pgSavedQueryRepo.Delete: %w", err)
+	}
+	return nil
+}
+
+func (r *PgSavedQueryRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM metadata.saved_queries`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("pgSavedQueryRepo.Count: %w", err)
+	}
+	return count, nil
+}
+
+func (r *PgSavedQueryRepository) ListVersions(ctx context.Context, id uuid.UUID) ([]SavedQueryVersion, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, saved_query_id, version, soql, params, change_summary, created_at
+		FROM metadata.saved_query_versions
+		WHERE saved_query_id = $1
+		ORDER BY version DESC`, id)
+	if err != nil {
+		return nil, fmt.Errorf("pgSavedQueryRepo.ListVersions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []SavedQueryVersion
+	for rows.Next() {
+		var v SavedQueryVersion
+		var paramsRaw []byte
+		if err := rows.Scan(
+			&v.ID, &v.SavedQueryID, &v.Version, &v.SOQL, &paramsRaw,
+			&v.ChangeSummary, &v.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("pgSavedQueryRepo.ListVersions: %w", err)
+		}
+		if err := json.Unmarshal(paramsRaw, &v.Params); err != nil {
+			return nil, fmt.Errorf("pgSavedQueryRepo.ListVersions: unmarshal params: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (r *PgSavedQueryRepository) ListByFieldID(ctx context.Context, fieldID uuid.UUID) ([]SavedQuery, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+savedQueryColumns+`
+		FROM metadata.saved_queries
+		WHERE id IN (SELECT saved_query_id FROM metadata.saved_query_fields WHERE field_id = $1)
+		ORDER BY api_name`, fieldID)
+	if err != nil {
+		return nil, fmt.Errorf("pgSavedQueryRepo.ListByFieldID: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSavedQueries(rows)
+}
+
+// insertSavedQueryVersion snapshots the current definition of the query,
+// unless its version is already recorded (only the label or description changed).
+func insertSavedQueryVersion(ctx context.Context, tx pgx.Tx, id uuid.UUID, changeSummary string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO metadata.saved_query_versions
+			(saved_query_id, version, soql, params, change_summary)
+		SELECT id, version, soql, params, $2
+		FROM metadata.saved_queries
+		WHERE id = $1
+		ON CONFLICT (saved_query_id, version) DO NOTHING`, id, changeSummary)
+	if err != nil {
+		return fmt.Errorf("insert version: %w", err)
+	}
+	return nil
+}
+
+func replaceSavedQueryFields(ctx context.Context, tx pgx.Tx, id uuid.UUID, fieldIDs []uuid.UUID) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM metadata.saved_query_fields WHERE saved_query_id = $1`, id); err != nil {
+		return fmt.Errorf("delete field references: %w", err)
+	}
+	if len(fieldIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO metadata.saved_query_fields (saved_query_id, field_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING`, id, fieldIDs)
+	if err != nil {
+		return fmt.Errorf("insert field references: %w", err)
+	}
+	return nil
+}
+
+func scanSavedQuery(row pgx.Row) (*SavedQuery, error) {
+	q := &SavedQuery{}
+	var paramsRaw []byte
+	if err := row.Scan(
+		&q.ID, &q.APIName, &q.Label, &q.Description, &q.SOQL, &paramsRaw,
+		&q.Version, &q.CreatedAt, &q.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(paramsRaw, &q.Params); err != nil {
+		return nil, fmt.Errorf("unmarshal params: %w", err)
+	}
+	return q, nil
+}
+
+func scanSavedQueries(rows pgx.Rows) ([]SavedQuery, error) {
+	var queries []SavedQuery
+	for rows.Next() {
+		q, err := scanSavedQuery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanSavedQueries: %w", err)
+		}
+		queries = append(queries, *q)
+	}
+	return queries, rows.Err()
+}
@@ -241,6 +241,15 @@ func (l *noopCacheLoader) LoadAllLayouts(_ context.Context) ([]Layout, error) {
 func (l *noopCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]SharedLayout, error) {
 	return nil, nil
 }
+func (l *noopCacheLoader) LoadAllSavedQueries(_ context.Context) ([]SavedQuery, error) {
+	return nil, nil
+}
+func (l *noopCacheLoader) LoadAllPicklistValues(_ context.Context) ([]PicklistValueRow, error) {
+	return nil, nil
+}
+func (l *noopCacheLoader) LoadAllTranslations(_ context.Context) ([]Translation, error) {
+	return nil, nil
+}
 func (l *noopCacheLoader) RefreshMaterializedView(_ context.Context) error { return nil }
 
 func TestProcedureService_Create(t *testing.T) {
@@ -57,6 +57,7 @@ type CommandDef struct {
 	ID        string                  `json:"id,omitempty"`
 	Data      map[string]string       `json:"data,omitempty"`
 	Query     string                  `json:"query,omitempty"`
+	Binds     map[string]string       `json:"binds,omitempty"`
 	Value     map[string]string       `json:"value,omitempty"`
 	Condition string                  `json:"condition,omitempty"`
 	Code      string                  `json:"code,omitempty"`
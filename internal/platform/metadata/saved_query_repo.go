@@ -0,0 +1,25 @@
+package metadata
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// SavedQueryRepository provides CRUD operations for saved queries, their
+// version history and the fields they reference.
+type SavedQueryRepository interface {
+	// Create stores the query as version 1 together with its field references.
+	Create(ctx context.Context, input CreateSavedQueryInput, fieldIDs []uuid.UUID) (*SavedQuery, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*SavedQuery, error)
+	GetByAPIName(ctx context.Context, apiName string) (*SavedQuery, error)
+	ListAll(ctx context.Context) ([]SavedQuery, error)
+	// Update replaces the query and its field references. A change of the
+	// SOQL or the parameters records a new version.
+	Update(ctx context.Context, id uuid.UUID, input UpdateSavedQueryInput, fieldIDs []uuid.UUID) (*SavedQuery, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Count(ctx context.Context) (int, error)
+	ListVersions(ctx context.Context, id uuid.UUID) ([]SavedQueryVersion, error)
+	// ListByFieldID returns the saved queries referencing the field.
+	ListByFieldID(ctx context.Context, fieldID uuid.UUID) ([]SavedQuery, error)
+}
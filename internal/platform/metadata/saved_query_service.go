@@ -0,0 +1,275 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+)
+
+const (
+	maxSavedQueryCount  = 500
+	maxSavedQueryParams = 20
+	maxSavedQuerySize   = 100000
+)
+
+var (
+	validSavedQueryAPIName   = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	validSavedQueryParamName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// savedQueryParamTypes are the SOQL types a parameter can be declared with.
+var savedQueryParamTypes = map[string]bool{
+	"string": true, "integer": true, "float": true, "boolean": true,
+	"date": true, "datetime": true, "id": true,
+}
+
+// SavedQueryAnalysis is what the SOQL engine learns from a saved query.
+type SavedQueryAnalysis struct {
+	// Binds maps each bind variable to the type its value is converted to,
+	// named as in SavedQueryParam.Type.
+	Binds map[string]string
+	// FieldIDs are the fields the query references.
+	FieldIDs []uuid.UUID
+}
+
+// SavedQueryAnalyzer validates the SOQL of a saved query against the
+// current metadata. Invalid queries yield an apperror.
+type SavedQueryAnalyzer interface {
+	AnalyzeSavedQuery(ctx context.Context, soql string) (*SavedQueryAnalysis, error)
+}
+
+// SavedQueryService provides business logic for saved queries.
+type SavedQueryService interface {
+	Create(ctx context.Context, input CreateSavedQueryInput) (*SavedQuery, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*SavedQuery, error)
+	// GetByAPIName returns a saved query from the metadata cache.
+	GetByAPIName(ctx context.Context, apiName string) (*SavedQuery, error)
+	ListAll(ctx context.Context) ([]SavedQuery, error)
+	Update(ctx context.Context, id uuid.UUID, input UpdateSavedQueryInput) (*SavedQuery, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListVersions(ctx context.Context, id uuid.UUID) ([]SavedQueryVersion, error)
+}
+
+type savedQueryService struct {
+	repo     SavedQueryRepository
+	analyzer SavedQueryAnalyzer
+	cache    *MetadataCache
+}
+
+// NewSavedQueryService creates a new SavedQueryService. Queries are checked
+// by analyzer whenever they are saved.
+func NewSavedQueryService(
+	repo SavedQueryRepository,
+	analyzer SavedQueryAnalyzer,
+	cache *MetadataCache,
+) SavedQueryService {
+	return &savedQueryService{
+		repo:     repo,
+		analyzer: analyzer,
+		cache:    cache,
+	}
+}
+
+func (s *savedQueryService) Create(ctx context.Context, input CreateSavedQueryInput) (*SavedQuery, error) {
+	if err := validateSavedQueryAPIName(input.APIName); err != nil {
+		return nil, fmt.Errorf("savedQueryService.Create: %w", err)
+	}
+	if input.Params == nil {
+		input.Params = []SavedQueryParam{}
+	}
+	analysis, err := s.analyze(ctx, input.Label, input.SOQL, input.Params)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryService.Create: %w", err)
+	}
+
+	count, err := s.repo.Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryService.Create: %w", err)
+	}
+	if count >= maxSavedQueryCount {
+		return nil, fmt.Errorf("savedQueryService.Create: %w",
+			apperror.BadRequest(fmt.Sprintf("max saved query limit reached (%d)", maxSavedQueryCount)))
+	}
+
+	existing, err := s.repo.GetByAPIName(ctx, input.APIName)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryService.Create: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("savedQueryService.Create: %w",
+			apperror.Conflict(fmt.Sprintf("saved query %q already exists", input.APIName)))
+	}
+
+	q, err := s.repo.Create(ctx, input, analysis.FieldIDs)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryService.Create: %w", err)
+	}
+
+	if err := s.cache.LoadSavedQueries(ctx); err != nil {
+		return nil, fmt.Errorf("savedQueryService.Create: %w", err)
+	}
+	return q, nil
+}
+
+func (s *savedQueryService) GetByID(ctx context.Context, id uuid.UUID) (*SavedQuery, error) {
+	q, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryService.GetByID: %w", err)
+	}
+	if q == nil {
+		return nil, fmt.Errorf("savedQueryService.GetByID: %w",
+			apperror.NotFound("saved_query", id.String()))
+	}
+	return q, nil
+}
+
+func (s *savedQueryService) GetByAPIName(_ context.Context, apiName string) (*SavedQuery, error) {
+	q, ok := s.cache.GetSavedQueryByAPIName(apiName)
+	if !ok {
+		return nil, fmt.Errorf("savedQueryService.GetByAPIName: %w",
+			apperror.NotFound("saved_query", apiName))
+	}
+	return &q, nil
+}
+
+func (s *savedQueryService) ListAll(ctx context.Context) ([]SavedQuery, error) {
+	queries, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryService.ListAll: %w", err)
+	}
+	return queries, nil
+}
+
+func (s *savedQueryService) Update(ctx context.Context, id uuid.UUID, input UpdateSavedQueryInput) (*SavedQuery, error) {
+	if input.Params == nil {
+		input.Params = []SavedQueryParam{}
+	}
+	analysis, err := s.analyze(ctx, input.Label, input.SOQL, input.Params)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryService.Update: %w", err)
+	}
+
+	q, err := s.repo.Update(ctx, id, input, analysis.FieldIDs)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryService.Update: %w", err)
+	}
+	if q == nil {
+		return nil, fmt.Errorf("savedQueryService.Update: %w",
+			apperror.NotFound("saved_query", id.String()))
+	}
+
+	if err := s.cache.LoadSavedQueries(ctx); err != nil {
+		return nil, fmt.Errorf("savedQueryService.Update: %w", err)
+	}
+	return q, nil
+}
+
+func (s *savedQueryService) Delete(ctx context.Context, id uuid.UUID) error {
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("savedQueryService.Delete: %w", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("savedQueryService.Delete: %w",
+			apperror.NotFound("saved_query", id.String()))
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("savedQueryService.Delete: %w", err)
+	}
+
+	if err := s.cache.LoadSavedQueries(ctx); err != nil {
+		return fmt.Errorf("savedQueryService.Delete: %w", err)
+	}
+	return nil
+}
+
+func (s *savedQueryService) ListVersions(ctx context.Context, id uuid.UUID) ([]SavedQueryVersion, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("savedQueryService.ListVersions: %w", err)
+	}
+
+	versions, err := s.repo.ListVersions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryService.ListVersions: %w", err)
+	}
+	return versions, nil
+}
+
+// analyze validates a saved query definition and checks that its declared
+// parameters match the bind variables of the SOQL.
+func (s *savedQueryService) analyze(ctx context.Context, label, soql string, params []SavedQueryParam) (*SavedQueryAnalysis, error) {
+	if label == "" {
+		return nil, apperror.BadRequest("label is required")
+	}
+	if strings.TrimSpace(soql) == "" {
+		return nil, apperror.BadRequest("soql is required")
+	}
+	if len(soql) > maxSavedQuerySize {
+		return nil, apperror.BadRequest(fmt.Sprintf("soql must be at most %d characters", maxSavedQuerySize))
+	}
+	if err := validateSavedQueryParams(params); err != nil {
+		return nil, err
+	}
+
+	analysis, err := s.analyzer.AnalyzeSavedQuery(ctx, soql)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := make(map[string]string, len(params))
+	for _, p := range params {
+		declared[p.Name] = p.Type
+	}
+	for name, typ := range analysis.Binds {
+		declaredType, ok := declared[name]
+		if !ok {
+			return nil, apperror.BadRequest(fmt.Sprintf("bind variable :%s is not declared as a parameter", name))
+		}
+		if declaredType != typ {
+			return nil, apperror.BadRequest(fmt.Sprintf(
+				"parameter %q is declared as %s, but the query uses it as %s", name, declaredType, typ))
+		}
+	}
+	for _, p := range params {
+		if _, ok := analysis.Binds[p.Name]; !ok {
+			return nil, apperror.BadRequest(fmt.Sprintf("parameter %q is not used by the query", p.Name))
+		}
+	}
+	return analysis, nil
+}
+
+func validateSavedQueryAPIName(apiName string) error {
+	if !validSavedQueryAPIName.MatchString(apiName) {
+		return apperror.BadRequest("api_name must match ^[a-z][a-z0-9_]*$")
+	}
+	if len(apiName) > 100 {
+		return apperror.BadRequest("api_name must be at most 100 characters")
+	}
+	return nil
+}
+
+func validateSavedQueryParams(params []SavedQueryParam) error {
+	if len(params) > maxSavedQueryParams {
+		return apperror.BadRequest(fmt.Sprintf("max %d parameters allowed", maxSavedQueryParams))
+	}
+	seen := make(map[string]bool, len(params))
+	for _, p := range params {
+		if !validSavedQueryParamName.MatchString(p.Name) {
+			return apperror.BadRequest(fmt.Sprintf("invalid parameter name %q", p.Name))
+		}
+		if seen[p.Name] {
+			return apperror.BadRequest(fmt.Sprintf("duplicate parameter %q", p.Name))
+		}
+		seen[p.Name] = true
+		if !savedQueryParamTypes[strings.TrimSuffix(p.Type, "[]")] {
+			return apperror.BadRequest(fmt.Sprintf("parameter %q has invalid type %q", p.Name, p.Type))
+		}
+	}
+	return nil
+}
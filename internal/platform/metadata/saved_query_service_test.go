@@ -0,0 +1,374 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+)
+
+// mockSavedQueryRepo implements SavedQueryRepository for testing.
+type mockSavedQueryRepo struct {
+	queries  map[uuid.UUID]*SavedQuery
+	versions map[uuid.UUID][]SavedQueryVersion
+	fields   map[uuid.UUID][]uuid.UUID
+}
+
+func newMockSavedQueryRepo() *mockSavedQueryRepo {
+	return &mockSavedQueryRepo{
+		queries:  make(map[uuid.UUID]*SavedQuery),
+		versions: make(map[uuid.UUID][]SavedQueryVersion),
+		fields:   make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+func (m *mockSavedQueryRepo) Create(_ context.Context, input CreateSavedQueryInput, fieldIDs []uuid.UUID) (*SavedQuery, error) {
+	q := &SavedQuery{
+		ID:          uuid.New(),
+		APIName:     input.APIName,
+		Label:       input.Label,
+		Description: input.Description,
+		SOQL:        input.SOQL,
+		Params:      input.Params,
+		Version:     1,
+	}
+	m.queries[q.ID] = q
+	m.versions[q.ID] = []SavedQueryVersion{{SavedQueryID: q.ID, Version: 1, SOQL: q.SOQL, Params: q.Params}}
+	m.fields[q.ID] = fieldIDs
+	return q, nil
+}
+
+func (m *mockSavedQueryRepo) GetByID(_ context.Context, id uuid.UUID) (*SavedQuery, error) {
+	return m.queries[id], nil
+}
+
+func (m *mockSavedQueryRepo) GetByAPIName(_ context.Context, apiName string) (*SavedQuery, error) {
+	for _, q := range m.queries {
+		if q.APIName == apiName {
+			return q, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockSavedQueryRepo) ListAll(_ context.Context) ([]SavedQuery, error) {
+	var result []SavedQuery
+	for _, q := range m.queries {
+		result = append(result, *q)
+	}
+	return result, nil
+}
+
+func (m *mockSavedQueryRepo) Update(_ context.Context, id uuid.UUID, input UpdateSavedQueryInput, fieldIDs []uuid.UUID) (*SavedQuery, error) {
+	q := m.queries[id]
+	if q == nil {
+		return nil, nil
+	}
+	if q.SOQL != input.SOQL {
+		q.Version++
+		m.versions[id] = append(m.versions[id], SavedQueryVersion{
+			SavedQueryID: id, Version: q.Version, SOQL: input.SOQL, Params: input.Params,
+			ChangeSummary: input.ChangeSummary,
+		})
+	}
+	q.Label = input.Label
+	q.Description = input.Description
+	q.SOQL = input.SOQL
+	q.Params = input.Params
+	m.fields[id] = fieldIDs
+	return q, nil
+}
+
+func (m *mockSavedQueryRepo) Delete(_ context.Context, id uuid.UUID) error {
+	delete(m.queries, id)
+	delete(m.fields, id)
+	return nil
+}
+
+func (m *mockSavedQueryRepo) Count(_ context.Context) (int, error) {
+	return len(m.queries), nil
+}
+
+func (m *mockSavedQueryRepo) ListVersions(_ context.Context, id uuid.UUID) ([]SavedQueryVersion, error) {
+	return m.versions[id], nil
+}
+
+func (m *mockSavedQueryRepo) ListByFieldID(_ context.Context, fieldID uuid.UUID) ([]SavedQuery, error) {
+	var result []SavedQuery
+	for id, fields := range m.fields {
+		for _, f := range fields {
+			if f == fieldID {
+				result = append(result, *m.queries[id])
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// savedQueryCacheLoader serves the saved queries of a mock repository.
+type savedQueryCacheLoader struct {
+	noopCacheLoader
+	repo *mockSavedQueryRepo
+}
+
+func (l *savedQueryCacheLoader) LoadAllSavedQueries(ctx context.Context) ([]SavedQuery, error) {
+	return l.repo.ListAll(ctx)
+}
+
+func (l *savedQueryCacheLoader) LoadAllPicklistValues(_ context.Context) ([]PicklistValueRow, error) {
+	return nil, nil
+}
+
+func (l *savedQueryCacheLoader) LoadAllTranslations(_ context.Context) ([]Translation, error) {
+	return nil, nil
+}
+
+// stubSavedQueryAnalyzer reports fixed bind variables and fields.
+type stubSavedQueryAnalyzer struct {
+	binds    map[string]string
+	fieldIDs []uuid.UUID
+	err      error
+}
+
+func (a *stubSavedQueryAnalyzer) AnalyzeSavedQuery(_ context.Context, _ string) (*SavedQueryAnalysis, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &SavedQueryAnalysis{Binds: a.binds, FieldIDs: a.fieldIDs}, nil
+}
+
+func setupSavedQueryServiceTest(t *testing.T, analyzer SavedQueryAnalyzer) (SavedQueryService, *mockSavedQueryRepo) {
+	t.Helper()
+	repo := newMockSavedQueryRepo()
+	cache := NewMetadataCache(&savedQueryCacheLoader{repo: repo})
+	require.NoError(t, cache.Load(context.Background()))
+	return NewSavedQueryService(repo, analyzer, cache), repo
+}
+
+func TestSavedQueryService_Create(t *testing.T) {
+	t.Parallel()
+
+	fieldID := uuid.New()
+	const soql = "SELECT Id FROM Account WHERE Industry = :industry"
+
+	tests := []struct {
+		name     string
+		input    CreateSavedQueryInput
+		analyzer *stubSavedQueryAnalyzer
+		errMsg   string
+	}{
+		{
+			name: "creates query with declared parameters",
+			input: CreateSavedQueryInput{
+				APIName: "accounts_by_industry", Label: "Accounts by industry", SOQL: soql,
+				Params: []SavedQueryParam{{Name: "industry", Type: "string"}},
+			},
+			analyzer: &stubSavedQueryAnalyzer{binds: map[string]string{"industry": "string"}, fieldIDs: []uuid.UUID{fieldID}},
+		},
+		{
+			name: "invalid api name",
+			input: CreateSavedQueryInput{
+				APIName: "Accounts", Label: "Accounts", SOQL: "SELECT Id FROM Account",
+			},
+			analyzer: &stubSavedQueryAnalyzer{},
+			errMsg:   "api_name must match",
+		},
+		{
+			name:     "label is required",
+			input:    CreateSavedQueryInput{APIName: "accounts", SOQL: "SELECT Id FROM Account"},
+			analyzer: &stubSavedQueryAnalyzer{},
+			errMsg:   "label is required",
+		},
+		{
+			name: "invalid soql",
+			input: CreateSavedQueryInput{
+				APIName: "accounts", Label: "Accounts", SOQL: "SELECT FROM",
+			},
+			analyzer: &stubSavedQueryAnalyzer{err: apperror.BadRequest("invalid soql: unexpected token")},
+			errMsg:   "invalid soql",
+		},
+		{
+			name: "undeclared bind variable",
+			input: CreateSavedQueryInput{
+				APIName: "accounts_by_industry", Label: "Accounts by industry", SOQL: soql,
+			},
+			analyzer: &stubSavedQueryAnalyzer{binds: map[string]string{"industry": "string"}},
+			errMsg:   "bind variable :industry is not declared",
+		},
+		{
+			name: "declared type differs from the query",
+			input: CreateSavedQueryInput{
+				APIName: "accounts_by_industry", Label: "Accounts by industry", SOQL: soql,
+				Params: []SavedQueryParam{{Name: "industry", Type: "integer"}},
+			},
+			analyzer: &stubSavedQueryAnalyzer{binds: map[string]string{"industry": "string"}},
+			errMsg:   `parameter "industry" is declared as integer, but the query uses it as string`,
+		},
+		{
+			name: "unused parameter",
+			input: CreateSavedQueryInput{
+				APIName: "accounts", Label: "Accounts", SOQL: "SELECT Id FROM Account",
+				Params: []SavedQueryParam{{Name: "industry", Type: "string"}},
+			},
+			analyzer: &stubSavedQueryAnalyzer{},
+			errMsg:   `parameter "industry" is not used`,
+		},
+		{
+			name: "unknown parameter type",
+			input: CreateSavedQueryInput{
+				APIName: "accounts_by_industry", Label: "Accounts by industry", SOQL: soql,
+				Params: []SavedQueryParam{{Name: "industry", Type: "text"}},
+			},
+			analyzer: &stubSavedQueryAnalyzer{binds: map[string]string{"industry": "string"}},
+			errMsg:   `invalid type "text"`,
+		},
+		{
+			name: "duplicate parameter",
+			input: CreateSavedQueryInput{
+				APIName: "accounts_by_industry", Label: "Accounts by industry", SOQL: soql,
+				Params: []SavedQueryParam{{Name: "industry", Type: "string"}, {Name: "industry", Type: "string"}},
+			},
+			analyzer: &stubSavedQueryAnalyzer{binds: map[string]string{"industry": "string"}},
+			errMsg:   `duplicate parameter "industry"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc, repo := setupSavedQueryServiceTest(t, tt.analyzer)
+
+			q, err := svc.Create(context.Background(), tt.input)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				assert.Empty(t, repo.queries)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, q.Version)
+			assert.Equal(t, tt.analyzer.fieldIDs, repo.fields[q.ID])
+
+			cached, err := svc.GetByAPIName(context.Background(), tt.input.APIName)
+			require.NoError(t, err)
+			assert.Equal(t, q.ID, cached.ID)
+		})
+	}
+}
+
+func TestSavedQueryService_Create_Duplicate(t *testing.T) {
+	t.Parallel()
+	svc, _ := setupSavedQueryServiceTest(t, &stubSavedQueryAnalyzer{})
+
+	input := CreateSavedQueryInput{APIName: "accounts", Label: "Accounts", SOQL: "SELECT Id FROM Account"}
+	_, err := svc.Create(context.Background(), input)
+	require.NoError(t, err)
+
+	_, err = svc.Create(context.Background(), input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
+
+func TestSavedQueryService_UpdateRecordsVersions(t *testing.T) {
+	t.Parallel()
+	svc, _ := setupSavedQueryServiceTest(t, &stubSavedQueryAnalyzer{})
+	ctx := context.Background()
+
+	q, err := svc.Create(ctx, CreateSavedQueryInput{APIName: "accounts", Label: "Accounts", SOQL: "SELECT Id FROM Account"})
+	require.NoError(t, err)
+
+	updated, err := svc.Update(ctx, q.ID, UpdateSavedQueryInput{
+		Label: "Accounts", SOQL: "SELECT Id, Name FROM Account", ChangeSummary: "Add Name",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+
+	versions, err := svc.ListVersions(ctx, q.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "Add Name", versions[1].ChangeSummary)
+
+	cached, err := svc.GetByAPIName(ctx, "accounts")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT Id, Name FROM Account", cached.SOQL)
+}
+
+func TestSavedQueryService_NotFound(t *testing.T) {
+	t.Parallel()
+	svc, _ := setupSavedQueryServiceTest(t, &stubSavedQueryAnalyzer{})
+	ctx := context.Background()
+
+	_, err := svc.Update(ctx, uuid.New(), UpdateSavedQueryInput{Label: "Accounts", SOQL: "SELECT Id FROM Account"})
+	assert.Contains(t, err.Error(), "not found")
+
+	err = svc.Delete(ctx, uuid.New())
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = svc.GetByAPIName(ctx, "missing")
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestSavedQuery_BindValues(t *testing.T) {
+	t.Parallel()
+
+	q := &SavedQuery{Params: []SavedQueryParam{
+		{Name: "industry", Type: "string"},
+		{Name: "ids", Type: "id[]"},
+		{Name: "limit_date", Type: "date", Default: "2026-01-01"},
+	}}
+
+	tests := []struct {
+		name   string
+		values map[string][]string
+		want   map[string]any
+		errMsg string
+	}{
+		{
+			name:   "values and defaults",
+			values: map[string][]string{"industry": {"Tech"}, "ids": {"a", "b"}, "other": {"x"}},
+			want: map[string]any{
+				"industry":   "Tech",
+				"ids":        []any{"a", "b"},
+				"limit_date": "2026-01-01",
+			},
+		},
+		{
+			name:   "overrides default",
+			values: map[string][]string{"industry": {"Tech"}, "ids": {"a"}, "limit_date": {"2026-03-01"}},
+			want: map[string]any{
+				"industry":   "Tech",
+				"ids":        []any{"a"},
+				"limit_date": "2026-03-01",
+			},
+		},
+		{
+			name:   "missing required parameter",
+			values: map[string][]string{"ids": {"a"}},
+			errMsg: `parameter "industry" is required`,
+		},
+		{
+			name:   "several values for a scalar",
+			values: map[string][]string{"industry": {"Tech", "Retail"}, "ids": {"a"}},
+			errMsg: `parameter "industry" takes a single value`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			binds, err := q.BindValues(tt.values)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, binds)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package metadata
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+)
+
+// SavedQuery is a named, parameterized SOQL query stored as metadata.
+// Its parameters are the bind variables of the query.
+type SavedQuery struct {
+	ID          uuid.UUID         `json:"id"`
+	APIName     string            `json:"api_name"`
+	Label       string            `json:"label"`
+	Description string            `json:"description"`
+	SOQL        string            `json:"soql"`
+	Params      []SavedQueryParam `json:"params"`
+	Version     int               `json:"version"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// SavedQueryParam declares a bind variable of a saved query.
+type SavedQueryParam struct {
+	Name string `json:"name"`
+	// Type is the SOQL type of the variable: string, integer, float, boolean,
+	// date, datetime or id, with a "[]" suffix for lists (e.g. "id[]").
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	// Default is used when a request omits the parameter; a parameter
+	// without a default is required.
+	Default any `json:"default,omitempty"`
+}
+
+// IsList reports whether the parameter takes a list of values.
+func (p SavedQueryParam) IsList() bool {
+	return strings.HasSuffix(p.Type, "[]")
+}
+
+// SavedQueryVersion is a snapshot of a saved query definition. A version is
+// recorded whenever the SOQL or the parameters change.
+type SavedQueryVersion struct {
+	ID            uuid.UUID         `json:"id"`
+	SavedQueryID  uuid.UUID         `json:"saved_query_id"`
+	Version       int               `json:"version"`
+	SOQL          string            `json:"soql"`
+	Params        []SavedQueryParam `json:"params"`
+	ChangeSummary string            `json:"change_summary"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// CreateSavedQueryInput is the input for creating a new saved query.
+type CreateSavedQueryInput struct {
+	APIName     string
+	Label       string
+	Description string
+	SOQL        string
+	Params      []SavedQueryParam
+}
+
+// UpdateSavedQueryInput is the input for updating a saved query.
+type UpdateSavedQueryInput struct {
+	Label         string
+	Description   string
+	SOQL          string
+	Params        []SavedQueryParam
+	ChangeSummary string
+}
+
+// BindValues builds the bind variables of the query from request values,
+// e.g. URL query parameters. Values stay strings; the SOQL engine converts
+// them to the type of each variable. A list parameter takes every value
+// given for its name. Values for undeclared names are ignored.
+func (q *SavedQuery) BindValues(values map[string][]string) (map[string]any, error) {
+	binds := make(map[string]any, len(q.Params))
+	for _, p := range q.Params {
+		given := values[p.Name]
+		switch {
+		case len(given) == 0:
+			if p.Default == nil {
+				return nil, apperror.BadRequest(fmt.Sprintf("parameter %q is required", p.Name))
+			}
+			binds[p.Name] = p.Default
+		case p.IsList():
+			items := make([]any, len(given))
+			for i, v := range given {
+				items[i] = v
+			}
+			binds[p.Name] = items
+		case len(given) > 1:
+			return nil, apperror.BadRequest(fmt.Sprintf("parameter %q takes a single value", p.Name))
+		default:
+			binds[p.Name] = given[0]
+		}
+	}
+	return binds, nil
+}
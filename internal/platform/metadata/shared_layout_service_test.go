@@ -133,6 +133,15 @@ func (m *mockSharedLayoutCacheLoader) LoadAllLayouts(_ context.Context) ([]Layou
 func (m *mockSharedLayoutCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]SharedLayout, error) {
 	return m.sharedLayouts, nil
 }
+func (m *mockSharedLayoutCacheLoader) LoadAllSavedQueries(_ context.Context) ([]SavedQuery, error) {
+	return nil, nil
+}
+func (m *mockSharedLayoutCacheLoader) LoadAllPicklistValues(_ context.Context) ([]PicklistValueRow, error) {
+	return nil, nil
+}
+func (m *mockSharedLayoutCacheLoader) LoadAllTranslations(_ context.Context) ([]Translation, error) {
+	return nil, nil
+}
 func (m *mockSharedLayoutCacheLoader) RefreshMaterializedView(_ context.Context) error {
 	return nil
 }
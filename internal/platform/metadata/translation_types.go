@@ -0,0 +1,35 @@
+package metadata
+
+import "github.com/google/uuid"
+
+// Translation resource types (metadata.translations.resource_type).
+// A translation is keyed by the resource, the translated property and
+// the locale:
+//
+//	object          object definition ID  label, plural_label
+//	field           field definition ID   label
+//	picklist_value  picklist value ID     label
+//
+// Picklist values of both local (FieldConfig.Values) and global
+// (metadata.picklist_values) picklists are translated by their value ID.
+const (
+	TranslationObject        = "object"
+	TranslationField         = "field"
+	TranslationPicklistValue = "picklist_value"
+)
+
+// Translation is a localized value of a metadata property.
+type Translation struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   uuid.UUID `json:"resource_id"`
+	FieldName    string    `json:"field_name"`
+	Locale       string    `json:"locale"`
+	Value        string    `json:"value"`
+}
+
+// translationKey identifies a translated property.
+type translationKey struct {
+	resourceType string
+	resourceID   uuid.UUID
+	fieldName    string
+}
@@ -211,11 +211,16 @@ func (e *RecordCommandExecutor) executeQuery(ctx context.Context, cmd metadata.C
 		return nil, fmt.Errorf("record.query: resolve query: %w", err)
 	}
 
+	binds, err := e.resolver.ResolveMap(cmd.Binds, execCtx)
+	if err != nil {
+		return nil, fmt.Errorf("record.query: resolve binds: %w", err)
+	}
+
 	if execCtx.DryRun {
 		return []map[string]any{}, nil
 	}
 
-	result, err := e.querySvc.Execute(ctx, query, nil)
+	result, err := e.querySvc.Execute(ctx, query, &soql.QueryParams{Binds: binds, Unpaged: true})
 	if err != nil {
 		return nil, fmt.Errorf("record.query: %w", err)
 	}
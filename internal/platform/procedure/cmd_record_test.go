@@ -0,0 +1,47 @@
+package procedure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/soql"
+)
+
+type stubQueryService struct {
+	soql.QueryService
+	query  string
+	params *soql.QueryParams
+}
+
+func (s *stubQueryService) Execute(_ context.Context, query string, params *soql.QueryParams) (*soql.QueryResult, error) {
+	s.query = query
+	s.params = params
+	return &soql.QueryResult{Records: []map[string]any{{"Id": "1"}}}, nil
+}
+
+func TestRecordCommandExecutor_QueryBinds(t *testing.T) {
+	t.Parallel()
+
+	querySvc := &stubQueryService{}
+	exec := NewRecordCommandExecutor(nil, querySvc, NewExpressionResolver(newTestCELCache()))
+
+	cmd := metadata.CommandDef{
+		Type:  "record.query",
+		Query: "SELECT Id FROM Opportunity WHERE OwnerId = :owner AND StageName = :stage",
+		Binds: map[string]string{"owner": "$.input.user_id", "stage": "Closed Won"},
+	}
+	execCtx := &ExecutionContext{Vars: map[string]any{"input": map[string]any{"user_id": "u-1"}}}
+
+	result, err := exec.Execute(context.Background(), cmd, execCtx)
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+
+	assert.Equal(t, cmd.Query, querySvc.query, "values must not be spliced into SOQL")
+	require.NotNil(t, querySvc.params)
+	assert.True(t, querySvc.params.Unpaged)
+	assert.Equal(t, map[string]any{"owner": "u-1", "stage": "Closed Won"}, querySvc.params.Binds)
+}
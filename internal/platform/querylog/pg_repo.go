@@ -0,0 +1,188 @@
+package querylog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository stores the query log.
+type Repository interface {
+	InsertBatch(ctx context.Context, entries []Entry) error
+	TopSlow(ctx context.Context, filter Filter) ([]SlowQuery, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, filter Filter) ([]Entry, error)
+
+	// Partition maintenance.
+	CreatePartition(ctx context.Context, day time.Time) error
+	ListPartitions(ctx context.Context) ([]string, error)
+	DropPartition(ctx context.Context, name string) error
+}
+
+// PgRepository is the PostgreSQL implementation of Repository.
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRepository creates a new PgRepository.
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// InsertBatch writes entries with a single COPY.
+func (r *PgRepository) InsertBatch(ctx context.Context, entries []Entry) error {
+	rows := make([][]any, len(entries))
+	for i, e := range entries {
+		var errMsg *string
+		if e.Error != "" {
+			errMsg = &e.Error
+		}
+		rows[i] = []any{
+			e.ID, string(e.Kind), e.UserID, e.Source, e.QueryHash, e.Object,
+			e.RowCount, e.DurationMs, e.RLSApplied, errMsg, e.CreatedAt,
+		}
+	}
+
+	_, err := r.pool.CopyFrom(ctx,
+		pgx.Identifier{"metadata", "query_log"},
+		[]string{
+			"id", "kind", "user_id", "source", "query_hash", "object",
+			"row_count", "duration_ms", "rls_applied", "error", "created_at",
+		},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("pgQueryLogRepo.InsertBatch: %w", err)
+	}
+	return nil
+}
+
+// TopSlow returns the statements with the highest average duration since
+// filter.Since, grouped by statement and source.
+func (r *PgRepository) TopSlow(ctx context.Context, filter Filter) ([]SlowQuery, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT query_hash, kind, object, source,
+			count(*), count(error),
+			avg(duration_ms), max(duration_ms),
+			sum(row_count), max(created_at)
+		FROM metadata.query_log
+		WHERE created_at >= $1
+			AND ($2 = '' OR kind = $2)
+			AND ($3 = '' OR object = $3)
+		GROUP BY query_hash, kind, object, source
+		ORDER BY avg(duration_ms) DESC, count(*) DESC
+		LIMIT $4
+	`, filter.Since, string(filter.Kind), filter.Object, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgQueryLogRepo.TopSlow: %w", err)
+	}
+	defer rows.Close()
+
+	var result []SlowQuery
+	for rows.Next() {
+		var q SlowQuery
+		var kind string
+		if err := rows.Scan(
+			&q.QueryHash, &kind, &q.Object, &q.Source,
+			&q.Executions, &q.Errors,
+			&q.AvgDurationMs, &q.MaxDurationMs,
+			&q.TotalRows, &q.LastSeenAt,
+		); err != nil {
+			return nil, fmt.Errorf("pgQueryLogRepo.TopSlow: scan: %w", err)
+		}
+		q.Kind = Kind(kind)
+		result = append(result, q)
+	}
+
+	return result, rows.Err()
+}
+
+// ListByUser returns the latest entries of a user since filter.Since.
+func (r *PgRepository) ListByUser(ctx context.Context, userID uuid.UUID, filter Filter) ([]Entry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, kind, user_id, source, query_hash, object,
+			row_count, duration_ms, rls_applied, error, created_at
+		FROM metadata.query_log
+		WHERE user_id = $1 AND created_at >= $2
+			AND ($3 = '' OR kind = $3)
+			AND ($4 = '' OR object = $4)
+		ORDER BY created_at DESC
+		LIMIT $5
+	`, userID, filter.Since, string(filter.Kind), filter.Object, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgQueryLogRepo.ListByUser: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var kind string
+		var errMsg *string
+		if err := rows.Scan(
+			&e.ID, &kind, &e.UserID, &e.Source, &e.QueryHash, &e.Object,
+			&e.RowCount, &e.DurationMs, &e.RLSApplied, &errMsg, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("pgQueryLogRepo.ListByUser: scan: %w", err)
+		}
+		e.Kind = Kind(kind)
+		if errMsg != nil {
+			e.Error = *errMsg
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// CreatePartition creates the partition holding the entries of day (UTC),
+// unless it exists.
+func (r *PgRepository) CreatePartition(ctx context.Context, day time.Time) error {
+	from := truncateDay(day)
+	_, err := r.pool.Exec(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS metadata.%s PARTITION OF metadata.query_log FOR VALUES FROM ('%s') TO ('%s')`,
+		partitionName(from), from.Format(time.RFC3339), from.AddDate(0, 0, 1).Format(time.RFC3339),
+	))
+	if err != nil {
+		return fmt.Errorf("pgQueryLogRepo.CreatePartition: %w", err)
+	}
+	return nil
+}
+
+// ListPartitions returns the names of the existing partitions.
+func (r *PgRepository) ListPartitions(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'metadata.query_log'::regclass
+		ORDER BY c.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("pgQueryLogRepo.ListPartitions: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("pgQueryLogRepo.ListPartitions: scan: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// DropPartition drops a partition with all its entries.
+func (r *PgRepository) DropPartition(ctx context.Context, name string) error {
+	_, err := r.pool.Exec(ctx, "DROP TABLE IF EXISTS metadata."+pgx.Identifier{name}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("pgQueryLogRepo.DropPartition: %w", err)
+	}
+	return nil
+}
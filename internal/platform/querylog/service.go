@@ -0,0 +1,87 @@
+package querylog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+)
+
+const (
+	defaultWindow = 24 * time.Hour
+	defaultLimit  = 50
+	maxLimit      = 500
+)
+
+// Service reads the query log for the admin API.
+type Service interface {
+	TopSlow(ctx context.Context, filter Filter) ([]SlowQuery, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, filter Filter) ([]Entry, error)
+}
+
+type service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+// NewService creates a new query log Service.
+func NewService(repo Repository) Service {
+	return &service{repo: repo, now: time.Now}
+}
+
+// TopSlow returns the slowest statements on average, grouped by statement
+// hash and source.
+func (s *service) TopSlow(ctx context.Context, filter Filter) ([]SlowQuery, error) {
+	filter, err := s.normalize(filter)
+	if err != nil {
+		return nil, fmt.Errorf("queryLogService.TopSlow: %w", err)
+	}
+
+	queries, err := s.repo.TopSlow(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("queryLogService.TopSlow: %w", err)
+	}
+	if queries == nil {
+		queries = []SlowQuery{}
+	}
+	return queries, nil
+}
+
+// ListByUser returns the latest statements of a user, newest first.
+func (s *service) ListByUser(ctx context.Context, userID uuid.UUID, filter Filter) ([]Entry, error) {
+	filter, err := s.normalize(filter)
+	if err != nil {
+		return nil, fmt.Errorf("queryLogService.ListByUser: %w", err)
+	}
+
+	entries, err := s.repo.ListByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("queryLogService.ListByUser: %w", err)
+	}
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return entries, nil
+}
+
+// normalize applies the default window and limit.
+func (s *service) normalize(filter Filter) (Filter, error) {
+	switch filter.Kind {
+	case "", KindSOQL, KindDML:
+	default:
+		return filter, apperror.BadRequest(fmt.Sprintf("invalid kind '%s': expected soql or dml", filter.Kind))
+	}
+	if filter.Since.IsZero() {
+		filter.Since = s.now().Add(-defaultWindow)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	return filter, nil
+}
@@ -0,0 +1,89 @@
+package querylog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+)
+
+// filterRepo captures the filter passed to the repository.
+type filterRepo struct {
+	fakeRepo
+	filter Filter
+}
+
+func (r *filterRepo) TopSlow(_ context.Context, filter Filter) ([]SlowQuery, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func (r *filterRepo) ListByUser(_ context.Context, _ uuid.UUID, filter Filter) ([]Entry, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func TestService_Filter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		filter     Filter
+		wantFilter Filter
+		wantErr    bool
+	}{
+		{
+			name:       "defaults",
+			filter:     Filter{},
+			wantFilter: Filter{Since: now.Add(-24 * time.Hour), Limit: 50},
+		},
+		{
+			name:       "keeps given values",
+			filter:     Filter{Since: since, Kind: KindDML, Object: "Deal", Limit: 10},
+			wantFilter: Filter{Since: since, Kind: KindDML, Object: "Deal", Limit: 10},
+		},
+		{
+			name:       "caps limit",
+			filter:     Filter{Since: since, Limit: 10000},
+			wantFilter: Filter{Since: since, Limit: 500},
+		},
+		{
+			name:    "rejects unknown kind",
+			filter:  Filter{Kind: "sosl"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := &filterRepo{}
+			svc := &service{repo: repo, now: func() time.Time { return now }}
+
+			queries, err := svc.TopSlow(context.Background(), tt.filter)
+			if tt.wantErr {
+				var appErr *apperror.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, apperror.CodeBadRequest, appErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, queries, "an empty result is a list")
+			assert.Equal(t, tt.wantFilter, repo.filter)
+
+			entries, err := svc.ListByUser(context.Background(), uuid.New(), tt.filter)
+			require.NoError(t, err)
+			assert.NotNil(t, entries)
+			assert.Equal(t, tt.wantFilter, repo.filter)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package querylog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/platform/security"
+)
+
+// Kind is the language of a logged statement.
+type Kind string
+
+const (
+	KindSOQL Kind = "soql"
+	KindDML  Kind = "dml"
+)
+
+// maxErrorLength caps the stored error message.
+const maxErrorLength = 1000
+
+// Entry is one logged SOQL query or DML statement.
+type Entry struct {
+	ID         uuid.UUID  `json:"id"`
+	Kind       Kind       `json:"kind"`
+	UserID     *uuid.UUID `json:"user_id"`
+	Source     string     `json:"source"`
+	QueryHash  string     `json:"query_hash"`
+	Object     string     `json:"object"`
+	RowCount   int        `json:"row_count"`
+	DurationMs int        `json:"duration_ms"`
+	RLSApplied bool       `json:"rls_applied"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// SlowQuery aggregates the executions of one statement from one source.
+type SlowQuery struct {
+	QueryHash     string    `json:"query_hash"`
+	Kind          Kind      `json:"kind"`
+	Object        string    `json:"object"`
+	Source        string    `json:"source"`
+	Executions    int       `json:"executions"`
+	Errors        int       `json:"errors"`
+	AvgDurationMs float64   `json:"avg_duration_ms"`
+	MaxDurationMs int       `json:"max_duration_ms"`
+	TotalRows     int64     `json:"total_rows"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+}
+
+// Filter narrows the log entries read by the admin API.
+type Filter struct {
+	Since  time.Time
+	Kind   Kind   // empty for both
+	Object string // empty for all objects
+	Limit  int
+}
+
+// Hash identifies a statement text without storing it: queries with the
+// same text share a hash, literals included.
+func Hash(statement string) string {
+	sum := sha256.Sum256([]byte(statement))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewEntry describes an execution of statement that started at started and
+// ended with err. The user, the source and the RLS flag come from ctx.
+func NewEntry(ctx context.Context, kind Kind, statement, object string, rows int, started time.Time, err error) Entry {
+	e := Entry{
+		ID:         uuid.New(),
+		Kind:       kind,
+		Source:     SourceFromContext(ctx),
+		QueryHash:  Hash(statement),
+		Object:     object,
+		RowCount:   rows,
+		DurationMs: int(time.Since(started).Milliseconds()),
+		RLSApplied: RLSApplied(ctx),
+		CreatedAt:  time.Now().UTC(),
+	}
+	if uc, ok := security.UserFromContext(ctx); ok && uc.UserID != uuid.Nil {
+		id := uc.UserID
+		e.UserID = &id
+	}
+	if err != nil {
+		e.Error = err.Error()
+		if len(e.Error) > maxErrorLength {
+			e.Error = e.Error[:maxErrorLength]
+		}
+	}
+	return e
+}
+
+type sourceKey struct{}
+
+// WithSource tags the statements executed with ctx, e.g. with the Object
+// View or saved query that issued them.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceFromContext returns the source set by WithSource, or "".
+func SourceFromContext(ctx context.Context) string {
+	source, _ := ctx.Value(sourceKey{}).(string)
+	return source
+}
+
+type traceKey struct{}
+
+// trace collects what executors report about a single execution.
+type trace struct {
+	rls bool
+}
+
+// Trace prepares ctx to collect the facts an executor reports about one
+// execution, such as MarkRLSApplied.
+func Trace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, traceKey{}, &trace{})
+}
+
+// MarkRLSApplied reports that row-level security predicates were added to
+// the statement executed with ctx. It does nothing outside Trace.
+func MarkRLSApplied(ctx context.Context) {
+	if t, ok := ctx.Value(traceKey{}).(*trace); ok {
+		t.rls = true
+	}
+}
+
+// RLSApplied reports whether MarkRLSApplied was called for ctx.
+func RLSApplied(ctx context.Context) bool {
+	t, ok := ctx.Value(traceKey{}).(*trace)
+	return ok && t.rls
+}
@@ -0,0 +1,84 @@
+package querylog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/platform/security"
+)
+
+func TestNewEntry(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		ctx       func() context.Context
+		err       error
+		wantUser  *uuid.UUID
+		wantRLS   bool
+		wantSrc   string
+		wantError string
+	}{
+		{
+			name:     "system context",
+			ctx:      context.Background,
+			wantUser: nil,
+		},
+		{
+			name: "user, source and RLS",
+			ctx: func() context.Context {
+				ctx := security.ContextWithUser(context.Background(), security.UserContext{UserID: userID})
+				ctx = Trace(WithSource(ctx, "view:deals/query:main"))
+				MarkRLSApplied(ctx)
+				return ctx
+			},
+			wantUser: &userID,
+			wantRLS:  true,
+			wantSrc:  "view:deals/query:main",
+		},
+		{
+			name:      "long error is truncated",
+			ctx:       context.Background,
+			err:       errors.New(strings.Repeat("x", 5000)),
+			wantError: strings.Repeat("x", maxErrorLength),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			started := time.Now().Add(-1500 * time.Millisecond)
+			e := NewEntry(tt.ctx(), KindSOQL, "SELECT Id FROM Deal", "Deal", 3, started, tt.err)
+
+			require.NotEqual(t, uuid.Nil, e.ID)
+			assert.Equal(t, KindSOQL, e.Kind)
+			assert.Equal(t, Hash("SELECT Id FROM Deal"), e.QueryHash)
+			assert.Len(t, e.QueryHash, 64)
+			assert.Equal(t, "Deal", e.Object)
+			assert.Equal(t, 3, e.RowCount)
+			assert.GreaterOrEqual(t, e.DurationMs, 1500)
+			assert.Equal(t, tt.wantUser, e.UserID)
+			assert.Equal(t, tt.wantRLS, e.RLSApplied)
+			assert.Equal(t, tt.wantSrc, e.Source)
+			assert.Equal(t, tt.wantError, e.Error)
+		})
+	}
+}
+
+func TestMarkRLSAppliedWithoutTrace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	MarkRLSApplied(ctx)
+	assert.False(t, RLSApplied(ctx))
+}
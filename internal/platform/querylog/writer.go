@@ -0,0 +1,198 @@
+package querylog
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	partitionPrefix = "query_log_"
+	partitionLayout = "20060102"
+
+	// partitionsAhead is the number of days after today whose partitions
+	// are created in advance.
+	partitionsAhead = 2
+)
+
+// Recorder receives executed statements. The SOQL and DML services report
+// to it when logging is enabled.
+type Recorder interface {
+	Record(entry Entry)
+}
+
+// Writer is a Recorder that stores entries in batches in the background, so
+// logging does not slow statements down. When the buffer is full, entries
+// are dropped rather than blocking. Run also maintains the daily partitions
+// of the log and drops those older than the retention period.
+type Writer struct {
+	repo        Repository
+	logger      *slog.Logger
+	entries     chan Entry
+	batchSize   int
+	interval    time.Duration
+	minDuration time.Duration
+	retention   time.Duration
+	now         func() time.Time
+}
+
+// WriterOption configures a Writer.
+type WriterOption func(*Writer)
+
+// WithMinDuration logs only statements that ran at least d, turning the log
+// into a slow-query log. Failed statements are always logged.
+func WithMinDuration(d time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.minDuration = d
+	}
+}
+
+// WithRetention drops entries older than d. Entries are dropped a day at a
+// time, with the whole partition.
+func WithRetention(d time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.retention = d
+	}
+}
+
+// WithBufferSize sets the number of entries waiting to be written before
+// new ones are dropped.
+func WithBufferSize(n int) WriterOption {
+	return func(w *Writer) {
+		w.entries = make(chan Entry, n)
+	}
+}
+
+// NewWriter creates a new Writer. Entries are written once Run is started.
+func NewWriter(repo Repository, logger *slog.Logger, opts ...WriterOption) *Writer {
+	w := &Writer{
+		repo:      repo,
+		logger:    logger,
+		entries:   make(chan Entry, 10000),
+		batchSize: 500,
+		interval:  time.Second,
+		retention: 30 * 24 * time.Hour,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Record queues an entry. It never blocks.
+func (w *Writer) Record(entry Entry) {
+	if entry.Error == "" && time.Duration(entry.DurationMs)*time.Millisecond < w.minDuration {
+		return
+	}
+	select {
+	case w.entries <- entry:
+	default:
+		w.logger.Warn("query log buffer is full, entry dropped", "kind", entry.Kind, "object", entry.Object)
+	}
+}
+
+// Run writes queued entries until ctx is cancelled, then writes the ones
+// still queued. Partitions are maintained on start and hourly.
+func (w *Writer) Run(ctx context.Context) error {
+	w.maintain(ctx)
+
+	flushTicker := time.NewTicker(w.interval)
+	defer flushTicker.Stop()
+	maintainTicker := time.NewTicker(time.Hour)
+	defer maintainTicker.Stop()
+
+	batch := make([]Entry, 0, w.batchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.repo.InsertBatch(ctx, batch); err != nil {
+			w.logger.Error("query log: write failed", "entries", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			for {
+				select {
+				case e := <-w.entries:
+					batch = append(batch, e)
+					if len(batch) == w.batchSize {
+						flush(drainCtx)
+					}
+				default:
+					flush(drainCtx)
+					return ctx.Err()
+				}
+			}
+		case e := <-w.entries:
+			batch = append(batch, e)
+			if len(batch) == w.batchSize {
+				flush(ctx)
+			}
+		case <-flushTicker.C:
+			flush(ctx)
+		case <-maintainTicker.C:
+			w.maintain(ctx)
+		}
+	}
+}
+
+// maintain creates the partitions of today and the next days and drops the
+// partitions past retention.
+func (w *Writer) maintain(ctx context.Context) {
+	today := truncateDay(w.now())
+	for i := 0; i <= partitionsAhead; i++ {
+		if err := w.repo.CreatePartition(ctx, today.AddDate(0, 0, i)); err != nil {
+			w.logger.Error("query log: create partition failed", "error", err)
+			return
+		}
+	}
+
+	names, err := w.repo.ListPartitions(ctx)
+	if err != nil {
+		w.logger.Error("query log: list partitions failed", "error", err)
+		return
+	}
+	for _, name := range expiredPartitions(names, w.now().Add(-w.retention)) {
+		if err := w.repo.DropPartition(ctx, name); err != nil {
+			w.logger.Error("query log: drop partition failed", "partition", name, "error", err)
+			continue
+		}
+		w.logger.Info("query log: partition dropped", "partition", name)
+	}
+}
+
+// partitionName returns the name of the partition holding the entries of day.
+func partitionName(day time.Time) string {
+	return partitionPrefix + day.UTC().Format(partitionLayout)
+}
+
+// expiredPartitions returns the partitions whose entries are all older than
+// cutoff. Names not made by partitionName are ignored.
+func expiredPartitions(names []string, cutoff time.Time) []string {
+	var expired []string
+	for _, name := range names {
+		day, err := time.Parse(partitionLayout, strings.TrimPrefix(name, partitionPrefix))
+		if err != nil || !strings.HasPrefix(name, partitionPrefix) {
+			continue
+		}
+		if !day.AddDate(0, 0, 1).After(cutoff) {
+			expired = append(expired, name)
+		}
+	}
+	sort.Strings(expired)
+	return expired
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,158 @@
+package querylog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepo records writes and partition changes.
+type fakeRepo struct {
+	mu         sync.Mutex
+	written    []Entry
+	partitions map[string]bool
+	dropped    []string
+}
+
+func newFakeRepo(partitions ...string) *fakeRepo {
+	r := &fakeRepo{partitions: make(map[string]bool)}
+	for _, p := range partitions {
+		r.partitions[p] = true
+	}
+	return r
+}
+
+func (r *fakeRepo) InsertBatch(_ context.Context, entries []Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.written = append(r.written, entries...)
+	return nil
+}
+
+func (r *fakeRepo) TopSlow(context.Context, Filter) ([]SlowQuery, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) ListByUser(context.Context, uuid.UUID, Filter) ([]Entry, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) CreatePartition(_ context.Context, day time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.partitions[partitionName(day)] = true
+	return nil
+}
+
+func (r *fakeRepo) ListPartitions(context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.partitions))
+	for name := range r.partitions {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (r *fakeRepo) DropPartition(_ context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.partitions, name)
+	r.dropped = append(r.dropped, name)
+	return nil
+}
+
+func (r *fakeRepo) writtenCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.written)
+}
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestExpiredPartitions(t *testing.T) {
+	t.Parallel()
+
+	cutoff := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	names := []string{
+		"query_log_20260310", // holds entries after the cutoff
+		"query_log_20260309", // ends at 2026-03-10 00:00
+		"query_log_20260301",
+		"query_log_default",
+		"other_20260101",
+	}
+
+	assert.Equal(t, []string{"query_log_20260301", "query_log_20260309"}, expiredPartitions(names, cutoff))
+}
+
+func TestWriter_Record(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		entry   Entry
+		wantLen int
+	}{
+		{name: "fast statement below threshold", entry: Entry{DurationMs: 10}, wantLen: 0},
+		{name: "slow statement", entry: Entry{DurationMs: 250}, wantLen: 1},
+		{name: "failed fast statement", entry: Entry{DurationMs: 1, Error: "boom"}, wantLen: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := NewWriter(newFakeRepo(), discardLogger, WithMinDuration(100*time.Millisecond))
+			w.Record(tt.entry)
+			assert.Len(t, w.entries, tt.wantLen)
+		})
+	}
+}
+
+func TestWriter_RecordDropsWhenFull(t *testing.T) {
+	t.Parallel()
+
+	w := NewWriter(newFakeRepo(), discardLogger, WithBufferSize(2))
+	for i := 0; i < 5; i++ {
+		w.Record(Entry{})
+	}
+	assert.Len(t, w.entries, 2)
+}
+
+func TestWriter_Run(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := newFakeRepo("query_log_20260201", "query_log_20260305")
+	w := NewWriter(repo, discardLogger, WithRetention(7*24*time.Hour))
+	w.now = func() time.Time { return now }
+	w.interval = 10 * time.Millisecond
+
+	for i := 0; i < 3; i++ {
+		w.Record(Entry{ID: uuid.New()})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return repo.writtenCount() == 3 }, time.Second, 10*time.Millisecond)
+
+	w.Record(Entry{ID: uuid.New()})
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, 4, repo.writtenCount(), "queued entries are written on shutdown")
+
+	names, _ := repo.ListPartitions(context.Background())
+	assert.ElementsMatch(t, []string{
+		"query_log_20260305", "query_log_20260310", "query_log_20260311", "query_log_20260312",
+	}, names)
+	assert.Equal(t, []string{"query_log_20260201"}, repo.dropped)
+}
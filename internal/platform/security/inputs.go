@@ -33,15 +33,17 @@ type UpdatePermissionSetInput struct {
 
 // CreateProfileInput contains input data for creating a profile.
 type CreateProfileInput struct {
-	APIName     string `json:"api_name"`
-	Label       string `json:"label"`
-	Description string `json:"description"`
+	APIName            string `json:"api_name"`
+	Label              string `json:"label"`
+	Description        string `json:"description"`
+	StatementTimeoutMs int    `json:"statement_timeout_ms"`
 }
 
 // UpdateProfileInput contains input data for updating a profile.
 type UpdateProfileInput struct {
-	Label       string `json:"label"`
-	Description string `json:"description"`
+	Label              string `json:"label"`
+	Description        string `json:"description"`
+	StatementTimeoutMs int    `json:"statement_timeout_ms"`
 }
 
 // CreateUserInput contains input data for creating a user.
@@ -52,6 +54,8 @@ type CreateUserInput struct {
 	LastName  string     `json:"last_name"`
 	ProfileID uuid.UUID  `json:"profile_id"`
 	RoleID    *uuid.UUID `json:"role_id"`
+	TimeZone  string     `json:"time_zone"`
+	Locale    string     `json:"locale"`
 }
 
 // UpdateUserInput contains input data for updating a user.
@@ -62,6 +66,15 @@ type UpdateUserInput struct {
 	ProfileID uuid.UUID  `json:"profile_id"`
 	RoleID    *uuid.UUID `json:"role_id"`
 	IsActive  bool       `json:"is_active"`
+	TimeZone  string     `json:"time_zone"`
+	Locale    string     `json:"locale"`
+}
+
+// UpdateOrgSettingsInput contains input data for updating organization settings.
+type UpdateOrgSettingsInput struct {
+	DefaultTimeZone      string `json:"default_time_zone"`
+	DefaultLocale        string `json:"default_locale"`
+	FiscalYearStartMonth int    `json:"fiscal_year_start_month"`
 }
 
 // SetObjectPermissionInput contains input for setting OLS permissions.
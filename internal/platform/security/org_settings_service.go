@@ -0,0 +1,90 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// orgSettingsTTL bounds how long other instances may serve stale settings
+// after an update; the updating instance refreshes immediately.
+const orgSettingsTTL = time.Minute
+
+// DefaultOrgSettings are used when the settings row is missing.
+var DefaultOrgSettings = OrgSettings{
+	DefaultTimeZone:      "UTC",
+	DefaultLocale:        "en_US",
+	FiscalYearStartMonth: 1,
+}
+
+type orgSettingsServiceImpl struct {
+	txBeginner TxBeginner
+	repo       OrgSettingsRepository
+
+	mu       sync.RWMutex
+	cached   *OrgSettings
+	loadedAt time.Time
+	now      func() time.Time
+}
+
+// NewOrgSettingsService creates a new OrgSettingsService.
+// Settings are read on every SOQL query, so Get serves them from memory.
+func NewOrgSettingsService(txBeginner TxBeginner, repo OrgSettingsRepository) OrgSettingsService {
+	return &orgSettingsServiceImpl{
+		txBeginner: txBeginner,
+		repo:       repo,
+		now:        time.Now,
+	}
+}
+
+func (s *orgSettingsServiceImpl) Get(ctx context.Context) (*OrgSettings, error) {
+	s.mu.RLock()
+	cached, loadedAt := s.cached, s.loadedAt
+	s.mu.RUnlock()
+	if cached != nil && s.now().Sub(loadedAt) < orgSettingsTTL {
+		return cached, nil
+	}
+
+	settings, err := s.repo.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("orgSettingsService.Get: %w", err)
+	}
+	if settings == nil {
+		defaults := DefaultOrgSettings
+		settings = &defaults
+	}
+	s.store(settings)
+	return settings, nil
+}
+
+func (s *orgSettingsServiceImpl) Update(ctx context.Context, input UpdateOrgSettingsInput) (*OrgSettings, error) {
+	if err := ValidateUpdateOrgSettings(input); err != nil {
+		return nil, fmt.Errorf("orgSettingsService.Update: %w", err)
+	}
+
+	var result *OrgSettings
+	err := withTx(ctx, s.txBeginner, func(tx pgx.Tx) error {
+		updated, err := s.repo.Update(ctx, tx, input)
+		if err != nil {
+			return fmt.Errorf("orgSettingsService.Update: %w", err)
+		}
+		result = updated
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.store(result)
+	return result, nil
+}
+
+func (s *orgSettingsServiceImpl) store(settings *OrgSettings) {
+	s.mu.Lock()
+	s.cached = settings
+	s.loadedAt = s.now()
+	s.mu.Unlock()
+}
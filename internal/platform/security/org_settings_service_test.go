@@ -0,0 +1,128 @@
+package security_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/security"
+)
+
+type mockOrgSettingsRepo struct {
+	settings *security.OrgSettings
+	gets     int
+}
+
+func (r *mockOrgSettingsRepo) Get(_ context.Context) (*security.OrgSettings, error) {
+	r.gets++
+	if r.settings == nil {
+		return nil, nil
+	}
+	s := *r.settings
+	return &s, nil
+}
+
+func (r *mockOrgSettingsRepo) Update(_ context.Context, _ pgx.Tx, input security.UpdateOrgSettingsInput) (*security.OrgSettings, error) {
+	r.settings = &security.OrgSettings{
+		DefaultTimeZone:      input.DefaultTimeZone,
+		DefaultLocale:        input.DefaultLocale,
+		FiscalYearStartMonth: input.FiscalYearStartMonth,
+	}
+	s := *r.settings
+	return &s, nil
+}
+
+func TestOrgSettingsService_Get(t *testing.T) {
+	t.Run("falls back to defaults when the row is missing", func(t *testing.T) {
+		svc := security.NewOrgSettingsService(&mockTxBeginner{}, &mockOrgSettingsRepo{})
+
+		got, err := svc.Get(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *got != security.DefaultOrgSettings {
+			t.Errorf("expected defaults, got %+v", got)
+		}
+	})
+
+	t.Run("serves repeated reads from cache", func(t *testing.T) {
+		repo := &mockOrgSettingsRepo{settings: &security.OrgSettings{
+			DefaultTimeZone: "Asia/Tokyo", DefaultLocale: "ja_JP", FiscalYearStartMonth: 4,
+		}}
+		svc := security.NewOrgSettingsService(&mockTxBeginner{}, repo)
+
+		for i := 0; i < 3; i++ {
+			got, err := svc.Get(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.DefaultTimeZone != "Asia/Tokyo" {
+				t.Errorf("expected Asia/Tokyo, got %s", got.DefaultTimeZone)
+			}
+		}
+		if repo.gets != 1 {
+			t.Errorf("expected 1 repository read, got %d", repo.gets)
+		}
+	})
+}
+
+func TestOrgSettingsService_Update(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   security.UpdateOrgSettingsInput
+		wantErr bool
+	}{
+		{
+			name:  "updates settings and refreshes cache",
+			input: security.UpdateOrgSettingsInput{DefaultTimeZone: "America/New_York", DefaultLocale: "en_US", FiscalYearStartMonth: 10},
+		},
+		{
+			name:    "rejects unknown time zone",
+			input:   security.UpdateOrgSettingsInput{DefaultTimeZone: "Atlantis/Capital", DefaultLocale: "en_US", FiscalYearStartMonth: 1},
+			wantErr: true,
+		},
+		{
+			name:    "rejects fiscal month out of range",
+			input:   security.UpdateOrgSettingsInput{DefaultTimeZone: "UTC", DefaultLocale: "en_US", FiscalYearStartMonth: 13},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockOrgSettingsRepo{}
+			svc := security.NewOrgSettingsService(&mockTxBeginner{}, repo)
+
+			// Warm the cache with defaults.
+			if _, err := svc.Get(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err := svc.Update(context.Background(), tt.input)
+			if tt.wantErr {
+				var appErr *apperror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != apperror.CodeValidation {
+					t.Errorf("expected validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := svc.Get(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.DefaultTimeZone != tt.input.DefaultTimeZone || got.FiscalYearStartMonth != tt.input.FiscalYearStartMonth {
+				t.Errorf("expected updated settings, got %+v", got)
+			}
+			if repo.gets != 1 {
+				t.Errorf("expected cached read after update, got %d repository reads", repo.gets)
+			}
+		})
+	}
+}
@@ -17,6 +17,23 @@ type OutboxWorker struct {
 	computer    EffectiveComputer
 	rlsComputer RLSEffectiveComputer
 	logger      *slog.Logger
+	listeners   []RecomputeListener
+}
+
+// RecomputeListener is notified after an outbox event has been applied to
+// the effective caches, e.g. to drop data computed under the old permissions.
+type RecomputeListener interface {
+	EffectiveCachesRecomputed(ctx context.Context, event OutboxEvent)
+}
+
+// OutboxWorkerOption configures an OutboxWorker.
+type OutboxWorkerOption func(*OutboxWorker)
+
+// WithRecomputeListener adds a listener called for every processed event.
+func WithRecomputeListener(listener RecomputeListener) OutboxWorkerOption {
+	return func(w *OutboxWorker) {
+		w.listeners = append(w.listeners, listener)
+	}
 }
 
 // NewOutboxWorker creates a new OutboxWorker.
@@ -26,14 +43,19 @@ func NewOutboxWorker(
 	computer EffectiveComputer,
 	rlsComputer RLSEffectiveComputer,
 	logger *slog.Logger,
+	opts ...OutboxWorkerOption,
 ) *OutboxWorker {
-	return &OutboxWorker{
+	w := &OutboxWorker{
 		connConfig:  connConfig,
 		outboxRepo:  outboxRepo,
 		computer:    computer,
 		rlsComputer: rlsComputer,
 		logger:      logger,
 	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
 }
 
 // Run starts the outbox worker loop. Blocks until ctx is cancelled.
@@ -123,6 +145,9 @@ func (w *OutboxWorker) processUnprocessed(ctx context.Context) error {
 			)
 			continue
 		}
+		for _, l := range w.listeners {
+			l.EffectiveCachesRecomputed(ctx, event)
+		}
 
 		if err := w.outboxRepo.MarkProcessed(ctx, event.ID); err != nil {
 			w.logger.Error("outbox worker: mark processed failed",
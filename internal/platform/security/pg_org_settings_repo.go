@@ -0,0 +1,54 @@
+package security
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgOrgSettingsRepository implements OrgSettingsRepository using pgx.
+type PgOrgSettingsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgOrgSettingsRepository creates a new PgOrgSettingsRepository.
+func NewPgOrgSettingsRepository(pool *pgxpool.Pool) *PgOrgSettingsRepository {
+	return &PgOrgSettingsRepository{pool: pool}
+}
+
+func (r *PgOrgSettingsRepository) Get(ctx context.Context) (*OrgSettings, error) {
+	var s OrgSettings
+	err := r.pool.QueryRow(ctx, `
+		SELECT default_time_zone, default_locale, fiscal_year_start_month, updated_at
+		FROM iam.org_settings WHERE id
+	`).Scan(&s.DefaultTimeZone, &s.DefaultLocale, &s.FiscalYearStartMonth, &s.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pgOrgSettingsRepo.Get: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *PgOrgSettingsRepository) Update(ctx context.Context, tx pgx.Tx, input UpdateOrgSettingsInput) (*OrgSettings, error) {
+	var s OrgSettings
+	err := tx.QueryRow(ctx, `
+		INSERT INTO iam.org_settings (id, default_time_zone, default_locale, fiscal_year_start_month)
+		VALUES (true, $1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET
+			default_time_zone = EXCLUDED.default_time_zone,
+			default_locale = EXCLUDED.default_locale,
+			fiscal_year_start_month = EXCLUDED.fiscal_year_start_month,
+			updated_at = now()
+		RETURNING default_time_zone, default_locale, fiscal_year_start_month, updated_at
+	`, input.DefaultTimeZone, input.DefaultLocale, input.FiscalYearStartMonth).Scan(
+		&s.DefaultTimeZone, &s.DefaultLocale, &s.FiscalYearStartMonth, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("pgOrgSettingsRepo.Update: %w", err)
+	}
+	return &s, nil
+}
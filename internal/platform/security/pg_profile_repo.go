@@ -22,12 +22,12 @@ func NewPgProfileRepository(pool *pgxpool.Pool) *PgProfileRepository {
 func (r *PgProfileRepository) Create(ctx context.Context, tx pgx.Tx, profile *Profile) (*Profile, error) {
 	var p Profile
 	err := tx.QueryRow(ctx, `
-		INSERT INTO iam.profiles (api_name, label, description, base_permission_set_id)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, api_name, label, description, base_permission_set_id, created_at, updated_at
-	`, profile.APIName, profile.Label, profile.Description, profile.BasePermissionSetID).Scan(
+		INSERT INTO iam.profiles (api_name, label, description, base_permission_set_id, statement_timeout_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, api_name, label, description, base_permission_set_id, statement_timeout_ms, created_at, updated_at
+	`, profile.APIName, profile.Label, profile.Description, profile.BasePermissionSetID, profile.StatementTimeoutMs).Scan(
 		&p.ID, &p.APIName, &p.Label, &p.Description,
-		&p.BasePermissionSetID, &p.CreatedAt, &p.UpdatedAt,
+		&p.BasePermissionSetID, &p.StatementTimeoutMs, &p.CreatedAt, &p.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("pgProfileRepo.Create: %w", err)
@@ -38,11 +38,11 @@ func (r *PgProfileRepository) Create(ctx context.Context, tx pgx.Tx, profile *Pr
 func (r *PgProfileRepository) GetByID(ctx context.Context, id uuid.UUID) (*Profile, error) {
 	var p Profile
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, api_name, label, description, base_permission_set_id, created_at, updated_at
+		SELECT id, api_name, label, description, base_permission_set_id, statement_timeout_ms, created_at, updated_at
 		FROM iam.profiles WHERE id = $1
 	`, id).Scan(
 		&p.ID, &p.APIName, &p.Label, &p.Description,
-		&p.BasePermissionSetID, &p.CreatedAt, &p.UpdatedAt,
+		&p.BasePermissionSetID, &p.StatementTimeoutMs, &p.CreatedAt, &p.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -56,11 +56,11 @@ func (r *PgProfileRepository) GetByID(ctx context.Context, id uuid.UUID) (*Profi
 func (r *PgProfileRepository) GetByAPIName(ctx context.Context, apiName string) (*Profile, error) {
 	var p Profile
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, api_name, label, description, base_permission_set_id, created_at, updated_at
+		SELECT id, api_name, label, description, base_permission_set_id, statement_timeout_ms, created_at, updated_at
 		FROM iam.profiles WHERE api_name = $1
 	`, apiName).Scan(
 		&p.ID, &p.APIName, &p.Label, &p.Description,
-		&p.BasePermissionSetID, &p.CreatedAt, &p.UpdatedAt,
+		&p.BasePermissionSetID, &p.StatementTimeoutMs, &p.CreatedAt, &p.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -73,7 +73,7 @@ func (r *PgProfileRepository) GetByAPIName(ctx context.Context, apiName string)
 
 func (r *PgProfileRepository) List(ctx context.Context, limit, offset int32) ([]Profile, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, api_name, label, description, base_permission_set_id, created_at, updated_at
+		SELECT id, api_name, label, description, base_permission_set_id, statement_timeout_ms, created_at, updated_at
 		FROM iam.profiles
 		ORDER BY created_at
 		LIMIT $1 OFFSET $2
@@ -88,7 +88,7 @@ func (r *PgProfileRepository) List(ctx context.Context, limit, offset int32) ([]
 		var p Profile
 		if err := rows.Scan(
 			&p.ID, &p.APIName, &p.Label, &p.Description,
-			&p.BasePermissionSetID, &p.CreatedAt, &p.UpdatedAt,
+			&p.BasePermissionSetID, &p.StatementTimeoutMs, &p.CreatedAt, &p.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("pgProfileRepo.List: scan: %w", err)
 		}
@@ -101,12 +101,12 @@ func (r *PgProfileRepository) Update(ctx context.Context, tx pgx.Tx, id uuid.UUI
 	var p Profile
 	err := tx.QueryRow(ctx, `
 		UPDATE iam.profiles SET
-			label = $2, description = $3, updated_at = now()
+			label = $2, description = $3, statement_timeout_ms = $4, updated_at = now()
 		WHERE id = $1
-		RETURNING id, api_name, label, description, base_permission_set_id, created_at, updated_at
-	`, id, input.Label, input.Description).Scan(
+		RETURNING id, api_name, label, description, base_permission_set_id, statement_timeout_ms, created_at, updated_at
+	`, id, input.Label, input.Description, input.StatementTimeoutMs).Scan(
 		&p.ID, &p.APIName, &p.Label, &p.Description,
-		&p.BasePermissionSetID, &p.CreatedAt, &p.UpdatedAt,
+		&p.BasePermissionSetID, &p.StatementTimeoutMs, &p.CreatedAt, &p.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("pgProfileRepo.Update: %w", err)
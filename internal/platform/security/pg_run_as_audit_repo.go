@@ -0,0 +1,42 @@
+package security
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgRunAsAuditRepository implements RunAsAuditRepository using pgx.
+type PgRunAsAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgRunAsAuditRepository creates a new PgRunAsAuditRepository.
+func NewPgRunAsAuditRepository(pool *pgxpool.Pool) *PgRunAsAuditRepository {
+	return &PgRunAsAuditRepository{pool: pool}
+}
+
+func (r *PgRunAsAuditRepository) Insert(ctx context.Context, entry *RunAsAuditEntry) error {
+	stripped := entry.StrippedFields
+	if stripped == nil {
+		stripped = []string{}
+	}
+	var errText *string
+	if entry.Error != "" {
+		errText = &entry.Error
+	}
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO security.run_as_audit
+			(admin_user_id, user_id, query, object, row_count, rls_filtered_rows, stripped_fields, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, entry.AdminUserID, entry.UserID, entry.Query, entry.Object,
+		entry.RowCount, entry.RLSFilteredRows, stripped, errText,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("pgRunAsAuditRepo.Insert: %w", err)
+	}
+	return nil
+}
@@ -22,14 +22,15 @@ func NewPgUserRepository(pool *pgxpool.Pool) *PgUserRepository {
 func (r *PgUserRepository) Create(ctx context.Context, tx pgx.Tx, input CreateUserInput) (*User, error) {
 	var u User
 	err := tx.QueryRow(ctx, `
-		INSERT INTO iam.users (username, email, first_name, last_name, profile_id, role_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO iam.users (username, email, first_name, last_name, profile_id, role_id,
+			time_zone, locale)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, username, email, first_name, last_name,
-			profile_id, role_id, is_active, created_at, updated_at
+			profile_id, role_id, is_active, time_zone, locale, created_at, updated_at
 	`, input.Username, input.Email, input.FirstName, input.LastName,
-		input.ProfileID, input.RoleID).Scan(
+		input.ProfileID, input.RoleID, input.TimeZone, input.Locale).Scan(
 		&u.ID, &u.Username, &u.Email, &u.FirstName, &u.LastName,
-		&u.ProfileID, &u.RoleID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		&u.ProfileID, &u.RoleID, &u.IsActive, &u.TimeZone, &u.Locale, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("pgUserRepo.Create: %w", err)
@@ -41,11 +42,11 @@ func (r *PgUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*User, er
 	var u User
 	err := r.pool.QueryRow(ctx, `
 		SELECT id, username, email, first_name, last_name,
-			profile_id, role_id, is_active, created_at, updated_at
+			profile_id, role_id, is_active, time_zone, locale, created_at, updated_at
 		FROM iam.users WHERE id = $1
 	`, id).Scan(
 		&u.ID, &u.Username, &u.Email, &u.FirstName, &u.LastName,
-		&u.ProfileID, &u.RoleID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		&u.ProfileID, &u.RoleID, &u.IsActive, &u.TimeZone, &u.Locale, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -60,11 +61,11 @@ func (r *PgUserRepository) GetByUsername(ctx context.Context, username string) (
 	var u User
 	err := r.pool.QueryRow(ctx, `
 		SELECT id, username, email, first_name, last_name,
-			profile_id, role_id, is_active, created_at, updated_at
+			profile_id, role_id, is_active, time_zone, locale, created_at, updated_at
 		FROM iam.users WHERE username = $1
 	`, username).Scan(
 		&u.ID, &u.Username, &u.Email, &u.FirstName, &u.LastName,
-		&u.ProfileID, &u.RoleID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		&u.ProfileID, &u.RoleID, &u.IsActive, &u.TimeZone, &u.Locale, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -78,7 +79,7 @@ func (r *PgUserRepository) GetByUsername(ctx context.Context, username string) (
 func (r *PgUserRepository) List(ctx context.Context, limit, offset int32) ([]User, error) {
 	rows, err := r.pool.Query(ctx, `
 		SELECT id, username, email, first_name, last_name,
-			profile_id, role_id, is_active, created_at, updated_at
+			profile_id, role_id, is_active, time_zone, locale, created_at, updated_at
 		FROM iam.users
 		ORDER BY created_at
 		LIMIT $1 OFFSET $2
@@ -93,7 +94,7 @@ func (r *PgUserRepository) List(ctx context.Context, limit, offset int32) ([]Use
 		var u User
 		if err := rows.Scan(
 			&u.ID, &u.Username, &u.Email, &u.FirstName, &u.LastName,
-			&u.ProfileID, &u.RoleID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+			&u.ProfileID, &u.RoleID, &u.IsActive, &u.TimeZone, &u.Locale, &u.CreatedAt, &u.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("pgUserRepo.List: scan: %w", err)
 		}
@@ -107,14 +108,15 @@ func (r *PgUserRepository) Update(ctx context.Context, tx pgx.Tx, id uuid.UUID,
 	err := tx.QueryRow(ctx, `
 		UPDATE iam.users SET
 			email = $2, first_name = $3, last_name = $4,
-			profile_id = $5, role_id = $6, is_active = $7, updated_at = now()
+			profile_id = $5, role_id = $6, is_active = $7,
+			time_zone = $8, locale = $9, updated_at = now()
 		WHERE id = $1
 		RETURNING id, username, email, first_name, last_name,
-			profile_id, role_id, is_active, created_at, updated_at
+			profile_id, role_id, is_active, time_zone, locale, created_at, updated_at
 	`, id, input.Email, input.FirstName, input.LastName,
-		input.ProfileID, input.RoleID, input.IsActive).Scan(
+		input.ProfileID, input.RoleID, input.IsActive, input.TimeZone, input.Locale).Scan(
 		&u.ID, &u.Username, &u.Email, &u.FirstName, &u.LastName,
-		&u.ProfileID, &u.RoleID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		&u.ProfileID, &u.RoleID, &u.IsActive, &u.TimeZone, &u.Locale, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("pgUserRepo.Update: %w", err)
@@ -59,6 +59,7 @@ func (s *profileServiceImpl) Create(ctx context.Context, input CreateProfileInpu
 			Label:               input.Label,
 			Description:         input.Description,
 			BasePermissionSetID: ps.ID,
+			StatementTimeoutMs:  input.StatementTimeoutMs,
 		}
 		created, err := s.profileRepo.Create(ctx, tx, profile)
 		if err != nil {
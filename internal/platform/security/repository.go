@@ -147,3 +147,14 @@ type RLSEffectiveCacheRepository interface {
 	GetRoleDescendants(ctx context.Context, roleID uuid.UUID) ([]uuid.UUID, error)
 	ListAllRoles(ctx context.Context) ([]EffectiveRoleHierarchy, error)
 }
+
+// OrgSettingsRepository defines data access for the organization settings row.
+type OrgSettingsRepository interface {
+	Get(ctx context.Context) (*OrgSettings, error)
+	Update(ctx context.Context, tx pgx.Tx, input UpdateOrgSettingsInput) (*OrgSettings, error)
+}
+
+// RunAsAuditRepository stores the audit trail of queries run as another user.
+type RunAsAuditRepository interface {
+	Insert(ctx context.Context, entry *RunAsAuditEntry) error
+}
@@ -16,6 +16,8 @@ type Enforcer interface {
 	CanReadRecord(ctx context.Context, userID, objectID, recordOwnerID uuid.UUID) error
 	CanUpdateRecord(ctx context.Context, userID, objectID, recordOwnerID uuid.UUID) error
 	BuildWhereClause(ctx context.Context, userID, objectID uuid.UUID) (string, []interface{}, error)
+	BuildAliasedWhereClause(ctx context.Context, userID, objectID uuid.UUID, alias string) (string, []interface{}, error)
+	BuildEditWhereClause(ctx context.Context, userID, objectID uuid.UUID) (string, []interface{}, error)
 	GetVisibility(ctx context.Context, objectID uuid.UUID) (string, error)
 }
 
@@ -114,10 +116,79 @@ func (e *enforcerImpl) checkWriteAccess(ctx context.Context, userID, objectID, r
 // BuildWhereClause generates a SQL WHERE fragment for RLS filtering.
 // Returns the clause (without leading AND/WHERE) and bind parameters.
 func (e *enforcerImpl) BuildWhereClause(ctx context.Context, userID, objectID uuid.UUID) (string, []interface{}, error) {
-	visibility, err := e.metadataAdapter.GetObjectVisibility(ctx, objectID)
+	clause, params, err := e.buildWhereClause(ctx, userID, objectID, "")
 	if err != nil {
 		return "", nil, fmt.Errorf("rlsEnforcer.BuildWhereClause: %w", err)
 	}
+	return clause, params, nil
+}
+
+// BuildAliasedWhereClause is BuildWhereClause with every column qualified by
+// the given table alias, for queries that reference the object more than once
+// (lookup joins, relationship subqueries).
+func (e *enforcerImpl) BuildAliasedWhereClause(ctx context.Context, userID, objectID uuid.UUID, alias string) (string, []interface{}, error) {
+	clause, params, err := e.buildWhereClause(ctx, userID, objectID, alias)
+	if err != nil {
+		return "", nil, fmt.Errorf("rlsEnforcer.BuildAliasedWhereClause: %w", err)
+	}
+	return clause, params, nil
+}
+
+// BuildEditWhereClause generates a SQL WHERE fragment matching the records
+// the user may edit or delete: all records of public_read_write objects,
+// otherwise the user's own records and those shared with one of the user's
+// groups for read_write. Role hierarchy grants read only (ADR-0011).
+func (e *enforcerImpl) BuildEditWhereClause(ctx context.Context, userID, objectID uuid.UUID) (string, []interface{}, error) {
+	visibility, err := e.metadataAdapter.GetObjectVisibility(ctx, objectID)
+	if err != nil {
+		return "", nil, fmt.Errorf("rlsEnforcer.BuildEditWhereClause: %w", err)
+	}
+
+	switch visibility {
+	case "public_read_write":
+		return "TRUE", nil, nil
+
+	case "public_read", "private", "controlled_by_parent":
+		tableName, err := e.metadataAdapter.GetObjectTableName(ctx, objectID)
+		if err != nil {
+			return "", nil, fmt.Errorf("rlsEnforcer.BuildEditWhereClause: %w", err)
+		}
+		groupIDs, err := e.rlsCacheRepo.GetGroupMemberships(ctx, userID)
+		if err != nil {
+			return "", nil, fmt.Errorf("rlsEnforcer.BuildEditWhereClause: %w", err)
+		}
+
+		conditions := []string{"owner_id = $1"}
+		params := []interface{}{userID}
+		if len(groupIDs) > 0 {
+			groupPlaceholders := make([]string, len(groupIDs))
+			for i, gid := range groupIDs {
+				groupPlaceholders[i] = fmt.Sprintf("$%d", i+2)
+				params = append(params, gid)
+			}
+			conditions = append(conditions, fmt.Sprintf(
+				`id IN (SELECT record_id FROM "%s__share" WHERE group_id IN (%s) AND access_level = 'read_write')`,
+				tableName, strings.Join(groupPlaceholders, ",")))
+		}
+		return "(" + strings.Join(conditions, " OR ") + ")", params, nil
+
+	default:
+		return "", nil, fmt.Errorf("rlsEnforcer.BuildEditWhereClause: unknown visibility %q", visibility)
+	}
+}
+
+func (e *enforcerImpl) buildWhereClause(ctx context.Context, userID, objectID uuid.UUID, alias string) (string, []interface{}, error) {
+	column := func(name string) string {
+		if alias == "" {
+			return name
+		}
+		return alias + "." + name
+	}
+
+	visibility, err := e.metadataAdapter.GetObjectVisibility(ctx, objectID)
+	if err != nil {
+		return "", nil, err
+	}
 
 	switch visibility {
 	case "public_read_write", "public_read":
@@ -127,19 +198,19 @@ func (e *enforcerImpl) BuildWhereClause(ctx context.Context, userID, objectID uu
 	case "private", "controlled_by_parent":
 		tableName, err := e.metadataAdapter.GetObjectTableName(ctx, objectID)
 		if err != nil {
-			return "", nil, fmt.Errorf("rlsEnforcer.BuildWhereClause: %w", err)
+			return "", nil, err
 		}
 
 		// Get visible owners for the user (includes self + role hierarchy subordinates)
 		visibleOwners, err := e.rlsCacheRepo.GetVisibleOwners(ctx, userID)
 		if err != nil {
-			return "", nil, fmt.Errorf("rlsEnforcer.BuildWhereClause: %w", err)
+			return "", nil, err
 		}
 
 		// Get user's effective group memberships
 		groupIDs, err := e.rlsCacheRepo.GetGroupMemberships(ctx, userID)
 		if err != nil {
-			return "", nil, fmt.Errorf("rlsEnforcer.BuildWhereClause: %w", err)
+			return "", nil, err
 		}
 
 		var conditions []string
@@ -154,7 +225,7 @@ func (e *enforcerImpl) BuildWhereClause(ctx context.Context, userID, objectID uu
 				params = append(params, ownerID)
 				paramIdx++
 			}
-			conditions = append(conditions, fmt.Sprintf("owner_id IN (%s)", strings.Join(placeholders, ",")))
+			conditions = append(conditions, fmt.Sprintf("%s IN (%s)", column("owner_id"), strings.Join(placeholders, ",")))
 		}
 
 		// Condition 2: record exists in share table for user's groups
@@ -167,19 +238,19 @@ func (e *enforcerImpl) BuildWhereClause(ctx context.Context, userID, objectID uu
 				paramIdx++
 			}
 			conditions = append(conditions, fmt.Sprintf(
-				"id IN (SELECT record_id FROM %s WHERE group_id IN (%s))",
-				shareTable, strings.Join(groupPlaceholders, ",")))
+				"%s IN (SELECT record_id FROM %s WHERE group_id IN (%s))",
+				column("id"), shareTable, strings.Join(groupPlaceholders, ",")))
 		}
 
 		if len(conditions) == 0 {
 			// User can only see own records (owner_id = user_id)
-			conditions = append(conditions, fmt.Sprintf("owner_id = $%d", paramIdx))
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", column("owner_id"), paramIdx))
 			params = append(params, userID)
 		}
 
 		return "(" + strings.Join(conditions, " OR ") + ")", params, nil
 
 	default:
-		return "", nil, fmt.Errorf("rlsEnforcer.BuildWhereClause: unknown visibility %q", visibility)
+		return "", nil, fmt.Errorf("unknown visibility %q", visibility)
 	}
 }
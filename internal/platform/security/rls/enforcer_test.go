@@ -0,0 +1,172 @@
+package rls
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/platform/security"
+)
+
+type stubRLSCacheRepo struct {
+	security.RLSEffectiveCacheRepository
+	owners []uuid.UUID
+	groups []uuid.UUID
+}
+
+func (r *stubRLSCacheRepo) GetVisibleOwners(_ context.Context, _ uuid.UUID) ([]uuid.UUID, error) {
+	return r.owners, nil
+}
+
+func (r *stubRLSCacheRepo) GetGroupMemberships(_ context.Context, _ uuid.UUID) ([]uuid.UUID, error) {
+	return r.groups, nil
+}
+
+type stubMetadataRLSAdapter struct {
+	security.MetadataRLSAdapter
+	visibility string
+	table      string
+}
+
+func (a *stubMetadataRLSAdapter) GetObjectVisibility(_ context.Context, _ uuid.UUID) (string, error) {
+	return a.visibility, nil
+}
+
+func (a *stubMetadataRLSAdapter) GetObjectTableName(_ context.Context, _ uuid.UUID) (string, error) {
+	return a.table, nil
+}
+
+func TestEnforcer_BuildAliasedWhereClause(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	ownerID := uuid.New()
+	groupID := uuid.New()
+
+	tests := []struct {
+		name       string
+		visibility string
+		owners     []uuid.UUID
+		groups     []uuid.UUID
+		alias      string
+		wantClause string
+		wantParams []interface{}
+	}{
+		{
+			name:       "public read needs no filter",
+			visibility: "public_read",
+			alias:      "t1",
+			wantClause: "TRUE",
+		},
+		{
+			name:       "private with owners and shares",
+			visibility: "private",
+			owners:     []uuid.UUID{userID, ownerID},
+			groups:     []uuid.UUID{groupID},
+			alias:      "t1",
+			wantClause: `(t1.owner_id IN ($1,$2) OR t1.id IN (SELECT record_id FROM "obj_account__share" WHERE group_id IN ($3)))`,
+			wantParams: []interface{}{userID, ownerID, groupID},
+		},
+		{
+			name:       "private falls back to own records",
+			visibility: "controlled_by_parent",
+			alias:      "sq",
+			wantClause: "(sq.owner_id = $1)",
+			wantParams: []interface{}{userID},
+		},
+		{
+			name:       "empty alias keeps unqualified columns",
+			visibility: "private",
+			owners:     []uuid.UUID{userID},
+			wantClause: "(owner_id IN ($1))",
+			wantParams: []interface{}{userID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := NewEnforcer(
+				&stubRLSCacheRepo{owners: tt.owners, groups: tt.groups},
+				&stubMetadataRLSAdapter{visibility: tt.visibility, table: "obj_account"},
+			)
+
+			clause, params, err := e.BuildAliasedWhereClause(context.Background(), userID, uuid.New(), tt.alias)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantClause, clause)
+			assert.Equal(t, tt.wantParams, params)
+		})
+	}
+}
+
+func TestEnforcer_BuildEditWhereClause(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	groupID := uuid.New()
+
+	tests := []struct {
+		name       string
+		visibility string
+		owners     []uuid.UUID
+		groups     []uuid.UUID
+		wantClause string
+		wantParams []interface{}
+	}{
+		{
+			name:       "public read write needs no filter",
+			visibility: "public_read_write",
+			wantClause: "TRUE",
+		},
+		{
+			name:       "public read allows own records only",
+			visibility: "public_read",
+			wantClause: "(owner_id = $1)",
+			wantParams: []interface{}{userID},
+		},
+		{
+			name:       "role hierarchy does not grant edit",
+			visibility: "private",
+			owners:     []uuid.UUID{userID, uuid.New()},
+			wantClause: "(owner_id = $1)",
+			wantParams: []interface{}{userID},
+		},
+		{
+			name:       "read write shares grant edit",
+			visibility: "controlled_by_parent",
+			groups:     []uuid.UUID{groupID},
+			wantClause: `(owner_id = $1 OR id IN (SELECT record_id FROM "obj_account__share" WHERE group_id IN ($2) AND access_level = 'read_write'))`,
+			wantParams: []interface{}{userID, groupID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := NewEnforcer(
+				&stubRLSCacheRepo{owners: tt.owners, groups: tt.groups},
+				&stubMetadataRLSAdapter{visibility: tt.visibility, table: "obj_account"},
+			)
+
+			clause, params, err := e.BuildEditWhereClause(context.Background(), userID, uuid.New())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantClause, clause)
+			assert.Equal(t, tt.wantParams, params)
+		})
+	}
+}
+
+func TestEnforcer_BuildWhereClauseUnknownVisibility(t *testing.T) {
+	t.Parallel()
+
+	e := NewEnforcer(&stubRLSCacheRepo{}, &stubMetadataRLSAdapter{visibility: "bogus"})
+
+	_, _, err := e.BuildWhereClause(context.Background(), uuid.New(), uuid.New())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rlsEnforcer.BuildWhereClause")
+}
@@ -0,0 +1,140 @@
+package rls
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubTerritoryResolver struct {
+	groups map[uuid.UUID][]uuid.UUID
+}
+
+func (r *stubTerritoryResolver) ResolveTerritoryGroups(_ context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return r.groups[userID], nil
+}
+
+func TestScopeFilter_BuildScopeClause(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	peerID := uuid.New()
+	groupID := uuid.New()
+	territoryID := uuid.New()
+	peerTerritoryID := uuid.New()
+
+	territories := map[uuid.UUID][]uuid.UUID{
+		userID: {territoryID},
+		peerID: {peerTerritoryID, territoryID},
+	}
+
+	tests := []struct {
+		name        string
+		scope       string
+		visibility  string
+		owners      []uuid.UUID
+		groups      []uuid.UUID
+		territories map[uuid.UUID][]uuid.UUID
+		wantClause  string
+		wantParams  []interface{}
+	}{
+		{
+			name:       "everything",
+			scope:      ScopeEverything,
+			wantClause: "TRUE",
+		},
+		{
+			name:       "mine",
+			scope:      ScopeMine,
+			wantClause: "t0.owner_id = $1",
+			wantParams: []interface{}{userID},
+		},
+		{
+			name:       "team follows visible owners",
+			scope:      ScopeTeam,
+			owners:     []uuid.UUID{userID, peerID},
+			wantClause: "t0.owner_id IN ($1,$2)",
+			wantParams: []interface{}{userID, peerID},
+		},
+		{
+			name:       "team without cache entries is the user alone",
+			scope:      ScopeTeam,
+			wantClause: "t0.owner_id IN ($1)",
+			wantParams: []interface{}{userID},
+		},
+		{
+			name:       "delegated excludes own records",
+			scope:      ScopeDelegated,
+			visibility: "private",
+			groups:     []uuid.UUID{groupID},
+			wantClause: `(t0.owner_id <> $2 AND t0.id IN (SELECT record_id FROM "obj_deal__share" WHERE group_id IN ($1)))`,
+			wantParams: []interface{}{groupID, userID},
+		},
+		{
+			name:       "delegated without groups",
+			scope:      ScopeDelegated,
+			visibility: "private",
+			wantClause: "FALSE",
+		},
+		{
+			name:       "delegated on object without share table",
+			scope:      ScopeDelegated,
+			visibility: "public_read_write",
+			groups:     []uuid.UUID{groupID},
+			wantClause: "FALSE",
+		},
+		{
+			name:        "my territory",
+			scope:       ScopeMyTerritory,
+			visibility:  "private",
+			territories: territories,
+			wantClause:  `t0.id IN (SELECT record_id FROM "obj_deal__share" WHERE group_id IN ($1))`,
+			wantParams:  []interface{}{territoryID},
+		},
+		{
+			name:       "my territory in community edition",
+			scope:      ScopeMyTerritory,
+			visibility: "private",
+			wantClause: "FALSE",
+		},
+		{
+			name:        "my team territory deduplicates groups",
+			scope:       ScopeMyTeamTerritory,
+			visibility:  "private",
+			owners:      []uuid.UUID{userID, peerID},
+			territories: territories,
+			wantClause:  `t0.id IN (SELECT record_id FROM "obj_deal__share" WHERE group_id IN ($1,$2))`,
+			wantParams:  []interface{}{territoryID, peerTerritoryID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := NewScopeFilter(
+				&stubRLSCacheRepo{owners: tt.owners, groups: tt.groups},
+				&stubMetadataRLSAdapter{visibility: tt.visibility, table: "obj_deal"},
+				&stubTerritoryResolver{groups: tt.territories},
+			)
+
+			clause, params, err := f.BuildScopeClause(context.Background(), userID, uuid.New(), tt.scope, "t0")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantClause, clause)
+			assert.Equal(t, tt.wantParams, params)
+		})
+	}
+}
+
+func TestScopeFilter_UnknownScope(t *testing.T) {
+	t.Parallel()
+
+	f := NewScopeFilter(&stubRLSCacheRepo{}, &stubMetadataRLSAdapter{}, &stubTerritoryResolver{})
+
+	_, _, err := f.BuildScopeClause(context.Background(), uuid.New(), uuid.New(), "friends", "t0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rlsScopeFilter.BuildScopeClause")
+}
@@ -45,6 +45,12 @@ type UserService interface {
 	ListPermissionSets(ctx context.Context, userID uuid.UUID) ([]PermissionSetToUser, error)
 }
 
+// OrgSettingsService defines business logic for organization-wide settings.
+type OrgSettingsService interface {
+	Get(ctx context.Context) (*OrgSettings, error)
+	Update(ctx context.Context, input UpdateOrgSettingsInput) (*OrgSettings, error)
+}
+
 // PermissionService defines business logic for OLS/FLS permission management.
 type PermissionService interface {
 	SetObjectPermission(ctx context.Context, psID uuid.UUID, input SetObjectPermissionInput) (*ObjectPermission, error)
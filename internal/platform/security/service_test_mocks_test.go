@@ -115,6 +115,7 @@ func (r *mockProfileRepo) Update(_ context.Context, _ pgx.Tx, id uuid.UUID, inpu
 	}
 	p.Label = input.Label
 	p.Description = input.Description
+	p.StatementTimeoutMs = input.StatementTimeoutMs
 	return p, nil
 }
 
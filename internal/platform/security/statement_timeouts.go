@@ -0,0 +1,63 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// statementTimeoutTTL bounds how long a changed profile timeout may take to
+// apply to queries.
+const statementTimeoutTTL = time.Minute
+
+// StatementTimeoutCache serves the statement timeouts of profiles from
+// memory, since one is needed for every SOQL query.
+type StatementTimeoutCache struct {
+	repo ProfileRepository
+	now  func() time.Time
+
+	mu      sync.RWMutex
+	entries map[uuid.UUID]statementTimeoutEntry
+}
+
+type statementTimeoutEntry struct {
+	timeout  time.Duration
+	loadedAt time.Time
+}
+
+// NewStatementTimeoutCache creates a StatementTimeoutCache reading profiles from repo.
+func NewStatementTimeoutCache(repo ProfileRepository) *StatementTimeoutCache {
+	return &StatementTimeoutCache{
+		repo:    repo,
+		now:     time.Now,
+		entries: make(map[uuid.UUID]statementTimeoutEntry),
+	}
+}
+
+// StatementTimeout returns the statement timeout of the profile; zero means
+// no limit. An unknown profile has no limit.
+func (c *StatementTimeoutCache) StatementTimeout(ctx context.Context, profileID uuid.UUID) (time.Duration, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[profileID]
+	c.mu.RUnlock()
+	if ok && c.now().Sub(entry.loadedAt) < statementTimeoutTTL {
+		return entry.timeout, nil
+	}
+
+	profile, err := c.repo.GetByID(ctx, profileID)
+	if err != nil {
+		return 0, fmt.Errorf("statementTimeoutCache.StatementTimeout: %w", err)
+	}
+	var timeout time.Duration
+	if profile != nil {
+		timeout = time.Duration(profile.StatementTimeoutMs) * time.Millisecond
+	}
+
+	c.mu.Lock()
+	c.entries[profileID] = statementTimeoutEntry{timeout: timeout, loadedAt: c.now()}
+	c.mu.Unlock()
+	return timeout, nil
+}
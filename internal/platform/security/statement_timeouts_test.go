@@ -0,0 +1,52 @@
+package security_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/platform/security"
+)
+
+func TestStatementTimeoutCache_StatementTimeout(t *testing.T) {
+	repo := newMockProfileRepo()
+	analyst, _ := repo.Create(context.Background(), nil, &security.Profile{APIName: "analyst", StatementTimeoutMs: 1500})
+	admin, _ := repo.Create(context.Background(), nil, &security.Profile{APIName: "admin"})
+
+	cache := security.NewStatementTimeoutCache(repo)
+
+	tests := []struct {
+		name      string
+		profileID uuid.UUID
+		want      time.Duration
+	}{
+		{name: "profile with a timeout", profileID: analyst.ID, want: 1500 * time.Millisecond},
+		{name: "profile without a timeout", profileID: admin.ID, want: 0},
+		{name: "unknown profile", profileID: uuid.New(), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cache.StatementTimeout(context.Background(), tt.profileID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("serves repeated reads from cache", func(t *testing.T) {
+		analyst.StatementTimeoutMs = 9000
+		got, err := cache.StatementTimeout(context.Background(), analyst.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != 1500*time.Millisecond {
+			t.Errorf("expected cached 1.5s, got %v", got)
+		}
+	})
+}
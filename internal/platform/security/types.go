@@ -45,6 +45,7 @@ type Profile struct {
 	Label               string    `json:"label"`
 	Description         string    `json:"description"`
 	BasePermissionSetID uuid.UUID `json:"base_permission_set_id"`
+	StatementTimeoutMs  int       `json:"statement_timeout_ms"` // 0 means no limit
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
 }
@@ -59,10 +60,20 @@ type User struct {
 	ProfileID uuid.UUID  `json:"profile_id"`
 	RoleID    *uuid.UUID `json:"role_id"`
 	IsActive  bool       `json:"is_active"`
+	TimeZone  string     `json:"time_zone"` // IANA name; empty = org default
+	Locale    string     `json:"locale"`    // e.g. "en_US"; empty = org default
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 }
 
+// OrgSettings holds organization-wide defaults for dates, fiscal periods and formatting.
+type OrgSettings struct {
+	DefaultTimeZone      string    `json:"default_time_zone"`
+	DefaultLocale        string    `json:"default_locale"`
+	FiscalYearStartMonth int       `json:"fiscal_year_start_month"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
 // PermissionSetToUser represents assignment of a PS to a user.
 type PermissionSetToUser struct {
 	ID              uuid.UUID `json:"id"`
@@ -224,6 +235,20 @@ type EffectiveObjectHierarchy struct {
 	Depth              int       `json:"depth"`
 }
 
+// RunAsAuditEntry records a SOQL query an administrator ran as another user.
+type RunAsAuditEntry struct {
+	ID              uuid.UUID `json:"id"`
+	AdminUserID     uuid.UUID `json:"admin_user_id"`
+	UserID          uuid.UUID `json:"user_id"`
+	Query           string    `json:"query"`
+	Object          string    `json:"object"`
+	RowCount        int       `json:"row_count"`
+	RLSFilteredRows int       `json:"rls_filtered_rows"`
+	StrippedFields  []string  `json:"stripped_fields"` // Object.Field
+	Error           string    `json:"error,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
 // Well-known UUIDs for seed data.
 var (
 	SystemAdminBasePermissionSetID = uuid.MustParse("00000000-0000-4000-a000-000000000001")
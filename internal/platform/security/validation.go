@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/adverax/crm/internal/pkg/apperror"
 )
 
 var apiNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,98}[a-zA-Z0-9]$`)
 
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(_[A-Z]{2})?$`)
+
 // ValidateCreateUserRole validates input for creating a user role.
 func ValidateCreateUserRole(input CreateUserRoleInput) error {
 	if err := validateAPIName(input.APIName); err != nil {
@@ -59,7 +62,7 @@ func ValidateCreateProfile(input CreateProfileInput) error {
 	if input.Label == "" {
 		return apperror.Validation("label is required")
 	}
-	return nil
+	return validateStatementTimeout(input.StatementTimeoutMs)
 }
 
 // ValidateUpdateProfile validates input for updating a profile.
@@ -67,6 +70,16 @@ func ValidateUpdateProfile(input UpdateProfileInput) error {
 	if input.Label == "" {
 		return apperror.Validation("label is required")
 	}
+	return validateStatementTimeout(input.StatementTimeoutMs)
+}
+
+// MaxStatementTimeoutMs is the longest statement timeout a profile may set (1 hour).
+const MaxStatementTimeoutMs = 3600000
+
+func validateStatementTimeout(ms int) error {
+	if ms < 0 || ms > MaxStatementTimeoutMs {
+		return apperror.Validation(fmt.Sprintf("statement_timeout_ms must be between 0 and %d", MaxStatementTimeoutMs))
+	}
 	return nil
 }
 
@@ -84,7 +97,7 @@ func ValidateCreateUser(input CreateUserInput) error {
 	if !strings.Contains(input.Email, "@") {
 		return apperror.Validation("email must be a valid email address")
 	}
-	return nil
+	return validateUserLocale(input.TimeZone, input.Locale)
 }
 
 // ValidateUpdateUser validates input for updating a user.
@@ -95,6 +108,57 @@ func ValidateUpdateUser(input UpdateUserInput) error {
 	if !strings.Contains(input.Email, "@") {
 		return apperror.Validation("email must be a valid email address")
 	}
+	return validateUserLocale(input.TimeZone, input.Locale)
+}
+
+// ValidateUpdateOrgSettings validates input for updating organization settings.
+func ValidateUpdateOrgSettings(input UpdateOrgSettingsInput) error {
+	if input.DefaultTimeZone == "" {
+		return apperror.Validation("default_time_zone is required")
+	}
+	if err := validateTimeZone(input.DefaultTimeZone); err != nil {
+		return err
+	}
+	if input.DefaultLocale == "" {
+		return apperror.Validation("default_locale is required")
+	}
+	if err := validateLocale(input.DefaultLocale); err != nil {
+		return err
+	}
+	if input.FiscalYearStartMonth < 1 || input.FiscalYearStartMonth > 12 {
+		return apperror.Validation("fiscal_year_start_month must be between 1 and 12")
+	}
+	return nil
+}
+
+// validateUserLocale validates the optional per-user time zone and locale.
+func validateUserLocale(timeZone, locale string) error {
+	if timeZone != "" {
+		if err := validateTimeZone(timeZone); err != nil {
+			return err
+		}
+	}
+	if locale != "" {
+		return validateLocale(locale)
+	}
+	return nil
+}
+
+func validateTimeZone(name string) error {
+	if len(name) > 64 {
+		return apperror.Validation("time zone must be at most 64 characters")
+	}
+	// "Local" would silently follow the server's time zone.
+	if _, err := time.LoadLocation(name); err != nil || name == "Local" {
+		return apperror.Validation(fmt.Sprintf("unknown time zone '%s'", name))
+	}
+	return nil
+}
+
+func validateLocale(locale string) error {
+	if !localePattern.MatchString(locale) {
+		return apperror.Validation(fmt.Sprintf("invalid locale '%s': expected language[_COUNTRY], e.g. en_US", locale))
+	}
 	return nil
 }
 
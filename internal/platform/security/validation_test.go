@@ -274,6 +274,8 @@ func TestValidateCreateProfile(t *testing.T) {
 		{name: "valid input", input: CreateProfileInput{APIName: "sales_profile", Label: "Sales"}, wantErr: false},
 		{name: "empty api_name", input: CreateProfileInput{APIName: "", Label: "Bad"}, wantErr: true},
 		{name: "empty label", input: CreateProfileInput{APIName: "good_name", Label: ""}, wantErr: true},
+		{name: "statement timeout", input: CreateProfileInput{APIName: "analyst", Label: "Analyst", StatementTimeoutMs: 30000}, wantErr: false},
+		{name: "negative statement timeout", input: CreateProfileInput{APIName: "analyst", Label: "Analyst", StatementTimeoutMs: -1}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -295,6 +297,7 @@ func TestValidateUpdateProfile(t *testing.T) {
 	}{
 		{name: "valid input", input: UpdateProfileInput{Label: "Updated"}, wantErr: false},
 		{name: "empty label", input: UpdateProfileInput{Label: ""}, wantErr: true},
+		{name: "statement timeout above maximum", input: UpdateProfileInput{Label: "Updated", StatementTimeoutMs: MaxStatementTimeoutMs + 1}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -317,6 +320,11 @@ func TestValidateUpdateUser(t *testing.T) {
 		{name: "valid input", input: UpdateUserInput{Email: "j@test.com"}, wantErr: false},
 		{name: "empty email", input: UpdateUserInput{Email: ""}, wantErr: true},
 		{name: "invalid email", input: UpdateUserInput{Email: "no-at"}, wantErr: true},
+		{name: "time zone and locale", input: UpdateUserInput{Email: "j@test.com", TimeZone: "America/Chicago", Locale: "en_US"}, wantErr: false},
+		{name: "language-only locale", input: UpdateUserInput{Email: "j@test.com", Locale: "de"}, wantErr: false},
+		{name: "unknown time zone", input: UpdateUserInput{Email: "j@test.com", TimeZone: "Mars/Olympus"}, wantErr: true},
+		{name: "server local time zone", input: UpdateUserInput{Email: "j@test.com", TimeZone: "Local"}, wantErr: true},
+		{name: "malformed locale", input: UpdateUserInput{Email: "j@test.com", Locale: "en-us"}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -329,6 +337,34 @@ func TestValidateUpdateUser(t *testing.T) {
 	}
 }
 
+func TestValidateUpdateOrgSettings(t *testing.T) {
+	t.Parallel()
+	valid := UpdateOrgSettingsInput{DefaultTimeZone: "Europe/London", DefaultLocale: "en_GB", FiscalYearStartMonth: 4}
+	tests := []struct {
+		name    string
+		modify  func(*UpdateOrgSettingsInput)
+		wantErr bool
+	}{
+		{name: "valid input", modify: func(*UpdateOrgSettingsInput) {}},
+		{name: "empty time zone", modify: func(in *UpdateOrgSettingsInput) { in.DefaultTimeZone = "" }, wantErr: true},
+		{name: "unknown time zone", modify: func(in *UpdateOrgSettingsInput) { in.DefaultTimeZone = "Nowhere/City" }, wantErr: true},
+		{name: "empty locale", modify: func(in *UpdateOrgSettingsInput) { in.DefaultLocale = "" }, wantErr: true},
+		{name: "fiscal month zero", modify: func(in *UpdateOrgSettingsInput) { in.FiscalYearStartMonth = 0 }, wantErr: true},
+		{name: "fiscal month thirteen", modify: func(in *UpdateOrgSettingsInput) { in.FiscalYearStartMonth = 13 }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			input := valid
+			tt.modify(&input)
+			err := ValidateUpdateOrgSettings(input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wantErr=%v, got err=%v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestValidateCreateGroup(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/metadata/ddl"
 	"github.com/adverax/crm/internal/platform/security"
 	"github.com/adverax/crm/internal/platform/security/fls"
 	"github.com/adverax/crm/internal/platform/security/ols"
@@ -49,14 +50,26 @@ func (a *MetadataAdapter) buildObjectMeta(
 	forwardRels []metadata.RelationshipInfo,
 	reverseRels []metadata.RelationshipInfo,
 ) *engine.ObjectMeta {
-	b := engine.NewObjectMeta(objDef.APIName, "public", objDef.TableName)
+	b := engine.NewObjectMeta(objDef.APIName, "public", objDef.TableName).
+		SoftDelete(ddl.IsDeletedColumn).
+		Label(objDef.Label, a.cache.GetTranslations(metadata.TranslationObject, objDef.ID, "label"))
 
 	// System fields always present on every object.
 	a.addSystemFields(b)
 
 	// User-defined fields.
+	searchable := false
 	for _, f := range fields {
 		b.FieldFull(a.convertFieldMeta(f))
+		if _, ok := ddl.SearchWeight(metadata.ToFieldInfo(f)); ok {
+			searchable = true
+		}
+	}
+
+	// The DDL generator maintains a search vector while the object is
+	// searchable and has at least one text field.
+	if objDef.IsSearchable && searchable {
+		b.Searchable(ddl.SearchVectorColumn)
 	}
 
 	// Lookups (child → parent, via forward relationships).
@@ -113,6 +126,26 @@ func (a *MetadataAdapter) addSystemFields(b *engine.ObjectMetaBuilder) {
 		Name: "UpdatedById", Column: "updated_by_id", Type: engine.FieldTypeID,
 		Nullable: true, Filterable: true, Sortable: true, Groupable: true,
 	})
+
+	// Record version, for optimistic locking.
+	b.FieldFull(&engine.FieldMeta{
+		Name: "SystemVersion", Column: ddl.VersionColumn, Type: engine.FieldTypeInteger,
+		Filterable: true, Sortable: true,
+	})
+
+	// Recycle bin fields, of interest in ALL ROWS queries.
+	b.FieldFull(&engine.FieldMeta{
+		Name: "IsDeleted", Column: ddl.IsDeletedColumn, Type: engine.FieldTypeBoolean,
+		Filterable: true, Groupable: true,
+	})
+	b.FieldFull(&engine.FieldMeta{
+		Name: "DeletedAt", Column: ddl.DeletedAtColumn, Type: engine.FieldTypeDateTime,
+		Nullable: true, Filterable: true, Sortable: true,
+	})
+	b.FieldFull(&engine.FieldMeta{
+		Name: "DeletedById", Column: ddl.DeletedByColumn, Type: engine.FieldTypeID,
+		Nullable: true, Filterable: true, Sortable: true, Groupable: true,
+	})
 }
 
 func (a *MetadataAdapter) convertFieldMeta(f metadata.FieldDefinition) *engine.FieldMeta {
@@ -126,7 +159,42 @@ func (a *MetadataAdapter) convertFieldMeta(f metadata.FieldDefinition) *engine.F
 		Sortable:     isSortable(ft),
 		Groupable:    isGroupable(ft),
 		Aggregatable: ft == engine.FieldTypeInteger || ft == engine.FieldTypeFloat,
+		Custom:       f.IsCustom,
+		Label:        f.Label,
+		Translations: a.cache.GetTranslations(metadata.TranslationField, f.ID, "label"),
+		Picklist:     a.convertPicklist(f),
+	}
+}
+
+// convertPicklist collects the values of a picklist field for toLabel().
+// Values of a global picklist come from its value set, otherwise from the
+// field config. Inactive values are kept: existing records may hold them.
+func (a *MetadataAdapter) convertPicklist(f metadata.FieldDefinition) *engine.PicklistMeta {
+	if f.FieldType != metadata.FieldTypePicklist {
+		return nil
+	}
+
+	pm := &engine.PicklistMeta{
+		Multi: f.FieldSubtype != nil && *f.FieldSubtype == metadata.SubtypeMulti,
+	}
+	add := func(id uuid.UUID, value, label string) {
+		pm.Values = append(pm.Values, &engine.PicklistValueMeta{
+			Value:        value,
+			Label:        label,
+			Translations: a.cache.GetTranslations(metadata.TranslationPicklistValue, id, "label"),
+		})
+	}
+
+	if f.Config.PicklistID != nil {
+		for _, v := range a.cache.GetPicklistValues(*f.Config.PicklistID) {
+			add(v.ID, v.Value, v.Label)
+		}
+		return pm
+	}
+	for _, v := range f.Config.Values {
+		add(v.ID, v.Value, v.Label)
 	}
+	return pm
 }
 
 func mapFieldType(ft metadata.FieldType, sub *metadata.FieldSubtype) engine.FieldType {
@@ -164,6 +232,8 @@ func isGroupable(ft engine.FieldType) bool { return isSortable(ft) }
 var systemFieldNames = map[string]bool{
 	"Id": true, "OwnerId": true, "CreatedAt": true, "UpdatedAt": true,
 	"CreatedById": true, "UpdatedById": true,
+	"IsDeleted": true, "DeletedAt": true, "DeletedById": true,
+	"SystemVersion": true,
 }
 
 // AccessControllerAdapter bridges OLS/FLS enforcers → engine.AccessController.
@@ -0,0 +1,76 @@
+package soql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/platform/metadata"
+)
+
+func TestMetadataAdapter_Labels(t *testing.T) {
+	t.Parallel()
+
+	objectID, stageID, tagsID, globalID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	newID, wonID, hotID := uuid.New(), uuid.New(), uuid.New()
+	multi := metadata.SubtypeMulti
+
+	reader := &stubMetadataReader{
+		object: metadata.ObjectDefinition{ID: objectID, APIName: "Deal", Label: "Deal", TableName: "obj_deal"},
+		fields: []metadata.FieldDefinition{
+			{ID: uuid.New(), APIName: "Name", Label: "Name", FieldType: metadata.FieldTypeText},
+			{
+				ID: stageID, APIName: "Stage", Label: "Stage", FieldType: metadata.FieldTypePicklist,
+				Config: metadata.FieldConfig{Values: []metadata.PicklistValue{
+					{ID: newID, Value: "new", Label: "New"},
+					{ID: wonID, Value: "won", Label: "Won", IsActive: false},
+				}},
+			},
+			{
+				ID: tagsID, APIName: "Tags", Label: "Tags", FieldType: metadata.FieldTypePicklist,
+				FieldSubtype: &multi, Config: metadata.FieldConfig{PicklistID: &globalID},
+			},
+		},
+		picklists: map[uuid.UUID][]metadata.PicklistValueRow{
+			globalID: {{ID: hotID, PicklistDefinitionID: globalID, Value: "hot", Label: "Hot"}},
+		},
+		translations: map[uuid.UUID]map[string]string{
+			objectID: {"de": "Geschäft"},
+			stageID:  {"de": "Phase"},
+			wonID:    {"de": "Gewonnen"},
+			hotID:    {"de": "Heiß"},
+		},
+	}
+
+	obj, err := NewMetadataAdapter(reader).GetObject(context.Background(), "Deal")
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+
+	if got := obj.LocalizedLabel("de"); got != "Geschäft" {
+		t.Errorf("object label = %q, want %q", got, "Geschäft")
+	}
+	if obj.GetField("Name").Picklist != nil {
+		t.Error("Name must not carry picklist values")
+	}
+
+	stage := obj.GetField("Stage")
+	if got := stage.LocalizedLabel("de_AT"); got != "Phase" {
+		t.Errorf("Stage label = %q, want %q", got, "Phase")
+	}
+	if stage.Picklist == nil || stage.Picklist.Multi || len(stage.Picklist.Values) != 2 {
+		t.Fatalf("Stage picklist = %+v, want two single-select values", stage.Picklist)
+	}
+	if got := stage.Picklist.Values[1].LocalizedLabel("de"); got != "Gewonnen" {
+		t.Errorf("inactive value label = %q, want %q", got, "Gewonnen")
+	}
+
+	tags := obj.GetField("Tags")
+	if tags.Picklist == nil || !tags.Picklist.Multi || len(tags.Picklist.Values) != 1 {
+		t.Fatalf("Tags picklist = %+v, want one multi-select value", tags.Picklist)
+	}
+	if v := tags.Picklist.Values[0]; v.Value != "hot" || v.LocalizedLabel("de") != "Heiß" {
+		t.Errorf("global value = %+v, want hot translated to Heiß", v)
+	}
+}
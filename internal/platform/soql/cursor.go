@@ -0,0 +1,76 @@
+package soql
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// NextRecordsPath is the URL prefix of nextRecordsUrl; the cursor follows it.
+const NextRecordsPath = "/api/v1/query/next/"
+
+// Keyset values are stored in the cursor as JSON. Types that JSON cannot
+// round-trip are wrapped in a single-key object tagging the original type,
+// so the next page binds exactly what PostgreSQL returned.
+const (
+	cursorTagUUID    = "$uuid"
+	cursorTagTime    = "$ts"
+	cursorTagNumeric = "$num"
+)
+
+// toCursorValue converts a raw keyset value into its JSON cursor form.
+func toCursorValue(v any) any {
+	switch val := v.(type) {
+	case [16]byte:
+		return map[string]any{cursorTagUUID: uuid.UUID(val).String()}
+	case uuid.UUID:
+		return map[string]any{cursorTagUUID: val.String()}
+	case time.Time:
+		return map[string]any{cursorTagTime: val.Format(time.RFC3339Nano)}
+	case pgtype.Numeric:
+		s, err := val.Value()
+		if err != nil || s == nil {
+			return nil
+		}
+		return map[string]any{cursorTagNumeric: s}
+	default:
+		return v
+	}
+}
+
+// fromCursorValue restores a keyset value decoded from a cursor.
+func fromCursorValue(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]any:
+		if len(val) != 1 {
+			return v
+		}
+		if s, ok := val[cursorTagUUID].(string); ok {
+			if id, err := uuid.Parse(s); err == nil {
+				return id
+			}
+		}
+		if s, ok := val[cursorTagTime].(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t
+			}
+		}
+		if s, ok := val[cursorTagNumeric].(string); ok {
+			var n pgtype.Numeric
+			if err := n.Scan(s); err == nil {
+				return n
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,57 @@
+package soql
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorValueRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	ts := time.Date(2026, 3, 14, 15, 9, 26, 535897000, time.UTC)
+	var amount pgtype.Numeric
+	require.NoError(t, amount.Scan("1234.50"))
+
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{name: "string", in: "Acme", want: "Acme"},
+		{name: "integer", in: int64(42), want: int64(42)},
+		{name: "float", in: 1.5, want: 1.5},
+		{name: "boolean", in: true, want: true},
+		{name: "uuid bytes", in: [16]byte(id), want: id},
+		{name: "timestamp", in: ts, want: ts},
+		{name: "numeric", in: amount, want: amount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := json.Marshal(toCursorValue(tt.in))
+			require.NoError(t, err)
+
+			var decoded any
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+			require.NoError(t, dec.Decode(&decoded))
+
+			got := fromCursorValue(decoded)
+			if want, ok := tt.want.(time.Time); ok {
+				assert.True(t, want.Equal(got.(time.Time)), "got %v, want %v", got, want)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
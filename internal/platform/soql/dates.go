@@ -0,0 +1,78 @@
+package soql
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+// sundayFirstCountries start the calendar week on Sunday; everyone else
+// uses the ISO 8601 Monday start.
+var sundayFirstCountries = map[string]bool{
+	"US": true, "CA": true, "MX": true, "BR": true, "JP": true, "KR": true,
+	"TW": true, "HK": true, "IL": true, "PH": true, "IN": true, "ZA": true,
+	"SA": true,
+}
+
+// locations caches parsed time zones; time.LoadLocation reads tzdata on every call.
+var locations sync.Map
+
+// dateResolver builds the resolver for date literals of the current request:
+// the user's time zone and locale override the organization defaults, and the
+// fiscal year always comes from the organization.
+func (e *Executor) dateResolver(ctx context.Context) (engine.DateResolver, error) {
+	var settings security.OrgSettings
+	if e.orgSettings != nil {
+		org, err := e.orgSettings.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if org != nil {
+			settings = *org
+		}
+	}
+
+	timeZone, locale := settings.DefaultTimeZone, settings.DefaultLocale
+	if uc, ok := security.UserFromContext(ctx); ok {
+		if uc.TimeZone != "" {
+			timeZone = uc.TimeZone
+		}
+		if uc.Locale != "" {
+			locale = uc.Locale
+		}
+	}
+
+	resolver := engine.NewDateResolverWithFiscalYear(loadLocation(timeZone), time.Month(settings.FiscalYearStartMonth))
+	resolver.WeekStartsOn = weekStart(locale)
+	return resolver, nil
+}
+
+// loadLocation returns the named time zone, falling back to UTC for unknown
+// names so that a stale setting never breaks queries.
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+// weekStart returns the first day of the week for a locale such as "en_US".
+func weekStart(locale string) time.Weekday {
+	_, country, ok := strings.Cut(locale, "_")
+	if ok && sundayFirstCountries[country] {
+		return time.Sunday
+	}
+	return time.Monday
+}
@@ -0,0 +1,129 @@
+package soql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+type stubOrgSettings struct {
+	settings *security.OrgSettings
+	err      error
+}
+
+func (s *stubOrgSettings) Get(_ context.Context) (*security.OrgSettings, error) {
+	return s.settings, s.err
+}
+
+func TestExecutor_DateResolver(t *testing.T) {
+	t.Parallel()
+
+	org := &security.OrgSettings{
+		DefaultTimeZone:      "America/New_York",
+		DefaultLocale:        "en_US",
+		FiscalYearStartMonth: 4,
+	}
+
+	tests := []struct {
+		name        string
+		org         OrgSettingsProvider
+		user        *security.UserContext
+		wantZone    string
+		wantWeek    time.Weekday
+		wantFiscalM time.Month
+	}{
+		{
+			name:        "no settings: UTC, ISO week, calendar fiscal year",
+			wantZone:    "UTC",
+			wantWeek:    time.Monday,
+			wantFiscalM: time.January,
+		},
+		{
+			name:        "organization defaults",
+			org:         &stubOrgSettings{settings: org},
+			user:        &security.UserContext{UserID: uuid.New()},
+			wantZone:    "America/New_York",
+			wantWeek:    time.Sunday,
+			wantFiscalM: time.April,
+		},
+		{
+			name:        "user preferences override time zone and locale, not fiscal year",
+			org:         &stubOrgSettings{settings: org},
+			user:        &security.UserContext{UserID: uuid.New(), TimeZone: "Europe/Berlin", Locale: "de_DE"},
+			wantZone:    "Europe/Berlin",
+			wantWeek:    time.Monday,
+			wantFiscalM: time.April,
+		},
+		{
+			name:        "unknown stored time zone falls back to UTC",
+			org:         &stubOrgSettings{settings: org},
+			user:        &security.UserContext{UserID: uuid.New(), TimeZone: "Invalid/Zone"},
+			wantZone:    "UTC",
+			wantWeek:    time.Sunday,
+			wantFiscalM: time.April,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			if tt.user != nil {
+				ctx = security.ContextWithUser(ctx, *tt.user)
+			}
+			e := &Executor{orgSettings: tt.org}
+
+			resolver, err := e.dateResolver(ctx)
+			require.NoError(t, err)
+
+			r, ok := resolver.(*engine.DefaultDateResolver)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantZone, r.Location.String())
+			assert.Equal(t, tt.wantWeek, r.WeekStartsOn)
+			assert.Equal(t, tt.wantFiscalM, r.FiscalYearStartMonth)
+		})
+	}
+}
+
+func TestExecutor_DateResolverTodayFollowsUserTimeZone(t *testing.T) {
+	t.Parallel()
+
+	// 2026-03-01 03:00 UTC is still February 28 in New York.
+	now := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	e := &Executor{orgSettings: &stubOrgSettings{settings: &security.OrgSettings{
+		DefaultTimeZone: "UTC", DefaultLocale: "en_GB", FiscalYearStartMonth: 1,
+	}}}
+
+	today := func(timeZone string) time.Time {
+		ctx := security.ContextWithUser(context.Background(), security.UserContext{UserID: uuid.New(), TimeZone: timeZone})
+		resolver, err := e.dateResolver(ctx)
+		require.NoError(t, err)
+		r := resolver.(*engine.DefaultDateResolver)
+		r.Now = func() time.Time { return now }
+		got, err := r.ResolveStatic(ctx, engine.DateToday)
+		require.NoError(t, err)
+		return got
+	}
+
+	assert.Equal(t, 1, today("").Day())
+	assert.Equal(t, 28, today("America/New_York").Day())
+}
+
+func TestExecutor_DateResolverSettingsError(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("db down")
+	e := &Executor{orgSettings: &stubOrgSettings{err: boom}}
+
+	_, err := e.dateResolver(context.Background())
+	assert.ErrorIs(t, err, boom)
+}
@@ -12,14 +12,27 @@ type Grammar struct {
 	IsRow                bool                `parser:"'SELECT' @'ROW'?"`
 	Select               []*SelectExpression `parser:"@@ (',' @@)*"`
 	From                 string              `parser:"'FROM' @Ident"`
+	Scope                FilterScope         `parser:"('USING' 'SCOPE' @('EVERYTHING' | 'MINE' | 'DELEGATED' | 'TEAM' | 'MY_TERRITORY' | 'MY_TEAM_TERRITORY'))?"`
 	Where                *Expression         `parser:"('WHERE' @@)?"`
-	WithSecurityEnforced bool                `parser:"@('WITH' 'SECURITY_ENFORCED')?"`
+	WithSecurityEnforced bool                `parser:"('WITH' ( @'SECURITY_ENFORCED'"`
+	Locale               []string            `parser:"  | 'LOCALE' @String ) )*"`
 	GroupBy              []*GroupClause      `parser:"('GROUP' 'BY' @@ (',' @@)*)?"`
 	Having               *Expression         `parser:"('HAVING' @@)?"`
 	OrderBy              []*OrderClause      `parser:"('ORDER' 'BY' @@ (',' @@)*)?"`
 	Limit                *int                `parser:"('LIMIT' @Integer)?"`
 	Offset               *int                `parser:"('OFFSET' @Integer)?"`
+	AllRows              bool                `parser:"@('ALL' 'ROWS')?"`
 	ForUpdate            bool                `parser:"@('FOR' 'UPDATE')?"`
+
+	// Search restricts the query to full-text matches. It is set when a SOSL
+	// RETURNING object is compiled and is never parsed from SOQL text.
+	Search *SearchClause
+}
+
+// SearchClause is a full-text condition on the object's search vector.
+type SearchClause struct {
+	// TSQuery is the text search query in to_tsquery syntax.
+	TSQuery string
 }
 
 // SelectExpression represents a single item in SELECT clause
@@ -35,9 +48,45 @@ type SelectItem struct {
 	Typeof    *TypeofExpression     `parser:"  @@"`
 	Aggregate *AggregateExpression  `parser:"| @@"`
 	Subquery  *RelationshipSubquery `parser:"| @@"`
+	Fields    *FieldsExpression     `parser:"| @@"`
 	Expr      *Expression           `parser:"| @@"`
 }
 
+// FieldsExpression selects a group of fields without listing them. The
+// validator replaces it with the fields of the object the user can read.
+// Example: FIELDS(CUSTOM)
+type FieldsExpression struct {
+	Pos lexer.Position
+	Set FieldSet `parser:"'FIELDS' '(' @('ALL' | 'STANDARD' | 'CUSTOM') ')'"`
+}
+
+// FieldSet is the group of fields selected by FIELDS().
+type FieldSet string
+
+const (
+	FieldSetAll      FieldSet = "ALL"
+	FieldSetStandard FieldSet = "STANDARD"
+	FieldSetCustom   FieldSet = "CUSTOM"
+)
+
+// Capture implements participle.Capture.
+func (s *FieldSet) Capture(values []string) error {
+	*s = FieldSet(strings.ToUpper(values[0]))
+	return nil
+}
+
+// includes reports whether the field belongs to the set.
+func (s FieldSet) includes(f *FieldMeta) bool {
+	switch s {
+	case FieldSetStandard:
+		return !f.Custom
+	case FieldSetCustom:
+		return f.Custom
+	default:
+		return true
+	}
+}
+
 // TypeofExpression represents a TYPEOF expression for polymorphic fields
 // Example: TYPEOF What WHEN Account THEN Name, Industry WHEN Opportunity THEN Name, Amount ELSE Name END
 type TypeofExpression struct {
@@ -68,17 +117,31 @@ type AggregateExpression struct {
 
 // RelationshipSubquery represents a Parent-to-Child subquery in SELECT
 // Example: (SELECT FirstName, Email FROM Contacts)
+// Example: (SELECT COUNT(Id), SUM(Amount) pipeline FROM Deals WHERE Stage = 'Open' HAVING COUNT(Id) > 0)
 type RelationshipSubquery struct {
 	Pos        lexer.Position
 	OpenParen  string              `parser:"'('"`
 	Select     []*SelectExpression `parser:"'SELECT' @@ (',' @@)*"`
 	From       string              `parser:"'FROM' @Ident"`
 	Where      *Expression         `parser:"('WHERE' @@)?"`
+	Having     *Expression         `parser:"('HAVING' @@)?"`
 	OrderBy    []*OrderClause      `parser:"('ORDER' 'BY' @@ (',' @@)*)?"`
 	Limit      *int                `parser:"('LIMIT' @Integer)?"`
 	CloseParen string              `parser:"')'"`
 }
 
+// IsAggregate reports whether the subquery selects aggregates. Such a
+// subquery returns one summary of the child records per parent record
+// instead of the child records themselves.
+func (s *RelationshipSubquery) IsAggregate() bool {
+	for _, sel := range s.Select {
+		if sel.Item != nil && sel.Item.Aggregate != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // WhereSubquery represents a subquery in WHERE IN clause (semi-join)
 // Example: SELECT Name FROM Account WHERE Id IN (SELECT AccountId FROM Contact)
 type WhereSubquery struct {
@@ -98,17 +161,122 @@ type OrderClause struct {
 	Nulls     *NullsOrder `parser:"('NULLS' @('FIRST' | 'LAST'))?"`
 }
 
-// OrderItem represents what to order by (field or aggregate)
+// OrderItem represents what to order by (field, aggregate, date function or picklist label)
 type OrderItem struct {
 	Pos       lexer.Position
 	Aggregate *AggregateExpression `parser:"  @@"`
+	DateFunc  *DateFunctionCall    `parser:"| @@"`
+	ToLabel   *ToLabelCall         `parser:"| @@"`
 	Field     []string             `parser:"| @Ident ('.' @Ident)*"`
 }
 
-// GroupClause represents a single GROUP BY item
+// GroupClause represents a single GROUP BY item: a field, a date function,
+// or ROLLUP/CUBE over such items, which adds subtotal rows.
+// Examples: StageName, FISCAL_QUARTER(CloseDate), ROLLUP(StageName, OwnerId)
 type GroupClause struct {
-	Pos   lexer.Position
-	Field []string `parser:"@Ident ('.' @Ident)*"`
+	Pos      lexer.Position
+	Set      GroupingSet       `parser:"(  @('ROLLUP' | 'CUBE') '('"`
+	Items    []*GroupClause    `parser:"   @@ (',' @@)* ')'"`
+	DateFunc *DateFunctionCall `parser:" | @@"`
+	Field    []string          `parser:" | @Ident ('.' @Ident)* )"`
+}
+
+// FilterScope narrows a query to records related to the current user.
+// Example: SELECT Name FROM Deal USING SCOPE team
+type FilterScope string
+
+const (
+	// FilterScopeEverything does not narrow the query (same as no scope).
+	FilterScopeEverything FilterScope = "everything"
+	// FilterScopeMine selects records owned by the user.
+	FilterScopeMine FilterScope = "mine"
+	// FilterScopeDelegated selects records owned by others and shared with the user.
+	FilterScopeDelegated FilterScope = "delegated"
+	// FilterScopeTeam selects records owned by the user or users in roles below.
+	FilterScopeTeam FilterScope = "team"
+	// FilterScopeMyTerritory selects records assigned to the user's territories.
+	FilterScopeMyTerritory FilterScope = "my_territory"
+	// FilterScopeMyTeamTerritory selects records assigned to territories of the user's team.
+	FilterScopeMyTeamTerritory FilterScope = "my_team_territory"
+)
+
+// Capture implements participle.Capture.
+func (s *FilterScope) Capture(values []string) error {
+	*s = FilterScope(strings.ToLower(values[0]))
+	return nil
+}
+
+// GroupingSet is the kind of subtotals a ROLLUP or CUBE group adds.
+type GroupingSet string
+
+const (
+	// GroupingRollup adds subtotals for each prefix of the items and a grand total.
+	GroupingRollup GroupingSet = "ROLLUP"
+	// GroupingCube adds subtotals for every combination of the items.
+	GroupingCube GroupingSet = "CUBE"
+)
+
+// Capture implements participle.Capture.
+func (s *GroupingSet) Capture(values []string) error {
+	*s = GroupingSet(strings.ToUpper(values[0]))
+	return nil
+}
+
+// key identifies a field or date function group item, so that GROUPING()
+// can be matched with it. ROLLUP and CUBE groups have no key.
+func (g *GroupClause) key() string {
+	switch {
+	case g.DateFunc != nil:
+		return g.DateFunc.key()
+	case len(g.Field) > 0:
+		return strings.ToLower(strings.Join(g.Field, "."))
+	default:
+		return ""
+	}
+}
+
+// DateFunctionCall buckets a date or datetime field. Datetime values are
+// converted to the user's time zone before they are bucketed.
+// Example: CALENDAR_YEAR(CloseDate)
+type DateFunctionCall struct {
+	Pos       lexer.Position
+	Name      DateFunction `parser:"@('CALENDAR_MONTH' | 'CALENDAR_QUARTER' | 'CALENDAR_YEAR' | 'FISCAL_QUARTER' | 'DAY_ONLY' | 'WEEK_IN_YEAR' | 'HOUR_IN_DAY')"`
+	Field     *Field       `parser:"'(' @@ ')'"`
+	FieldType FieldType
+}
+
+func (d *DateFunctionCall) key() string {
+	return strings.ToLower(d.Name.String() + "(" + strings.Join(d.Field.Path, ".") + ")")
+}
+
+// ToLabelCall returns the label of a picklist value instead of the stored
+// value, translated to the query locale (WITH LOCALE). Values without a
+// label are returned as stored.
+// Example: toLabel(Stage)
+type ToLabelCall struct {
+	Pos       lexer.Position
+	Field     *Field `parser:"'TOLABEL' '(' @@ ')'"`
+	FieldType FieldType
+
+	// Picklist is the picklist of the field, set by the validator.
+	Picklist *PicklistMeta
+}
+
+// GroupingExpression is 1 in the subtotal rows of a ROLLUP or CUBE where
+// the GROUP BY item is rolled up, and 0 otherwise.
+// Example: GROUPING(OwnerId)
+type GroupingExpression struct {
+	Pos  lexer.Position
+	Item *GroupClause `parser:"'GROUPING' '(' @@ ')'"`
+}
+
+// ExistsExpression is true when a record has child records in a
+// relationship, optionally only those matching a condition.
+// Example: EXISTS Contacts(Email LIKE '%@acme.com')
+type ExistsExpression struct {
+	Pos          lexer.Position
+	Relationship string      `parser:"'EXISTS' @Ident"`
+	Where        *Expression `parser:"('(' @@ ')')?"`
 }
 
 // Expression is the top-level expression node
@@ -149,12 +317,14 @@ type CompareExpr struct {
 // Supports both literal values and subqueries:
 //   - Id IN ('001', '002', '003')
 //   - Id IN (SELECT AccountId FROM Contact WHERE Status = 'Active')
+//   - Id IN :ids
 type InExpr struct {
 	Left      *LikeExpr      `parser:"@@"`
 	Not       bool           `parser:"(@'NOT'?"`
 	In        bool           `parser:"@'IN'"`
 	Subquery  *WhereSubquery `parser:"( @@"`
-	Values    []*Value       `parser:"| '(' @@ (',' @@)* ')' ))?"`
+	Values    []*Value       `parser:"| '(' @@ (',' @@)* ')'"`
+	ListBind  *Bind          `parser:"| @@ ))?"`
 	FieldType FieldType
 }
 
@@ -216,6 +386,10 @@ type Primary struct {
 	Subexpression *Expression          `parser:"  '(' @@ ')'"`
 	Aggregate     *AggregateExpression `parser:"| @@"`
 	FuncCall      *FuncCall            `parser:"| @@"`
+	DateFunc      *DateFunctionCall    `parser:"| @@"`
+	ToLabel       *ToLabelCall         `parser:"| @@"`
+	Grouping      *GroupingExpression  `parser:"| @@"`
+	Exists        *ExistsExpression    `parser:"| @@"`
 	Const         *Const               `parser:"| @@"`
 	Field         *Field               `parser:"| @@"`
 	FieldType     FieldType
@@ -244,6 +418,7 @@ type FuncCall struct {
 
 // Const represents a constant value
 type Const struct {
+	Pos         lexer.Position
 	DynamicDate *DynamicDateLiteral `parser:"  @DynamicDateLiteral"`
 	StaticDate  *StaticDateLiteral  `parser:"| @StaticDateLiteral"`
 	DateTime    *DateTime           `parser:"| @DateTime"`
@@ -253,9 +428,18 @@ type Const struct {
 	Integer     *int                `parser:"| @Integer"`
 	Boolean     *Boolean            `parser:"| @('TRUE' | 'FALSE')"`
 	Null        bool                `parser:"| @'NULL'"`
+	Bind        *Bind               `parser:"| @@"`
 	FieldType   FieldType
 }
 
+// Bind is a :name bind variable whose value is supplied at execution time.
+// Its type is taken from the expression it is compared with.
+type Bind struct {
+	Pos       lexer.Position
+	Name      string `parser:"@Bind"`
+	FieldType FieldType
+}
+
 // InferFieldType walks the expression tree to find the leaf field type.
 // For simple field references or constants, returns their resolved type.
 // For complex expressions (comparisons, boolean ops), returns FieldTypeUnknown.
@@ -303,6 +487,14 @@ func (e *Expression) InferFieldType() FieldType {
 		return p.Const.GetFieldType()
 	case p.FuncCall != nil:
 		return p.FuncCall.FieldType
+	case p.DateFunc != nil:
+		return p.DateFunc.FieldType
+	case p.ToLabel != nil:
+		return p.ToLabel.FieldType
+	case p.Grouping != nil:
+		return FieldTypeInteger
+	case p.Exists != nil:
+		return FieldTypeBoolean
 	case p.Aggregate != nil:
 		return p.Aggregate.FieldType
 	case p.Subexpression != nil:
@@ -311,8 +503,75 @@ func (e *Expression) InferFieldType() FieldType {
 	return e.FieldType
 }
 
+// primary returns the expression when it is a single primary expression
+// without operators, or nil otherwise.
+func (e *Expression) primary() *Primary {
+	if e == nil || e.Or == nil || len(e.Or.And) != 1 || len(e.Or.And[0].Not) != 1 {
+		return nil
+	}
+	not := e.Or.And[0].Not[0]
+	if not.Not || not.Compare == nil || not.Compare.Operator != nil {
+		return nil
+	}
+	return not.Compare.Left.primary()
+}
+
+// primary returns the operand when it is a single primary expression
+// (no IN, LIKE, IS, arithmetic or sign), or nil otherwise.
+func (in *InExpr) primary() *Primary {
+	if in == nil || in.In {
+		return nil
+	}
+	return in.Left.primary()
+}
+
+func (l *LikeExpr) primary() *Primary {
+	if l == nil || l.Like || l.Left == nil || l.Left.Is {
+		return nil
+	}
+	add := l.Left.Left
+	if add == nil || len(add.Right) > 0 || add.Left == nil || len(add.Left.Right) > 0 {
+		return nil
+	}
+	unary := add.Left.Left
+	if unary == nil || unary.Operator != nil {
+		return nil
+	}
+	return unary.Primary
+}
+
+// operandType returns the resolved type of a comparison operand.
+func (p *Primary) operandType() FieldType {
+	switch {
+	case p == nil:
+		return FieldTypeUnknown
+	case p.Field != nil:
+		return p.Field.FieldType
+	case p.Const != nil:
+		return p.Const.GetFieldType()
+	case p.FuncCall != nil:
+		return p.FuncCall.FieldType
+	case p.DateFunc != nil:
+		return p.DateFunc.FieldType
+	case p.ToLabel != nil:
+		return p.ToLabel.FieldType
+	case p.Grouping != nil:
+		return FieldTypeInteger
+	case p.Exists != nil:
+		return FieldTypeBoolean
+	case p.Aggregate != nil:
+		return p.Aggregate.FieldType
+	case p.Subexpression != nil:
+		return p.Subexpression.InferFieldType()
+	}
+	return FieldTypeUnknown
+}
+
 // GetFieldType returns the inferred type of the constant
 func (c *Const) GetFieldType() FieldType {
+	if c.Bind != nil {
+		return c.Bind.FieldType
+	}
 	if c.FieldType != FieldTypeUnknown {
 		return c.FieldType
 	}
@@ -344,8 +603,7 @@ func (c *Const) GetFieldType() FieldType {
 }
 
 // IsRowQuery checks whether a SOQL string uses SELECT ROW syntax.
-// Uses simple prefix matching because the SOQL may contain :param placeholders
-// that the parser does not handle.
+// Uses simple prefix matching so that it works on SOQL that does not parse.
 func IsRowQuery(soql string) bool {
 	s := strings.TrimSpace(soql)
 	upper := strings.ToUpper(s)
@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bind returns a copy of the query with bind variable values placed in Params.
+// Each value is converted to the type of the operand its variable is compared
+// with, so strings (e.g. URL parameters) are parsed and JSON numbers are
+// accepted for integer fields. Values without a matching variable are ignored.
+// The query itself is left untouched, so a cached query can be bound concurrently.
+func (q *CompiledQuery) Bind(values map[string]any) (*CompiledQuery, error) {
+	if len(q.BindParams) == 0 {
+		return q, nil
+	}
+
+	bound := *q
+	bound.Params = slices.Clone(q.Params)
+	for _, bp := range q.BindParams {
+		value, ok := values[bp.Name]
+		if !ok {
+			return nil, NewValidationError(ErrCodeInvalidBind,
+				fmt.Sprintf("missing value for bind variable :%s", bp.Name))
+		}
+		converted, err := convertBindValue(bp.Type, value)
+		if err != nil {
+			return nil, NewValidationError(ErrCodeTypeMismatch,
+				fmt.Sprintf("bind variable :%s: %s", bp.Name, err))
+		}
+		if bp.ParamIndex > 0 && bp.ParamIndex <= len(bound.Params) {
+			bound.Params[bp.ParamIndex-1] = converted
+		}
+	}
+	return &bound, nil
+}
+
+// convertBindValue converts a bind value to the Go type pgx encodes for t.
+func convertBindValue(t FieldType, value any) (any, error) {
+	if !t.IsArray() {
+		return convertBindScalar(t, value)
+	}
+
+	items, err := bindListItems(value)
+	if err != nil {
+		return nil, err
+	}
+	switch elem := t.Base(); elem {
+	case FieldTypeID:
+		return convertBindList[uuid.UUID](elem, items)
+	case FieldTypeString:
+		return convertBindList[string](elem, items)
+	case FieldTypeInteger:
+		return convertBindList[int64](elem, items)
+	case FieldTypeFloat:
+		return convertBindList[float64](elem, items)
+	case FieldTypeBoolean:
+		return convertBindList[bool](elem, items)
+	case FieldTypeDate, FieldTypeDateTime:
+		return convertBindList[time.Time](elem, items)
+	default:
+		return nil, fmt.Errorf("lists of %s are not supported", elem)
+	}
+}
+
+// bindListItems returns the elements of a list value. A single scalar is
+// treated as a one-element list, so a lone URL parameter can feed IN :ids.
+func bindListItems(value any) ([]any, error) {
+	if value == nil {
+		return nil, fmt.Errorf("expected a list, got null")
+	}
+	if items, ok := value.([]any); ok {
+		return items, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return []any{value}, nil
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, nil
+}
+
+func convertBindList[T any](elem FieldType, items []any) ([]T, error) {
+	list := make([]T, len(items))
+	for i, item := range items {
+		v, err := convertBindScalar(elem, item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		typed, ok := v.(T)
+		if !ok {
+			return nil, fmt.Errorf("item %d: expected %s, got null", i, elem)
+		}
+		list[i] = typed
+	}
+	return list, nil
+}
+
+// convertBindScalar converts a single bind value. Nil binds SQL NULL.
+func convertBindScalar(t FieldType, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t {
+	case FieldTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case FieldTypeID:
+		switch v := value.(type) {
+		case uuid.UUID:
+			return v, nil
+		case string:
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid id %q", v)
+			}
+			return id, nil
+		}
+	case FieldTypeInteger:
+		if n, ok := bindInteger(value); ok {
+			return n, nil
+		}
+	case FieldTypeFloat:
+		if f, ok := bindFloat(value); ok {
+			return f, nil
+		}
+	case FieldTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+	case FieldTypeDate, FieldTypeDateTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			if tm, err := time.Parse(time.RFC3339, v); err == nil {
+				return tm, nil
+			}
+			if tm, err := time.Parse("2006-01-02", v); err == nil {
+				return tm, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("values of type %s cannot be bound", t)
+	}
+	return nil, fmt.Errorf("expected %s, got %v (%T)", t, value, value)
+}
+
+func bindInteger(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), true
+		}
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func bindFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,259 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEngine_PrepareBinds(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	eng := NewEngine(WithMetadata(setupTestMetadata()))
+
+	tests := []struct {
+		name      string
+		query     string
+		wantSQL   []string
+		wantBinds []BindParam
+	}{
+		{
+			name:      "compared with a field",
+			query:     "SELECT Id FROM Account WHERE Name = :name AND AnnualRevenue > :minRevenue",
+			wantSQL:   []string{`t0."name" = $1`, `t0."annual_revenue" > $2`},
+			wantBinds: []BindParam{{1, "name", FieldTypeString}, {2, "minRevenue", FieldTypeFloat}},
+		},
+		{
+			name:      "bind on the left",
+			query:     "SELECT Id FROM Opportunity WHERE :day = CloseDate",
+			wantSQL:   []string{`$1 = t0."close_date"`},
+			wantBinds: []BindParam{{1, "day", FieldTypeDate}},
+		},
+		{
+			name:      "list bind",
+			query:     "SELECT Id FROM Account WHERE Id IN :ids",
+			wantSQL:   []string{`t0."id" = ANY($1)`},
+			wantBinds: []BindParam{{1, "ids", FieldTypeID | FieldTypeArray}},
+		},
+		{
+			name:      "NOT IN list bind",
+			query:     "SELECT Id FROM Account WHERE Id NOT IN :ids",
+			wantSQL:   []string{`t0."id" <> ALL($1)`},
+			wantBinds: []BindParam{{1, "ids", FieldTypeID | FieldTypeArray}},
+		},
+		{
+			name:      "binds in IN values",
+			query:     "SELECT Id FROM Account WHERE Industry IN (:first, 'Tech')",
+			wantSQL:   []string{`t0."industry" IN ($1, 'Tech')`},
+			wantBinds: []BindParam{{1, "first", FieldTypeString}},
+		},
+		{
+			name:      "LIKE pattern",
+			query:     "SELECT Id FROM Account WHERE Name LIKE :pattern",
+			wantSQL:   []string{`t0."name" LIKE $1`},
+			wantBinds: []BindParam{{1, "pattern", FieldTypeString}},
+		},
+		{
+			name:      "lookup field",
+			query:     "SELECT Id FROM Contact WHERE Account.Name = :name",
+			wantSQL:   []string{`."name" = $1`},
+			wantBinds: []BindParam{{1, "name", FieldTypeString}},
+		},
+		{
+			name:      "repeated bind",
+			query:     "SELECT Id FROM Account WHERE Name = :q OR Industry = :q",
+			wantSQL:   []string{`t0."name" = $1`, `t0."industry" = $2`},
+			wantBinds: []BindParam{{1, "q", FieldTypeString}, {2, "q", FieldTypeString}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			compiled, err := eng.Prepare(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(compiled.SQL, want) {
+					t.Errorf("SQL missing %q:\n%s", want, compiled.SQL)
+				}
+			}
+			if len(compiled.BindParams) != len(tt.wantBinds) {
+				t.Fatalf("BindParams = %d, want %d", len(compiled.BindParams), len(tt.wantBinds))
+			}
+			for i, want := range tt.wantBinds {
+				if *compiled.BindParams[i] != want {
+					t.Errorf("BindParams[%d] = %+v, want %+v", i, *compiled.BindParams[i], want)
+				}
+			}
+		})
+	}
+
+	errorTests := []struct {
+		name  string
+		query string
+	}{
+		{name: "bind in SELECT", query: "SELECT :x FROM Account"},
+		{name: "bind in arithmetic", query: "SELECT Id FROM Account WHERE AnnualRevenue > :x * 2"},
+		{name: "conflicting types", query: "SELECT Id FROM Account WHERE Name = :x OR AnnualRevenue = :x"},
+		{name: "relationship subquery", query: "SELECT Id, (SELECT Id FROM Contacts WHERE Email = :email) FROM Account"},
+		{name: "WHERE subquery", query: "SELECT Id FROM Account WHERE Id IN (SELECT AccountId FROM Contact WHERE Email = :email)"},
+	}
+	for _, tt := range errorTests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := eng.Prepare(ctx, tt.query)
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Code != ErrCodeInvalidBind {
+				t.Errorf("Prepare(%q) error = %v, want InvalidBind", tt.query, err)
+			}
+		})
+	}
+
+	t.Run("rejects binds in SOSL", func(t *testing.T) {
+		t.Parallel()
+		_, err := eng.PrepareSearch(ctx, "FIND {acme} RETURNING Account(Name WHERE Industry = :industry)")
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Code != ErrCodeInvalidBind {
+			t.Errorf("PrepareSearch() error = %v, want InvalidBind", err)
+		}
+	})
+}
+
+func TestCompiledQuery_Bind(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	eng := NewEngine(WithMetadata(setupTestMetadata()))
+	compiled, err := eng.Prepare(ctx,
+		"SELECT Id FROM Opportunity WHERE Name = :name AND Amount > :amount AND CloseDate = :day AND Id IN :ids")
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	id := uuid.New()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("converts values to field types", func(t *testing.T) {
+		t.Parallel()
+		bound, err := compiled.Bind(map[string]any{
+			"name":   "Acme",
+			"amount": "1500.5",
+			"day":    "2026-03-01",
+			"ids":    []any{id.String()},
+			"unused": true,
+		})
+		if err != nil {
+			t.Fatalf("Bind() error = %v", err)
+		}
+		if bound.Params[0] != "Acme" || bound.Params[1] != 1500.5 || bound.Params[2] != day {
+			t.Errorf("Params = %v", bound.Params)
+		}
+		ids, ok := bound.Params[3].([]uuid.UUID)
+		if !ok || len(ids) != 1 || ids[0] != id {
+			t.Errorf("ids = %#v, want [%s]", bound.Params[3], id)
+		}
+		if compiled.Params[0] != nil {
+			t.Error("Bind() must not modify the compiled query")
+		}
+	})
+
+	t.Run("single value binds a one-element list", func(t *testing.T) {
+		t.Parallel()
+		bound, err := compiled.Bind(map[string]any{
+			"name": "Acme", "amount": json.Number("10"), "day": day, "ids": id.String(),
+		})
+		if err != nil {
+			t.Fatalf("Bind() error = %v", err)
+		}
+		if ids, ok := bound.Params[3].([]uuid.UUID); !ok || len(ids) != 1 {
+			t.Errorf("ids = %#v", bound.Params[3])
+		}
+	})
+
+	bindErrors := []struct {
+		name     string
+		values   map[string]any
+		wantCode ValidationErrorCode
+	}{
+		{
+			name:     "missing value",
+			values:   map[string]any{"name": "Acme", "amount": 1, "day": day},
+			wantCode: ErrCodeInvalidBind,
+		},
+		{
+			name:     "number for string field",
+			values:   map[string]any{"name": 42, "amount": 1, "day": day, "ids": []string{id.String()}},
+			wantCode: ErrCodeTypeMismatch,
+		},
+		{
+			name:     "invalid id in list",
+			values:   map[string]any{"name": "Acme", "amount": 1, "day": day, "ids": []string{"nope"}},
+			wantCode: ErrCodeTypeMismatch,
+		},
+		{
+			name:     "invalid date",
+			values:   map[string]any{"name": "Acme", "amount": 1, "day": "March", "ids": []string{id.String()}},
+			wantCode: ErrCodeTypeMismatch,
+		},
+	}
+	for _, tt := range bindErrors {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := compiled.Bind(tt.values)
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Code != tt.wantCode {
+				t.Errorf("Bind() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestConvertBindScalar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		typ     FieldType
+		value   any
+		want    any
+		wantErr bool
+	}{
+		{name: "integer from JSON float", typ: FieldTypeInteger, value: float64(7), want: int64(7)},
+		{name: "integer from string", typ: FieldTypeInteger, value: "7", want: int64(7)},
+		{name: "fractional integer", typ: FieldTypeInteger, value: 7.5, wantErr: true},
+		{name: "float from int", typ: FieldTypeFloat, value: 3, want: float64(3)},
+		{name: "boolean from string", typ: FieldTypeBoolean, value: "true", want: true},
+		{name: "boolean from number", typ: FieldTypeBoolean, value: 1, wantErr: true},
+		{name: "datetime from RFC3339", typ: FieldTypeDateTime, value: "2026-01-02T03:04:05Z",
+			want: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "null", typ: FieldTypeString, value: nil, want: nil},
+		{name: "object type", typ: FieldTypeObject, value: "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := convertBindScalar(tt.typ, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("convertBindScalar() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("convertBindScalar() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("convertBindScalar() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
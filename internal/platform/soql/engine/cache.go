@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-process Cache with a time-to-live and a bounded
+// number of entries. When full, the least recently used entry is evicted.
+type MemoryCache[K comparable, V any] struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu     sync.Mutex
+	items  map[K]*list.Element
+	order  *list.List // front = most recently used
+	hits   int64
+	misses int64
+}
+
+type memoryCacheEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a MemoryCache. A zero ttl keeps entries until they
+// are evicted or removed; a zero maxEntries does not bound the cache.
+func NewMemoryCache[K comparable, V any](ttl time.Duration, maxEntries int) *MemoryCache[K, V] {
+	return &MemoryCache[K, V]{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		items:      make(map[K]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get implements Cache.
+func (c *MemoryCache[K, V]) Get(_ context.Context, key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return zero, false
+	}
+	entry := el.Value.(*memoryCacheEntry[K, V])
+	if c.expired(entry) {
+		c.removeElement(el)
+		c.misses++
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	c.hits++
+	return entry.value, true
+}
+
+// Set implements Cache.
+func (c *MemoryCache[K, V]) Set(_ context.Context, key K, value V) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = c.now().Add(c.ttl)
+	}
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*memoryCacheEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&memoryCacheEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+	return nil
+}
+
+// Delete implements Cache.
+func (c *MemoryCache[K, V]) Delete(_ context.Context, key K) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	return nil
+}
+
+// Clear implements Cache.
+func (c *MemoryCache[K, V]) Clear(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[K]*list.Element)
+	c.order.Init()
+	return nil
+}
+
+// Remove implements Cache.
+func (c *MemoryCache[K, V]) Remove(predicate func(V) bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if predicate(el.Value.(*memoryCacheEntry[K, V]).value) {
+			c.removeElement(el)
+		}
+		el = next
+	}
+	return nil
+}
+
+// GetStats implements Cache.
+func (c *MemoryCache[K, V]) GetStats() *CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &CacheStats{
+		Hits:   c.hits,
+		Misses: c.misses,
+		Size:   int64(len(c.items)),
+	}
+}
+
+// StartGarbageCollector implements CacheWithGC. It removes expired entries
+// every interval until ctx is cancelled.
+func (c *MemoryCache[K, V]) StartGarbageCollector(ctx context.Context, interval time.Duration) {
+	if c.ttl <= 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.removeExpired()
+			}
+		}
+	}()
+}
+
+func (c *MemoryCache[K, V]) removeExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if c.expired(el.Value.(*memoryCacheEntry[K, V])) {
+			c.removeElement(el)
+		}
+		el = next
+	}
+}
+
+func (c *MemoryCache[K, V]) expired(entry *memoryCacheEntry[K, V]) bool {
+	return !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt)
+}
+
+func (c *MemoryCache[K, V]) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*memoryCacheEntry[K, V]).key)
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("expires entries after the TTL", func(t *testing.T) {
+		t.Parallel()
+		now := time.Now()
+		c := NewMemoryCache[string, int](time.Minute, 0)
+		c.now = func() time.Time { return now }
+
+		_ = c.Set(ctx, "a", 1)
+		if v, ok := c.Get(ctx, "a"); !ok || v != 1 {
+			t.Fatalf("Get() = %v, %v, want 1, true", v, ok)
+		}
+
+		now = now.Add(time.Minute)
+		if _, ok := c.Get(ctx, "a"); ok {
+			t.Error("Get() returned an expired entry")
+		}
+		if stats := c.GetStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Size != 0 {
+			t.Errorf("GetStats() = %+v, want 1 hit, 1 miss, size 0", stats)
+		}
+	})
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		t.Parallel()
+		c := NewMemoryCache[string, int](0, 2)
+
+		_ = c.Set(ctx, "a", 1)
+		_ = c.Set(ctx, "b", 2)
+		c.Get(ctx, "a")
+		_ = c.Set(ctx, "c", 3)
+
+		if _, ok := c.Get(ctx, "b"); ok {
+			t.Error("b should have been evicted")
+		}
+		for _, key := range []string{"a", "c"} {
+			if _, ok := c.Get(ctx, key); !ok {
+				t.Errorf("%s should be cached", key)
+			}
+		}
+	})
+
+	t.Run("removes matching entries", func(t *testing.T) {
+		t.Parallel()
+		c := NewMemoryCache[string, int](0, 0)
+		for i, key := range []string{"a", "b", "c", "d"} {
+			_ = c.Set(ctx, key, i)
+		}
+
+		_ = c.Remove(func(v int) bool { return v%2 == 0 })
+		if got := c.GetStats().Size; got != 2 {
+			t.Errorf("Size = %d, want 2", got)
+		}
+		if _, ok := c.Get(ctx, "b"); !ok {
+			t.Error("b should be kept")
+		}
+
+		_ = c.Clear(ctx)
+		if got := c.GetStats().Size; got != 0 {
+			t.Errorf("Size after Clear = %d, want 0", got)
+		}
+	})
+
+	t.Run("collects expired entries", func(t *testing.T) {
+		t.Parallel()
+		now := time.Now()
+		c := NewMemoryCache[string, int](time.Minute, 0)
+		c.now = func() time.Time { return now }
+
+		_ = c.Set(ctx, "a", 1)
+		now = now.Add(30 * time.Second)
+		_ = c.Set(ctx, "b", 2)
+		now = now.Add(30 * time.Second)
+
+		c.removeExpired()
+		if got := c.GetStats().Size; got != 1 {
+			t.Errorf("Size = %d, want 1", got)
+		}
+	})
+}
@@ -22,6 +22,7 @@ type CompiledQuery struct {
 	SQL        string       // SQL with placeholders ($1, $2...)
 	Params     []any        // Static parameters
 	DateParams []*DateParam // Date literal parameters for runtime resolution
+	BindParams []*BindParam // Bind variable parameters, filled in by Bind
 	Shape      *ResultShape // Structure of expected result
 
 	// Pagination contains keyset pagination metadata.
@@ -44,6 +45,10 @@ type CompiledQuery struct {
 	// IsRow indicates that this is a SELECT ROW query expecting at most one record.
 	// When true, the executor should return a single record (or nil) instead of a list.
 	IsRow bool
+
+	// RLSTargets lists every table reference (root, lookup joins, subqueries)
+	// whose placeholder in SQL must be replaced via ApplyRLS before execution.
+	RLSTargets []*RLSTarget
 }
 
 // DateParam represents a date literal parameter that needs runtime resolution.
@@ -53,11 +58,20 @@ type DateParam struct {
 	Dynamic    *DynamicDateLiteral // Dynamic date literal (LAST_N_DAYS:30, etc.)
 	IsRange    bool                // Whether this is a range comparison (BETWEEN)
 	EndIndex   int                 // End index for range comparisons
+	Calendar   CalendarSetting     // Calendar setting of date functions, instead of a literal
+}
+
+// BindParam represents a :name bind variable placeholder.
+type BindParam struct {
+	ParamIndex int       // Index in Params slice where the bound value will be
+	Name       string    // Variable name without the colon
+	Type       FieldType // Expected value type; array types take a list
 }
 
 // ResultShape describes the structure of the query result.
 type ResultShape struct {
 	Object        string               // SOQL object name
+	Label         string               // Object label in the query locale
 	Table         string               // SQL table name
 	Fields        []*FieldShape        // Fields in order
 	Relationships []*RelationshipShape // Child relationships (subqueries)
@@ -69,12 +83,14 @@ type FieldShape struct {
 	Column string    // SQL column expression
 	Type   FieldType // Field type
 	Alias  string    // SQL alias used in query
+	Label  string    // Field label in the query locale, empty for expressions
 }
 
 // RelationshipShape describes a child relationship subquery result.
 type RelationshipShape struct {
-	Name  string       // Relationship name
-	Shape *ResultShape // Nested result shape
+	Name      string       // Relationship name
+	Shape     *ResultShape // Nested result shape
+	Aggregate bool         // One object of aggregates instead of an array of records
 }
 
 // Compiler compiles validated SOQL queries to SQL.
@@ -96,15 +112,36 @@ type compileContext struct {
 	validated       *ValidatedQuery
 	params          []any
 	dateParams      []*DateParam
+	bindParams      []*BindParam
 	paramCount      int
 	joinAliases     map[string]string // join path -> alias
 	joinSQL         []string          // JOIN clauses
+	lateralCount    int               // Aggregate subqueries joined with LATERAL
 	shape           *ResultShape
 	mainAlias       string
 	whereSubqueries []*ValidatedWhereSubquery // WHERE subqueries from validation
 
 	// Keyset pagination fields
 	keysetFields []*KeysetField // ORDER BY fields for keyset pagination
+
+	// Row-level security placeholders
+	rlsNonce   string
+	rlsTargets []*RLSTarget
+
+	// allRows keeps records in the recycle bin (ALL ROWS).
+	allRows bool
+
+	// aggregated is set once an aggregate is compiled in the root query;
+	// such queries return groups, not records, and are not paginated.
+	aggregated bool
+
+	// calendarParams maps calendar settings to their parameter index, so that
+	// a date function compiles to the same SQL in SELECT and GROUP BY.
+	calendarParams map[CalendarSetting]int
+
+	// labelExprs maps field paths to their compiled toLabel(), so that the
+	// labels are passed once for SELECT, WHERE and ORDER BY.
+	labelExprs map[string]string
 }
 
 func newCompileContext(c *Compiler, v *ValidatedQuery) *compileContext {
@@ -120,15 +157,21 @@ func newCompileContext(c *Compiler, v *ValidatedQuery) *compileContext {
 		mainAlias:       "t0",
 		whereSubqueries: v.WhereSubqueries,
 		keysetFields:    make([]*KeysetField, 0),
+		rlsNonce:        newRLSNonce(),
+		allRows:         v.AST.AllRows,
+		calendarParams:  make(map[CalendarSetting]int),
+		labelExprs:      make(map[string]string),
 	}
 }
 
 // Compile compiles a validated SOQL query to SQL.
 func (c *Compiler) Compile(validated *ValidatedQuery) (*CompiledQuery, error) {
 	ctx := newCompileContext(c, validated)
+	ctx.aggregated = len(validated.AST.GroupBy) > 0
 
 	// Initialize result shape
 	ctx.shape.Object = validated.RootObject.Name
+	ctx.shape.Label = validated.RootObject.LocalizedLabel(validated.Locale)
 	ctx.shape.Table = validated.RootObject.QualifiedTableName()
 
 	// Build SELECT clause
@@ -154,6 +197,36 @@ func (c *Compiler) Compile(validated *ValidatedQuery) (*CompiledQuery, error) {
 		}
 	}
 
+	// SOSL: keep full-text matches only and rank them.
+	var searchRank string
+	if search := validated.AST.Search; search != nil {
+		var searchSQL string
+		searchSQL, searchRank = c.compileSearch(ctx, search)
+		if whereSQL != "" {
+			whereSQL += " AND " + searchSQL
+		} else {
+			whereSQL = searchSQL
+		}
+		selectSQL += ", " + searchRank + " AS " + SearchRankAlias
+		ctx.shape.Fields = append(ctx.shape.Fields, &FieldShape{
+			Name:   SearchRankAlias,
+			Column: SearchRankAlias,
+			Type:   FieldTypeFloat,
+			Alias:  SearchRankAlias,
+		})
+	}
+
+	// Restrict root rows by sharing; the placeholder is resolved by ApplyRLS.
+	rootRLS := c.addRLSTarget(ctx, RLSTargetRoot, validated.RootObject, ctx.mainAlias)
+	if scope := validated.AST.Scope; scope != "" && scope != FilterScopeEverything {
+		rootRLS += " AND " + c.addScopeTarget(ctx, scope, validated.RootObject.Name, ctx.mainAlias)
+	}
+	if whereSQL != "" {
+		whereSQL += " AND " + rootRLS
+	} else {
+		whereSQL = rootRLS
+	}
+
 	// Build GROUP BY clause
 	var groupBySQL string
 	if len(validated.AST.GroupBy) > 0 {
@@ -182,23 +255,42 @@ func (c *Compiler) Compile(validated *ValidatedQuery) (*CompiledQuery, error) {
 		}
 	}
 
-	// For keyset pagination, ensure we have a tie-breaker
-	// Add id as tie-breaker if not already present
-	tieBreaker := c.ensureTieBreaker(ctx, validated.RootObject)
-	if tieBreaker != "" {
-		if orderBySQL != "" {
-			orderBySQL += ", " + tieBreaker
-		} else {
-			// Default order: use tie-breaker as the only ORDER BY
-			orderBySQL = tieBreaker
+	// Search results are ranked, best match first, unless ordered explicitly.
+	if searchRank != "" && !hasExplicitOrderBy {
+		orderBySQL = searchRank + " DESC"
+	}
+
+	// Aggregate queries return groups: there is no row identity to page by,
+	// and selecting the tie-breaker would break GROUP BY. Search results are
+	// capped by LIMIT rather than paged.
+	paginated := !ctx.aggregated && !validated.AST.IsRow && validated.AST.Search == nil
+
+	if !ctx.aggregated {
+		// For keyset pagination, ensure we have a tie-breaker
+		// Add id as tie-breaker if not already present
+		tieBreaker := c.ensureTieBreaker(ctx, validated.RootObject)
+		if tieBreaker != "" {
+			if orderBySQL != "" {
+				orderBySQL += ", " + tieBreaker
+			} else {
+				// Default order: use tie-breaker as the only ORDER BY
+				orderBySQL = tieBreaker
+			}
+		}
+
+		// Add keyset fields to SELECT for cursor building
+		// These fields are needed even if not explicitly selected
+		keysetSelectSQL := c.ensureKeysetFieldsInSelect(ctx)
+		if keysetSelectSQL != "" {
+			selectSQL += ", " + keysetSelectSQL
 		}
 	}
 
-	// Add keyset fields to SELECT for cursor building
-	// These fields are needed even if not explicitly selected
-	keysetSelectSQL := c.ensureKeysetFieldsInSelect(ctx)
-	if keysetSelectSQL != "" {
-		selectSQL += ", " + keysetSelectSQL
+	// Keyset predicate for subsequent pages; resolved by ApplyKeyset.
+	var keysetPlaceholder string
+	if paginated {
+		keysetPlaceholder = fmt.Sprintf("{{keyset:%s}}", ctx.rlsNonce)
+		whereSQL += " AND " + keysetPlaceholder
 	}
 
 	// Build LIMIT (OFFSET is not used with keyset pagination)
@@ -258,24 +350,41 @@ func (c *Compiler) Compile(validated *ValidatedQuery) (*CompiledQuery, error) {
 	}
 
 	// Build pagination info for keyset cursor
-	pagination := c.buildPaginationInfo(ctx, validated.RootObject.Name, hasExplicitOrderBy, limit)
+	var pagination *PaginationInfo
+	if paginated {
+		pagination = c.buildPaginationInfo(ctx, validated.RootObject.Name, hasExplicitOrderBy, limit)
+		pagination.Placeholder = keysetPlaceholder
+	}
 
 	return &CompiledQuery{
 		SQL:                  sql.String(),
 		Params:               ctx.params,
 		DateParams:           ctx.dateParams,
+		BindParams:           ctx.bindParams,
 		Shape:                ctx.shape,
 		Pagination:           pagination,
 		Dependencies:         c.collectDependencies(validated),
 		ForUpdate:            validated.AST.ForUpdate,
 		WithSecurityEnforced: validated.AST.WithSecurityEnforced,
 		IsRow:                validated.AST.IsRow,
+		RLSTargets:           ctx.rlsTargets,
 	}, nil
 }
 
+// compileSearch binds the text search query and returns the match predicate
+// and the rank expression for the root object's search vector.
+func (c *Compiler) compileSearch(ctx *compileContext, search *SearchClause) (string, string) {
+	ctx.paramCount++
+	ctx.params = append(ctx.params, search.TSQuery)
+
+	column := qualifiedColumn(ctx.mainAlias, ctx.validated.RootObject.SearchColumn)
+	tsQuery := fmt.Sprintf("to_tsquery('simple', $%d)", ctx.paramCount)
+	return column + " @@ " + tsQuery, "ts_rank(" + column + ", " + tsQuery + ")"
+}
+
 // collectDependencies extracts all object API names that the query depends on.
 // This includes the root object, objects from lookups (joins), objects from subqueries,
-// and objects from WHERE subqueries.
+// objects from WHERE subqueries and EXISTS filters, and TYPEOF targets.
 func (c *Compiler) collectDependencies(validated *ValidatedQuery) []string {
 	deps := make(map[string]struct{})
 
@@ -307,6 +416,22 @@ func (c *Compiler) collectDependencies(validated *ValidatedQuery) []string {
 		}
 	}
 
+	// Objects from EXISTS filters
+	for _, ex := range validated.ExistsFilters {
+		if ex.ChildObject != nil {
+			deps[ex.ChildObject.Name] = struct{}{}
+		}
+	}
+
+	// Objects a polymorphic field may point to
+	for _, typeof := range validated.TypeofExpressions {
+		for _, when := range typeof.WhenClauses {
+			if when.Object != nil {
+				deps[when.Object.Name] = struct{}{}
+			}
+		}
+	}
+
 	// Convert map to slice
 	result := make([]string, 0, len(deps))
 	for dep := range deps {
@@ -339,6 +464,7 @@ func (c *Compiler) ensureTieBreaker(ctx *compileContext, rootObject *ObjectMeta)
 		SQLColumn:  tieBreaker,
 		TableAlias: ctx.mainAlias,
 		Direction:  direction,
+		NullsFirst: direction == "desc",
 	})
 
 	// Return SQL expression
@@ -351,42 +477,49 @@ func (c *Compiler) ensureTieBreaker(ctx *compileContext, rootObject *ObjectMeta)
 
 // ensureKeysetFieldsInSelect adds keyset pagination fields to SELECT if not already present.
 // These fields are needed for cursor building even if not explicitly selected by user.
+// Each keyset field records the SELECT alias its value is returned under.
 func (c *Compiler) ensureKeysetFieldsInSelect(ctx *compileContext) string {
 	if len(ctx.keysetFields) == 0 {
 		return ""
 	}
 
-	// Build a set of already selected column names (by SQL column name)
-	selectedCols := make(map[string]bool)
+	// Index already selected plain columns by their SQL expression
+	selectedCols := make(map[string]string)
+	usedAliases := make(map[string]bool)
 	for _, f := range ctx.shape.Fields {
-		selectedCols[f.Column] = true
-		// Also check the alias in case it matches the SOQL name
-		if f.Alias != "" {
-			selectedCols[f.Alias] = true
-		}
-		if f.Name != "" {
-			selectedCols[f.Name] = true
+		if _, ok := selectedCols[f.Column]; !ok {
+			selectedCols[f.Column] = f.Alias
 		}
+		usedAliases[strings.ToLower(f.Alias)] = true
 	}
 
 	var parts []string
 	for _, kf := range ctx.keysetFields {
 		// Check if this keyset field is already in SELECT
-		fullColumn := qualifiedColumn(kf.TableAlias, kf.SQLColumn)
-		if selectedCols[kf.SQLColumn] || selectedCols[fullColumn] || selectedCols[kf.SOQLName] {
+		fullColumn := kf.sortExpr()
+		if alias, ok := selectedCols[fullColumn]; ok {
+			kf.ResultAlias = alias
 			continue
 		}
 
-		// Add to SELECT with SOQL name as alias
-		part := fullColumn + " AS " + kf.SOQLName
+		// Add to SELECT with SOQL name as alias (lookup paths use "_" like natural aliases)
+		alias := strings.ReplaceAll(kf.SOQLName, ".", "_")
+		if usedAliases[strings.ToLower(alias)] {
+			alias = fmt.Sprintf("keyset_%d", len(parts))
+		}
+		usedAliases[strings.ToLower(alias)] = true
+		selectedCols[fullColumn] = alias
+		kf.ResultAlias = alias
+
+		part := fullColumn + " AS " + alias
 		parts = append(parts, part)
 
 		// Add to shape for result parsing
 		ctx.shape.Fields = append(ctx.shape.Fields, &FieldShape{
-			Name:   kf.SOQLName,
+			Name:   alias,
 			Column: kf.SQLColumn,
 			Type:   FieldTypeID, // Keyset fields are typically IDs or sortable types
-			Alias:  kf.SOQLName,
+			Alias:  alias,
 		})
 	}
 
@@ -426,6 +559,8 @@ func (c *Compiler) buildPaginationInfo(ctx *compileContext, objectName string, h
 		PageSize:    pageSize,
 		HasOrderBy:  hasOrderBy,
 		Object:      objectName,
+		Fields:      ctx.keysetFields,
+		Limit:       pageSize,
 	}
 }
 
@@ -479,6 +614,9 @@ func (c *Compiler) compileSelectExpression(ctx *compileContext, sel *SelectExpre
 	case item.Subquery != nil:
 		expr, err = c.compileSubquery(ctx, item.Subquery)
 		fieldType = FieldTypeArray
+		if item.Subquery.IsAggregate() {
+			fieldType = FieldTypeObject
+		}
 
 	case item.Expr != nil:
 		expr, err = c.compileExpression(ctx, item.Expr)
@@ -503,11 +641,34 @@ func (c *Compiler) compileSelectExpression(ctx *compileContext, sel *SelectExpre
 		Column: expr,
 		Type:   fieldType,
 		Alias:  *alias,
+		Label:  selectLabel(ctx, item),
 	})
 
 	return fmt.Sprintf("%s AS %s", expr, *alias), nil
 }
 
+// selectLabel returns the label of a SELECT item that is a field or the
+// label of one, in the query locale.
+func selectLabel(ctx *compileContext, item *SelectItem) string {
+	primary := item.Expr.primary()
+	var field *Field
+	switch {
+	case primary == nil:
+		return ""
+	case primary.Field != nil:
+		field = primary.Field
+	case primary.ToLabel != nil:
+		field = primary.ToLabel.Field
+	default:
+		return ""
+	}
+	ref, ok := ctx.validated.ResolvedRefs[strings.Join(field.Path, ".")]
+	if !ok {
+		return ""
+	}
+	return ref.Field.LocalizedLabel(ctx.validated.Locale)
+}
+
 // extractNaturalAlias extracts a meaningful alias from a SelectExpression.
 // For simple fields: uses field name (e.g., "name" → "name")
 // For lookup fields: joins path with underscore (e.g., "Account.Name" → "Account_Name")
@@ -643,31 +804,56 @@ func extractFieldNameFromPrimary(primary *Primary) string {
 		return primary.FuncCall.Name.String()
 	}
 
+	// Date function → FUNC_field
+	if primary.DateFunc != nil {
+		return primary.DateFunc.Name.String() + "_" + strings.Join(primary.DateFunc.Field.Path, "_")
+	}
+
+	// toLabel → field path, as the label stands for the value
+	if primary.ToLabel != nil {
+		return strings.Join(primary.ToLabel.Field.Path, "_")
+	}
+
+	// GROUPING → GROUPING_item
+	if primary.Grouping != nil {
+		item := primary.Grouping.Item
+		if item.DateFunc != nil {
+			return "GROUPING_" + item.DateFunc.Name.String() + "_" + strings.Join(item.DateFunc.Field.Path, "_")
+		}
+		return "GROUPING_" + strings.Join(item.Field, "_")
+	}
+
 	return ""
 }
 
 // compileAggregate compiles an aggregate expression.
 func (c *Compiler) compileAggregate(ctx *compileContext, agg *AggregateExpression) (string, error) {
+	ctx.aggregated = true
+
 	inner, err := c.compileExpression(ctx, agg.Expression)
 	if err != nil {
 		return "", err
 	}
+	return aggregateSQL(agg.Function, inner), nil
+}
 
-	switch agg.Function {
+// aggregateSQL applies an aggregate function to a compiled argument.
+func aggregateSQL(fn Aggregate, inner string) string {
+	switch fn {
 	case AggregateCount:
-		return fmt.Sprintf("COUNT(%s)", inner), nil
+		return fmt.Sprintf("COUNT(%s)", inner)
 	case AggregateCountDistinct:
-		return fmt.Sprintf("COUNT(DISTINCT %s)", inner), nil
+		return fmt.Sprintf("COUNT(DISTINCT %s)", inner)
 	case AggregateSum:
-		return fmt.Sprintf("SUM(%s)", inner), nil
+		return fmt.Sprintf("SUM(%s)", inner)
 	case AggregateAvg:
-		return fmt.Sprintf("AVG(%s)", inner), nil
+		return fmt.Sprintf("AVG(%s)", inner)
 	case AggregateMin:
-		return fmt.Sprintf("MIN(%s)", inner), nil
+		return fmt.Sprintf("MIN(%s)", inner)
 	case AggregateMax:
-		return fmt.Sprintf("MAX(%s)", inner), nil
+		return fmt.Sprintf("MAX(%s)", inner)
 	default:
-		return fmt.Sprintf("COUNT(%s)", inner), nil
+		return fmt.Sprintf("COUNT(%s)", inner)
 	}
 }
 
@@ -786,10 +972,11 @@ func (c *Compiler) getOrCreateTypeofJoin(ctx *compileContext, polyFieldName, obj
 	typeColumn := baseColumn + "_type"
 
 	// Polymorphic join: LEFT JOIN on both id and type match
-	joinSQL := fmt.Sprintf("LEFT JOIN %s AS %s ON %s = %s AND %s = '%s'",
+	joinSQL := fmt.Sprintf("LEFT JOIN %s AS %s ON %s = %s AND %s = '%s' AND %s",
 		obj.QualifiedTableName(), alias,
 		qualifiedColumn(ctx.mainAlias, idColumn), qualifiedColumn(alias, "id"),
-		qualifiedColumn(ctx.mainAlias, typeColumn), objectType)
+		qualifiedColumn(ctx.mainAlias, typeColumn), objectType,
+		c.addRLSTarget(ctx, RLSTargetLookup, obj, alias))
 
 	ctx.joinSQL = append(ctx.joinSQL, joinSQL)
 
@@ -809,6 +996,9 @@ func (c *Compiler) compileSubquery(ctx *compileContext, sub *RelationshipSubquer
 	if validatedSub == nil {
 		return "", fmt.Errorf("subquery not validated: %s", sub.From)
 	}
+	if sub.IsAggregate() {
+		return c.compileAggregateSubquery(ctx, validatedSub)
+	}
 
 	// Create nested shape
 	nestedShape := &ResultShape{
@@ -831,9 +1021,13 @@ func (c *Compiler) compileSubquery(ctx *compileContext, sub *RelationshipSubquer
 			return "", err
 		}
 
+		// Plain fields keep their name; other expressions get a positional key.
 		alias := sel.Alias
 		if alias == nil {
 			defaultAlias := fmt.Sprintf("f%d", i)
+			if p := sel.Item.Expr.primary(); p != nil && p.Field != nil {
+				defaultAlias = strings.Join(p.Field.Path, ".")
+			}
 			alias = &defaultAlias
 		}
 
@@ -880,6 +1074,8 @@ func (c *Compiler) compileSubquery(ctx *compileContext, sub *RelationshipSubquer
 	sql.WriteString(qualifiedColumn(childAlias, validatedSub.Relationship.ChildField))
 	sql.WriteString(" = ")
 	sql.WriteString(qualifiedColumn(ctx.mainAlias, validatedSub.Relationship.ParentField))
+	sql.WriteString(" AND ")
+	sql.WriteString(c.addRLSTarget(ctx, RLSTargetSubquery, validatedSub.ChildObject, childAlias))
 
 	// Add WHERE condition if present
 	if sub.Where != nil {
@@ -908,6 +1104,90 @@ func (c *Compiler) compileSubquery(ctx *compileContext, sub *RelationshipSubquer
 	return sql.String(), nil
 }
 
+// compileAggregateSubquery compiles an aggregate relationship subquery to a
+// LATERAL join that returns one JSON object of the aggregates per parent:
+//
+//	JOIN LATERAL (SELECT json_build_object('COUNT_Id', COUNT(sq."id")) AS value
+//	    FROM "deals" AS sq WHERE sq."account_id" = t0."id" AND <rls>) AS sa0 ON TRUE
+//
+// Without GROUP BY the lateral subquery yields exactly one row, so every
+// parent is kept; a HAVING clause that rejects the row drops the parent.
+func (c *Compiler) compileAggregateSubquery(ctx *compileContext, validatedSub *ValidatedSubquery) (string, error) {
+	sub := validatedSub.AST
+	child := validatedSub.ChildObject
+	childAlias := "sq"
+	lateralAlias := fmt.Sprintf("sa%d", ctx.lateralCount)
+	ctx.lateralCount++
+
+	nestedShape := &ResultShape{
+		Object: child.Name,
+		Table:  child.QualifiedTableName(),
+	}
+
+	var selectParts []string
+	for _, sel := range sub.Select {
+		agg := sel.Item.Aggregate
+		inner, err := c.compileSubqueryField(child, childAlias, agg.Expression)
+		if err != nil {
+			return "", err
+		}
+
+		alias := extractNaturalAlias(sel)
+		if sel.Alias != nil {
+			alias = *sel.Alias
+		}
+		selectParts = append(selectParts, fmt.Sprintf("'%s', %s", alias, aggregateSQL(agg.Function, inner)))
+		nestedShape.Fields = append(nestedShape.Fields, &FieldShape{
+			Name:  alias,
+			Type:  agg.FieldType,
+			Alias: alias,
+		})
+	}
+
+	var sql strings.Builder
+	sql.WriteString("JOIN LATERAL (SELECT json_build_object(")
+	sql.WriteString(strings.Join(selectParts, ", "))
+	sql.WriteString(") AS value FROM ")
+	sql.WriteString(child.QualifiedTableName())
+	sql.WriteString(" AS ")
+	sql.WriteString(childAlias)
+	sql.WriteString(" WHERE ")
+	sql.WriteString(qualifiedColumn(childAlias, validatedSub.Relationship.ChildField))
+	sql.WriteString(" = ")
+	sql.WriteString(qualifiedColumn(ctx.mainAlias, validatedSub.Relationship.ParentField))
+	sql.WriteString(" AND ")
+	sql.WriteString(c.addRLSTarget(ctx, RLSTargetSubquery, child, childAlias))
+
+	if sub.Where != nil {
+		whereSQL, err := c.compileSubqueryExpression(ctx, child, childAlias, sub.Where)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(" AND ")
+		sql.WriteString(whereSQL)
+	}
+
+	if sub.Having != nil {
+		havingSQL, err := c.compileSubqueryExpression(ctx, child, childAlias, sub.Having)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(" HAVING ")
+		sql.WriteString(havingSQL)
+	}
+
+	fmt.Fprintf(&sql, ") AS %s ON TRUE", lateralAlias)
+	ctx.joinSQL = append(ctx.joinSQL, sql.String())
+
+	ctx.shape.Relationships = append(ctx.shape.Relationships, &RelationshipShape{
+		Name:      sub.From,
+		Shape:     nestedShape,
+		Aggregate: true,
+	})
+
+	return lateralAlias + ".value", nil
+}
+
 // compileSubqueryField compiles a field expression within a subquery context.
 func (c *Compiler) compileSubqueryField(obj *ObjectMeta, alias string, expr *Expression) (string, error) {
 	if expr == nil || expr.Or == nil {
@@ -982,8 +1262,29 @@ func (c *Compiler) compileSubqueryInExpr(obj *ObjectMeta, alias string, in *InEx
 	if err != nil {
 		return "", err
 	}
-	// Simplified - not handling IN clause in subquery for now
-	return left, nil
+
+	if !in.In {
+		return left, nil
+	}
+	if len(in.Values) == 0 {
+		return "", fmt.Errorf("only value lists are supported by IN in subqueries")
+	}
+
+	op := "IN"
+	if in.Not {
+		op = "NOT IN"
+	}
+
+	values := make([]string, 0, len(in.Values))
+	for _, val := range in.Values {
+		v, err := c.compileSubqueryValue(obj, alias, val)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, v)
+	}
+
+	return fmt.Sprintf("%s %s (%s)", left, op, strings.Join(values, ", ")), nil
 }
 
 func (c *Compiler) compileSubqueryLikeExpr(obj *ObjectMeta, alias string, like *LikeExpr) (string, error) {
@@ -991,8 +1292,35 @@ func (c *Compiler) compileSubqueryLikeExpr(obj *ObjectMeta, alias string, like *
 	if err != nil {
 		return "", err
 	}
-	// Simplified - not handling LIKE in subquery for now
-	return left, nil
+
+	if !like.Like || like.Pattern == nil {
+		return left, nil
+	}
+
+	pattern, err := c.compileSubqueryValue(obj, alias, like.Pattern)
+	if err != nil {
+		return "", err
+	}
+
+	op := "LIKE"
+	if like.Not {
+		op = "NOT LIKE"
+	}
+
+	return fmt.Sprintf("%s %s %s", left, op, pattern), nil
+}
+
+func (c *Compiler) compileSubqueryValue(obj *ObjectMeta, alias string, val *Value) (string, error) {
+	switch {
+	case val == nil:
+		return "", fmt.Errorf("empty value")
+	case val.Const != nil:
+		return c.compileConstValue(val.Const), nil
+	case val.Field != nil:
+		return c.compileSubqueryFieldRef(obj, alias, val.Field)
+	default:
+		return "", fmt.Errorf("invalid value")
+	}
 }
 
 func (c *Compiler) compileSubqueryIsExpr(obj *ObjectMeta, alias string, is *IsExpr) (string, error) {
@@ -1012,15 +1340,49 @@ func (c *Compiler) compileSubqueryIsExpr(obj *ObjectMeta, alias string, is *IsEx
 }
 
 func (c *Compiler) compileSubqueryAddExpr(obj *ObjectMeta, alias string, add *AddExpr) (string, error) {
-	return c.compileSubqueryMulExpr(obj, alias, add.Left)
+	result, err := c.compileSubqueryMulExpr(obj, alias, add.Left)
+	if err != nil {
+		return "", err
+	}
+
+	for _, op := range add.Right {
+		right, err := c.compileSubqueryMulExpr(obj, alias, op.Right)
+		if err != nil {
+			return "", err
+		}
+		result = fmt.Sprintf("%s %s %s", result, op.Operator.String(), right)
+	}
+
+	return result, nil
 }
 
 func (c *Compiler) compileSubqueryMulExpr(obj *ObjectMeta, alias string, mul *MulExpr) (string, error) {
-	return c.compileSubqueryUnaryExpr(obj, alias, mul.Left)
+	result, err := c.compileSubqueryUnaryExpr(obj, alias, mul.Left)
+	if err != nil {
+		return "", err
+	}
+
+	for _, op := range mul.Right {
+		right, err := c.compileSubqueryUnaryExpr(obj, alias, op.Right)
+		if err != nil {
+			return "", err
+		}
+		result = fmt.Sprintf("%s %s %s", result, op.Operator.String(), right)
+	}
+
+	return result, nil
 }
 
 func (c *Compiler) compileSubqueryUnaryExpr(obj *ObjectMeta, alias string, unary *UnaryExpr) (string, error) {
-	return c.compileSubqueryPrimary(obj, alias, unary.Primary)
+	primary, err := c.compileSubqueryPrimary(obj, alias, unary.Primary)
+	if err != nil {
+		return "", err
+	}
+
+	if unary.Operator != nil {
+		return unary.Operator.String() + primary, nil
+	}
+	return primary, nil
 }
 
 func (c *Compiler) compileSubqueryPrimary(obj *ObjectMeta, alias string, primary *Primary) (string, error) {
@@ -1033,6 +1395,12 @@ func (c *Compiler) compileSubqueryPrimary(obj *ObjectMeta, alias string, primary
 		return c.compileSubqueryFieldRef(obj, alias, primary.Field)
 	case primary.Const != nil:
 		return c.compileConstValue(primary.Const), nil
+	case primary.Aggregate != nil:
+		inner, err := c.compileSubqueryField(obj, alias, primary.Aggregate.Expression)
+		if err != nil {
+			return "", err
+		}
+		return aggregateSQL(primary.Aggregate.Function, inner), nil
 	case primary.Subexpression != nil:
 		inner, err := c.compileSubqueryField(obj, alias, primary.Subexpression)
 		if err != nil {
@@ -1201,6 +1569,15 @@ func (c *Compiler) compileInExpr(ctx *compileContext, in *InExpr) (string, error
 		return fmt.Sprintf("%s %s %s", left, op, subSQL), nil
 	}
 
+	// A list bind is passed as a single array parameter
+	if in.ListBind != nil {
+		param := c.compileBind(ctx, in.ListBind)
+		if in.Not {
+			return fmt.Sprintf("%s <> ALL(%s)", left, param), nil
+		}
+		return fmt.Sprintf("%s = ANY(%s)", left, param), nil
+	}
+
 	// Compile IN values
 	var values []string
 	for _, val := range in.Values {
@@ -1238,13 +1615,15 @@ func (c *Compiler) compileWhereSubquery(ctx *compileContext, sub *WhereSubquery)
 	sql.WriteString(validated.Object.QualifiedTableName())
 	sql.WriteString(" AS ")
 	sql.WriteString(alias)
+	sql.WriteString(" WHERE ")
+	sql.WriteString(c.addRLSTarget(ctx, RLSTargetWhereSubquery, validated.Object, alias))
 
 	if sub.Where != nil {
 		whereSQL, err := c.compileSubqueryField(validated.Object, alias, sub.Where)
 		if err != nil {
 			return "", err
 		}
-		sql.WriteString(" WHERE ")
+		sql.WriteString(" AND ")
 		sql.WriteString(whereSQL)
 	}
 
@@ -1256,6 +1635,48 @@ func (c *Compiler) compileWhereSubquery(ctx *compileContext, sub *WhereSubquery)
 	return sql.String(), nil
 }
 
+// compileExists compiles an EXISTS relationship filter to a correlated
+// EXISTS subquery over the child records visible to the user.
+func (c *Compiler) compileExists(ctx *compileContext, ex *ExistsExpression) (string, error) {
+	var validated *ValidatedExists
+	for _, ve := range ctx.validated.ExistsFilters {
+		if ve.AST == ex {
+			validated = ve
+			break
+		}
+	}
+	if validated == nil {
+		return "", fmt.Errorf("EXISTS not validated: %s", ex.Relationship)
+	}
+
+	alias := "wsq"
+
+	// Build: EXISTS (SELECT 1 FROM child AS wsq WHERE wsq.fk = t0.pk AND ...)
+	var sql strings.Builder
+	sql.WriteString("EXISTS (SELECT 1 FROM ")
+	sql.WriteString(validated.ChildObject.QualifiedTableName())
+	sql.WriteString(" AS ")
+	sql.WriteString(alias)
+	sql.WriteString(" WHERE ")
+	sql.WriteString(qualifiedColumn(alias, validated.Relationship.ChildField))
+	sql.WriteString(" = ")
+	sql.WriteString(qualifiedColumn(ctx.mainAlias, validated.Relationship.ParentField))
+	sql.WriteString(" AND ")
+	sql.WriteString(c.addRLSTarget(ctx, RLSTargetWhereSubquery, validated.ChildObject, alias))
+
+	if ex.Where != nil {
+		whereSQL, err := c.compileSubqueryField(validated.ChildObject, alias, ex.Where)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(" AND ")
+		sql.WriteString(whereSQL)
+	}
+
+	sql.WriteString(")")
+	return sql.String(), nil
+}
+
 func (c *Compiler) compileLikeExpr(ctx *compileContext, like *LikeExpr) (string, error) {
 	left, err := c.compileIsExpr(ctx, like.Left)
 	if err != nil {
@@ -1360,6 +1781,22 @@ func (c *Compiler) compilePrimary(ctx *compileContext, primary *Primary) (string
 	case primary.FuncCall != nil:
 		return c.compileFuncCall(ctx, primary.FuncCall)
 
+	case primary.DateFunc != nil:
+		return c.compileDateFunction(ctx, primary.DateFunc)
+
+	case primary.ToLabel != nil:
+		return c.compileToLabel(ctx, primary.ToLabel)
+
+	case primary.Grouping != nil:
+		item, err := c.compileGroupItem(ctx, primary.Grouping.Item)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("GROUPING(%s)", item), nil
+
+	case primary.Exists != nil:
+		return c.compileExists(ctx, primary.Exists)
+
 	case primary.Const != nil:
 		return c.compileConst(ctx, primary.Const), nil
 
@@ -1429,8 +1866,101 @@ func (c *Compiler) compileFuncCall(ctx *compileContext, fn *FuncCall) (string, e
 	}
 }
 
+// compileDateFunction compiles a date function. Datetime values are bucketed
+// in the user's time zone; it and the fiscal year start are parameters
+// resolved per request, so the compiled SQL can be cached across users.
+func (c *Compiler) compileDateFunction(ctx *compileContext, fn *DateFunctionCall) (string, error) {
+	value, err := c.compileField(ctx, fn.Field)
+	if err != nil {
+		return "", err
+	}
+	if fn.Field.FieldType == FieldTypeDateTime {
+		value = fmt.Sprintf("(%s AT TIME ZONE %s)", value, c.calendarParam(ctx, CalendarTimeZone))
+	}
+
+	switch fn.Name {
+	case DateFuncCalendarMonth:
+		return fmt.Sprintf("EXTRACT(MONTH FROM %s)::int", value), nil
+	case DateFuncCalendarQuarter:
+		return fmt.Sprintf("EXTRACT(QUARTER FROM %s)::int", value), nil
+	case DateFuncCalendarYear:
+		return fmt.Sprintf("EXTRACT(YEAR FROM %s)::int", value), nil
+	case DateFuncFiscalQuarter:
+		// Months since the fiscal year start, in whole quarters
+		return fmt.Sprintf("((EXTRACT(MONTH FROM %s)::int + 12 - %s) %% 12 / 3 + 1)",
+			value, c.calendarParam(ctx, CalendarFiscalYearStart)), nil
+	case DateFuncDayOnly:
+		return value + "::date", nil
+	case DateFuncWeekInYear:
+		// Week 1 is January 1-7, as opposed to the ISO week
+		return fmt.Sprintf("((EXTRACT(DOY FROM %s)::int - 1) / 7 + 1)", value), nil
+	case DateFuncHourInDay:
+		return fmt.Sprintf("EXTRACT(HOUR FROM %s)::int", value), nil
+	default:
+		return "", fmt.Errorf("unsupported date function: %s", fn.Name)
+	}
+}
+
+// compileToLabel compiles toLabel() to a lookup of the value in two array
+// parameters: the picklist values and their labels in the query locale.
+// Each value of a multi-select picklist is looked up in turn.
+func (c *Compiler) compileToLabel(ctx *compileContext, fn *ToLabelCall) (string, error) {
+	key := strings.Join(fn.Field.Path, ".")
+	if expr, ok := ctx.labelExprs[key]; ok {
+		return expr, nil
+	}
+
+	column, err := c.compileField(ctx, fn.Field)
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]string, len(fn.Picklist.Values))
+	labels := make([]string, len(fn.Picklist.Values))
+	for i, v := range fn.Picklist.Values {
+		values[i] = v.Value
+		labels[i] = v.LocalizedLabel(ctx.validated.Locale)
+	}
+	ctx.params = append(ctx.params, values, labels)
+	ctx.paramCount += 2
+	valuesParam, labelsParam := ctx.paramCount-1, ctx.paramCount
+
+	label := func(value string) string {
+		return fmt.Sprintf("COALESCE(($%d::text[])[array_position($%d::text[], %s::text)], %s)",
+			labelsParam, valuesParam, value, value)
+	}
+
+	expr := label(column)
+	if fn.Picklist.Multi {
+		expr = fmt.Sprintf("ARRAY(SELECT %s FROM unnest(%s) WITH ORDINALITY AS l(v, n) ORDER BY l.n)", label("l.v"), column)
+	}
+	ctx.labelExprs[key] = expr
+	return expr, nil
+}
+
+// calendarParam returns the placeholder of a calendar setting, adding the
+// parameter on first use.
+func (c *Compiler) calendarParam(ctx *compileContext, setting CalendarSetting) string {
+	idx, ok := ctx.calendarParams[setting]
+	if !ok {
+		ctx.paramCount++
+		idx = ctx.paramCount
+		ctx.params = append(ctx.params, nil) // Placeholder
+		ctx.dateParams = append(ctx.dateParams, &DateParam{ParamIndex: idx, Calendar: setting})
+		ctx.calendarParams[setting] = idx
+	}
+	if setting == CalendarFiscalYearStart {
+		return fmt.Sprintf("$%d::int", idx)
+	}
+	return fmt.Sprintf("$%d::text", idx)
+}
+
 // compileConst compiles a constant value, adding parameters as needed.
 func (c *Compiler) compileConst(ctx *compileContext, cnst *Const) string {
+	if cnst.Bind != nil {
+		return c.compileBind(ctx, cnst.Bind)
+	}
+
 	// Handle date literals specially - they need runtime resolution
 	if cnst.StaticDate != nil || cnst.DynamicDate != nil {
 		ctx.paramCount++
@@ -1454,6 +1984,18 @@ func (c *Compiler) compileConst(ctx *compileContext, cnst *Const) string {
 	return c.compileConstValue(cnst)
 }
 
+// compileBind adds a placeholder parameter for a bind variable.
+func (c *Compiler) compileBind(ctx *compileContext, bind *Bind) string {
+	ctx.paramCount++
+	ctx.params = append(ctx.params, nil) // Placeholder
+	ctx.bindParams = append(ctx.bindParams, &BindParam{
+		ParamIndex: ctx.paramCount,
+		Name:       bind.Name,
+		Type:       bind.FieldType,
+	})
+	return fmt.Sprintf("$%d", ctx.paramCount)
+}
+
 // compileConstValue returns the SQL representation of a constant.
 func (c *Compiler) compileConstValue(cnst *Const) string {
 	switch {
@@ -1552,12 +2094,14 @@ func (c *Compiler) ensureJoin(ctx *compileContext, joins []*Join) string {
 			toColumn = &FieldMeta{Column: join.ToField}
 		}
 
-		// Build JOIN SQL
-		joinSQL := fmt.Sprintf("LEFT JOIN %s AS %s ON %s = %s",
+		// Build JOIN SQL. The RLS predicate lives in the ON clause so that a
+		// parent the user cannot see is nulled out instead of hiding the row.
+		joinSQL := fmt.Sprintf("LEFT JOIN %s AS %s ON %s = %s AND %s",
 			join.ToObject.QualifiedTableName(),
 			newAlias,
 			qualifiedColumn(prevAlias, fromColumn.Column),
 			qualifiedColumn(newAlias, toColumn.Column),
+			c.addRLSTarget(ctx, RLSTargetLookup, join.ToObject, newAlias),
 		)
 
 		ctx.joinSQL = append(ctx.joinSQL, joinSQL)
@@ -1572,26 +2116,53 @@ func (c *Compiler) compileGroupBy(ctx *compileContext, groups []*GroupClause) (s
 	var parts []string
 
 	for _, group := range groups {
-		pathKey := strings.Join(group.Field, ".")
-
-		if ref, ok := ctx.validated.ResolvedRefs[pathKey]; ok {
-			if len(ref.Joins) > 0 {
-				alias := c.ensureJoin(ctx, ref.Joins)
-				parts = append(parts, qualifiedColumn(alias, ref.Field.Column))
-			} else {
-				parts = append(parts, qualifiedColumn(ctx.mainAlias, ref.Field.Column))
+		if group.Set == "" {
+			part, err := c.compileGroupItem(ctx, group)
+			if err != nil {
+				return "", err
 			}
-		} else if len(group.Field) == 1 {
-			fieldMeta := ctx.validated.RootObject.GetField(group.Field[0])
-			if fieldMeta != nil {
-				parts = append(parts, qualifiedColumn(ctx.mainAlias, fieldMeta.Column))
+			if part != "" {
+				parts = append(parts, part)
+			}
+			continue
+		}
+
+		items := make([]string, 0, len(group.Items))
+		for _, item := range group.Items {
+			part, err := c.compileGroupItem(ctx, item)
+			if err != nil {
+				return "", err
 			}
+			items = append(items, part)
 		}
+		parts = append(parts, fmt.Sprintf("%s(%s)", group.Set, strings.Join(items, ", ")))
 	}
 
 	return strings.Join(parts, ", "), nil
 }
 
+// compileGroupItem compiles a GROUP BY field or date function.
+func (c *Compiler) compileGroupItem(ctx *compileContext, group *GroupClause) (string, error) {
+	if group.DateFunc != nil {
+		return c.compileDateFunction(ctx, group.DateFunc)
+	}
+
+	pathKey := strings.Join(group.Field, ".")
+	if ref, ok := ctx.validated.ResolvedRefs[pathKey]; ok {
+		if len(ref.Joins) > 0 {
+			alias := c.ensureJoin(ctx, ref.Joins)
+			return qualifiedColumn(alias, ref.Field.Column), nil
+		}
+		return qualifiedColumn(ctx.mainAlias, ref.Field.Column), nil
+	}
+	if len(group.Field) == 1 {
+		if fieldMeta := ctx.validated.RootObject.GetField(group.Field[0]); fieldMeta != nil {
+			return qualifiedColumn(ctx.mainAlias, fieldMeta.Column), nil
+		}
+	}
+	return "", nil
+}
+
 // compileOrderBy compiles the ORDER BY clause and tracks keyset fields.
 func (c *Compiler) compileOrderBy(ctx *compileContext, orders []*OrderClause) (string, error) {
 	var parts []string
@@ -1605,6 +2176,7 @@ func (c *Compiler) compileOrderBy(ctx *compileContext, orders []*OrderClause) (s
 		var tableAlias string
 		var columnName string
 		var soqlName string
+		var sortExpr string
 		var err error
 
 		if order.OrderItem.Aggregate != nil {
@@ -1613,6 +2185,26 @@ func (c *Compiler) compileOrderBy(ctx *compileContext, orders []*OrderClause) (s
 			if err != nil {
 				return "", err
 			}
+		} else if order.OrderItem.DateFunc != nil {
+			// Date functions order groups, which are not paginated
+			fieldExpr, err = c.compileDateFunction(ctx, order.OrderItem.DateFunc)
+			if err != nil {
+				return "", err
+			}
+		} else if fn := order.OrderItem.ToLabel; fn != nil {
+			// Pages are keyed by the label, which is selected for the cursor
+			fieldExpr, err = c.compileToLabel(ctx, fn)
+			if err != nil {
+				return "", err
+			}
+			ref := ctx.validated.ResolvedRefs[strings.Join(fn.Field.Path, ".")]
+			soqlName = strings.Join(fn.Field.Path, ".")
+			tableAlias = ctx.mainAlias
+			if len(ref.Joins) > 0 {
+				tableAlias = c.ensureJoin(ctx, ref.Joins)
+			}
+			columnName = ref.Field.Column
+			sortExpr = fieldExpr
 		} else if len(order.OrderItem.Field) > 0 {
 			pathKey := strings.Join(order.OrderItem.Field, ".")
 			soqlName = pathKey
@@ -1648,8 +2240,11 @@ func (c *Compiler) compileOrderBy(ctx *compileContext, orders []*OrderClause) (s
 			direction = "desc"
 			part += " DESC"
 		}
+		// PostgreSQL sorts NULLs as larger than any value by default
+		nullsFirst := direction == "desc"
 		if order.Nulls != nil && *order.Nulls != NullsDefault {
 			part += " " + order.Nulls.String()
+			nullsFirst = *order.Nulls == NullsFirst
 		}
 
 		parts = append(parts, part)
@@ -1660,7 +2255,9 @@ func (c *Compiler) compileOrderBy(ctx *compileContext, orders []*OrderClause) (s
 				SOQLName:   soqlName,
 				SQLColumn:  columnName,
 				TableAlias: tableAlias,
+				Expr:       sortExpr,
 				Direction:  direction,
+				NullsFirst: nullsFirst,
 			})
 		}
 	}
@@ -2,6 +2,9 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -271,6 +274,438 @@ func TestCompileGroupBy(t *testing.T) {
 	}
 }
 
+func TestCompileDateFunctions(t *testing.T) {
+	metadata := setupTestMetadata()
+	validator := NewValidator(metadata, nil, nil)
+	compiler := NewCompiler(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		query        string
+		wantSQL      []string
+		wantCalendar []CalendarSetting
+		wantShape    string
+	}{
+		{
+			name:  "datetime is bucketed in the user time zone",
+			query: "SELECT CALENDAR_MONTH(CreatedDate), COUNT(Id) FROM Account GROUP BY CALENDAR_MONTH(CreatedDate) ORDER BY CALENDAR_MONTH(CreatedDate)",
+			wantSQL: []string{
+				`SELECT EXTRACT(MONTH FROM (t0."created_at" AT TIME ZONE $1::text))::int AS CALENDAR_MONTH_CreatedDate`,
+				`GROUP BY EXTRACT(MONTH FROM (t0."created_at" AT TIME ZONE $1::text))::int`,
+				`ORDER BY EXTRACT(MONTH FROM (t0."created_at" AT TIME ZONE $1::text))::int`,
+			},
+			wantCalendar: []CalendarSetting{CalendarTimeZone},
+			wantShape:    "CALENDAR_MONTH_CreatedDate:integer",
+		},
+		{
+			name:         "date is bucketed as is",
+			query:        "SELECT CALENDAR_YEAR(CloseDate) yr, SUM(Amount) FROM Opportunity GROUP BY CALENDAR_YEAR(CloseDate)",
+			wantSQL:      []string{`EXTRACT(YEAR FROM t0."close_date")::int AS yr`},
+			wantCalendar: nil,
+			wantShape:    "yr:integer",
+		},
+		{
+			name:         "fiscal quarter",
+			query:        "SELECT FISCAL_QUARTER(CloseDate), SUM(Amount) FROM Opportunity GROUP BY FISCAL_QUARTER(CloseDate)",
+			wantSQL:      []string{`((EXTRACT(MONTH FROM t0."close_date")::int + 12 - $1::int) % 12 / 3 + 1)`},
+			wantCalendar: []CalendarSetting{CalendarFiscalYearStart},
+			wantShape:    "FISCAL_QUARTER_CloseDate:integer",
+		},
+		{
+			name:         "day only",
+			query:        "SELECT DAY_ONLY(CreatedDate), COUNT(Id) FROM Contact GROUP BY DAY_ONLY(CreatedDate)",
+			wantSQL:      []string{`(t0."created_at" AT TIME ZONE $1::text)::date`},
+			wantCalendar: []CalendarSetting{CalendarTimeZone},
+			wantShape:    "DAY_ONLY_CreatedDate:date",
+		},
+		{
+			name:  "week and hour share the time zone",
+			query: "SELECT WEEK_IN_YEAR(CreatedDate), HOUR_IN_DAY(CreatedDate), COUNT(Id) FROM Contact GROUP BY WEEK_IN_YEAR(CreatedDate), HOUR_IN_DAY(CreatedDate)",
+			wantSQL: []string{
+				`((EXTRACT(DOY FROM (t0."created_at" AT TIME ZONE $1::text))::int - 1) / 7 + 1)`,
+				`EXTRACT(HOUR FROM (t0."created_at" AT TIME ZONE $1::text))::int`,
+			},
+			wantCalendar: []CalendarSetting{CalendarTimeZone},
+			wantShape:    "WEEK_IN_YEAR_CreatedDate:integer",
+		},
+		{
+			name:         "filter by date function",
+			query:        "SELECT Id FROM Opportunity WHERE CALENDAR_QUARTER(CloseDate) = 2",
+			wantSQL:      []string{`EXTRACT(QUARTER FROM t0."close_date")::int = 2`},
+			wantCalendar: nil,
+			wantShape:    "Id:id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			validated, err := validator.Validate(ctx, ast)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			compiled, err := compiler.Compile(validated)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(compiled.SQL, want) {
+					t.Errorf("SQL does not contain expected string\nGot: %s\nWant to contain: %s", compiled.SQL, want)
+				}
+			}
+
+			var calendar []CalendarSetting
+			for _, dp := range compiled.DateParams {
+				calendar = append(calendar, dp.Calendar)
+			}
+			if fmt.Sprint(calendar) != fmt.Sprint(tt.wantCalendar) {
+				t.Errorf("calendar params = %v, want %v", calendar, tt.wantCalendar)
+			}
+
+			first := compiled.Shape.Fields[0]
+			if got := first.Name + ":" + first.Type.String(); got != tt.wantShape {
+				t.Errorf("first field = %s, want %s", got, tt.wantShape)
+			}
+		})
+	}
+}
+
+func TestCompileGroupingSets(t *testing.T) {
+	metadata := setupTestMetadata()
+	validator := NewValidator(metadata, nil, nil)
+	compiler := NewCompiler(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		query   string
+		wantSQL []string
+	}{
+		{
+			name: "rollup with subtotals",
+			query: `SELECT FISCAL_QUARTER(CloseDate), OwnerId, GROUPING(OwnerId) ownerTotal, SUM(Amount)
+				FROM Opportunity WHERE StageName = 'Closed Won'
+				GROUP BY ROLLUP(FISCAL_QUARTER(CloseDate), OwnerId)`,
+			wantSQL: []string{
+				`GROUP BY ROLLUP(((EXTRACT(MONTH FROM t0."close_date")::int + 12 - $1::int) % 12 / 3 + 1), t0."owner_id")`,
+				`GROUPING(t0."owner_id") AS ownerTotal`,
+			},
+		},
+		{
+			name:    "cube",
+			query:   "SELECT StageName, OwnerId, COUNT(Id) FROM Opportunity GROUP BY CUBE(StageName, OwnerId)",
+			wantSQL: []string{`GROUP BY CUBE(t0."stage_name", t0."owner_id")`},
+		},
+		{
+			name:    "grouping set after a plain field",
+			query:   "SELECT AccountId, StageName, COUNT(Id) FROM Opportunity GROUP BY AccountId, ROLLUP(StageName)",
+			wantSQL: []string{`GROUP BY t0."account_id", ROLLUP(t0."stage_name")`},
+		},
+		{
+			name:    "grouping of a date function",
+			query:   "SELECT CALENDAR_YEAR(CloseDate), GROUPING(CALENDAR_YEAR(CloseDate)), COUNT(Id) FROM Opportunity GROUP BY ROLLUP(CALENDAR_YEAR(CloseDate))",
+			wantSQL: []string{`GROUPING(EXTRACT(YEAR FROM t0."close_date")::int) AS GROUPING_CALENDAR_YEAR_CloseDate`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			validated, err := validator.Validate(ctx, ast)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			compiled, err := compiler.Compile(validated)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(compiled.SQL, want) {
+					t.Errorf("SQL does not contain expected string\nGot: %s\nWant to contain: %s", compiled.SQL, want)
+				}
+			}
+			if compiled.Pagination != nil {
+				t.Error("grouped queries must not be paginated")
+			}
+		})
+	}
+}
+
+func TestValidateDateFunctionErrors(t *testing.T) {
+	metadata := setupTestMetadata()
+	validator := NewValidator(metadata, nil, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode ValidationErrorCode
+	}{
+		{
+			name:     "non-date field",
+			query:    "SELECT CALENDAR_YEAR(Name) FROM Account",
+			wantCode: ErrCodeTypeMismatch,
+		},
+		{
+			name:     "hour of a date",
+			query:    "SELECT HOUR_IN_DAY(CloseDate), COUNT(Id) FROM Opportunity GROUP BY HOUR_IN_DAY(CloseDate)",
+			wantCode: ErrCodeTypeMismatch,
+		},
+		{
+			name:     "grouping without group by",
+			query:    "SELECT GROUPING(StageName) FROM Opportunity",
+			wantCode: ErrCodeInvalidAggregation,
+		},
+		{
+			name:     "grouping of a field not grouped by",
+			query:    "SELECT StageName, GROUPING(OwnerId) FROM Opportunity GROUP BY ROLLUP(StageName)",
+			wantCode: ErrCodeInvalidAggregation,
+		},
+		{
+			name:     "nested rollup",
+			query:    "SELECT StageName FROM Opportunity GROUP BY ROLLUP(CUBE(StageName))",
+			wantCode: ErrCodeInvalidExpression,
+		},
+		{
+			name:     "order by date function not grouped by",
+			query:    "SELECT Id FROM Opportunity ORDER BY CALENDAR_YEAR(CloseDate)",
+			wantCode: ErrCodeInvalidExpression,
+		},
+		{
+			name:     "date function in subquery",
+			query:    "SELECT Id, (SELECT CALENDAR_YEAR(CreatedDate) FROM Contacts) FROM Account",
+			wantCode: ErrCodeInvalidExpression,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			_, err = validator.Validate(ctx, ast)
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Code != tt.wantCode {
+				t.Errorf("Validate() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+// setupLabelMetadata returns a Deal object with translated labels and
+// picklist fields.
+func setupLabelMetadata() MetadataProvider {
+	stage := &FieldMeta{
+		Name: "Stage", Column: "stage", Type: FieldTypeString,
+		Nullable: true, Filterable: true, Sortable: true, Groupable: true,
+		Label: "Stage", Translations: map[string]string{"de": "Phase"},
+		Picklist: &PicklistMeta{Values: []*PicklistValueMeta{
+			{Value: "new", Label: "New", Translations: map[string]string{"de": "Neu"}},
+			{Value: "won", Label: "Closed Won", Translations: map[string]string{"de": "Gewonnen", "de_AT": "Abgeschlossen"}},
+		}},
+	}
+	tags := &FieldMeta{
+		Name: "Tags", Column: "tags", Type: FieldTypeString,
+		Nullable: true, Filterable: true, Sortable: true,
+		Picklist: &PicklistMeta{Multi: true, Values: []*PicklistValueMeta{
+			{Value: "hot", Label: "Hot", Translations: map[string]string{"de": "Heiß"}},
+		}},
+	}
+
+	return NewStaticMetadataProvider(map[string]*ObjectMeta{
+		"Deal": NewObjectMeta("Deal", "", "deals").
+			Label("Deal", map[string]string{"de": "Geschäft"}).
+			Field("Id", "id", FieldTypeID).
+			Field("Name", "name", FieldTypeString).
+			FieldFull(stage).
+			FieldFull(tags).
+			Build(),
+	})
+}
+
+func TestCompileToLabel(t *testing.T) {
+	validator := NewValidator(setupLabelMetadata(), nil, nil)
+	compiler := NewCompiler(nil)
+	ctx := context.Background()
+
+	const label = `COALESCE(($2::text[])[array_position($1::text[], t0."stage"::text)], t0."stage")`
+
+	tests := []struct {
+		name       string
+		query      string
+		wantSQL    []string
+		wantLabels []string // labels passed for Stage
+		wantShape  string   // first field as name:label
+		wantObject string
+	}{
+		{
+			name:       "default labels",
+			query:      "SELECT toLabel(Stage) FROM Deal",
+			wantSQL:    []string{label + ` AS Stage`},
+			wantLabels: []string{"New", "Closed Won"},
+			wantShape:  "Stage:Stage",
+			wantObject: "Deal",
+		},
+		{
+			name:       "translated labels",
+			query:      "SELECT Name, toLabel(Stage) FROM Deal WITH LOCALE 'de'",
+			wantSQL:    []string{label + ` AS Stage`},
+			wantLabels: []string{"Neu", "Gewonnen"},
+			wantShape:  "Name:",
+			wantObject: "Geschäft",
+		},
+		{
+			name:       "country falls back to language",
+			query:      "SELECT toLabel(Stage) s FROM Deal WITH LOCALE 'de_AT'",
+			wantSQL:    []string{label + ` AS s`},
+			wantLabels: []string{"Neu", "Abgeschlossen"},
+			wantShape:  "s:Phase",
+			wantObject: "Geschäft",
+		},
+		{
+			name:  "labels are passed once for select, filter and order",
+			query: "SELECT toLabel(Stage) FROM Deal WHERE toLabel(Stage) LIKE 'G%' WITH LOCALE 'de' ORDER BY toLabel(Stage) DESC",
+			wantSQL: []string{
+				`WHERE ` + label + ` LIKE 'G%'`,
+				`ORDER BY ` + label + ` DESC, t0."id" DESC`,
+			},
+			wantLabels: []string{"Neu", "Gewonnen"},
+			wantShape:  "Stage:Phase",
+			wantObject: "Geschäft",
+		},
+		{
+			name:  "multi-select picklist",
+			query: "SELECT toLabel(Tags) FROM Deal WITH LOCALE 'de'",
+			wantSQL: []string{
+				`ARRAY(SELECT COALESCE(($2::text[])[array_position($1::text[], l.v::text)], l.v) FROM unnest(t0."tags") WITH ORDINALITY AS l(v, n) ORDER BY l.n) AS Tags`,
+			},
+			wantShape:  "Tags:",
+			wantObject: "Geschäft",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			validated, err := validator.Validate(ctx, ast)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			compiled, err := compiler.Compile(validated)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(compiled.SQL, want) {
+					t.Errorf("SQL does not contain expected string\nGot: %s\nWant to contain: %s", compiled.SQL, want)
+				}
+			}
+			if tt.wantLabels != nil && fmt.Sprint(compiled.Params[1]) != fmt.Sprint(tt.wantLabels) {
+				t.Errorf("labels = %v, want %v", compiled.Params[1], tt.wantLabels)
+			}
+			first := compiled.Shape.Fields[0]
+			if got := first.Name + ":" + first.Label; got != tt.wantShape {
+				t.Errorf("first field = %s, want %s", got, tt.wantShape)
+			}
+			if compiled.Shape.Label != tt.wantObject {
+				t.Errorf("object label = %q, want %q", compiled.Shape.Label, tt.wantObject)
+			}
+		})
+	}
+}
+
+func TestCompileToLabelKeyset(t *testing.T) {
+	ast, err := Parse("SELECT Name FROM Deal ORDER BY toLabel(Stage)")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	validated, err := NewValidator(setupLabelMetadata(), nil, nil).Validate(context.Background(), ast)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	compiled, err := NewCompiler(nil).Compile(validated)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	const label = `COALESCE(($2::text[])[array_position($1::text[], t0."stage"::text)], t0."stage")`
+	if !strings.Contains(compiled.SQL, label+` AS Stage`) {
+		t.Errorf("the label is not selected for the cursor: %s", compiled.SQL)
+	}
+
+	sql, params, err := ApplyKeyset(compiled, compiled.SQL, compiled.Params, []any{"Neu", "00000000-0000-0000-0000-000000000001"}, 10)
+	if err != nil {
+		t.Fatalf("ApplyKeyset() error = %v", err)
+	}
+	if want := `((` + label + ` > $3 OR ` + label + ` IS NULL))`; !strings.Contains(sql, want) {
+		t.Errorf("keyset predicate does not sort by the label\nGot: %s\nWant to contain: %s", sql, want)
+	}
+	if len(params) != 4 {
+		t.Errorf("params = %v, want labels and the two keyset values", params)
+	}
+}
+
+func TestValidateToLabelErrors(t *testing.T) {
+	validator := NewValidator(setupLabelMetadata(), nil, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode ValidationErrorCode
+	}{
+		{
+			name:     "not a picklist",
+			query:    "SELECT toLabel(Name) FROM Deal",
+			wantCode: ErrCodeTypeMismatch,
+		},
+		{
+			name:     "invalid locale",
+			query:    "SELECT toLabel(Stage) FROM Deal WITH LOCALE 'German'",
+			wantCode: ErrCodeInvalidExpression,
+		},
+		{
+			name:     "locale given twice",
+			query:    "SELECT toLabel(Stage) FROM Deal WITH LOCALE 'de' WITH LOCALE 'fr'",
+			wantCode: ErrCodeInvalidExpression,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			_, err = validator.Validate(ctx, ast)
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Code != tt.wantCode {
+				t.Errorf("Validate() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
 func TestCompileSubqueries(t *testing.T) {
 	metadata := setupTestMetadata()
 	validator := NewValidator(metadata, nil, nil)
@@ -1216,6 +1651,149 @@ func TestCompileSubqueryShape(t *testing.T) {
 	}
 }
 
+func TestCompileAggregateSubquery(t *testing.T) {
+	metadata := setupTestMetadata()
+	validator := NewValidator(metadata, nil, nil)
+	compiler := NewCompiler(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		query     string
+		wantSQL   []string
+		wantField map[string]FieldType
+	}{
+		{
+			name:  "counts and sums per parent",
+			query: "SELECT Name, (SELECT COUNT(Id), SUM(Amount) pipeline FROM Opportunities WHERE StageName = 'Open') FROM Account",
+			wantSQL: []string{
+				`sa0.value AS Opportunities`,
+				`JOIN LATERAL (SELECT json_build_object('COUNT_Id', COUNT(sq."id"), 'pipeline', SUM(sq."amount")) AS value FROM "opportunities" AS sq WHERE sq."account_id" = t0."id" AND {{rls:`,
+				`sq."stage_name" = 'Open') AS sa0 ON TRUE`,
+			},
+			wantField: map[string]FieldType{"COUNT_Id": FieldTypeInteger, "pipeline": FieldTypeFloat},
+		},
+		{
+			name:  "having filters parents",
+			query: "SELECT Name, (SELECT MAX(CreatedDate) lastContact FROM Contacts HAVING COUNT(Id) > 2) FROM Account",
+			wantSQL: []string{
+				`HAVING COUNT(sq."id") > 2) AS sa0 ON TRUE`,
+			},
+			wantField: map[string]FieldType{"lastContact": FieldTypeDateTime},
+		},
+		{
+			name:  "aggregate subquery next to a record subquery",
+			query: "SELECT Name, (SELECT Email FROM Contacts), (SELECT COUNT(Id) FROM Opportunities), (SELECT COUNT(Id) FROM Contacts) FROM Account",
+			wantSQL: []string{
+				"JSON_AGG",
+				"sa0.value AS Opportunities",
+				"sa1.value AS Contacts",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			validated, err := validator.Validate(ctx, ast)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			compiled, err := compiler.Compile(validated)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(compiled.SQL, want) {
+					t.Errorf("SQL does not contain expected string\nGot: %s\nWant to contain: %s", compiled.SQL, want)
+				}
+			}
+			if compiled.Pagination == nil {
+				t.Error("aggregate subqueries must not disable pagination of the parent records")
+			}
+
+			var rel *RelationshipShape
+			for _, r := range compiled.Shape.Relationships {
+				if r.Aggregate {
+					rel = r
+				}
+			}
+			if rel == nil {
+				t.Fatal("expected an aggregate relationship in shape")
+			}
+			for _, f := range rel.Shape.Fields {
+				if want, ok := tt.wantField[f.Name]; ok && f.Type != want {
+					t.Errorf("field %s type = %s, want %s", f.Name, f.Type, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAggregateSubqueryErrors(t *testing.T) {
+	metadata := setupTestMetadata()
+	validator := NewValidator(metadata, nil, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode ValidationErrorCode
+	}{
+		{
+			name:     "aggregates mixed with fields",
+			query:    "SELECT Name, (SELECT Email, COUNT(Id) FROM Contacts) FROM Account",
+			wantCode: ErrCodeInvalidAggregation,
+		},
+		{
+			name:     "having without aggregates",
+			query:    "SELECT Name, (SELECT Email FROM Contacts HAVING COUNT(Id) > 1) FROM Account",
+			wantCode: ErrCodeInvalidAggregation,
+		},
+		{
+			name:     "order by in aggregate subquery",
+			query:    "SELECT Name, (SELECT COUNT(Id) FROM Contacts ORDER BY Email) FROM Account",
+			wantCode: ErrCodeInvalidAggregation,
+		},
+		{
+			name:     "grouped parent",
+			query:    "SELECT Industry, (SELECT COUNT(Id) FROM Contacts) FROM Account GROUP BY Industry",
+			wantCode: ErrCodeInvalidAggregation,
+		},
+		{
+			name:     "aggregate parent",
+			query:    "SELECT COUNT(Id), (SELECT COUNT(Id) FROM Contacts) FROM Account",
+			wantCode: ErrCodeInvalidAggregation,
+		},
+		{
+			name:     "for update",
+			query:    "SELECT Name, (SELECT COUNT(Id) FROM Contacts) FROM Account FOR UPDATE",
+			wantCode: ErrCodeInvalidExpression,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			_, err = validator.Validate(ctx, ast)
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Code != tt.wantCode {
+				t.Errorf("Validate() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
 func TestCompileWhereSubquery(t *testing.T) {
 	metadata := setupTestMetadata()
 	validator := NewValidator(metadata, nil, nil)
@@ -1268,6 +1846,172 @@ func TestCompileWhereSubquery(t *testing.T) {
 	}
 }
 
+func TestCompileExists(t *testing.T) {
+	metadata := setupTestMetadata()
+	validator := NewValidator(metadata, nil, nil)
+	compiler := NewCompiler(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		query   string
+		wantSQL []string
+	}{
+		{
+			name:  "EXISTS with LIKE",
+			query: "SELECT Name FROM Account WHERE EXISTS Contacts(Email LIKE '%@acme.com')",
+			wantSQL: []string{
+				`EXISTS (SELECT 1 FROM "contacts" AS wsq WHERE wsq."account_id" = t0."id" AND `,
+				`wsq."email" LIKE '%@acme.com')`,
+			},
+		},
+		{
+			name:  "NOT EXISTS",
+			query: "SELECT Name FROM Account WHERE NOT EXISTS Opportunities(StageName = 'Open')",
+			wantSQL: []string{
+				`NOT EXISTS (SELECT 1 FROM "opportunities" AS wsq`,
+				`wsq."stage_name" = 'Open')`,
+			},
+		},
+		{
+			name:    "without condition",
+			query:   "SELECT Name FROM Account WHERE EXISTS Contacts AND Industry = 'Tech'",
+			wantSQL: []string{`wsq."account_id" = t0."id" AND {{rls:`, `t0."industry" = 'Tech'`},
+		},
+		{
+			name:    "IN list and arithmetic",
+			query:   "SELECT Name FROM Account WHERE EXISTS Opportunities(StageName NOT IN ('Lost', 'Open') AND Amount * 2 > 100)",
+			wantSQL: []string{`wsq."stage_name" NOT IN ('Lost', 'Open')`, `wsq."amount" * 2 > 100`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			validated, err := validator.Validate(ctx, ast)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			compiled, err := compiler.Compile(validated)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(compiled.SQL, want) {
+					t.Errorf("SQL does not contain expected string\nGot: %s\nWant to contain: %s", compiled.SQL, want)
+				}
+			}
+		})
+	}
+
+	errorTests := []struct {
+		name     string
+		query    string
+		wantCode ValidationErrorCode
+	}{
+		{
+			name:     "unknown relationship",
+			query:    "SELECT Name FROM Account WHERE EXISTS Cases",
+			wantCode: ErrCodeUnknownRelationship,
+		},
+		{
+			name:     "unknown child field",
+			query:    "SELECT Name FROM Account WHERE EXISTS Contacts(Title = 'CEO')",
+			wantCode: ErrCodeUnknownField,
+		},
+		{
+			name:     "inside a subquery",
+			query:    "SELECT Name, (SELECT Name FROM Contacts WHERE EXISTS Opportunities) FROM Account",
+			wantCode: ErrCodeNestedSubqueryNotAllowed,
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			_, err = validator.Validate(ctx, ast)
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Code != tt.wantCode {
+				t.Errorf("Validate() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCompileDependencies(t *testing.T) {
+	metadata := setupTestMetadata()
+	validator := NewValidator(metadata, nil, nil)
+	compiler := NewCompiler(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "root object",
+			query: "SELECT Name FROM Account",
+			want:  []string{"Account"},
+		},
+		{
+			name:  "lookup",
+			query: "SELECT Name, Account.Name FROM Contact",
+			want:  []string{"Account", "Contact"},
+		},
+		{
+			name:  "relationship subquery",
+			query: "SELECT Name, (SELECT Name FROM Contacts) FROM Account",
+			want:  []string{"Account", "Contact"},
+		},
+		{
+			name:  "WHERE subquery",
+			query: "SELECT Name FROM Account WHERE Id IN (SELECT AccountId FROM Opportunity)",
+			want:  []string{"Account", "Opportunity"},
+		},
+		{
+			name:  "EXISTS filter",
+			query: "SELECT Name FROM Account WHERE NOT EXISTS Opportunities",
+			want:  []string{"Account", "Opportunity"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			validated, err := validator.Validate(ctx, ast)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			compiled, err := compiler.Compile(validated)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			got := append([]string(nil), compiled.Dependencies...)
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Dependencies = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCompileAllDateLiterals(t *testing.T) {
 	metadata := setupTestMetadata()
 	validator := NewValidator(metadata, nil, nil)
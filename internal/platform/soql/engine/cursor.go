@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// CursorVersion is the current cursor payload format version.
+const CursorVersion = 1
+
+// DefaultCursorTTL is how long a cursor stays valid after it was issued.
+const DefaultCursorTTL = 15 * time.Minute
+
+// ErrCursorExpired indicates the cursor is older than its TTL.
+var ErrCursorExpired = &PaginationError{Code: "CURSOR_EXPIRED", Message: "cursor has expired"}
+
+// HMACCursorManager is a stateless CursorManager. Cursors are the JSON payload
+// and its HMAC-SHA256 signature, both base64url encoded and joined by a dot.
+type HMACCursorManager struct {
+	secret     SecretProvider
+	tieBreaker string
+	ttl        time.Duration
+	fid        FIDBuilder
+	now        func() time.Time
+}
+
+// CursorOption configures an HMACCursorManager.
+type CursorOption func(*HMACCursorManager)
+
+// WithCursorTTL sets how long issued cursors remain valid.
+func WithCursorTTL(ttl time.Duration) CursorOption {
+	return func(m *HMACCursorManager) {
+		if ttl > 0 {
+			m.ttl = ttl
+		}
+	}
+}
+
+// WithCursorFIDBuilder sets the FID builder used by Next.
+func WithCursorFIDBuilder(fid FIDBuilder) CursorOption {
+	return func(m *HMACCursorManager) {
+		if fid != nil {
+			m.fid = fid
+		}
+	}
+}
+
+// WithCursorClock overrides the time source (for tests).
+func WithCursorClock(now func() time.Time) CursorOption {
+	return func(m *HMACCursorManager) {
+		if now != nil {
+			m.now = now
+		}
+	}
+}
+
+// NewCursorManager creates an HMAC-signed CursorManager.
+func NewCursorManager(secret SecretProvider, tieBreaker string, opts ...CursorOption) *HMACCursorManager {
+	if tieBreaker == "" {
+		tieBreaker = DefaultTieBreaker
+	}
+	m := &HMACCursorManager{
+		secret:     secret,
+		tieBreaker: tieBreaker,
+		ttl:        DefaultCursorTTL,
+		fid:        &DefaultFIDBuilder{},
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Encode implements CursorManager. Version and expiry are filled in when unset.
+func (m *HMACCursorManager) Encode(payload *CursorPayload) (string, error) {
+	if payload.Version == 0 {
+		payload.Version = CursorVersion
+	}
+	if payload.ExpiresAt == 0 {
+		payload.ExpiresAt = m.now().Add(m.ttl).Unix()
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(data) + "." + enc.EncodeToString(m.sign(data)), nil
+}
+
+// Decode implements CursorManager.
+func (m *HMACCursorManager) Decode(cursor string) (*CursorPayload, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	dataPart, sigPart, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	enc := base64.RawURLEncoding
+	data, err := enc.DecodeString(dataPart)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(sigPart)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if !hmac.Equal(sig, m.sign(data)) {
+		return nil, ErrCursorTampered
+	}
+
+	var payload CursorPayload
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if payload.Version != CursorVersion {
+		return nil, ErrInvalidCursorVersion
+	}
+	if m.now().Unix() > payload.ExpiresAt {
+		return nil, ErrCursorExpired
+	}
+
+	return &payload, nil
+}
+
+// ValidateContext implements CursorManager.
+func (m *HMACCursorManager) ValidateContext(payload *CursorPayload, fid string, orderBy SortKeys) error {
+	if payload == nil {
+		return ErrInvalidCursor
+	}
+	if !hmac.Equal([]byte(payload.FID), []byte(fid)) {
+		return ErrCursorMismatch
+	}
+	if !payload.OrderBy.Equals(orderBy) {
+		return ErrCursorMismatch
+	}
+	if len(orderBy) == 0 || orderBy[len(orderBy)-1].Field != m.tieBreaker {
+		return ErrCursorMismatch
+	}
+	return nil
+}
+
+// Next implements CursorManager.
+func (m *HMACCursorManager) Next(lastRow map[string]interface{}, namespace string, userID string,
+	filter map[string]interface{}, orderBy SortKeys) (string, error) {
+	if err := orderBy.Validate(); err != nil {
+		return "", err
+	}
+	return m.Encode(&CursorPayload{
+		OrderBy: orderBy,
+		LastRow: lastRow,
+		FID:     m.fid.BuildFID(namespace, userID, filter),
+	})
+}
+
+func (m *HMACCursorManager) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, m.secret.Secret())
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestCursorManager(now time.Time) *HMACCursorManager {
+	return NewCursorManager(NewStaticSecret("test-secret"), DefaultTieBreaker,
+		WithCursorTTL(time.Minute),
+		WithCursorClock(func() time.Time { return now }),
+	)
+}
+
+func testCursorPayload(fid string) *CursorPayload {
+	return &CursorPayload{
+		OrderBy: SortKeys{{Field: "name", Dir: SortAsc}, {Field: "id", Dir: SortAsc}},
+		LastRow: map[string]interface{}{"Name": "Acme", "Id": "00000000-0000-0000-0000-000000000001"},
+		FID:     fid,
+		Query:   "SELECT Name FROM Account ORDER BY Name",
+	}
+}
+
+func TestHMACCursorManager_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	m := newTestCursorManager(now)
+
+	cursor, err := m.Encode(testCursorPayload("fid"))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	got, err := m.Decode(cursor)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.Version != CursorVersion {
+		t.Errorf("Version = %d, want %d", got.Version, CursorVersion)
+	}
+	if got.ExpiresAt != now.Add(time.Minute).Unix() {
+		t.Errorf("ExpiresAt = %d, want %d", got.ExpiresAt, now.Add(time.Minute).Unix())
+	}
+	if got.Query != "SELECT Name FROM Account ORDER BY Name" {
+		t.Errorf("Query = %q", got.Query)
+	}
+	if got.LastRow["Name"] != "Acme" {
+		t.Errorf("LastRow[Name] = %v, want Acme", got.LastRow["Name"])
+	}
+	if err := m.ValidateContext(got, "fid", got.OrderBy); err != nil {
+		t.Errorf("ValidateContext() error = %v", err)
+	}
+}
+
+func TestHMACCursorManager_DecodeRejects(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	m := newTestCursorManager(now)
+
+	cursor, err := m.Encode(testCursorPayload("fid"))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	dataPart, sigPart, _ := strings.Cut(cursor, ".")
+
+	otherKey := NewCursorManager(NewStaticSecret("other-secret"), DefaultTieBreaker,
+		WithCursorClock(func() time.Time { return now }))
+	forged, err := otherKey.Encode(testCursorPayload("fid"))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		manager *HMACCursorManager
+		cursor  string
+		wantErr error
+	}{
+		{
+			name:    "not a cursor",
+			manager: m,
+			cursor:  "garbage",
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "modified payload",
+			manager: m,
+			cursor:  "x" + dataPart + "." + sigPart,
+			wantErr: ErrCursorTampered,
+		},
+		{
+			name:    "signed with another key",
+			manager: m,
+			cursor:  forged,
+			wantErr: ErrCursorTampered,
+		},
+		{
+			name:    "expired",
+			manager: newTestCursorManager(now.Add(2 * time.Minute)),
+			cursor:  cursor,
+			wantErr: ErrCursorExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.manager.Decode(tt.cursor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHMACCursorManager_ValidateContext(t *testing.T) {
+	t.Parallel()
+
+	m := newTestCursorManager(time.Now())
+	fids := &DefaultFIDBuilder{}
+	filter := map[string]interface{}{"q": "SELECT Name FROM Account"}
+	ownerFID := fids.BuildFID("Account", "user-1", filter)
+	payload := testCursorPayload(ownerFID)
+
+	untied := SortKeys{{Field: "name", Dir: SortAsc}}
+
+	tests := []struct {
+		name          string
+		fid           string
+		cursorOrderBy SortKeys
+		orderBy       SortKeys
+		wantErr       error
+	}{
+		{
+			name:    "same user and query",
+			fid:     ownerFID,
+			orderBy: payload.OrderBy,
+		},
+		{
+			name:    "another user",
+			fid:     fids.BuildFID("Account", "user-2", filter),
+			orderBy: payload.OrderBy,
+			wantErr: ErrCursorMismatch,
+		},
+		{
+			name:    "another query",
+			fid:     fids.BuildFID("Account", "user-1", map[string]interface{}{"q": "SELECT Id FROM Account"}),
+			orderBy: payload.OrderBy,
+			wantErr: ErrCursorMismatch,
+		},
+		{
+			name:    "different ordering",
+			fid:     ownerFID,
+			orderBy: SortKeys{{Field: "name", Dir: SortDesc}, {Field: "id", Dir: SortAsc}},
+			wantErr: ErrCursorMismatch,
+		},
+		{
+			name:          "missing tie-breaker",
+			fid:           ownerFID,
+			cursorOrderBy: untied,
+			orderBy:       untied,
+			wantErr:       ErrCursorMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := *payload
+			if tt.cursorOrderBy != nil {
+				p.OrderBy = tt.cursorOrderBy
+			}
+			err := m.ValidateContext(&p, tt.fid, tt.orderBy)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("ValidateContext() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
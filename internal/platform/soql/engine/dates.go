@@ -23,8 +23,22 @@ type DateResolver interface {
 
 	// ResolveDynamicRange resolves a dynamic date literal to a date range.
 	ResolveDynamicRange(ctx context.Context, literal *DynamicDateLiteral) (start, end time.Time, err error)
+
+	// Calendar returns the time zone and fiscal year start month that date
+	// functions such as CALENDAR_YEAR() and FISCAL_QUARTER() bucket by.
+	Calendar() (loc *time.Location, fiscalYearStart time.Month)
 }
 
+// CalendarSetting identifies a calendar parameter of date functions, which
+// is supplied per request like date literals.
+type CalendarSetting int
+
+const (
+	CalendarNone            CalendarSetting = iota
+	CalendarTimeZone                        // IANA time zone name
+	CalendarFiscalYearStart                 // Month number (1-12) the fiscal year starts in
+)
+
 // DefaultDateResolver resolves date literals based on the current time.
 // Uses a configurable "now" function for testing purposes.
 type DefaultDateResolver struct {
@@ -192,6 +206,19 @@ func (r *DefaultDateResolver) fiscalQuarterNumber(t time.Time) int {
 	return (monthsSinceFYStart / 3) + 1
 }
 
+// Calendar implements DateResolver.
+func (r *DefaultDateResolver) Calendar() (*time.Location, time.Month) {
+	loc := r.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	month := r.FiscalYearStartMonth
+	if month < time.January || month > time.December {
+		month = time.January
+	}
+	return loc, month
+}
+
 // ResolveStatic implements DateResolver.
 func (r *DefaultDateResolver) ResolveStatic(ctx context.Context, literal StaticDateLiteral) (time.Time, error) {
 	start, _, err := r.ResolveStaticRange(ctx, literal)
@@ -425,6 +452,11 @@ func ResolveDateParams(ctx context.Context, query *CompiledQuery, resolver DateR
 	}
 
 	for _, dp := range query.DateParams {
+		if dp.Calendar != CalendarNone {
+			resolveCalendarParam(query, dp, resolver)
+			continue
+		}
+
 		var resolvedValue time.Time
 		var err error
 
@@ -458,6 +490,11 @@ func ResolveDateParamsForRange(ctx context.Context, query *CompiledQuery, resolv
 	}
 
 	for _, dp := range query.DateParams {
+		if dp.Calendar != CalendarNone {
+			resolveCalendarParam(query, dp, resolver)
+			continue
+		}
+
 		var start, end time.Time
 		var err error
 
@@ -486,3 +523,17 @@ func ResolveDateParamsForRange(ctx context.Context, query *CompiledQuery, resolv
 
 	return nil
 }
+
+// resolveCalendarParam sets the value of a date function calendar parameter.
+func resolveCalendarParam(query *CompiledQuery, dp *DateParam, resolver DateResolver) {
+	if dp.ParamIndex <= 0 || dp.ParamIndex > len(query.Params) {
+		return
+	}
+	loc, fiscalYearStart := resolver.Calendar()
+	switch dp.Calendar {
+	case CalendarTimeZone:
+		query.Params[dp.ParamIndex-1] = loc.String()
+	case CalendarFiscalYearStart:
+		query.Params[dp.ParamIndex-1] = int(fiscalYearStart)
+	}
+}
@@ -336,6 +336,42 @@ func TestResolveDateParamsWithDynamic(t *testing.T) {
 	}
 }
 
+func TestResolveDateParamsWithCalendar(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		resolver DateResolver
+		wantZone string
+		wantFY   int
+	}{
+		{name: "user calendar", resolver: NewDateResolverWithFiscalYear(berlin, time.April), wantZone: "Europe/Berlin", wantFY: 4},
+		{name: "defaults", resolver: &DefaultDateResolver{}, wantZone: "UTC", wantFY: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &CompiledQuery{
+				Params: []any{nil, nil},
+				DateParams: []*DateParam{
+					{ParamIndex: 1, Calendar: CalendarTimeZone},
+					{ParamIndex: 2, Calendar: CalendarFiscalYearStart},
+				},
+			}
+			if err := ResolveDateParams(ctx, query, tt.resolver); err != nil {
+				t.Fatalf("ResolveDateParams() error = %v", err)
+			}
+			if query.Params[0] != tt.wantZone || query.Params[1] != tt.wantFY {
+				t.Errorf("Params = %v, want [%s %d]", query.Params, tt.wantZone, tt.wantFY)
+			}
+		})
+	}
+}
+
 func TestDefaultDateResolver(t *testing.T) {
 	// Test that default resolver works with real time
 	resolver := NewDefaultDateResolver()
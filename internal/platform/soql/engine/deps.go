@@ -6,6 +6,11 @@ package engine
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -176,6 +181,26 @@ type ObjectMeta struct {
 	// Relationships maps SOQL relationship names to Parent-to-Child relationships.
 	// Example: Account has relationship "Contacts" that returns child Contact records.
 	Relationships map[string]*RelationshipMeta
+
+	// SearchColumn is the tsvector column used by SOSL, or empty when the
+	// object is not searchable. Lexemes are weighted A for name fields,
+	// B for email, C for phone and D for other text fields.
+	SearchColumn string
+
+	// DeletedColumn is the boolean column flagging records in the recycle
+	// bin, or empty when records are deleted for good. Queries skip flagged
+	// records unless they specify ALL ROWS.
+	DeletedColumn string
+
+	// Label is the display name of the object, and Translations its
+	// translations keyed by locale (e.g. "de", "de_AT").
+	Label        string
+	Translations map[string]string
+}
+
+// LocalizedLabel returns the object label in the given locale.
+func (o *ObjectMeta) LocalizedLabel(locale string) string {
+	return Localize(o.Label, o.Translations, locale)
 }
 
 // QualifiedTableName returns the fully qualified and properly quoted table name.
@@ -257,6 +282,67 @@ type FieldMeta struct {
 
 	// Aggregatable indicates whether the field can be used with aggregate functions.
 	Aggregatable bool
+
+	// Custom marks a user-defined field. FIELDS(CUSTOM) selects custom
+	// fields and FIELDS(STANDARD) all others.
+	Custom bool
+
+	// Label is the display name of the field, and Translations its
+	// translations keyed by locale.
+	Label        string
+	Translations map[string]string
+
+	// Picklist lists the values of a picklist field; nil for other fields.
+	Picklist *PicklistMeta
+}
+
+// LocalizedLabel returns the field label in the given locale.
+func (f *FieldMeta) LocalizedLabel(locale string) string {
+	return Localize(f.Label, f.Translations, locale)
+}
+
+// PicklistMeta describes the values of a picklist field. toLabel() maps
+// the stored values to their labels.
+type PicklistMeta struct {
+	// Multi marks a multi-select picklist, stored as an array of values.
+	Multi bool
+
+	// Values are the picklist values, inactive ones included: records may
+	// still hold them.
+	Values []*PicklistValueMeta
+}
+
+// PicklistValueMeta is a picklist value with its label.
+type PicklistValueMeta struct {
+	// Value is the stored value.
+	Value string
+
+	// Label is the display name of the value, and Translations its
+	// translations keyed by locale.
+	Label        string
+	Translations map[string]string
+}
+
+// LocalizedLabel returns the value label in the given locale.
+func (v *PicklistValueMeta) LocalizedLabel(locale string) string {
+	return Localize(v.Label, v.Translations, locale)
+}
+
+// Localize returns the translation of label for a locale such as "de_AT",
+// falling back to the language ("de") and then to label itself.
+func Localize(label string, translations map[string]string, locale string) string {
+	if locale == "" || len(translations) == 0 {
+		return label
+	}
+	if value, ok := translations[locale]; ok {
+		return value
+	}
+	if lang, _, ok := strings.Cut(locale, "_"); ok {
+		if value, ok := translations[lang]; ok {
+			return value
+		}
+	}
+	return label
 }
 
 // LookupMeta describes a Child-to-Parent relationship (lookup).
@@ -386,6 +472,26 @@ func (b *ObjectMetaBuilder) Relationship(name, childObject, childField, parentFi
 	return b
 }
 
+// Searchable marks the object as searchable through the given tsvector column.
+func (b *ObjectMetaBuilder) Searchable(column string) *ObjectMetaBuilder {
+	b.meta.SearchColumn = column
+	return b
+}
+
+// SoftDelete marks the object as keeping deleted records, flagged by the
+// given boolean column.
+func (b *ObjectMetaBuilder) SoftDelete(column string) *ObjectMetaBuilder {
+	b.meta.DeletedColumn = column
+	return b
+}
+
+// Label sets the object label and its translations.
+func (b *ObjectMetaBuilder) Label(label string, translations map[string]string) *ObjectMetaBuilder {
+	b.meta.Label = label
+	b.meta.Translations = translations
+	return b
+}
+
 // Build returns the constructed ObjectMeta.
 func (b *ObjectMetaBuilder) Build() *ObjectMeta {
 	return b.meta
@@ -532,6 +638,18 @@ type CursorPayload struct {
 	OrderBy SortKeys               `json:"ob"`
 	LastRow map[string]interface{} `json:"k"`
 	FID     string                 `json:"fid"`
+
+	// ExpiresAt is the Unix time after which the cursor is rejected.
+	ExpiresAt int64 `json:"exp"`
+
+	// Query is the SOQL text to resume; PageSize and Remaining (rows left
+	// under the query LIMIT, zero when unbounded) describe the next page.
+	Query     string `json:"q,omitempty"`
+	PageSize  int    `json:"ps,omitempty"`
+	Remaining int    `json:"r,omitempty"`
+
+	// Binds holds the bind variable values of Query.
+	Binds map[string]any `json:"b,omitempty"`
 }
 
 // CursorManager handles cursor encoding, decoding, and validation.
@@ -624,11 +742,20 @@ type Dependencies struct {
 // DefaultFIDBuilder is a simple FID builder using JSON serialization.
 type DefaultFIDBuilder struct{}
 
-// BuildFID implements FIDBuilder using a simple hash of parameters.
+// BuildFID implements FIDBuilder as a SHA-256 of the namespace, user and the
+// JSON-serialized filter (map keys are sorted by encoding/json).
 func (b *DefaultFIDBuilder) BuildFID(namespace string, userID string, filter map[string]interface{}) string {
-	// Simple implementation - can be overridden with more sophisticated hashing
-	if filter == nil {
-		return namespace
+	h := sha256.New()
+	h.Write([]byte(namespace))
+	h.Write([]byte{0})
+	h.Write([]byte(userID))
+	h.Write([]byte{0})
+	if filter != nil {
+		data, err := json.Marshal(filter)
+		if err != nil {
+			data = []byte(fmt.Sprintf("%v", filter))
+		}
+		h.Write(data)
 	}
-	return namespace // Simplified - real impl should hash filter
+	return hex.EncodeToString(h.Sum(nil))
 }
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -190,6 +191,133 @@ func (e *Engine) PrepareAndResolve(ctx context.Context, query string) (*Compiled
 	return compiled, nil
 }
 
+// CompiledSearch is a compiled SOSL search: one ranked query per searched object.
+type CompiledSearch struct {
+	// Queries are the per-object queries in RETURNING order.
+	Queries []*CompiledQuery
+
+	// Limit is the maximum number of records across all objects.
+	Limit int
+}
+
+// ParseSearch parses a SOSL search string into an AST.
+// Returns a ParseError if the search has syntax errors.
+func (e *Engine) ParseSearch(search string) (*SearchGrammar, error) {
+	if e.limits != nil && e.limits.MaxQueryLength > 0 && len(search) > e.limits.MaxQueryLength {
+		return nil, NewLimitError(LimitTypeMaxQueryLength, e.limits.MaxQueryLength, len(search))
+	}
+
+	ast, err := ParseSearch(search)
+	if err != nil {
+		return nil, NewParseErrorFromParticiple(err)
+	}
+
+	return ast, nil
+}
+
+// PrepareSearch parses a SOSL search and compiles every RETURNING object into
+// a SOQL query filtered by the search vector, with date parameters resolved.
+// Each query passes the same validation (OLS/FLS) and RLS placeholders as SOQL.
+// Without RETURNING, all searchable objects the user can read are searched.
+func (e *Engine) PrepareSearch(ctx context.Context, search string) (*CompiledSearch, error) {
+	if e.metadata == nil {
+		return nil, fmt.Errorf("metadata provider is required for validation")
+	}
+
+	ast, err := e.ParseSearch(search)
+	if err != nil {
+		return nil, err
+	}
+
+	tsQuery, err := BuildSearchQuery(ast.Find, ast.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := DefaultSearchLimit
+	if ast.Limit != nil {
+		limit = *ast.Limit
+	}
+	if limit <= 0 {
+		return nil, NewValidationErrorWithPos(ErrCodeInvalidExpression, PosFromLexer(ast.Pos), "LIMIT must be positive")
+	}
+
+	returning := ast.Returning
+	if len(returning) == 0 {
+		returning, err = e.searchableObjects(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &CompiledSearch{Limit: limit}
+	seen := make(map[string]bool, len(returning))
+	for _, r := range returning {
+		if seen[r.Object] {
+			return nil, NewValidationErrorWithPos(ErrCodeInvalidExpression, PosFromLexer(r.Pos),
+				fmt.Sprintf("object %s is listed twice in RETURNING", r.Object))
+		}
+		seen[r.Object] = true
+
+		if err := r.validateFields(); err != nil {
+			return nil, err
+		}
+
+		validated, err := e.Validate(ctx, r.grammar(tsQuery, limit))
+		if err != nil {
+			return nil, err
+		}
+		if validated.RootObject.SearchColumn == "" {
+			verr := NewValidationErrorWithPos(ErrCodeObjectNotSearchable, PosFromLexer(r.Pos),
+				fmt.Sprintf("object %s is not searchable", r.Object))
+			verr.Object = r.Object
+			return nil, verr
+		}
+
+		compiled, err := e.Compile(validated)
+		if err != nil {
+			return nil, err
+		}
+		if len(compiled.BindParams) > 0 {
+			return nil, NewValidationErrorWithPos(ErrCodeInvalidBind, PosFromLexer(r.Pos),
+				"bind variables are not supported in SOSL")
+		}
+		if len(compiled.DateParams) > 0 {
+			if err := ResolveDateParams(ctx, compiled, e.dateResolver); err != nil {
+				return nil, fmt.Errorf("failed to resolve date parameters: %w", err)
+			}
+		}
+		result.Queries = append(result.Queries, compiled)
+	}
+
+	return result, nil
+}
+
+// searchableObjects lists the searchable objects the current user can read.
+func (e *Engine) searchableObjects(ctx context.Context) ([]*SearchReturning, error) {
+	names, err := e.metadata.ListObjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	sort.Strings(names)
+
+	var result []*SearchReturning
+	for _, name := range names {
+		obj, err := e.metadata.GetObject(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get object metadata: %w", err)
+		}
+		if obj == nil || obj.SearchColumn == "" {
+			continue
+		}
+		if e.access.CanAccessObject(ctx, name) != nil {
+			continue
+		}
+		result = append(result, &SearchReturning{Object: name})
+	}
+	return result, nil
+}
+
 // MustParse parses a SOQL query and panics on error.
 // Useful for queries that are known at compile time.
 func (e *Engine) MustParse(query string) *Grammar {
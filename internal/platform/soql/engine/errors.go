@@ -150,6 +150,9 @@ const (
 	ErrCodeInvalidPagination
 	ErrCodeWhereSubquerySingleField    // WHERE subquery must select exactly one field
 	ErrCodeWhereSubqueryAggregateField // WHERE subquery cannot use aggregates
+	ErrCodeObjectNotSearchable         // SOSL RETURNING object has no search vector
+	ErrCodeInvalidSearchTerm           // SOSL FIND term cannot be translated to a text search query
+	ErrCodeInvalidBind                 // Bind variable is misplaced, untyped or has no value
 )
 
 func (c ValidationErrorCode) String() string {
@@ -192,6 +195,12 @@ func (c ValidationErrorCode) String() string {
 		return "WhereSubquerySingleField"
 	case ErrCodeWhereSubqueryAggregateField:
 		return "WhereSubqueryAggregateField"
+	case ErrCodeObjectNotSearchable:
+		return "ObjectNotSearchable"
+	case ErrCodeInvalidSearchTerm:
+		return "InvalidSearchTerm"
+	case ErrCodeInvalidBind:
+		return "InvalidBind"
 	default:
 		return "UnknownValidationError"
 	}
@@ -436,6 +445,8 @@ const (
 	LimitTypeMaxSubqueryRecords
 	LimitTypeMaxOffset
 	LimitTypeMaxQueryLength
+	LimitTypeMaxEstimatedCost
+	LimitTypeMaxEstimatedRows
 )
 
 func (t LimitType) String() string {
@@ -454,6 +465,10 @@ func (t LimitType) String() string {
 		return "MaxOffset"
 	case LimitTypeMaxQueryLength:
 		return "MaxQueryLength"
+	case LimitTypeMaxEstimatedCost:
+		return "MaxEstimatedCost"
+	case LimitTypeMaxEstimatedRows:
+		return "MaxEstimatedRows"
 	default:
 		return "UnknownLimit"
 	}
@@ -375,6 +375,8 @@ func TestLimitTypeString(t *testing.T) {
 		{LimitTypeMaxSubqueryRecords, "MaxSubqueryRecords"},
 		{LimitTypeMaxOffset, "MaxOffset"},
 		{LimitTypeMaxQueryLength, "MaxQueryLength"},
+		{LimitTypeMaxEstimatedCost, "MaxEstimatedCost"},
+		{LimitTypeMaxEstimatedRows, "MaxEstimatedRows"},
 		{LimitType(99), "UnknownLimit"},
 	}
 
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxFieldsAllLimit is the largest LIMIT allowed with FIELDS(ALL), which
+// selects every column of potentially wide rows.
+const MaxFieldsAllLimit = 200
+
+// checkFieldsWildcards enforces the clauses FIELDS() requires: no GROUP BY,
+// and a LIMIT of at most MaxFieldsAllLimit for FIELDS(ALL).
+func checkFieldsWildcards(ast *Grammar) error {
+	for _, sel := range ast.Select {
+		if sel.Item == nil || sel.Item.Fields == nil {
+			continue
+		}
+		fe := sel.Item.Fields
+		pos := PosFromLexer(fe.Pos)
+		if len(ast.GroupBy) > 0 {
+			return NewValidationErrorWithPos(ErrCodeInvalidExpression, pos,
+				fmt.Sprintf("FIELDS(%s) cannot be used with GROUP BY", fe.Set))
+		}
+		if fe.Set != FieldSetAll {
+			continue
+		}
+		if ast.Limit == nil {
+			return NewValidationErrorWithPos(ErrCodeMissingRequiredClause, pos,
+				fmt.Sprintf("FIELDS(ALL) requires a LIMIT of at most %d", MaxFieldsAllLimit))
+		}
+		if *ast.Limit > MaxFieldsAllLimit {
+			return NewValidationErrorWithPos(ErrCodeInvalidExpression, pos,
+				fmt.Sprintf("FIELDS(ALL) allows a LIMIT of at most %d, got %d", MaxFieldsAllLimit, *ast.Limit))
+		}
+	}
+	return nil
+}
+
+// expandFields replaces FIELDS() items with the matching fields of object,
+// Id first and the rest by name. Fields the access controller denies are
+// dropped instead of failing the query, and fields selected explicitly or by
+// another FIELDS() item are not repeated.
+func (v *Validator) expandFields(vctx *validationContext, object *ObjectMeta, selects []*SelectExpression) ([]*SelectExpression, error) {
+	hasWildcard := false
+	selected := make(map[string]bool)
+	for _, sel := range selects {
+		if sel.Item == nil {
+			continue
+		}
+		if sel.Item.Fields != nil {
+			hasWildcard = true
+			continue
+		}
+		if p := sel.Item.Expr.primary(); p != nil && p.Field != nil && sel.Alias == nil {
+			selected[strings.ToLower(strings.Join(p.Field.Path, "."))] = true
+		}
+	}
+	if !hasWildcard {
+		return selects, nil
+	}
+
+	names := make([]string, 0, len(object.Fields))
+	for name := range object.Fields {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == "Id") != (names[j] == "Id") {
+			return names[i] == "Id"
+		}
+		return names[i] < names[j]
+	})
+
+	expanded := make([]*SelectExpression, 0, len(selects)+len(names))
+	for _, sel := range selects {
+		if sel.Item == nil || sel.Item.Fields == nil {
+			expanded = append(expanded, sel)
+			continue
+		}
+		fe := sel.Item.Fields
+		for _, name := range names {
+			if selected[strings.ToLower(name)] || !fe.Set.includes(object.Fields[name]) {
+				continue
+			}
+			if err := v.access.CanAccessField(vctx.ctx, object.Name, name); err != nil {
+				var accessErr *AccessError
+				if errors.As(err, &accessErr) {
+					continue
+				}
+				return nil, err
+			}
+			selected[strings.ToLower(name)] = true
+			expr := fieldExpression(name)
+			expr.Pos = fe.Pos
+			expanded = append(expanded, &SelectExpression{Pos: sel.Pos, Item: &SelectItem{Pos: fe.Pos, Expr: expr}})
+		}
+	}
+
+	if len(expanded) == 0 {
+		return nil, NewValidationError(ErrCodeMissingRequiredClause,
+			fmt.Sprintf("FIELDS() selects no accessible fields of %s", object.Name))
+	}
+	return expanded, nil
+}
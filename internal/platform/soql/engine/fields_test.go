@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func setupFieldsMetadata() MetadataProvider {
+	return NewStaticMetadataProvider(map[string]*ObjectMeta{
+		"Deal": NewObjectMeta("Deal", "", "obj_deal").
+			Field("Id", "id", FieldTypeID).
+			Field("Name", "name", FieldTypeString).
+			Field("OwnerId", "owner_id", FieldTypeID).
+			FieldFull(&FieldMeta{Name: "Amount", Column: "amount", Type: FieldTypeFloat, Filterable: true, Sortable: true, Custom: true}).
+			FieldFull(&FieldMeta{Name: "Margin", Column: "margin", Type: FieldTypeFloat, Filterable: true, Sortable: true, Custom: true}).
+			Relationship("Notes", "Note", "deal_id", "id").
+			Build(),
+		"Note": NewObjectMeta("Note", "", "obj_note").
+			Field("Id", "id", FieldTypeID).
+			Field("DealId", "deal_id", FieldTypeID).
+			FieldFull(&FieldMeta{Name: "Body", Column: "body", Type: FieldTypeString, Filterable: true, Sortable: true, Custom: true}).
+			Lookup("Deal", "deal_id", "Deal", "id").
+			Build(),
+	})
+}
+
+func TestEngine_FieldsWildcard(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	access := &FieldAccessController{AllowedFields: map[string]map[string]bool{
+		"Deal": {"Id": true, "Name": true, "OwnerId": true, "Amount": true},
+	}}
+	eng := NewEngine(WithMetadata(setupFieldsMetadata()), WithAccessController(access))
+
+	shapeNames := func(shape *ResultShape) string {
+		names := make([]string, len(shape.Fields))
+		for i, f := range shape.Fields {
+			names[i] = f.Name
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantFields string
+	}{
+		{name: "custom drops denied fields", query: "SELECT FIELDS(CUSTOM) FROM Deal", wantFields: "Amount,Id"},
+		{name: "standard", query: "SELECT FIELDS(STANDARD) FROM Deal", wantFields: "Id,Name,OwnerId"},
+		{name: "all with limit", query: "SELECT FIELDS(ALL) FROM Deal LIMIT 200", wantFields: "Id,Amount,Name,OwnerId"},
+		{name: "case insensitive", query: "select fields(custom) from Deal", wantFields: "Amount,Id"},
+		{name: "explicit fields are not repeated", query: "SELECT Name, FIELDS(STANDARD) FROM Deal", wantFields: "Name,Id,OwnerId"},
+		{name: "overlapping sets", query: "SELECT FIELDS(STANDARD), FIELDS(ALL) FROM Deal LIMIT 10", wantFields: "Id,Name,OwnerId,Amount"},
+		{name: "aliased field stays", query: "SELECT Name n, FIELDS(STANDARD) FROM Deal", wantFields: "n,Id,Name,OwnerId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			compiled, err := eng.Prepare(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			if got := shapeNames(compiled.Shape); got != tt.wantFields {
+				t.Errorf("fields = %s, want %s", got, tt.wantFields)
+			}
+		})
+	}
+
+	t.Run("subquery", func(t *testing.T) {
+		t.Parallel()
+		compiled, err := eng.Prepare(ctx, "SELECT Name, (SELECT FIELDS(CUSTOM) FROM Notes) FROM Deal")
+		if err != nil {
+			t.Fatalf("Prepare() error = %v", err)
+		}
+		if len(compiled.Shape.Relationships) != 1 {
+			t.Fatalf("relationships = %d, want 1", len(compiled.Shape.Relationships))
+		}
+		if got := shapeNames(compiled.Shape.Relationships[0].Shape); got != "Body" {
+			t.Errorf("subquery fields = %s, want Body", got)
+		}
+	})
+
+	t.Run("field named like the function", func(t *testing.T) {
+		t.Parallel()
+		meta := NewStaticMetadataProvider(map[string]*ObjectMeta{
+			"Form": NewObjectMeta("Form", "", "obj_form").
+				Field("Id", "id", FieldTypeID).
+				Field("Fields", "fields", FieldTypeString).
+				Build(),
+		})
+		compiled, err := NewEngine(WithMetadata(meta)).Prepare(ctx, "SELECT Fields FROM Form")
+		if err != nil {
+			t.Fatalf("Prepare() error = %v", err)
+		}
+		if got := shapeNames(compiled.Shape); got != "Fields,Id" {
+			t.Errorf("fields = %s, want Fields,Id", got)
+		}
+	})
+
+	errorTests := []struct {
+		name     string
+		query    string
+		wantCode ValidationErrorCode
+	}{
+		{name: "ALL without LIMIT", query: "SELECT FIELDS(ALL) FROM Deal", wantCode: ErrCodeMissingRequiredClause},
+		{name: "ALL with large LIMIT", query: "SELECT FIELDS(ALL) FROM Deal LIMIT 201", wantCode: ErrCodeInvalidExpression},
+		{name: "GROUP BY", query: "SELECT FIELDS(STANDARD) FROM Deal GROUP BY Name", wantCode: ErrCodeInvalidExpression},
+		{name: "no accessible fields", query: "SELECT FIELDS(CUSTOM) FROM Deal", wantCode: ErrCodeMissingRequiredClause},
+	}
+	denyCustom := NewEngine(WithMetadata(setupFieldsMetadata()), WithAccessController(&FieldAccessController{
+		AllowedFields: map[string]map[string]bool{"Deal": {"Id": true}},
+	}))
+	for _, tt := range errorTests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := denyCustom.Prepare(ctx, tt.query)
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Code != tt.wantCode {
+				t.Errorf("Prepare(%q) error = %v, want %s", tt.query, err, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("rejects FIELDS in SOSL", func(t *testing.T) {
+		t.Parallel()
+		_, err := eng.PrepareSearch(ctx, "FIND {acme} RETURNING Account(FIELDS(STANDARD))")
+		if err == nil {
+			t.Error("PrepareSearch() expected error")
+		}
+	})
+}
@@ -4,8 +4,8 @@ import (
 	"github.com/alecthomas/participle/v2/lexer"
 )
 
-// Lexer defines tokens for SOQL
-var Lexer = lexer.MustSimple([]lexer.SimpleRule{
+// lexerRules are the SOQL token rules, shared with the SOSL lexer.
+var lexerRules = []lexer.SimpleRule{
 	// Keywords (must come before Ident to take precedence)
 	{Name: "Keyword", Pattern: `\b(?i:SELECT|ROW|FROM|WHERE|WITH|SECURITY_ENFORCED|GROUP|BY|HAVING|ORDER|LIMIT|OFFSET|AND|OR|NOT|IN|LIKE|IS|NULL|TRUE|FALSE|AS|ASC|DESC|NULLS|FIRST|LAST|FOR|UPDATE|TYPEOF|WHEN|THEN|ELSE|END|COUNT|COUNT_DISTINCT|SUM|AVG|MIN|MAX|COALESCE|NULLIF|CONCAT|UPPER|LOWER|TRIM|LENGTH|LEN|SUBSTRING|SUBSTR|ABS|ROUND|FLOOR|CEIL|CEILING)\b`},
 
@@ -21,6 +21,9 @@ var Lexer = lexer.MustSimple([]lexer.SimpleRule{
 	// Date: 2024-01-15
 	{Name: "Date", Pattern: `\d{4}-\d{2}-\d{2}`},
 
+	// Bind variables: :accountId
+	{Name: "Bind", Pattern: `:[a-zA-Z_][a-zA-Z0-9_]*`},
+
 	// Identifiers
 	{Name: "Ident", Pattern: `[a-zA-Z_][a-zA-Z0-9_]*`},
 
@@ -44,4 +47,7 @@ var Lexer = lexer.MustSimple([]lexer.SimpleRule{
 
 	// Comments (SQL style)
 	{Name: "Comment", Pattern: `--[^\n]*`},
-})
+}
+
+// Lexer defines tokens for SOQL
+var Lexer = lexer.MustSimple(lexerRules)
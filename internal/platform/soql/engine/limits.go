@@ -1,5 +1,7 @@
 package engine
 
+import "math"
+
 // Limits defines configurable limits for SOQL queries.
 // These limits help protect against resource exhaustion and
 // ensure queries stay within reasonable bounds.
@@ -48,6 +50,15 @@ type Limits struct {
 	// DefaultLimit is the default LIMIT value if not specified in query.
 	// If 0, no default limit is applied (MaxRecords still enforced).
 	DefaultLimit int
+
+	// MaxEstimatedCost is the highest PostgreSQL planner cost (EXPLAIN
+	// "Total Cost") a query may have. Queries above it are rejected before
+	// they run. 0 means no limit.
+	MaxEstimatedCost float64
+
+	// MaxEstimatedRows is the highest number of rows the planner may expect
+	// a query to return. 0 means no limit.
+	MaxEstimatedRows int
 }
 
 // DefaultLimits contains the default limits matching Salesforce SOQL.
@@ -137,6 +148,22 @@ func (l *Limits) CheckQueryLength(length int) error {
 	return nil
 }
 
+// HasEstimateLimits reports whether queries must be planned before they run.
+func (l *Limits) HasEstimateLimits() bool {
+	return l.MaxEstimatedCost > 0 || l.MaxEstimatedRows > 0
+}
+
+// CheckEstimate checks the planner estimates of a query against the cost ceiling.
+func (l *Limits) CheckEstimate(cost float64, rows int) error {
+	if l.MaxEstimatedCost > 0 && cost > l.MaxEstimatedCost {
+		return NewLimitError(LimitTypeMaxEstimatedCost, int(math.Ceil(l.MaxEstimatedCost)), int(math.Ceil(cost)))
+	}
+	if l.MaxEstimatedRows > 0 && rows > l.MaxEstimatedRows {
+		return NewLimitError(LimitTypeMaxEstimatedRows, l.MaxEstimatedRows, rows)
+	}
+	return nil
+}
+
 // EffectiveLimit returns the effective LIMIT value for a query.
 // It considers the requested limit, default limit, and max records.
 func (l *Limits) EffectiveLimit(requested *int) int {
@@ -218,6 +245,12 @@ func (l *Limits) Merge(override *Limits) *Limits {
 	if override.DefaultLimit > 0 {
 		result.DefaultLimit = override.DefaultLimit
 	}
+	if override.MaxEstimatedCost > 0 {
+		result.MaxEstimatedCost = override.MaxEstimatedCost
+	}
+	if override.MaxEstimatedRows > 0 {
+		result.MaxEstimatedRows = override.MaxEstimatedRows
+	}
 
 	return &result
 }
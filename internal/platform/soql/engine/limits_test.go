@@ -226,6 +226,37 @@ func TestCheckQueryLength(t *testing.T) {
 	}
 }
 
+func TestCheckEstimate(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   Limits
+		cost     float64
+		rows     int
+		wantType LimitType
+		wantErr  bool
+	}{
+		{"within limits", Limits{MaxEstimatedCost: 1000, MaxEstimatedRows: 500}, 999.5, 500, 0, false},
+		{"cost exceeded", Limits{MaxEstimatedCost: 1000}, 1000.01, 10, LimitTypeMaxEstimatedCost, true},
+		{"rows exceeded", Limits{MaxEstimatedRows: 500}, 10, 501, LimitTypeMaxEstimatedRows, true},
+		{"no limits (0)", Limits{}, 1e9, 1e9, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.HasEstimateLimits(); got != (tt.limits != Limits{}) {
+				t.Errorf("HasEstimateLimits() = %v", got)
+			}
+			err := tt.limits.CheckEstimate(tt.cost, tt.rows)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckEstimate(%v, %d) error = %v, wantErr %v", tt.cost, tt.rows, err, tt.wantErr)
+			}
+			if le, ok := err.(*LimitError); ok && le.LimitType != tt.wantType {
+				t.Errorf("LimitType = %s, want %s", le.LimitType, tt.wantType)
+			}
+		})
+	}
+}
+
 func TestEffectiveLimit(t *testing.T) {
 	tests := []struct {
 		name          string
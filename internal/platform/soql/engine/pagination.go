@@ -1,5 +1,10 @@
 package engine
 
+import (
+	"fmt"
+	"strings"
+)
+
 // DefaultTieBreaker is the default tie-breaker field for keyset pagination.
 // Uses id as the unique identifier for stable pagination (ADR-0001).
 const DefaultTieBreaker = "id"
@@ -43,6 +48,16 @@ type PaginationInfo struct {
 
 	// Object is the root object API name (for FID generation).
 	Object string
+
+	// Fields are the keyset fields in ORDER BY order, including the tie-breaker.
+	Fields []*KeysetField
+
+	// Limit is the total number of rows the query may return across all pages
+	// (explicit LIMIT or the configured default). Zero means unbounded.
+	Limit int
+
+	// Placeholder is the marker in the root WHERE clause replaced by ApplyKeyset.
+	Placeholder string
 }
 
 // KeysetField maps a SOQL field to its SQL column for pagination.
@@ -56,8 +71,19 @@ type KeysetField struct {
 	// TableAlias is the table alias in the query (e.g., "t0").
 	TableAlias string
 
+	// Expr is the SQL expression sorted by when it is not the column itself,
+	// as for toLabel(). It may refer to parameters of the compiled query.
+	Expr string
+
 	// Direction is the sort direction ("asc" or "desc").
 	Direction string
+
+	// NullsFirst reports whether NULLs sort before non-NULL values,
+	// either explicitly (NULLS FIRST) or by PostgreSQL default for DESC.
+	NullsFirst bool
+
+	// ResultAlias is the SELECT alias of the column carrying the field value.
+	ResultAlias string
 }
 
 // FullColumn returns the fully qualified column name (alias.column).
@@ -67,3 +93,124 @@ func (f *KeysetField) FullColumn() string {
 	}
 	return f.TableAlias + "." + f.SQLColumn
 }
+
+// sortExpr returns the SQL expression the field is sorted by.
+func (f *KeysetField) sortExpr() string {
+	if f.Expr != "" {
+		return f.Expr
+	}
+	return qualifiedColumn(f.TableAlias, f.SQLColumn)
+}
+
+// ApplyKeyset prepares one page of a paginated query. It replaces the keyset
+// placeholder with a predicate selecting rows strictly after the given keyset
+// values (nil for the first page) and rewrites the root LIMIT to limit.
+// sql and params are the query as already processed by ApplyRLS.
+func ApplyKeyset(compiled *CompiledQuery, sql string, params []any, after []any, limit int) (string, []any, error) {
+	p := compiled.Pagination
+	if p == nil {
+		return sql, params, nil
+	}
+
+	predicate := "TRUE"
+	if after != nil {
+		if len(after) != len(p.Fields) {
+			return "", nil, ErrCursorMismatch
+		}
+		predicate, params = keysetPredicate(p.Fields, after, params)
+	}
+	sql = strings.Replace(sql, p.Placeholder, predicate, 1)
+	if limit <= 0 {
+		return sql, params, nil
+	}
+
+	limitSQL := fmt.Sprintf("\nLIMIT %d", limit)
+	if start, end, ok := rootLimit(sql); ok {
+		sql = sql[:start] + limitSQL + sql[end:]
+	} else if idx := strings.LastIndex(sql, "\nFOR UPDATE"); idx >= 0 {
+		sql = sql[:idx] + limitSQL + sql[idx:]
+	} else {
+		sql += limitSQL
+	}
+
+	return sql, params, nil
+}
+
+// WithoutLimit removes the root LIMIT from the SQL of a query, so it matches
+// every record the query selects. Subquery limits are kept.
+func WithoutLimit(sql string) string {
+	if start, end, ok := rootLimit(sql); ok {
+		return sql[:start] + sql[end:]
+	}
+	return sql
+}
+
+// rootLimit locates the root LIMIT clause of sql. The root LIMIT is always
+// the last line before an optional FOR UPDATE; subquery limits are emitted
+// inline and never start a line.
+func rootLimit(sql string) (start, end int, ok bool) {
+	start = strings.LastIndex(sql, "\nLIMIT ")
+	if start < 0 {
+		return 0, 0, false
+	}
+	end = start + len("\nLIMIT ")
+	for end < len(sql) && sql[end] >= '0' && sql[end] <= '9' {
+		end++
+	}
+	return start, end, true
+}
+
+// keysetPredicate builds the row-after-row condition for mixed sort directions:
+//
+//	(k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+//
+// honouring NULL placement, since NULLs compare neither greater nor equal.
+func keysetPredicate(fields []*KeysetField, after []any, params []any) (string, []any) {
+	placeholders := make([]string, len(after))
+	for i, v := range after {
+		if v != nil {
+			params = append(params, v)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+	}
+
+	var disjuncts []string
+	for i, f := range fields {
+		col := f.sortExpr()
+
+		var cond string
+		if placeholders[i] == "" {
+			// After NULL: only non-NULLs follow, and only if NULLs come first.
+			if !f.NullsFirst {
+				continue
+			}
+			cond = col + " IS NOT NULL"
+		} else {
+			op := ">"
+			if f.Direction == "desc" {
+				op = "<"
+			}
+			cond = fmt.Sprintf("%s %s %s", col, op, placeholders[i])
+			if !f.NullsFirst {
+				cond = fmt.Sprintf("(%s OR %s IS NULL)", cond, col)
+			}
+		}
+
+		conj := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			prev := fields[j].sortExpr()
+			if placeholders[j] == "" {
+				conj = append(conj, prev+" IS NULL")
+			} else {
+				conj = append(conj, fmt.Sprintf("%s = %s", prev, placeholders[j]))
+			}
+		}
+		conj = append(conj, cond)
+		disjuncts = append(disjuncts, "("+strings.Join(conj, " AND ")+")")
+	}
+
+	if len(disjuncts) == 0 {
+		return "FALSE", params
+	}
+	return "(" + strings.Join(disjuncts, " OR ") + ")", params
+}
@@ -2,13 +2,13 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 )
 
 func TestKeysetFieldsInSelect(t *testing.T) {
-	t.Skip("TODO: Requires full SOQL parser and compiler implementation")
-
 	metadata := setupTestMetadata()
 	validator := NewValidator(metadata, nil, nil)
 	compiler := NewCompiler(nil)
@@ -64,3 +64,227 @@ func TestKeysetFieldsInSelect(t *testing.T) {
 		})
 	}
 }
+
+func TestCompilePagination(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		query         string
+		wantPaginated bool
+		wantKeys      []string
+	}{
+		{
+			name:          "default order by id",
+			query:         "SELECT Name FROM Account",
+			wantPaginated: true,
+			wantKeys:      []string{"Id"},
+		},
+		{
+			name:          "explicit order gets tie-breaker",
+			query:         "SELECT Name FROM Account ORDER BY Industry DESC, Name",
+			wantPaginated: true,
+			wantKeys:      []string{"Industry", "Name", "Id"},
+		},
+		{
+			name:  "aggregate query is not paginated",
+			query: "SELECT Industry, COUNT(Id) FROM Account GROUP BY Industry",
+		},
+		{
+			name:  "select row is not paginated",
+			query: "SELECT ROW Name FROM Account WHERE Name = 'Acme'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			compiled := compileForRLS(t, tt.query)
+
+			if !tt.wantPaginated {
+				if compiled.Pagination != nil {
+					t.Fatalf("Pagination = %+v, want nil", compiled.Pagination)
+				}
+				if strings.Contains(compiled.SQL, "{{keyset:") {
+					t.Errorf("unexpected keyset placeholder\nSQL: %s", compiled.SQL)
+				}
+				if strings.Contains(compiled.SQL, `t0."id" AS`) && strings.Contains(compiled.SQL, "GROUP BY") {
+					t.Errorf("tie-breaker must not be selected in aggregate query\nSQL: %s", compiled.SQL)
+				}
+				return
+			}
+
+			p := compiled.Pagination
+			if p == nil {
+				t.Fatalf("Pagination = nil\nSQL: %s", compiled.SQL)
+			}
+			if strings.Count(compiled.SQL, p.Placeholder) != 1 {
+				t.Errorf("keyset placeholder must appear exactly once\nSQL: %s", compiled.SQL)
+			}
+			if len(p.Fields) != len(tt.wantKeys) {
+				t.Fatalf("keyset fields = %d, want %d", len(p.Fields), len(tt.wantKeys))
+			}
+			for i, want := range tt.wantKeys {
+				if p.Fields[i].SOQLName != want {
+					t.Errorf("Fields[%d] = %s, want %s", i, p.Fields[i].SOQLName, want)
+				}
+				if p.Fields[i].ResultAlias == "" {
+					t.Errorf("Fields[%d] (%s) is not selected", i, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyKeyset(t *testing.T) {
+	t.Parallel()
+
+	compiled := compileForRLS(t, "SELECT Name FROM Account ORDER BY Name")
+	sql, params, err := ApplyRLS(context.Background(), compiled, nil)
+	if err != nil {
+		t.Fatalf("ApplyRLS() error = %v", err)
+	}
+	base := len(params)
+
+	tests := []struct {
+		name       string
+		after      []any
+		limit      int
+		wantSQL    []string
+		wantParams int
+		wantErr    error
+	}{
+		{
+			name:    "first page",
+			limit:   11,
+			wantSQL: []string{"AND TRUE", "\nLIMIT 11"},
+		},
+		{
+			name:  "after value",
+			after: []any{"Acme", "id-1"},
+			limit: 11,
+			wantSQL: []string{
+				fmt.Sprintf(`((t0."name" > $%[1]d OR t0."name" IS NULL)) OR (t0."name" = $%[1]d AND (t0."id" > $%[2]d`, base+1, base+2),
+				"\nLIMIT 11",
+			},
+			wantParams: 2,
+		},
+		{
+			name:  "after null sorted last",
+			after: []any{nil, "id-1"},
+			limit: 11,
+			wantSQL: []string{
+				fmt.Sprintf(`((t0."name" IS NULL AND (t0."id" > $%d`, base+1),
+			},
+			wantParams: 1,
+		},
+		{
+			name:    "wrong number of values",
+			after:   []any{"Acme"},
+			limit:   11,
+			wantErr: ErrCursorMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotParams, err := ApplyKeyset(compiled, sql, params, tt.after, tt.limit)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ApplyKeyset() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyKeyset() error = %v", err)
+			}
+			if strings.Contains(got, "{{keyset:") {
+				t.Errorf("unresolved keyset placeholder\nSQL: %s", got)
+			}
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(got, want) {
+					t.Errorf("SQL does not contain %q\nGot: %s", want, got)
+				}
+			}
+			if len(gotParams) != base+tt.wantParams {
+				t.Errorf("params = %d, want %d", len(gotParams), base+tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestApplyKeyset_NullsFirst(t *testing.T) {
+	t.Parallel()
+
+	compiled := compileForRLS(t, "SELECT Name FROM Account ORDER BY Name DESC")
+	sql, params, err := ApplyRLS(context.Background(), compiled, nil)
+	if err != nil {
+		t.Fatalf("ApplyRLS() error = %v", err)
+	}
+
+	// DESC puts NULLs first: after a NULL only non-NULL names follow,
+	// and the tie-breaker inherits the descending direction.
+	got, _, err := ApplyKeyset(compiled, sql, params, []any{nil, "id-1"}, 5)
+	if err != nil {
+		t.Fatalf("ApplyKeyset() error = %v", err)
+	}
+	want := fmt.Sprintf(`((t0."name" IS NOT NULL) OR (t0."name" IS NULL AND t0."id" < $%d))`, len(params)+1)
+	if !strings.Contains(got, want) {
+		t.Errorf("SQL does not contain %q\nGot: %s", want, got)
+	}
+}
+
+func TestWithoutLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "root limit",
+			sql:  "SELECT t0.\"name\"\nFROM obj_account AS t0\nORDER BY t0.\"id\"\nLIMIT 10",
+			want: "SELECT t0.\"name\"\nFROM obj_account AS t0\nORDER BY t0.\"id\"",
+		},
+		{
+			name: "subquery limit is kept",
+			sql:  "SELECT (SELECT x FROM c LIMIT 5)\nFROM obj_account AS t0\nLIMIT 10",
+			want: "SELECT (SELECT x FROM c LIMIT 5)\nFROM obj_account AS t0",
+		},
+		{
+			name: "limit before FOR UPDATE",
+			sql:  "SELECT t0.\"name\"\nFROM obj_account AS t0\nLIMIT 1\nFOR UPDATE",
+			want: "SELECT t0.\"name\"\nFROM obj_account AS t0\nFOR UPDATE",
+		},
+		{
+			name: "no limit",
+			sql:  "SELECT (SELECT x FROM c LIMIT 5)\nFROM obj_account AS t0",
+			want: "SELECT (SELECT x FROM c LIMIT 5)\nFROM obj_account AS t0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := WithoutLimit(tt.sql); got != tt.want {
+				t.Errorf("WithoutLimit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithoutLimit_CompiledQuery(t *testing.T) {
+	t.Parallel()
+
+	compiled := compileForRLS(t, "SELECT Name FROM Account ORDER BY Name LIMIT 10")
+	if !strings.Contains(compiled.SQL, "\nLIMIT 10") {
+		t.Fatalf("compiled SQL has no root LIMIT\nSQL: %s", compiled.SQL)
+	}
+	if got := WithoutLimit(compiled.SQL); strings.Contains(got, "LIMIT") {
+		t.Errorf("LIMIT not removed\nSQL: %s", got)
+	}
+}
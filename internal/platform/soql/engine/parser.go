@@ -11,30 +11,46 @@ import (
 // Parser is the SOQL parser
 var Parser = participle.MustBuild[Grammar](
 	participle.Lexer(Lexer),
-	participle.CaseInsensitive("Keyword"),
+	participle.CaseInsensitive("Keyword", "Ident"),
 	participle.Elide("Whitespace", "Comment"),
+	unquoteStrings(),
+	unquoteIdents(Lexer),
+	trimBinds(),
+)
 
-	// Unquote string literals (remove surrounding quotes and handle escapes)
-	participle.Map(func(token lexer.Token) (lexer.Token, error) {
+// unquoteStrings removes surrounding quotes from string literals and handles escapes.
+func unquoteStrings() participle.Option {
+	return participle.Map(func(token lexer.Token) (lexer.Token, error) {
 		// Remove surrounding single quotes and unescape ''
 		s := token.Value[1 : len(token.Value)-1]
 		s = strings.ReplaceAll(s, "''", "'")
 		token.Value = s
 		return token, nil
-	}, "String"),
+	}, "String")
+}
 
-	// Handle quoted identifiers
-	participle.Map(func(token lexer.Token) (lexer.Token, error) {
+// unquoteIdents turns quoted identifiers into plain identifiers of the given lexer.
+func unquoteIdents(def lexer.Definition) participle.Option {
+	ident := def.Symbols()["Ident"]
+	return participle.Map(func(token lexer.Token) (lexer.Token, error) {
 		// Unquote the identifier
 		value, err := strconv.Unquote(token.Value)
 		if err != nil {
 			return token, participle.Errorf(token.Pos, "invalid quoted identifier %q: %s", token.Value, err.Error())
 		}
-		token.Type = Lexer.Symbols()["Ident"]
+		token.Type = ident
 		token.Value = value
 		return token, nil
-	}, "QuotedIdent"),
-)
+	}, "QuotedIdent")
+}
+
+// trimBinds strips the leading colon from bind variable names.
+func trimBinds() participle.Option {
+	return participle.Map(func(token lexer.Token) (lexer.Token, error) {
+		token.Value = token.Value[1:]
+		return token, nil
+	}, "Bind")
+}
 
 // Parse parses a SOQL query string into an AST
 func Parse(query string) (*Grammar, error) {
@@ -456,6 +456,31 @@ func TestParseGroupBy(t *testing.T) {
 			query:   "SELECT Industry, COUNT(Id) cnt FROM Account GROUP BY Industry HAVING COUNT(Id) > 5",
 			wantErr: false,
 		},
+		{
+			name:    "group by date function",
+			query:   "SELECT calendar_year(CreatedDate), COUNT(Id) FROM Account GROUP BY CALENDAR_YEAR(CreatedDate)",
+			wantErr: false,
+		},
+		{
+			name:    "group by rollup",
+			query:   "SELECT Industry, GROUPING(Industry), COUNT(Id) FROM Account GROUP BY ROLLUP(Industry, Type)",
+			wantErr: false,
+		},
+		{
+			name:    "group by cube with date function",
+			query:   "SELECT Industry, COUNT(Id) FROM Account GROUP BY cube(Industry, FISCAL_QUARTER(CreatedDate))",
+			wantErr: false,
+		},
+		{
+			name:    "field named like a grouping set",
+			query:   "SELECT Rollup, COUNT(Id) FROM Account GROUP BY Rollup",
+			wantErr: false,
+		},
+		{
+			name:    "empty rollup",
+			query:   "SELECT Industry FROM Account GROUP BY ROLLUP()",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -498,6 +523,11 @@ func TestParseRelationshipSubquery(t *testing.T) {
 			query:   "SELECT Name, (SELECT Subject FROM Tasks), (SELECT Subject FROM Events) FROM Account",
 			wantErr: false,
 		},
+		{
+			name:    "aggregate subquery with having",
+			query:   "SELECT Name, (SELECT COUNT(Id), SUM(Amount) pipeline FROM Deals WHERE Stage = 'Open' HAVING SUM(Amount) > 0) FROM Account",
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -818,6 +848,68 @@ func intPtr(i int) *int {
 	return &i
 }
 
+func TestParseExists(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		wantErr      bool
+		wantNot      bool
+		wantRel      string
+		wantFiltered bool
+	}{
+		{
+			name:         "EXISTS with condition",
+			query:        "SELECT Name FROM Account WHERE EXISTS Contacts(Email LIKE '%@acme.com')",
+			wantRel:      "Contacts",
+			wantFiltered: true,
+		},
+		{
+			name:         "NOT EXISTS",
+			query:        "SELECT Name FROM Account WHERE NOT EXISTS Opportunities(StageName = 'Open')",
+			wantNot:      true,
+			wantRel:      "Opportunities",
+			wantFiltered: true,
+		},
+		{
+			name:    "without condition",
+			query:   "select Name from Account where exists Contacts",
+			wantRel: "Contacts",
+		},
+		{
+			name:    "missing relationship",
+			query:   "SELECT Name FROM Account WHERE EXISTS (Email = 'x')",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			not := ast.Where.Or.And[0].Not[0]
+			if not.Not != tt.wantNot {
+				t.Errorf("Not = %v, want %v", not.Not, tt.wantNot)
+			}
+			ex := not.Compare.Left.primary().Exists
+			if ex == nil {
+				t.Fatal("expected EXISTS expression")
+			}
+			if ex.Relationship != tt.wantRel {
+				t.Errorf("Relationship = %q, want %q", ex.Relationship, tt.wantRel)
+			}
+			if (ex.Where != nil) != tt.wantFiltered {
+				t.Errorf("Where = %v, want filtered %v", ex.Where, tt.wantFiltered)
+			}
+		})
+	}
+}
+
 func TestParseFunctions(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1417,6 +1509,58 @@ func TestParseWithSecurityEnforced(t *testing.T) {
 	}
 }
 
+func TestParseUsingScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantErr   bool
+		wantScope FilterScope
+	}{
+		{
+			name:      "mine",
+			query:     "SELECT Name FROM Account USING SCOPE mine",
+			wantScope: FilterScopeMine,
+		},
+		{
+			name:      "case insensitive",
+			query:     "select Name from Account using scope My_Team_Territory",
+			wantScope: FilterScopeMyTeamTerritory,
+		},
+		{
+			name:      "before WHERE and ORDER BY",
+			query:     "SELECT Name FROM Opportunity USING SCOPE team WHERE Amount > 0 ORDER BY Name",
+			wantScope: FilterScopeTeam,
+		},
+		{
+			name:  "no scope",
+			query: "SELECT Name FROM Account",
+		},
+		{
+			name:    "unknown scope",
+			query:   "SELECT Name FROM Account USING SCOPE friends",
+			wantErr: true,
+		},
+		{
+			name:    "scope after WHERE",
+			query:   "SELECT Name FROM Account WHERE Name = 'x' USING SCOPE mine",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := Parse(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && ast.Scope != tt.wantScope {
+				t.Errorf("Scope = %q, want %q", ast.Scope, tt.wantScope)
+			}
+		})
+	}
+}
+
 func TestParseForUpdate(t *testing.T) {
 	tests := []struct {
 		name      string
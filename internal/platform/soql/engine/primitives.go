@@ -701,3 +701,73 @@ func (f Function) MaxArgs() int {
 		return 0
 	}
 }
+
+// DateFunction represents the date bucketing functions used with GROUP BY.
+type DateFunction int
+
+const (
+	DateFuncCalendarMonth DateFunction = iota
+	DateFuncCalendarQuarter
+	DateFuncCalendarYear
+	DateFuncFiscalQuarter
+	DateFuncDayOnly
+	DateFuncWeekInYear
+	DateFuncHourInDay
+)
+
+func (f *DateFunction) Capture(s []string) error {
+	val := strings.ToUpper(strings.TrimSpace(s[0]))
+	switch val {
+	case "CALENDAR_MONTH":
+		*f = DateFuncCalendarMonth
+	case "CALENDAR_QUARTER":
+		*f = DateFuncCalendarQuarter
+	case "CALENDAR_YEAR":
+		*f = DateFuncCalendarYear
+	case "FISCAL_QUARTER":
+		*f = DateFuncFiscalQuarter
+	case "DAY_ONLY":
+		*f = DateFuncDayOnly
+	case "WEEK_IN_YEAR":
+		*f = DateFuncWeekInYear
+	case "HOUR_IN_DAY":
+		*f = DateFuncHourInDay
+	default:
+		return fmt.Errorf("unknown date function: %s", val)
+	}
+	return nil
+}
+
+func (f DateFunction) String() string {
+	switch f {
+	case DateFuncCalendarMonth:
+		return "CALENDAR_MONTH"
+	case DateFuncCalendarQuarter:
+		return "CALENDAR_QUARTER"
+	case DateFuncCalendarYear:
+		return "CALENDAR_YEAR"
+	case DateFuncFiscalQuarter:
+		return "FISCAL_QUARTER"
+	case DateFuncDayOnly:
+		return "DAY_ONLY"
+	case DateFuncWeekInYear:
+		return "WEEK_IN_YEAR"
+	case DateFuncHourInDay:
+		return "HOUR_IN_DAY"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// NeedsTime reports whether the function only applies to datetime fields.
+func (f DateFunction) NeedsTime() bool {
+	return f == DateFuncDayOnly || f == DateFuncHourInDay
+}
+
+// ResultType returns the type of the bucket the function produces.
+func (f DateFunction) ResultType() FieldType {
+	if f == DateFuncDayOnly {
+		return FieldTypeDate
+	}
+	return FieldTypeInteger
+}
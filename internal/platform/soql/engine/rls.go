@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RLSTargetKind describes where a table reference appears in the compiled SQL.
+type RLSTargetKind int
+
+const (
+	// RLSTargetRoot is the FROM object of the query.
+	RLSTargetRoot RLSTargetKind = iota
+	// RLSTargetLookup is a parent object reached through a lookup (LEFT JOIN).
+	RLSTargetLookup
+	// RLSTargetSubquery is a child object of a relationship subquery.
+	RLSTargetSubquery
+	// RLSTargetWhereSubquery is the object of a WHERE semi-join: IN (SELECT ...) or EXISTS.
+	RLSTargetWhereSubquery
+	// RLSTargetScope narrows the root object to the USING SCOPE filter scope.
+	RLSTargetScope
+)
+
+func (k RLSTargetKind) String() string {
+	switch k {
+	case RLSTargetRoot:
+		return "root"
+	case RLSTargetLookup:
+		return "lookup"
+	case RLSTargetSubquery:
+		return "subquery"
+	case RLSTargetWhereSubquery:
+		return "where_subquery"
+	case RLSTargetScope:
+		return "scope"
+	default:
+		return "unknown"
+	}
+}
+
+// RLSTarget identifies a table reference in CompiledQuery.SQL whose rows
+// must be restricted by row-level security before the query is executed.
+//
+// The compiler leaves Placeholder in the SQL where the predicate belongs:
+// in the root WHERE clause, in the ON clause of a lookup join (so invisible
+// parents come back as NULL), and in the WHERE clause of every subquery.
+// A USING SCOPE filter adds a second root target of kind RLSTargetScope,
+// since scopes also depend on the current user.
+type RLSTarget struct {
+	Placeholder string        // Marker embedded in SQL, replaced by ApplyRLS
+	Object      string        // SOQL object name
+	Alias       string        // SQL alias of the table reference
+	Kind        RLSTargetKind // Position of the reference in the query
+	Scope       FilterScope   // Filter scope of an RLSTargetScope target
+}
+
+// RLSPredicateFunc returns a boolean SQL predicate restricting rows of the
+// target to those visible to the current user. Columns must be qualified with
+// target.Alias. Bind placeholders are numbered from $1 and are renumbered
+// when spliced into the query.
+type RLSPredicateFunc func(ctx context.Context, target *RLSTarget) (string, []any, error)
+
+// rlsParamPattern matches positional bind placeholders in an RLS predicate.
+var rlsParamPattern = regexp.MustCompile(`\$(\d+)`)
+
+// ApplyRLS returns the compiled SQL with every RLS placeholder replaced by the
+// predicate produced by fn, and the params extended with the predicate binds.
+// A nil fn replaces every placeholder with TRUE (trusted system context).
+// The compiled query is not modified, so cached queries stay reusable.
+func ApplyRLS(ctx context.Context, compiled *CompiledQuery, fn RLSPredicateFunc) (string, []any, error) {
+	sql := compiled.SQL
+	params := make([]any, len(compiled.Params), len(compiled.Params)+len(compiled.RLSTargets))
+	copy(params, compiled.Params)
+
+	for _, target := range compiled.RLSTargets {
+		predicate := "TRUE"
+		if fn != nil {
+			clause, clauseParams, err := fn(ctx, target)
+			if err != nil {
+				return "", nil, fmt.Errorf("engine.ApplyRLS: %s %s: %w", target.Kind, target.Object, err)
+			}
+			if clause != "" {
+				offset := len(params)
+				predicate = rlsParamPattern.ReplaceAllStringFunc(clause, func(m string) string {
+					n, _ := strconv.Atoi(m[1:])
+					return "$" + strconv.Itoa(n+offset)
+				})
+				params = append(params, clauseParams...)
+			}
+		}
+		sql = strings.Replace(sql, target.Placeholder, predicate, 1)
+	}
+
+	return sql, params, nil
+}
+
+// newRLSNonce returns a random token that makes RLS placeholders of a compiled
+// query impossible to forge from string literals in the SOQL text.
+func newRLSNonce() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("engine: rls nonce: %v", err))
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// addRLSTarget registers a table reference that requires an RLS predicate and
+// returns the condition to embed in the SQL: the placeholder, followed by the
+// recycle bin filter when the object keeps deleted records and the query is
+// not ALL ROWS.
+func (c *Compiler) addRLSTarget(ctx *compileContext, kind RLSTargetKind, object *ObjectMeta, alias string) string {
+	placeholder := c.newRLSTarget(ctx, kind, object.Name, alias).Placeholder
+	if object.DeletedColumn != "" && !ctx.allRows {
+		placeholder += " AND " + qualifiedColumn(alias, object.DeletedColumn) + " = false"
+	}
+	return placeholder
+}
+
+// addScopeTarget registers the USING SCOPE filter of the root object and
+// returns the placeholder to embed in the SQL.
+func (c *Compiler) addScopeTarget(ctx *compileContext, scope FilterScope, object, alias string) string {
+	target := c.newRLSTarget(ctx, RLSTargetScope, object, alias)
+	target.Scope = scope
+	return target.Placeholder
+}
+
+func (c *Compiler) newRLSTarget(ctx *compileContext, kind RLSTargetKind, object, alias string) *RLSTarget {
+	target := &RLSTarget{
+		Placeholder: fmt.Sprintf("{{rls:%s:%d}}", ctx.rlsNonce, len(ctx.rlsTargets)),
+		Object:      object,
+		Alias:       alias,
+		Kind:        kind,
+	}
+	ctx.rlsTargets = append(ctx.rlsTargets, target)
+	return target
+}
@@ -0,0 +1,447 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func compileForRLS(t *testing.T, query string) *CompiledQuery {
+	t.Helper()
+
+	ast, err := Parse(query)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	validated, err := NewValidator(setupTestMetadata(), nil, nil).Validate(context.Background(), ast)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	compiled, err := NewCompiler(nil).Compile(validated)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	return compiled
+}
+
+func TestCompileRLSTargets(t *testing.T) {
+	t.Parallel()
+
+	type target struct {
+		kind   RLSTargetKind
+		object string
+		alias  string
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []target
+	}{
+		{
+			name:  "root only",
+			query: "SELECT Name FROM Account",
+			want:  []target{{RLSTargetRoot, "Account", "t0"}},
+		},
+		{
+			name:  "parent lookup",
+			query: "SELECT Name, Account.Name FROM Contact",
+			want: []target{
+				{RLSTargetLookup, "Account", "t1"},
+				{RLSTargetRoot, "Contact", "t0"},
+			},
+		},
+		{
+			name:  "nested parent lookup",
+			query: "SELECT Name, Account.Owner.Name FROM Contact",
+			want: []target{
+				{RLSTargetLookup, "Account", "t1"},
+				{RLSTargetLookup, "User", "t2"},
+				{RLSTargetRoot, "Contact", "t0"},
+			},
+		},
+		{
+			name:  "child subquery",
+			query: "SELECT Name, (SELECT Name FROM Contacts) FROM Account",
+			want: []target{
+				{RLSTargetSubquery, "Contact", "sq"},
+				{RLSTargetRoot, "Account", "t0"},
+			},
+		},
+		{
+			name:  "where subquery",
+			query: "SELECT Name FROM Account WHERE Id IN (SELECT AccountId FROM Contact)",
+			want: []target{
+				{RLSTargetWhereSubquery, "Contact", "wsq"},
+				{RLSTargetRoot, "Account", "t0"},
+			},
+		},
+		{
+			name:  "exists filter",
+			query: "SELECT Name FROM Account WHERE NOT EXISTS Contacts(Email LIKE '%@acme.com')",
+			want: []target{
+				{RLSTargetWhereSubquery, "Contact", "wsq"},
+				{RLSTargetRoot, "Account", "t0"},
+			},
+		},
+		{
+			name:  "filter scope",
+			query: "SELECT Name, Owner.Name FROM Opportunity USING SCOPE team",
+			want: []target{
+				{RLSTargetLookup, "User", "t1"},
+				{RLSTargetRoot, "Opportunity", "t0"},
+				{RLSTargetScope, "Opportunity", "t0"},
+			},
+		},
+		{
+			name:  "everything scope does not filter",
+			query: "SELECT Name FROM Opportunity USING SCOPE everything",
+			want:  []target{{RLSTargetRoot, "Opportunity", "t0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			compiled := compileForRLS(t, tt.query)
+
+			if len(compiled.RLSTargets) != len(tt.want) {
+				t.Fatalf("RLSTargets count = %d, want %d\nSQL: %s", len(compiled.RLSTargets), len(tt.want), compiled.SQL)
+			}
+			for i, w := range tt.want {
+				got := compiled.RLSTargets[i]
+				if got.Kind != w.kind || got.Object != w.object || got.Alias != w.alias {
+					t.Errorf("RLSTargets[%d] = {%s %s %s}, want {%s %s %s}",
+						i, got.Kind, got.Object, got.Alias, w.kind, w.object, w.alias)
+				}
+				if strings.Count(compiled.SQL, got.Placeholder) != 1 {
+					t.Errorf("placeholder %s must appear exactly once in SQL: %s", got.Placeholder, compiled.SQL)
+				}
+			}
+		})
+	}
+}
+
+// tableRefPattern matches every table reference the compiler emits:
+// the root (t0), lookup joins (t1..tN), child subqueries (sq) and semi-joins (wsq).
+var tableRefPattern = regexp.MustCompile(`" AS (t\d+|sq|wsq)\b`)
+
+func TestApplyRLS_NoRelationshipPathBypassesSharing(t *testing.T) {
+	t.Parallel()
+
+	queries := []string{
+		"SELECT Name FROM Account",
+		"SELECT Name, Account.Name FROM Contact",
+		"SELECT Name, Account.Owner.Name, Account.Owner.Manager.Name FROM Contact WHERE Account.Industry = 'Tech'",
+		"SELECT Name FROM Contact ORDER BY Account.Name",
+		"SELECT Name, (SELECT Name FROM Contacts), (SELECT Name, Amount FROM Opportunities WHERE Amount > 100) FROM Account",
+		"SELECT Name, Owner.Name, (SELECT Email FROM Contacts) FROM Account WHERE Id IN (SELECT AccountId FROM Opportunity WHERE Amount > 0)",
+		"SELECT Name FROM Account WHERE Id NOT IN (SELECT AccountId FROM Contact) OR Industry = 'Tech'",
+		"SELECT Subject, TYPEOF WhatId WHEN Account THEN Name WHEN Opportunity THEN Name, Amount END FROM Task",
+		"SELECT StageName, COUNT(Id) FROM Opportunity WHERE Account.Industry = 'Tech' GROUP BY StageName",
+		"SELECT Name, (SELECT COUNT(Id), SUM(Amount) FROM Opportunities HAVING COUNT(Id) > 0), (SELECT Email FROM Contacts) FROM Account",
+		"SELECT Name FROM Account WHERE EXISTS Contacts(Email LIKE '%@acme.com') AND NOT EXISTS Opportunities",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			t.Parallel()
+
+			compiled := compileForRLS(t, query)
+
+			refs := tableRefPattern.FindAllStringSubmatch(compiled.SQL, -1)
+			if len(refs) != len(compiled.RLSTargets) {
+				t.Fatalf("table references = %d, RLS targets = %d\nSQL: %s", len(refs), len(compiled.RLSTargets), compiled.SQL)
+			}
+
+			// Deny everything: each target gets a predicate that can only be
+			// satisfied by the (absent) owner passed as a bind parameter.
+			seen := make(map[string]int)
+			sql, params, err := ApplyRLS(context.Background(), compiled, func(_ context.Context, target *RLSTarget) (string, []any, error) {
+				seen[target.Alias]++
+				return fmt.Sprintf("%s.owner_id = $1", target.Alias), []any{target.Object}, nil
+			})
+			if err != nil {
+				t.Fatalf("ApplyRLS() error = %v", err)
+			}
+
+			for _, ref := range refs {
+				alias := ref[1]
+				if seen[alias] == 0 {
+					t.Errorf("table alias %s has no RLS predicate\nSQL: %s", alias, sql)
+				}
+			}
+			if strings.Contains(sql, "{{rls:") {
+				t.Errorf("unresolved RLS placeholder in SQL: %s", sql)
+			}
+			if len(params) != len(compiled.Params)+len(compiled.RLSTargets) {
+				t.Errorf("params = %d, want %d", len(params), len(compiled.Params)+len(compiled.RLSTargets))
+			}
+			for i := len(compiled.Params); i < len(params); i++ {
+				if !strings.Contains(sql, fmt.Sprintf("owner_id = $%d", i+1)) {
+					t.Errorf("RLS param $%d is not referenced\nSQL: %s", i+1, sql)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyRLS_LookupPredicateInJoinCondition(t *testing.T) {
+	t.Parallel()
+
+	compiled := compileForRLS(t, "SELECT Name, Account.Name FROM Contact")
+
+	sql, _, err := ApplyRLS(context.Background(), compiled, func(_ context.Context, target *RLSTarget) (string, []any, error) {
+		return target.Alias + ".owner_id = $1", []any{"u"}, nil
+	})
+	if err != nil {
+		t.Fatalf("ApplyRLS() error = %v", err)
+	}
+
+	// Invisible parents must be nulled out by the LEFT JOIN, not filter the child row.
+	want := `LEFT JOIN "accounts" AS t1 ON t0."account_id" = t1."id" AND t1.owner_id = $1`
+	if !strings.Contains(sql, want) {
+		t.Errorf("SQL does not contain %q\nGot: %s", want, sql)
+	}
+	if !strings.Contains(sql, "WHERE t0.owner_id = $2") {
+		t.Errorf("root predicate missing\nGot: %s", sql)
+	}
+}
+
+func TestApplyRLS_ScopeNarrowsRoot(t *testing.T) {
+	t.Parallel()
+
+	compiled := compileForRLS(t, "SELECT Name FROM Opportunity USING SCOPE mine WHERE Amount > 0")
+
+	sql, _, err := ApplyRLS(context.Background(), compiled, func(_ context.Context, target *RLSTarget) (string, []any, error) {
+		if target.Kind == RLSTargetScope {
+			if target.Scope != FilterScopeMine {
+				t.Errorf("Scope = %q, want mine", target.Scope)
+			}
+			return target.Alias + ".owner_id = $1", []any{"me"}, nil
+		}
+		return "visible(" + target.Alias + ")", nil, nil
+	})
+	if err != nil {
+		t.Fatalf("ApplyRLS() error = %v", err)
+	}
+
+	// The scope narrows the visible rows; it never replaces the sharing predicate.
+	want := `WHERE t0."amount" > 0 AND visible(t0) AND t0.owner_id = $1`
+	if !strings.Contains(sql, want) {
+		t.Errorf("SQL does not contain %q\nGot: %s", want, sql)
+	}
+}
+
+func TestValidateUsingScope(t *testing.T) {
+	t.Parallel()
+
+	validator := NewValidator(setupTestMetadata(), nil, nil)
+
+	for _, query := range []string{
+		"SELECT Name FROM Contact USING SCOPE mine",
+		"SELECT Name FROM Lead USING SCOPE my_territory",
+	} {
+		t.Run(query, func(t *testing.T) {
+			t.Parallel()
+			ast, err := Parse(query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			_, err = validator.Validate(context.Background(), ast)
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Code != ErrCodeInvalidExpression {
+				t.Errorf("Validate() error = %v, want InvalidExpression", err)
+			}
+		})
+	}
+
+	t.Run("everything needs no owner", func(t *testing.T) {
+		t.Parallel()
+		ast, err := Parse("SELECT Name FROM Contact USING SCOPE everything")
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if _, err := validator.Validate(context.Background(), ast); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+	})
+}
+
+func TestApplyRLS_RenumbersParams(t *testing.T) {
+	t.Parallel()
+
+	compiled := compileForRLS(t, "SELECT Name FROM Account WHERE CreatedDate = TODAY")
+	base := len(compiled.Params)
+
+	var ownerParams []any
+	var placeholders []string
+	for i := 1; i <= 11; i++ {
+		ownerParams = append(ownerParams, i)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i))
+	}
+
+	sql, params, err := ApplyRLS(context.Background(), compiled, func(_ context.Context, target *RLSTarget) (string, []any, error) {
+		return fmt.Sprintf("%s.owner_id IN (%s)", target.Alias, strings.Join(placeholders, ",")), ownerParams, nil
+	})
+	if err != nil {
+		t.Fatalf("ApplyRLS() error = %v", err)
+	}
+
+	var want []string
+	for i := 1; i <= 11; i++ {
+		want = append(want, fmt.Sprintf("$%d", i+base))
+	}
+	if !strings.Contains(sql, "t0.owner_id IN ("+strings.Join(want, ",")+")") {
+		t.Errorf("params not renumbered after %d existing params\nGot: %s", base, sql)
+	}
+	if len(params) != base+11 {
+		t.Errorf("params = %d, want %d", len(params), base+11)
+	}
+	if len(compiled.Params) != base {
+		t.Errorf("ApplyRLS must not modify the compiled query params")
+	}
+}
+
+func TestApplyRLS_NilPredicateIsTrusted(t *testing.T) {
+	t.Parallel()
+
+	compiled := compileForRLS(t, "SELECT Name, Account.Name FROM Contact")
+
+	sql, params, err := ApplyRLS(context.Background(), compiled, nil)
+	if err != nil {
+		t.Fatalf("ApplyRLS() error = %v", err)
+	}
+	if strings.Contains(sql, "{{rls:") {
+		t.Errorf("unresolved RLS placeholder in SQL: %s", sql)
+	}
+	if !strings.Contains(sql, "AND TRUE") || !strings.Contains(sql, "WHERE TRUE") {
+		t.Errorf("expected TRUE predicates\nGot: %s", sql)
+	}
+	if len(params) != len(compiled.Params) {
+		t.Errorf("params = %d, want %d", len(params), len(compiled.Params))
+	}
+}
+
+func TestApplyRLS_PredicateError(t *testing.T) {
+	t.Parallel()
+
+	compiled := compileForRLS(t, "SELECT Name, Account.Name FROM Contact")
+	boom := errors.New("boom")
+
+	_, _, err := ApplyRLS(context.Background(), compiled, func(_ context.Context, target *RLSTarget) (string, []any, error) {
+		return "", nil, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("ApplyRLS() error = %v, want %v", err, boom)
+	}
+}
+
+func TestApplyRLS_PlaceholderCannotBeForged(t *testing.T) {
+	t.Parallel()
+
+	probe := compileForRLS(t, "SELECT Name FROM Account")
+	forged := probe.RLSTargets[0].Placeholder
+
+	compiled := compileForRLS(t, fmt.Sprintf("SELECT Name FROM Account WHERE Name = '%s'", forged))
+
+	sql, _, err := ApplyRLS(context.Background(), compiled, func(_ context.Context, target *RLSTarget) (string, []any, error) {
+		return "FALSE", nil, nil
+	})
+	if err != nil {
+		t.Fatalf("ApplyRLS() error = %v", err)
+	}
+	if !strings.Contains(sql, "'"+forged+"'") {
+		t.Errorf("string literal was rewritten\nGot: %s", sql)
+	}
+	if !strings.Contains(sql, "AND FALSE") {
+		t.Errorf("root predicate missing\nGot: %s", sql)
+	}
+}
+
+func TestCompileRecycleBinFilter(t *testing.T) {
+	t.Parallel()
+
+	meta := NewStaticMetadataProvider(map[string]*ObjectMeta{
+		"Account": NewObjectMeta("Account", "", "accounts").
+			Field("Id", "id", FieldTypeID).
+			Field("Name", "name", FieldTypeString).
+			Relationship("Contacts", "Contact", "account_id", "id").
+			SoftDelete("is_deleted").
+			Build(),
+		"Contact": NewObjectMeta("Contact", "", "contacts").
+			Field("Id", "id", FieldTypeID).
+			Field("Name", "name", FieldTypeString).
+			Field("AccountId", "account_id", FieldTypeID).
+			Field("IsDeleted", "is_deleted", FieldTypeBoolean).
+			Lookup("Account", "account_id", "Account", "id").
+			SoftDelete("is_deleted").
+			Build(),
+	})
+
+	tests := []struct {
+		name        string
+		query       string
+		wantContain []string
+		wantAbsent  []string
+	}{
+		{
+			name:        "root skips deleted records",
+			query:       "SELECT Name FROM Contact WHERE Name = 'Ann'",
+			wantContain: []string{`WHERE t0."name" = 'Ann' AND TRUE AND t0."is_deleted" = false`},
+		},
+		{
+			name:        "deleted parents are nulled out",
+			query:       "SELECT Name, Account.Name FROM Contact",
+			wantContain: []string{`ON t0."account_id" = t1."id" AND TRUE AND t1."is_deleted" = false`},
+		},
+		{
+			name:        "subquery skips deleted children",
+			query:       "SELECT Name, (SELECT Name FROM Contacts) FROM Account",
+			wantContain: []string{`WHERE TRUE AND t0."is_deleted" = false`, `= t0."id" AND TRUE AND sq."is_deleted" = false`},
+		},
+		{
+			name:       "ALL ROWS keeps deleted records",
+			query:      "SELECT Name, Account.Name FROM Contact WHERE IsDeleted = true ALL ROWS",
+			wantAbsent: []string{`"is_deleted" = false`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			validated, err := NewValidator(meta, nil, nil).Validate(context.Background(), ast)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			compiled, err := NewCompiler(nil).Compile(validated)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			sql, _, err := ApplyRLS(context.Background(), compiled, nil)
+			if err != nil {
+				t.Fatalf("ApplyRLS() error = %v", err)
+			}
+
+			for _, want := range tt.wantContain {
+				if !strings.Contains(sql, want) {
+					t.Errorf("SQL does not contain %q\nGot: %s", want, sql)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(sql, absent) {
+					t.Errorf("SQL contains %q\nGot: %s", absent, sql)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// SearchRankAlias is the result column holding the text search rank of a SOSL record.
+const SearchRankAlias = "search_rank"
+
+// DefaultSearchLimit caps the number of SOSL records when the search has no LIMIT.
+const DefaultSearchLimit = 200
+
+// SearchScope selects the field group a SOSL search looks in.
+type SearchScope string
+
+const (
+	SearchScopeAll   SearchScope = "ALL"
+	SearchScopeName  SearchScope = "NAME"
+	SearchScopeEmail SearchScope = "EMAIL"
+	SearchScopePhone SearchScope = "PHONE"
+)
+
+// weight returns the tsquery weight label matching the scope (see ObjectMeta.SearchColumn).
+func (s SearchScope) weight() string {
+	switch s {
+	case SearchScopeName:
+		return "A"
+	case SearchScopeEmail:
+		return "B"
+	case SearchScopePhone:
+		return "C"
+	default:
+		return ""
+	}
+}
+
+// Capture implements participle.Capture.
+func (s *SearchScope) Capture(values []string) error {
+	*s = SearchScope(strings.ToUpper(values[0]))
+	return nil
+}
+
+// SearchGrammar is the root AST node representing a SOSL search.
+// Example: FIND {acme*} IN NAME FIELDS RETURNING Account(Name, Industry WHERE Industry = 'Tech'), Contact(Email)
+type SearchGrammar struct {
+	Pos       lexer.Position
+	Find      string             `parser:"'FIND' @SearchText"`
+	Scope     SearchScope        `parser:"('IN' @('ALL' | 'NAME' | 'EMAIL' | 'PHONE') 'FIELDS')?"`
+	Returning []*SearchReturning `parser:"('RETURNING' @@ (',' @@)*)?"`
+	Limit     *int               `parser:"('LIMIT' @Integer)?"`
+}
+
+// SearchReturning is a single object in the RETURNING clause.
+// Without a field list only Id is returned.
+// Example: Account(Name, Industry WHERE Industry = 'Tech' ORDER BY Name LIMIT 10)
+type SearchReturning struct {
+	Pos     lexer.Position
+	Object  string              `parser:"@Ident"`
+	Fields  []*SelectExpression `parser:"( '(' @@ (',' @@)*"`
+	Where   *Expression         `parser:"  ('WHERE' @@)?"`
+	OrderBy []*OrderClause      `parser:"  ('ORDER' 'BY' @@ (',' @@)*)?"`
+	Limit   *int                `parser:"  ('LIMIT' @Integer)? ')' )?"`
+}
+
+// searchLexer extends the SOQL tokens with the braced FIND text.
+var searchLexer = lexer.MustSimple(append([]lexer.SimpleRule{
+	{Name: "SearchText", Pattern: `\{(?:\\.|[^\\}])*\}`},
+}, lexerRules...))
+
+// SearchParser is the SOSL parser. RETURNING clauses reuse the SOQL grammar
+// for fields, WHERE and ORDER BY. SOSL words such as FIND and RETURNING are
+// identifiers, not SOQL keywords, so they are matched case-insensitively too.
+var SearchParser = participle.MustBuild[SearchGrammar](
+	participle.Lexer(searchLexer),
+	participle.CaseInsensitive("Keyword", "Ident"),
+	participle.Elide("Whitespace", "Comment"),
+	unquoteStrings(),
+	unquoteIdents(searchLexer),
+	trimBinds(),
+	participle.Map(func(token lexer.Token) (lexer.Token, error) {
+		token.Value = token.Value[1 : len(token.Value)-1]
+		return token, nil
+	}, "SearchText"),
+)
+
+// ParseSearch parses a SOSL search string into an AST
+func ParseSearch(search string) (*SearchGrammar, error) {
+	return SearchParser.ParseString("", search)
+}
+
+// grammar converts a RETURNING object into a SOQL query restricted to the search.
+func (r *SearchReturning) grammar(tsQuery string, limit int) *Grammar {
+	g := &Grammar{
+		Pos:     r.Pos,
+		Select:  r.Fields,
+		From:    r.Object,
+		Where:   r.Where,
+		OrderBy: r.OrderBy,
+		Limit:   r.Limit,
+		Search:  &SearchClause{TSQuery: tsQuery},
+	}
+	if len(g.Select) == 0 {
+		g.Select = []*SelectExpression{{Pos: r.Pos, Item: &SelectItem{Expr: fieldExpression("Id")}}}
+	}
+	if g.Limit == nil || *g.Limit > limit {
+		g.Limit = &limit
+	}
+	return g
+}
+
+// validateFields rejects SELECT items SOSL does not support.
+func (r *SearchReturning) validateFields() error {
+	for _, sel := range r.Fields {
+		if sel.Item == nil || sel.Item.Expr != nil {
+			continue
+		}
+		return NewValidationErrorWithPos(ErrCodeInvalidExpression, PosFromLexer(sel.Pos),
+			"RETURNING "+r.Object+" supports only fields and expressions")
+	}
+	return nil
+}
+
+// fieldExpression builds the expression tree of a plain field reference.
+func fieldExpression(path ...string) *Expression {
+	return &Expression{Or: &OrExpr{And: []*AndExpr{{Not: []*NotExpr{{Compare: &CompareExpr{
+		Left: &InExpr{Left: &LikeExpr{Left: &IsExpr{Left: &AddExpr{Left: &MulExpr{Left: &UnaryExpr{
+			Primary: &Primary{Field: &Field{Path: path}},
+		}}}}}},
+	}}}}}}}
+}
@@ -0,0 +1,265 @@
+package engine
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minWildcardTermLength is the number of characters a term needs before a
+// trailing wildcard, so that "a*" cannot scan the whole index.
+const minWildcardTermLength = 2
+
+type searchTokenKind int
+
+const (
+	searchTokenWord searchTokenKind = iota
+	searchTokenPhrase
+	searchTokenAnd
+	searchTokenOr
+	searchTokenAndNot
+	searchTokenOpen
+	searchTokenClose
+)
+
+type searchToken struct {
+	kind  searchTokenKind
+	value string
+}
+
+// BuildSearchQuery translates the text of a SOSL FIND clause into a
+// to_tsquery expression restricted to the scope's field group.
+//
+// Terms are ANDed by default; AND, OR, AND NOT and parentheses combine them,
+// "double quotes" match a phrase and a trailing * matches a prefix. Special
+// characters are escaped with a backslash.
+func BuildSearchQuery(text string, scope SearchScope) (string, error) {
+	tokens, err := tokenizeSearch(text)
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "", invalidSearchTerm("search text is empty")
+	}
+
+	p := &searchQueryParser{tokens: tokens, weight: scope.weight()}
+	query, err := p.parseOr()
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(p.tokens) {
+		return "", invalidSearchTerm("unexpected " + p.tokens[p.pos].String())
+	}
+	return query, nil
+}
+
+func invalidSearchTerm(message string) *ValidationError {
+	return NewValidationError(ErrCodeInvalidSearchTerm, "invalid search term: "+message)
+}
+
+func (t searchToken) String() string {
+	switch t.kind {
+	case searchTokenOpen:
+		return `"("`
+	case searchTokenClose:
+		return `")"`
+	case searchTokenPhrase:
+		return `"\"` + t.value + `\""`
+	default:
+		return `"` + t.value + `"`
+	}
+}
+
+// tokenizeSearch splits FIND text into words, phrases, operators and parentheses.
+func tokenizeSearch(text string) ([]searchToken, error) {
+	var tokens []searchToken
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, searchToken{kind: searchTokenOpen, value: "("})
+			i += size
+		case r == ')':
+			tokens = append(tokens, searchToken{kind: searchTokenClose, value: ")"})
+			i += size
+		case r == '"':
+			end := strings.IndexByte(text[i+1:], '"')
+			if end < 0 {
+				return nil, invalidSearchTerm("unterminated phrase")
+			}
+			tokens = append(tokens, searchToken{kind: searchTokenPhrase, value: text[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			word, n := scanSearchWord(text[i:])
+			i += n
+			tokens = appendSearchWord(tokens, word)
+		}
+	}
+	return tokens, nil
+}
+
+// scanSearchWord reads a word up to whitespace or a parenthesis, resolving
+// backslash escapes. An escaped wildcard is kept as a literal character.
+func scanSearchWord(s string) (string, int) {
+	var b strings.Builder
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+			break
+		}
+		if r == '\\' && i+size < len(s) {
+			next, nextSize := utf8.DecodeRuneInString(s[i+size:])
+			if next != '*' && next != '?' {
+				b.WriteRune(next)
+				i += size + nextSize
+				continue
+			}
+		}
+		b.WriteRune(r)
+		i += size
+	}
+	return b.String(), i
+}
+
+// appendSearchWord appends a word, recognizing the boolean operators.
+func appendSearchWord(tokens []searchToken, word string) []searchToken {
+	switch strings.ToUpper(word) {
+	case "AND":
+		return append(tokens, searchToken{kind: searchTokenAnd, value: word})
+	case "OR":
+		return append(tokens, searchToken{kind: searchTokenOr, value: word})
+	case "NOT":
+		if n := len(tokens); n > 0 && tokens[n-1].kind == searchTokenAnd {
+			tokens[n-1] = searchToken{kind: searchTokenAndNot, value: tokens[n-1].value + " " + word}
+			return tokens
+		}
+	}
+	return append(tokens, searchToken{kind: searchTokenWord, value: word})
+}
+
+// searchQueryParser is a recursive descent parser over search tokens:
+//
+//	or   := and ("OR" and)*
+//	and  := term (["AND" | "AND NOT"] term)*
+//	term := word | phrase | "(" or ")"
+type searchQueryParser struct {
+	tokens []searchToken
+	pos    int
+	weight string
+}
+
+func (p *searchQueryParser) peek() (searchToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return searchToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *searchQueryParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	parts := []string{left}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind != searchTokenOr {
+			break
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, right)
+	}
+	if len(parts) == 1 {
+		return left, nil
+	}
+	return "(" + strings.Join(parts, " | ") + ")", nil
+}
+
+func (p *searchQueryParser) parseAnd() (string, error) {
+	query, err := p.parseTerm()
+	if err != nil {
+		return "", err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == searchTokenOr || tok.kind == searchTokenClose {
+			return query, nil
+		}
+		op := " & "
+		switch tok.kind {
+		case searchTokenAnd:
+			p.pos++
+		case searchTokenAndNot:
+			p.pos++
+			op = " & !"
+		}
+		right, err := p.parseTerm()
+		if err != nil {
+			return "", err
+		}
+		query += op + right
+	}
+}
+
+func (p *searchQueryParser) parseTerm() (string, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return "", invalidSearchTerm("unexpected end of search text")
+	}
+	p.pos++
+
+	switch tok.kind {
+	case searchTokenOpen:
+		query, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != searchTokenClose {
+			return "", invalidSearchTerm("missing closing parenthesis")
+		}
+		p.pos++
+		return "(" + query + ")", nil
+	case searchTokenWord:
+		return p.lexeme(tok.value, true)
+	case searchTokenPhrase:
+		return p.lexeme(tok.value, false)
+	default:
+		return "", invalidSearchTerm("unexpected " + tok.String())
+	}
+}
+
+// lexeme quotes a term for to_tsquery and adds the prefix and weight labels.
+func (p *searchQueryParser) lexeme(term string, allowWildcard bool) (string, error) {
+	prefix := false
+	if allowWildcard && strings.HasSuffix(term, "*") && !strings.HasSuffix(term, `\*`) {
+		prefix = true
+		term = strings.TrimSuffix(term, "*")
+		if utf8.RuneCountInString(term) < minWildcardTermLength {
+			return "", invalidSearchTerm("wildcard terms need at least 2 characters")
+		}
+	}
+	if strings.ContainsAny(strings.ReplaceAll(strings.ReplaceAll(term, `\*`, ""), `\?`, ""), "*?") {
+		return "", invalidSearchTerm("wildcards are only supported at the end of a word")
+	}
+	term = strings.NewReplacer(`\*`, "*", `\?`, "?").Replace(term)
+	if strings.TrimSpace(term) == "" {
+		return "", invalidSearchTerm("empty phrase")
+	}
+
+	quoted := "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(strings.ToLower(term)) + "'"
+	labels := p.weight
+	if prefix {
+		labels = "*" + labels
+	}
+	if labels != "" {
+		quoted += ":" + labels
+	}
+	return quoted, nil
+}
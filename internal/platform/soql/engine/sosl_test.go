@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSearch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("full search", func(t *testing.T) {
+		t.Parallel()
+		ast, err := ParseSearch(`find {acme* AND "big corp"} in name fields
+			RETURNING Account(Name, Industry WHERE Industry = 'Tech' ORDER BY Name LIMIT 5), Contact
+			LIMIT 20`)
+		if err != nil {
+			t.Fatalf("ParseSearch() error = %v", err)
+		}
+		if ast.Find != `acme* AND "big corp"` {
+			t.Errorf("Find = %q", ast.Find)
+		}
+		if ast.Scope != SearchScopeName {
+			t.Errorf("Scope = %q, want NAME", ast.Scope)
+		}
+		if len(ast.Returning) != 2 {
+			t.Fatalf("Returning = %d objects, want 2", len(ast.Returning))
+		}
+		account := ast.Returning[0]
+		if account.Object != "Account" || len(account.Fields) != 2 || account.Where == nil ||
+			len(account.OrderBy) != 1 || account.Limit == nil || *account.Limit != 5 {
+			t.Errorf("Account returning = %+v", account)
+		}
+		if contact := ast.Returning[1]; contact.Object != "Contact" || len(contact.Fields) != 0 {
+			t.Errorf("Contact returning = %+v", contact)
+		}
+		if ast.Limit == nil || *ast.Limit != 20 {
+			t.Errorf("Limit = %v, want 20", ast.Limit)
+		}
+	})
+
+	t.Run("escaped brace in search text", func(t *testing.T) {
+		t.Parallel()
+		ast, err := ParseSearch(`FIND {a\}b}`)
+		if err != nil {
+			t.Fatalf("ParseSearch() error = %v", err)
+		}
+		if ast.Find != `a\}b` {
+			t.Errorf("Find = %q", ast.Find)
+		}
+	})
+
+	for _, search := range []string{
+		`FIND acme`,
+		`FIND {acme} IN EVERY FIELDS`,
+		`FIND {acme} RETURNING Account(Name`,
+	} {
+		t.Run("rejects "+search, func(t *testing.T) {
+			t.Parallel()
+			if _, err := ParseSearch(search); err == nil {
+				t.Errorf("ParseSearch(%q) expected error", search)
+			}
+		})
+	}
+}
+
+func TestBuildSearchQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		text    string
+		scope   SearchScope
+		want    string
+		wantErr bool
+	}{
+		{name: "single word", text: "Acme", want: "'acme'"},
+		{name: "implicit AND", text: "acme corp", want: "'acme' & 'corp'"},
+		{name: "prefix", text: "acm*", want: "'acm':*"},
+		{name: "scope weight", text: "acm* smith", scope: SearchScopeName, want: "'acm':*A & 'smith':A"},
+		{name: "email scope", text: "john@acme.com", scope: SearchScopeEmail, want: "'john@acme.com':B"},
+		{name: "OR binds looser than AND", text: "a1 AND b1 OR c1", want: "('a1' & 'b1' | 'c1')"},
+		{name: "AND NOT", text: "acme AND NOT corp", want: "'acme' & !'corp'"},
+		{name: "groups", text: "(acme OR globex) AND corp", want: "(('acme' | 'globex')) & 'corp'"},
+		{name: "phrase", text: `"Big Corp" ltd`, want: "'big corp' & 'ltd'"},
+		{name: "quotes are escaped", text: `o'brien`, want: "'o''brien'"},
+		{name: "escaped wildcard is literal", text: `50\*`, want: "'50*'"},
+		{name: "empty", text: "  ", wantErr: true},
+		{name: "short wildcard", text: "a*", wantErr: true},
+		{name: "leading wildcard", text: "*acme", wantErr: true},
+		{name: "single char wildcard", text: "ac?e", wantErr: true},
+		{name: "dangling operator", text: "acme OR", wantErr: true},
+		{name: "unbalanced parenthesis", text: "(acme", wantErr: true},
+		{name: "unterminated phrase", text: `"acme`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := BuildSearchQuery(tt.text, tt.scope)
+			if tt.wantErr {
+				var verr *ValidationError
+				if !errors.As(err, &verr) || verr.Code != ErrCodeInvalidSearchTerm {
+					t.Errorf("BuildSearchQuery(%q) error = %v, want InvalidSearchTerm", tt.text, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildSearchQuery(%q) error = %v", tt.text, err)
+			}
+			if got != tt.want {
+				t.Errorf("BuildSearchQuery(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_PrepareSearch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	eng := NewEngine(WithMetadata(setupTestMetadata()))
+
+	t.Run("compiles ranked query per object", func(t *testing.T) {
+		t.Parallel()
+		search, err := eng.PrepareSearch(ctx,
+			`FIND {acme*} RETURNING Account(Name WHERE Industry = 'Tech'), Contact(Email)`)
+		if err != nil {
+			t.Fatalf("PrepareSearch() error = %v", err)
+		}
+		if len(search.Queries) != 2 {
+			t.Fatalf("Queries = %d, want 2", len(search.Queries))
+		}
+		if search.Limit != DefaultSearchLimit {
+			t.Errorf("Limit = %d, want %d", search.Limit, DefaultSearchLimit)
+		}
+
+		account := search.Queries[0]
+		for _, want := range []string{
+			`t0."search_vector" @@ to_tsquery('simple', $1)`,
+			`ts_rank(t0."search_vector", to_tsquery('simple', $1)) AS search_rank`,
+			`ORDER BY ts_rank(t0."search_vector", to_tsquery('simple', $1)) DESC, t0."id" DESC`,
+			"t0.\"industry\" = 'Tech' AND t0.\"search_vector\"",
+			"LIMIT 200",
+		} {
+			if !strings.Contains(account.SQL, want) {
+				t.Errorf("Account SQL missing %q:\n%s", want, account.SQL)
+			}
+		}
+		if len(account.Params) != 1 || account.Params[0] != "'acme':*" {
+			t.Errorf("Account params = %v", account.Params)
+		}
+		if account.Pagination != nil {
+			t.Error("search queries must not be paginated")
+		}
+		if len(account.RLSTargets) == 0 {
+			t.Error("search queries must carry RLS targets")
+		}
+		if search.Queries[1].Shape.Object != "Contact" {
+			t.Errorf("second query object = %s, want Contact", search.Queries[1].Shape.Object)
+		}
+	})
+
+	t.Run("explicit ORDER BY replaces ranking order", func(t *testing.T) {
+		t.Parallel()
+		search, err := eng.PrepareSearch(ctx, `FIND {acme} RETURNING Account(Name ORDER BY Name LIMIT 5) LIMIT 50`)
+		if err != nil {
+			t.Fatalf("PrepareSearch() error = %v", err)
+		}
+		sql := search.Queries[0].SQL
+		if !strings.Contains(sql, `ORDER BY t0."name", t0."id"`) || !strings.Contains(sql, "LIMIT 5") {
+			t.Errorf("SQL = %s", sql)
+		}
+	})
+
+	t.Run("default RETURNING searches searchable objects", func(t *testing.T) {
+		t.Parallel()
+		search, err := eng.PrepareSearch(ctx, `FIND {acme}`)
+		if err != nil {
+			t.Fatalf("PrepareSearch() error = %v", err)
+		}
+		var objects []string
+		for _, q := range search.Queries {
+			objects = append(objects, q.Shape.Object)
+		}
+		if strings.Join(objects, ",") != "Account,Contact" {
+			t.Errorf("objects = %v, want [Account Contact]", objects)
+		}
+	})
+
+	t.Run("default RETURNING skips objects without access", func(t *testing.T) {
+		t.Parallel()
+		restricted := NewEngine(
+			WithMetadata(setupTestMetadata()),
+			WithAccessController(&ObjectAccessController{AllowedObjects: map[string]bool{"Contact": true}}),
+		)
+		search, err := restricted.PrepareSearch(ctx, `FIND {acme}`)
+		if err != nil {
+			t.Fatalf("PrepareSearch() error = %v", err)
+		}
+		if len(search.Queries) != 1 || search.Queries[0].Shape.Object != "Contact" {
+			t.Errorf("queries = %d, want Contact only", len(search.Queries))
+		}
+	})
+
+	errorTests := []struct {
+		name     string
+		search   string
+		access   AccessController
+		wantCode ValidationErrorCode
+		wantAcc  bool
+	}{
+		{name: "object not searchable", search: `FIND {acme} RETURNING Lead(Name)`, wantCode: ErrCodeObjectNotSearchable},
+		{name: "unknown object", search: `FIND {acme} RETURNING Nope`, wantCode: ErrCodeUnknownObject},
+		{name: "unknown field", search: `FIND {acme} RETURNING Account(Nope)`, wantCode: ErrCodeUnknownField},
+		{name: "aggregate field", search: `FIND {acme} RETURNING Account(COUNT(Id))`, wantCode: ErrCodeInvalidExpression},
+		{name: "duplicate object", search: `FIND {acme} RETURNING Account, Account(Name)`, wantCode: ErrCodeInvalidExpression},
+		{name: "bad term", search: `FIND {a*} RETURNING Account`, wantCode: ErrCodeInvalidSearchTerm},
+		{name: "zero limit", search: `FIND {acme} RETURNING Account LIMIT 0`, wantCode: ErrCodeInvalidExpression},
+		{
+			name:    "object access denied",
+			search:  `FIND {acme} RETURNING Account(Name)`,
+			access:  &ObjectAccessController{AllowedObjects: map[string]bool{"Contact": true}},
+			wantAcc: true,
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e := eng
+			if tt.access != nil {
+				e = NewEngine(WithMetadata(setupTestMetadata()), WithAccessController(tt.access))
+			}
+			_, err := e.PrepareSearch(ctx, tt.search)
+			if tt.wantAcc {
+				var accErr *AccessError
+				if !errors.As(err, &accErr) {
+					t.Errorf("PrepareSearch() error = %v, want AccessError", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Code != tt.wantCode {
+				t.Errorf("PrepareSearch() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
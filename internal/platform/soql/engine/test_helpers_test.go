@@ -89,6 +89,7 @@ func setupTestMetadata() MetadataProvider {
 			Lookup("Owner", "owner_id", "User", "id").
 			Relationship("Contacts", "Contact", "account_id", "id").
 			Relationship("Opportunities", "Opportunity", "account_id", "id").
+			Searchable("search_vector").
 			Build(),
 
 		"Contact": NewObjectMeta("Contact", "", "contacts").
@@ -101,6 +102,7 @@ func setupTestMetadata() MetadataProvider {
 			Field("AccountId", "account_id", FieldTypeID).
 			Field("CreatedDate", "created_at", FieldTypeDateTime).
 			Lookup("Account", "account_id", "Account", "id").
+			Searchable("search_vector").
 			Build(),
 
 		"Opportunity": NewObjectMeta("Opportunity", "", "opportunities").
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/alecthomas/participle/v2/lexer"
@@ -15,8 +16,12 @@ type ValidatedQuery struct {
 	ResolvedRefs      map[string]*ResolvedRef // path -> resolved reference
 	Subqueries        []*ValidatedSubquery
 	WhereSubqueries   []*ValidatedWhereSubquery
+	ExistsFilters     []*ValidatedExists
 	TypeofExpressions []*ValidatedTypeof
 	FieldCount        int
+
+	// Locale is the WITH LOCALE locale of labels, empty for untranslated labels.
+	Locale string
 }
 
 // ResolvedRef represents a resolved field reference.
@@ -53,6 +58,13 @@ type ValidatedWhereSubquery struct {
 	ResolvedRefs map[string]*ResolvedRef // Resolved field references in the subquery
 }
 
+// ValidatedExists represents a validated EXISTS relationship filter.
+type ValidatedExists struct {
+	AST          *ExistsExpression
+	Relationship *RelationshipMeta
+	ChildObject  *ObjectMeta
+}
+
 // ValidatedTypeof represents a validated TYPEOF expression.
 type ValidatedTypeof struct {
 	AST         *TypeofExpression
@@ -98,6 +110,7 @@ type validationContext struct {
 	resolvedRefs         map[string]*ResolvedRef
 	subqueries           []*ValidatedSubquery
 	whereSubqueries      []*ValidatedWhereSubquery
+	existsFilters        []*ValidatedExists
 	typeofExpressions    []*ValidatedTypeof
 	fieldCount           int
 	lookupDepth          int
@@ -105,8 +118,13 @@ type validationContext struct {
 	inWhereSubquery      bool
 	parentCtx            *validationContext // For nested subqueries
 	withSecurityEnforced bool               // WITH SECURITY_ENFORCED flag
+	binds                []*Bind            // Bind variables, typed once the query is validated
+	groupKeys            map[string]bool    // Keys of the GROUP BY fields and date functions
 }
 
+// localePattern matches locales such as "de" and "de_AT".
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(_[A-Z]{2})?$`)
+
 func newValidationContext(ctx context.Context, v *Validator, root *ObjectMeta, withSecurityEnforced bool) *validationContext {
 	return &validationContext{
 		ctx:                  ctx,
@@ -144,13 +162,42 @@ func (v *Validator) Validate(ctx context.Context, ast *Grammar) (*ValidatedQuery
 		return nil, err
 	}
 
+	// Filter scopes are defined by record ownership
+	if ast.Scope != "" && ast.Scope != FilterScopeEverything {
+		if owner := rootObject.GetField("OwnerId"); owner == nil || owner.Column != "owner_id" {
+			return nil, NewValidationErrorWithPos(ErrCodeInvalidExpression, PosFromLexer(ast.Pos),
+				fmt.Sprintf("USING SCOPE %s requires an owned object, %s has no OwnerId", ast.Scope, ast.From))
+		}
+	}
+
+	locale, err := validateLocale(ast)
+	if err != nil {
+		return nil, err
+	}
+
 	vctx := newValidationContext(ctx, v, rootObject, ast.WithSecurityEnforced)
 
+	// Expand FIELDS() wildcards into the fields the user can read
+	if err := checkFieldsWildcards(ast); err != nil {
+		return nil, err
+	}
+	if ast.Select, err = v.expandFields(vctx, rootObject, ast.Select); err != nil {
+		return nil, err
+	}
+
+	// GROUPING() and ORDER BY date functions refer to GROUP BY items
+	vctx.groupKeys = groupKeys(ast.GroupBy)
+
 	// Validate SELECT clause
 	if err := v.validateSelect(vctx, ast.Select); err != nil {
 		return nil, err
 	}
 
+	// Aggregate subqueries are joined per record, so the root must return records
+	if err := validateAggregateSubqueryParent(ast, vctx.subqueries); err != nil {
+		return nil, err
+	}
+
 	// Validate WHERE clause
 	if ast.Where != nil {
 		if err := v.validateExpression(vctx, ast.Where); err != nil {
@@ -194,17 +241,41 @@ func (v *Validator) Validate(ctx context.Context, ast *Grammar) (*ValidatedQuery
 		return nil, err
 	}
 
+	if err := checkBindTypes(vctx.binds); err != nil {
+		return nil, err
+	}
+
 	return &ValidatedQuery{
 		AST:               ast,
 		RootObject:        rootObject,
 		ResolvedRefs:      vctx.resolvedRefs,
 		Subqueries:        vctx.subqueries,
 		WhereSubqueries:   vctx.whereSubqueries,
+		ExistsFilters:     vctx.existsFilters,
 		TypeofExpressions: vctx.typeofExpressions,
 		FieldCount:        vctx.fieldCount,
+		Locale:            locale,
 	}, nil
 }
 
+// validateLocale returns the WITH LOCALE locale of the query.
+func validateLocale(ast *Grammar) (string, error) {
+	switch len(ast.Locale) {
+	case 0:
+		return "", nil
+	case 1:
+	default:
+		return "", NewValidationErrorWithPos(ErrCodeInvalidExpression, PosFromLexer(ast.Pos),
+			"WITH LOCALE can be specified only once")
+	}
+	locale := ast.Locale[0]
+	if !localePattern.MatchString(locale) {
+		return "", NewValidationErrorWithPos(ErrCodeInvalidExpression, PosFromLexer(ast.Pos),
+			fmt.Sprintf("invalid locale '%s': expected language[_COUNTRY], e.g. de or de_AT", locale))
+	}
+	return locale, nil
+}
+
 // validateSelect validates the SELECT clause.
 func (v *Validator) validateSelect(vctx *validationContext, selects []*SelectExpression) error {
 	if len(selects) == 0 {
@@ -292,6 +363,9 @@ func (v *Validator) validateRelationshipSubquery(vctx *validationContext, sub *R
 	}
 
 	// Validate subquery SELECT
+	if sub.Select, err = v.expandFields(childCtx, childObject, sub.Select); err != nil {
+		return fmt.Errorf("in subquery %s: %w", sub.From, err)
+	}
 	for _, sel := range sub.Select {
 		if err := v.validateSelectExpression(childCtx, sel); err != nil {
 			return fmt.Errorf("in subquery %s: %w", sub.From, err)
@@ -305,6 +379,21 @@ func (v *Validator) validateRelationshipSubquery(vctx *validationContext, sub *R
 		}
 	}
 
+	// Aggregate subqueries summarize the children; HAVING filters the parents
+	if sub.IsAggregate() {
+		if err := validateAggregateSubquery(sub); err != nil {
+			return err
+		}
+	} else if sub.Having != nil {
+		return NewValidationErrorWithPos(ErrCodeInvalidAggregation, PosFromLexer(sub.Having.Pos),
+			fmt.Sprintf("HAVING in subquery %s requires aggregate functions in its SELECT", sub.From))
+	}
+	if sub.Having != nil {
+		if err := v.validateExpression(childCtx, sub.Having); err != nil {
+			return fmt.Errorf("in subquery %s HAVING: %w", sub.From, err)
+		}
+	}
+
 	// Validate subquery ORDER BY
 	if err := v.validateOrderBy(childCtx, sub.OrderBy); err != nil {
 		return fmt.Errorf("in subquery %s: %w", sub.From, err)
@@ -327,6 +416,49 @@ func (v *Validator) validateRelationshipSubquery(vctx *validationContext, sub *R
 	return nil
 }
 
+// validateAggregateSubqueryParent rejects aggregate subqueries in queries
+// that do not return one row per record: grouped and aggregate queries,
+// and FOR UPDATE, which cannot lock aggregated rows.
+func validateAggregateSubqueryParent(ast *Grammar, subqueries []*ValidatedSubquery) error {
+	for _, sub := range subqueries {
+		if !sub.AST.IsAggregate() {
+			continue
+		}
+		pos := PosFromLexer(sub.AST.Pos)
+		if ast.ForUpdate {
+			return NewValidationErrorWithPos(ErrCodeInvalidExpression, pos,
+				fmt.Sprintf("aggregate subquery %s cannot be used with FOR UPDATE", sub.AST.From))
+		}
+		if len(ast.GroupBy) > 0 {
+			return NewValidationErrorWithPos(ErrCodeInvalidAggregation, pos,
+				fmt.Sprintf("aggregate subquery %s cannot be used with GROUP BY", sub.AST.From))
+		}
+		for _, sel := range ast.Select {
+			if sel.Item != nil && sel.Item.Aggregate != nil {
+				return NewValidationErrorWithPos(ErrCodeInvalidAggregation, pos,
+					fmt.Sprintf("aggregate subquery %s cannot be used with aggregate fields", sub.AST.From))
+			}
+		}
+	}
+	return nil
+}
+
+// validateAggregateSubquery checks that an aggregate subquery selects only
+// aggregates and has no clauses that apply to child records.
+func validateAggregateSubquery(sub *RelationshipSubquery) error {
+	for _, sel := range sub.Select {
+		if sel.Item == nil || sel.Item.Aggregate == nil {
+			return NewValidationErrorWithPos(ErrCodeInvalidAggregation, PosFromLexer(sel.Pos),
+				fmt.Sprintf("subquery %s mixes aggregates with fields; aggregate subqueries select aggregates only", sub.From))
+		}
+	}
+	if len(sub.OrderBy) > 0 || sub.Limit != nil {
+		return NewValidationErrorWithPos(ErrCodeInvalidAggregation, PosFromLexer(sub.Pos),
+			fmt.Sprintf("aggregate subquery %s cannot use ORDER BY or LIMIT", sub.From))
+	}
+	return nil
+}
+
 // validateAggregate validates an aggregate expression.
 func (v *Validator) validateAggregate(vctx *validationContext, agg *AggregateExpression) error {
 	if agg.Expression == nil {
@@ -342,6 +474,14 @@ func (v *Validator) validateAggregate(vctx *validationContext, agg *AggregateExp
 	// For SUM, AVG - need numeric types
 	// For MIN, MAX - need comparable types
 	// Type checking will be done during type inference
+	switch agg.Function {
+	case AggregateCount, AggregateCountDistinct:
+		agg.FieldType = FieldTypeInteger
+	case AggregateSum, AggregateAvg:
+		agg.FieldType = FieldTypeFloat
+	default:
+		agg.FieldType = agg.Expression.InferFieldType()
+	}
 
 	return nil
 }
@@ -389,6 +529,8 @@ func (v *Validator) validateCompareExpr(vctx *validationContext, cmp *CompareExp
 		if err := v.validateInExpr(vctx, cmp.Right); err != nil {
 			return err
 		}
+		inferBindType(cmp.Left.primary(), cmp.Right.primary())
+		inferBindType(cmp.Right.primary(), cmp.Left.primary())
 	}
 
 	return nil
@@ -408,11 +550,27 @@ func (v *Validator) validateInExpr(vctx *validationContext, in *InExpr) error {
 		return v.validateWhereSubquery(vctx, in.Subquery)
 	}
 
+	leftType := in.Left.primary().operandType()
+
+	// Handle a list bind: Id IN :ids
+	if in.ListBind != nil {
+		if err := v.validateBind(vctx, in.ListBind); err != nil {
+			return err
+		}
+		if leftType != FieldTypeUnknown {
+			in.ListBind.FieldType = leftType | FieldTypeArray
+		}
+		return nil
+	}
+
 	// Handle literal values
 	for _, val := range in.Values {
 		if err := v.validateValue(vctx, val); err != nil {
 			return err
 		}
+		if val.Const != nil && val.Const.Bind != nil {
+			val.Const.Bind.FieldType = leftType
+		}
 	}
 
 	return nil
@@ -489,6 +647,55 @@ func (v *Validator) validateWhereSubquery(vctx *validationContext, sub *WhereSub
 	return nil
 }
 
+// validateExists validates an EXISTS filter on a child relationship of the root object.
+func (v *Validator) validateExists(vctx *validationContext, ex *ExistsExpression) error {
+	if vctx.inSubquery {
+		return NewValidationErrorWithPos(ErrCodeNestedSubqueryNotAllowed, PosFromLexer(ex.Pos),
+			"EXISTS is not allowed in subqueries")
+	}
+
+	rel := vctx.rootObject.GetRelationship(ex.Relationship)
+	if rel == nil {
+		return UnknownRelationshipErrorAt(vctx.rootObject.Name, ex.Relationship, ex.Pos)
+	}
+
+	childObject, err := v.metadata.GetObject(vctx.ctx, rel.ChildObject)
+	if err != nil {
+		return fmt.Errorf("failed to get child object metadata: %w", err)
+	}
+	if childObject == nil {
+		return UnknownObjectError(rel.ChildObject)
+	}
+
+	if err := v.access.CanAccessObject(vctx.ctx, rel.ChildObject); err != nil {
+		return err
+	}
+
+	// The condition is evaluated against the child records, like a semi-join
+	if ex.Where != nil {
+		childCtx := &validationContext{
+			ctx:             vctx.ctx,
+			validator:       v,
+			rootObject:      childObject,
+			resolvedRefs:    make(map[string]*ResolvedRef),
+			inSubquery:      true,
+			inWhereSubquery: true,
+			parentCtx:       vctx,
+		}
+		if err := v.validateExpression(childCtx, ex.Where); err != nil {
+			return fmt.Errorf("in EXISTS %s: %w", ex.Relationship, err)
+		}
+	}
+
+	vctx.existsFilters = append(vctx.existsFilters, &ValidatedExists{
+		AST:          ex,
+		Relationship: rel,
+		ChildObject:  childObject,
+	})
+
+	return nil
+}
+
 // extractSingleFieldFromExpression extracts a single field from a SELECT expression in WHERE subquery.
 // Returns error if the expression is not a simple field reference or contains aggregates.
 func (v *Validator) extractSingleFieldFromExpression(expr *Expression, obj *ObjectMeta, pos lexer.Position) (*FieldMeta, error) {
@@ -576,6 +783,9 @@ func (v *Validator) validateLikeExpr(vctx *validationContext, like *LikeExpr) er
 		if err := v.validateValue(vctx, like.Pattern); err != nil {
 			return err
 		}
+		if like.Pattern.Const != nil && like.Pattern.Const.Bind != nil {
+			like.Pattern.Const.Bind.FieldType = FieldTypeString
+		}
 	}
 
 	return nil
@@ -632,6 +842,18 @@ func (v *Validator) validatePrimary(vctx *validationContext, primary *Primary) e
 	case primary.FuncCall != nil:
 		return v.validateFuncCall(vctx, primary.FuncCall)
 
+	case primary.DateFunc != nil:
+		return v.validateDateFunction(vctx, primary.DateFunc, true)
+
+	case primary.ToLabel != nil:
+		return v.validateToLabel(vctx, primary.ToLabel, true)
+
+	case primary.Grouping != nil:
+		return v.validateGrouping(vctx, primary.Grouping)
+
+	case primary.Exists != nil:
+		return v.validateExists(vctx, primary.Exists)
+
 	case primary.Const != nil:
 		return v.validateConst(vctx, primary.Const)
 
@@ -686,11 +908,53 @@ func (v *Validator) validateFuncCall(vctx *validationContext, fn *FuncCall) erro
 }
 
 func (v *Validator) validateConst(vctx *validationContext, c *Const) error {
+	if c.Bind != nil {
+		return v.validateBind(vctx, c.Bind)
+	}
 	// Constants are always valid, just ensure type can be inferred
 	c.GetFieldType()
 	return nil
 }
 
+// validateBind registers a bind variable. Its type is inferred from the
+// operand it is compared with and checked by checkBindTypes at the end.
+func (v *Validator) validateBind(vctx *validationContext, b *Bind) error {
+	if vctx.inSubquery {
+		return NewValidationErrorWithPos(ErrCodeInvalidBind, PosFromLexer(b.Pos),
+			fmt.Sprintf("bind variable :%s is not supported in subqueries", b.Name))
+	}
+	vctx.binds = append(vctx.binds, b)
+	return nil
+}
+
+// inferBindType types a bind variable operand after the operand it is compared with.
+func inferBindType(operand, other *Primary) {
+	if operand == nil || operand.Const == nil || operand.Const.Bind == nil {
+		return
+	}
+	if t := other.operandType(); t != FieldTypeUnknown && t != FieldTypeNull {
+		operand.Const.Bind.FieldType = t
+	}
+}
+
+// checkBindTypes ensures every bind variable got a type and that all uses
+// of the same name agree on it.
+func checkBindTypes(binds []*Bind) error {
+	types := make(map[string]FieldType, len(binds))
+	for _, b := range binds {
+		if b.FieldType == FieldTypeUnknown {
+			return NewValidationErrorWithPos(ErrCodeInvalidBind, PosFromLexer(b.Pos),
+				fmt.Sprintf("cannot infer the type of bind variable :%s: compare it with a field", b.Name))
+		}
+		if t, ok := types[b.Name]; ok && t != b.FieldType {
+			return NewValidationErrorWithPos(ErrCodeInvalidBind, PosFromLexer(b.Pos),
+				fmt.Sprintf("bind variable :%s is used as both %s and %s", b.Name, t, b.FieldType))
+		}
+		types[b.Name] = b.FieldType
+	}
+	return nil
+}
+
 // validateField validates a field reference and resolves it through lookups.
 func (v *Validator) validateField(vctx *validationContext, field *Field, checkFilterable bool) error {
 	if field == nil || len(field.Path) == 0 {
@@ -700,7 +964,8 @@ func (v *Validator) validateField(vctx *validationContext, field *Field, checkFi
 	pathKey := strings.Join(field.Path, ".")
 
 	// Check if already resolved
-	if _, ok := vctx.resolvedRefs[pathKey]; ok {
+	if ref, ok := vctx.resolvedRefs[pathKey]; ok {
+		field.FieldType = ref.Field.Type
 		return nil
 	}
 
@@ -798,30 +1063,141 @@ func (v *Validator) resolveFieldPath(vctx *validationContext, path []string, che
 // validateGroupBy validates the GROUP BY clause.
 func (v *Validator) validateGroupBy(vctx *validationContext, groups []*GroupClause) error {
 	for _, group := range groups {
-		if len(group.Field) == 0 {
-			return NewValidationError(ErrCodeInvalidExpression, "empty GROUP BY field")
+		if group.Set == "" {
+			if err := v.validateGroupItem(vctx, group); err != nil {
+				return err
+			}
+			continue
 		}
 
-		// Resolve the field
-		pathKey := strings.Join(group.Field, ".")
-		if _, ok := vctx.resolvedRefs[pathKey]; !ok {
-			resolved, err := v.resolveFieldPath(vctx, group.Field, false, group.Pos)
-			if err != nil {
-				return fmt.Errorf("invalid GROUP BY field: %w", err)
+		for _, item := range group.Items {
+			if item.Set != "" {
+				return NewValidationErrorWithPos(ErrCodeInvalidExpression, PosFromLexer(item.Pos),
+					fmt.Sprintf("%s cannot be nested in %s", item.Set, group.Set))
 			}
-
-			// Check if field is groupable
-			if !resolved.Field.Groupable {
-				return FieldNotGroupableError(resolved.Object.Name, resolved.Field.Name)
+			if err := v.validateGroupItem(vctx, item); err != nil {
+				return err
 			}
+		}
+	}
 
-			vctx.resolvedRefs[pathKey] = resolved
+	return nil
+}
+
+// validateGroupItem validates a GROUP BY field or date function.
+func (v *Validator) validateGroupItem(vctx *validationContext, group *GroupClause) error {
+	if group.DateFunc != nil {
+		if err := v.validateDateFunction(vctx, group.DateFunc, false); err != nil {
+			return fmt.Errorf("invalid GROUP BY function: %w", err)
 		}
+		ref := vctx.resolvedRefs[strings.Join(group.DateFunc.Field.Path, ".")]
+		if !ref.Field.Groupable {
+			return FieldNotGroupableError(ref.Object.Name, ref.Field.Name)
+		}
+		return nil
+	}
+
+	if len(group.Field) == 0 {
+		return NewValidationError(ErrCodeInvalidExpression, "empty GROUP BY field")
+	}
+
+	// Resolve the field
+	pathKey := strings.Join(group.Field, ".")
+	if _, ok := vctx.resolvedRefs[pathKey]; !ok {
+		resolved, err := v.resolveFieldPath(vctx, group.Field, false, group.Pos)
+		if err != nil {
+			return fmt.Errorf("invalid GROUP BY field: %w", err)
+		}
+
+		// Check if field is groupable
+		if !resolved.Field.Groupable {
+			return FieldNotGroupableError(resolved.Object.Name, resolved.Field.Name)
+		}
+
+		vctx.resolvedRefs[pathKey] = resolved
 	}
 
 	return nil
 }
 
+// groupKeys returns the keys of the fields and date functions in GROUP BY,
+// including those inside ROLLUP and CUBE.
+func groupKeys(groups []*GroupClause) map[string]bool {
+	if len(groups) == 0 {
+		return nil
+	}
+	keys := make(map[string]bool)
+	for _, group := range groups {
+		if key := group.key(); key != "" {
+			keys[key] = true
+		}
+		for _, item := range group.Items {
+			if key := item.key(); key != "" {
+				keys[key] = true
+			}
+		}
+	}
+	return keys
+}
+
+// validateDateFunction validates a date function and types its result.
+func (v *Validator) validateDateFunction(vctx *validationContext, fn *DateFunctionCall, checkFilterable bool) error {
+	pos := PosFromLexer(fn.Pos)
+	if vctx.inSubquery || vctx.inWhereSubquery {
+		return NewValidationErrorWithPos(ErrCodeInvalidExpression, pos,
+			fmt.Sprintf("%s is not allowed in subqueries", fn.Name))
+	}
+
+	if err := v.validateField(vctx, fn.Field, checkFilterable); err != nil {
+		return err
+	}
+
+	switch t := fn.Field.FieldType; {
+	case t == FieldTypeDateTime:
+	case t == FieldTypeDate && !fn.Name.NeedsTime():
+	case fn.Name.NeedsTime():
+		return NewValidationErrorWithPos(ErrCodeTypeMismatch, pos,
+			fmt.Sprintf("%s requires a datetime field, got %s", fn.Name, t))
+	default:
+		return NewValidationErrorWithPos(ErrCodeTypeMismatch, pos,
+			fmt.Sprintf("%s requires a date or datetime field, got %s", fn.Name, t))
+	}
+
+	fn.FieldType = fn.Name.ResultType()
+	return nil
+}
+
+// validateToLabel validates toLabel(), whose argument must be a picklist field.
+func (v *Validator) validateToLabel(vctx *validationContext, fn *ToLabelCall, checkFilterable bool) error {
+	pos := PosFromLexer(fn.Pos)
+	if vctx.inSubquery || vctx.inWhereSubquery {
+		return NewValidationErrorWithPos(ErrCodeInvalidExpression, pos, "toLabel is not allowed in subqueries")
+	}
+
+	if err := v.validateField(vctx, fn.Field, checkFilterable); err != nil {
+		return err
+	}
+
+	ref := vctx.resolvedRefs[strings.Join(fn.Field.Path, ".")]
+	if ref.Field.Picklist == nil {
+		return NewValidationErrorWithPos(ErrCodeTypeMismatch, pos,
+			fmt.Sprintf("toLabel requires a picklist field, %s.%s is not a picklist", ref.Object.Name, ref.Field.Name))
+	}
+
+	fn.Picklist = ref.Field.Picklist
+	fn.FieldType = fn.Field.FieldType
+	return nil
+}
+
+// validateGrouping validates GROUPING(), whose argument must be grouped by.
+func (v *Validator) validateGrouping(vctx *validationContext, g *GroupingExpression) error {
+	if key := g.Item.key(); key == "" || !vctx.groupKeys[key] {
+		return NewValidationErrorWithPos(ErrCodeInvalidAggregation, PosFromLexer(g.Pos),
+			"GROUPING() requires a field or date function from GROUP BY")
+	}
+	return v.validateGroupItem(vctx, g.Item)
+}
+
 // validateOrderBy validates the ORDER BY clause.
 func (v *Validator) validateOrderBy(vctx *validationContext, orders []*OrderClause) error {
 	for _, order := range orders {
@@ -836,6 +1212,23 @@ func (v *Validator) validateOrderBy(vctx *validationContext, orders []*OrderClau
 			if err := v.validateAggregate(vctx, item.Aggregate); err != nil {
 				return fmt.Errorf("invalid ORDER BY aggregate: %w", err)
 			}
+		} else if item.DateFunc != nil {
+			// Date functions order groups, so they must be grouped by
+			if !vctx.groupKeys[item.DateFunc.key()] {
+				return NewValidationErrorWithPos(ErrCodeInvalidExpression, PosFromLexer(item.Pos),
+					fmt.Sprintf("ORDER BY %s requires the same function in GROUP BY", item.DateFunc.Name))
+			}
+			if err := v.validateDateFunction(vctx, item.DateFunc, false); err != nil {
+				return fmt.Errorf("invalid ORDER BY function: %w", err)
+			}
+		} else if item.ToLabel != nil {
+			if err := v.validateToLabel(vctx, item.ToLabel, false); err != nil {
+				return fmt.Errorf("invalid ORDER BY function: %w", err)
+			}
+			ref := vctx.resolvedRefs[strings.Join(item.ToLabel.Field.Path, ".")]
+			if !ref.Field.Sortable {
+				return FieldNotSortableError(ref.Object.Name, ref.Field.Name)
+			}
 		} else if len(item.Field) > 0 {
 			// Validate field in ORDER BY
 			pathKey := strings.Join(item.Field, ".")
@@ -3,89 +3,193 @@ package soql
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/querylog"
 	"github.com/adverax/crm/internal/platform/security"
 	"github.com/adverax/crm/internal/platform/security/rls"
 	"github.com/adverax/crm/internal/platform/soql/engine"
 )
 
+// OrgSettingsProvider supplies organization defaults for date literal resolution.
+type OrgSettingsProvider interface {
+	Get(ctx context.Context) (*security.OrgSettings, error)
+}
+
+// StatementTimeoutProvider supplies the statement timeout of a profile.
+// Zero means no limit.
+type StatementTimeoutProvider interface {
+	StatementTimeout(ctx context.Context, profileID uuid.UUID) (time.Duration, error)
+}
+
 // Executor executes compiled SOQL queries against PostgreSQL.
 type Executor struct {
 	pool        *pgxpool.Pool
+	replica     *pgxpool.Pool
 	cache       metadata.MetadataReader
 	rlsEnforcer rls.Enforcer
+	scopes      rls.ScopeFilter
+	orgSettings OrgSettingsProvider
+	timeouts    StatementTimeoutProvider
+}
+
+// querier is a connection pool or a transaction a query runs on.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ExecutorOption configures an Executor.
+type ExecutorOption func(*Executor)
+
+// WithOrgSettings makes date literals (TODAY, THIS_FISCAL_QUARTER, ...) follow
+// the organization time zone, locale and fiscal year. Without it they are
+// resolved in UTC with a January fiscal year.
+func WithOrgSettings(provider OrgSettingsProvider) ExecutorOption {
+	return func(e *Executor) {
+		e.orgSettings = provider
+	}
+}
+
+// WithScopeFilter enables USING SCOPE. Without it queries with a filter
+// scope other than everything fail for authenticated users.
+func WithScopeFilter(filter rls.ScopeFilter) ExecutorOption {
+	return func(e *Executor) {
+		e.scopes = filter
+	}
+}
+
+// WithReplica lets queries routed by the QueryService run on a read replica.
+// Without it every query runs on the primary pool.
+func WithReplica(replica *pgxpool.Pool) ExecutorOption {
+	return func(e *Executor) {
+		e.replica = replica
+	}
+}
+
+// WithStatementTimeouts limits the run time of a user's queries to the
+// statement timeout of their profile.
+func WithStatementTimeouts(provider StatementTimeoutProvider) ExecutorOption {
+	return func(e *Executor) {
+		e.timeouts = provider
+	}
 }
 
 // NewExecutor creates a new pgx-based SOQL executor.
-func NewExecutor(pool *pgxpool.Pool, cache metadata.MetadataReader, rlsEnforcer rls.Enforcer) *Executor {
-	return &Executor{
+func NewExecutor(pool *pgxpool.Pool, cache metadata.MetadataReader, rlsEnforcer rls.Enforcer, opts ...ExecutorOption) *Executor {
+	e := &Executor{
 		pool:        pool,
 		cache:       cache,
 		rlsEnforcer: rlsEnforcer,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Page selects one keyset page of a paginated query.
+type Page struct {
+	// Size is the maximum number of records to return.
+	Size int
+
+	// After holds the keyset values of the last record of the previous page,
+	// in PaginationInfo.Fields order. Nil selects the first page.
+	After []any
 }
 
 // Execute runs a compiled SOQL query and returns the result.
 func (e *Executor) Execute(ctx context.Context, compiled *engine.CompiledQuery) (*QueryResult, error) {
-	uc, _ := security.UserFromContext(ctx)
+	result, _, err := e.execute(ctx, compiled, nil)
+	if err != nil {
+		return nil, fmt.Errorf("soqlExecutor.Execute: %w", err)
+	}
+	return result, nil
+}
 
-	sql := compiled.SQL
-	params := compiled.Params
+// ExecutePage runs a single keyset page of a paginated query. When more
+// records follow, it returns the keyset values of the last returned record
+// and leaves result.Done false.
+func (e *Executor) ExecutePage(ctx context.Context, compiled *engine.CompiledQuery, page *Page) (*QueryResult, []any, error) {
+	result, next, err := e.execute(ctx, compiled, page)
+	if err != nil {
+		return nil, nil, fmt.Errorf("soqlExecutor.ExecutePage: %w", err)
+	}
+	return result, next, nil
+}
 
-	// Inject RLS WHERE clause if the user context is available.
-	if uc.UserID != uuid.Nil && compiled.Shape.Table != "" {
-		objectID, err := resolveObjectID(e.cache, compiled.Shape.Object)
-		if err == nil {
-			rlsClause, rlsParams, rlsErr := e.rlsEnforcer.BuildWhereClause(ctx, uc.UserID, objectID)
-			if rlsErr != nil {
-				return nil, fmt.Errorf("soqlExecutor.Execute: RLS: %w", rlsErr)
-			}
-			if rlsClause != "" && rlsClause != "TRUE" {
-				sql, params = injectRLSClause(sql, params, rlsClause, rlsParams)
-			}
-		}
+func (e *Executor) execute(ctx context.Context, compiled *engine.CompiledQuery, page *Page) (*QueryResult, []any, error) {
+	sql, params, err := e.render(ctx, compiled, page, e.rlsPredicate(ctx))
+	if err != nil {
+		return nil, nil, err
 	}
+	return e.run(ctx, compiled, page, sql, params)
+}
 
-	// Resolve date parameters.
-	if len(compiled.DateParams) > 0 {
-		resolver := engine.NewDefaultDateResolver()
-		queryCopy := *compiled
-		queryCopy.SQL = sql
-		queryCopy.Params = params
-		if err := engine.ResolveDateParams(ctx, &queryCopy, resolver); err != nil {
-			return nil, fmt.Errorf("soqlExecutor.Execute: date resolve: %w", err)
-		}
-		sql = queryCopy.SQL
-		params = queryCopy.Params
+// renderForUser renders a complete (unpaged) query for the current user, as
+// Execute sends it to PostgreSQL. Two users get the same SQL and params
+// exactly when the same records and fields are visible to them.
+func (e *Executor) renderForUser(ctx context.Context, compiled *engine.CompiledQuery) (string, []any, error) {
+	sql, params, err := e.render(ctx, compiled, nil, e.rlsPredicate(ctx))
+	if err != nil {
+		return "", nil, fmt.Errorf("soqlExecutor.Render: %w", err)
+	}
+	return sql, params, nil
+}
+
+// executeRendered runs a complete query rendered by renderForUser.
+func (e *Executor) executeRendered(ctx context.Context, compiled *engine.CompiledQuery, sql string, params []any) (*QueryResult, error) {
+	result, _, err := e.run(ctx, compiled, nil, sql, params)
+	if err != nil {
+		return nil, fmt.Errorf("soqlExecutor.Execute: %w", err)
+	}
+	return result, nil
+}
+
+// run sends rendered SQL to PostgreSQL and maps the rows to a result.
+func (e *Executor) run(ctx context.Context, compiled *engine.CompiledQuery, page *Page, sql string, params []any) (*QueryResult, []any, error) {
+	q, release, err := e.begin(ctx, compiled)
+	if err != nil {
+		return nil, nil, err
 	}
+	defer release()
 
-	rows, err := e.pool.Query(ctx, sql, params...)
+	rows, err := q.Query(ctx, sql, params...)
 	if err != nil {
-		return nil, fmt.Errorf("soqlExecutor.Execute: query: %w", err)
+		return nil, nil, fmt.Errorf("query: %w", err)
 	}
 	defer rows.Close()
 
 	fieldDescs := rows.FieldDescriptions()
 	var records []map[string]any
+	var lastValues []any
+	hasMore := false
 
 	for rows.Next() {
+		if page != nil && len(records) == page.Size {
+			// The extra row only proves that another page exists.
+			hasMore = true
+			break
+		}
 		values, scanErr := rows.Values()
 		if scanErr != nil {
-			return nil, fmt.Errorf("soqlExecutor.Execute: scan: %w", scanErr)
+			return nil, nil, fmt.Errorf("scan: %w", scanErr)
 		}
 		record := make(map[string]any, len(fieldDescs))
 		for i, fd := range fieldDescs {
 			record[fd.Name] = values[i]
 		}
 		records = append(records, record)
+		lastValues = values
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("soqlExecutor.Execute: rows: %w", err)
+		return nil, nil, fmt.Errorf("rows: %w", err)
 	}
 
 	// Map SQL columns back to SOQL field names using shape.
@@ -93,16 +197,184 @@ func (e *Executor) Execute(ctx context.Context, compiled *engine.CompiledQuery)
 
 	// Enforce single-row constraint for SELECT ROW queries.
 	if compiled.IsRow && len(mappedRecords) > 1 {
-		return nil, fmt.Errorf("soqlExecutor.Execute: SELECT ROW returned %d records, expected at most 1", len(mappedRecords))
+		return nil, nil, fmt.Errorf("SELECT ROW returned %d records, expected at most 1", len(mappedRecords))
+	}
+
+	var next []any
+	if hasMore {
+		next = keysetValues(compiled, lastValues)
 	}
 
 	return &QueryResult{
 		Fields:    shapeToFieldInfo(compiled.Shape),
 		TotalSize: len(mappedRecords),
-		Done:      true,
+		Done:      next == nil,
 		Records:   mappedRecords,
 		IsRow:     compiled.IsRow,
-	}, nil
+	}, next, nil
+}
+
+// Stream runs a compiled query and hands each record to w as it is read from
+// PostgreSQL, without materializing the result. Security and the query LIMIT
+// apply exactly as in Execute. The header is written only once the query has
+// started, so an error before any output leaves w untouched.
+func (e *Executor) Stream(ctx context.Context, compiled *engine.CompiledQuery, w RecordWriter) (int, error) {
+	sql, params, err := e.render(ctx, compiled, nil, e.rlsPredicate(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("soqlExecutor.Stream: %w", err)
+	}
+
+	q, release, err := e.begin(ctx, compiled)
+	if err != nil {
+		return 0, fmt.Errorf("soqlExecutor.Stream: %w", err)
+	}
+	defer release()
+
+	rows, err := q.Query(ctx, sql, params...)
+	if err != nil {
+		return 0, fmt.Errorf("soqlExecutor.Stream: query: %w", err)
+	}
+	defer rows.Close()
+
+	names := soqlColumnNames(rows.FieldDescriptions(), compiled.Shape)
+	if err := w.WriteHeader(shapeToFieldInfo(compiled.Shape)); err != nil {
+		return 0, fmt.Errorf("soqlExecutor.Stream: %w", err)
+	}
+
+	count := 0
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return count, fmt.Errorf("soqlExecutor.Stream: scan: %w", err)
+		}
+		record := make(map[string]any, len(names))
+		for i, name := range names {
+			record[name] = values[i]
+		}
+		if err := w.WriteRecord(record); err != nil {
+			return count, fmt.Errorf("soqlExecutor.Stream: %w", err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("soqlExecutor.Stream: rows: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return count, fmt.Errorf("soqlExecutor.Stream: %w", err)
+	}
+	return count, nil
+}
+
+// begin returns where a query runs: the replica when the QueryService routed
+// it there, the primary otherwise. When the user's profile has a statement
+// timeout, the query runs in a transaction that sets it with SET LOCAL, so
+// the pooled connection keeps its default; release ends that transaction.
+func (e *Executor) begin(ctx context.Context, compiled *engine.CompiledQuery) (querier, func(), error) {
+	pool := e.pool
+	if e.replica != nil && routedToReplica(ctx) {
+		pool = e.replica
+	}
+
+	timeout, err := e.statementTimeout(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("statement timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return pool, func() {}, nil
+	}
+
+	// SOQL never writes; only row locks need a read-write transaction.
+	opts := pgx.TxOptions{AccessMode: pgx.ReadOnly}
+	if compiled.ForUpdate {
+		opts.AccessMode = pgx.ReadWrite
+	}
+	tx, err := pool.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin: %w", err)
+	}
+	release := func() { _ = tx.Rollback(context.WithoutCancel(ctx)) }
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())); err != nil {
+		release()
+		return nil, nil, fmt.Errorf("set statement_timeout: %w", err)
+	}
+	return tx, release, nil
+}
+
+// statementTimeout returns the statement timeout of the current user's
+// profile, or zero in system context.
+func (e *Executor) statementTimeout(ctx context.Context) (time.Duration, error) {
+	if e.timeouts == nil {
+		return 0, nil
+	}
+	uc, ok := security.UserFromContext(ctx)
+	if !ok || uc.ProfileID == uuid.Nil {
+		return 0, nil
+	}
+	return e.timeouts.StatementTimeout(ctx, uc.ProfileID)
+}
+
+// render produces the SQL and params sent to PostgreSQL: RLS predicates from
+// rlsFn are spliced in, the keyset page is resolved and date literals are
+// bound in the organization time zone.
+func (e *Executor) render(
+	ctx context.Context,
+	compiled *engine.CompiledQuery,
+	page *Page,
+	rlsFn engine.RLSPredicateFunc,
+) (string, []any, error) {
+	sql, params, err := engine.ApplyRLS(ctx, compiled, rlsFn)
+	if err != nil {
+		return "", nil, fmt.Errorf("RLS: %w", err)
+	}
+
+	// Resolve the keyset placeholder; fetch one extra row to detect more pages.
+	if p := compiled.Pagination; p != nil {
+		limit := p.Limit
+		var after []any
+		if page != nil {
+			limit = page.Size + 1
+			after = page.After
+		}
+		sql, params, err = engine.ApplyKeyset(compiled, sql, params, after, limit)
+		if err != nil {
+			return "", nil, fmt.Errorf("keyset: %w", err)
+		}
+	}
+
+	// Resolve date parameters.
+	if len(compiled.DateParams) > 0 {
+		resolver, err := e.dateResolver(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("date resolver: %w", err)
+		}
+		queryCopy := *compiled
+		queryCopy.SQL = sql
+		queryCopy.Params = params
+		if err := engine.ResolveDateParams(ctx, &queryCopy, resolver); err != nil {
+			return "", nil, fmt.Errorf("date resolve: %w", err)
+		}
+		sql = queryCopy.SQL
+		params = queryCopy.Params
+	}
+
+	return sql, params, nil
+}
+
+// keysetValues extracts the keyset values of a raw result row in
+// PaginationInfo.Fields order, using the SELECT position of each field.
+func keysetValues(compiled *engine.CompiledQuery, values []any) []any {
+	positions := make(map[string]int, len(compiled.Shape.Fields))
+	for i, f := range compiled.Shape.Fields {
+		positions[f.Alias] = i
+	}
+
+	keys := make([]any, len(compiled.Pagination.Fields))
+	for i, kf := range compiled.Pagination.Fields {
+		if pos, ok := positions[kf.ResultAlias]; ok && pos < len(values) {
+			keys[i] = values[pos]
+		}
+	}
+	return keys
 }
 
 // mapRecordsToSOQL converts SQL column names to SOQL field names.
@@ -131,35 +403,56 @@ func mapRecordsToSOQL(records []map[string]any, shape *engine.ResultShape) []map
 	return result
 }
 
-// injectRLSClause adds the RLS WHERE clause to the compiled SQL.
-// It re-numbers the RLS parameters starting after existing params.
-func injectRLSClause(sql string, params []any, rlsClause string, rlsParams []any) (string, []any) {
-	// Re-number RLS parameter placeholders ($1, $2, ...) to start after existing params.
-	offset := len(params)
-	rewrittenClause := rlsClause
-	for i := len(rlsParams); i >= 1; i-- {
-		old := fmt.Sprintf("$%d", i)
-		new := fmt.Sprintf("$%d", i+offset)
-		rewrittenClause = strings.ReplaceAll(rewrittenClause, old, new)
-	}
-
-	// Find where to inject: after WHERE keyword or before ORDER BY/LIMIT.
-	upperSQL := strings.ToUpper(sql)
-	if idx := strings.Index(upperSQL, "WHERE "); idx >= 0 {
-		// Existing WHERE — append with AND.
-		insertPoint := idx + len("WHERE ")
-		sql = sql[:insertPoint] + rewrittenClause + " AND " + sql[insertPoint:]
-	} else {
-		// No WHERE — inject before ORDER BY, GROUP BY, LIMIT, or at end.
-		insertBefore := len(sql)
-		for _, kw := range []string{"ORDER BY", "GROUP BY", "HAVING", "LIMIT", "OFFSET", "FOR UPDATE"} {
-			if pos := strings.Index(upperSQL, kw); pos >= 0 && pos < insertBefore {
-				insertBefore = pos
-			}
+// soqlColumnNames maps result columns to SOQL field names using the shape,
+// as mapRecordsToSOQL does for materialized results.
+func soqlColumnNames(fieldDescs []pgconn.FieldDescription, shape *engine.ResultShape) []string {
+	colToName := make(map[string]string)
+	if shape != nil {
+		for _, f := range shape.Fields {
+			colToName[f.Column] = f.Name
+		}
+	}
+
+	names := make([]string, len(fieldDescs))
+	for i, fd := range fieldDescs {
+		names[i] = fd.Name
+		if name, ok := colToName[fd.Name]; ok {
+			names[i] = name
 		}
-		sql = sql[:insertBefore] + "WHERE " + rewrittenClause + " " + sql[insertBefore:]
+	}
+	return names
+}
+
+// rlsPredicate returns the RLS predicate builder for the current user, or nil
+// when the query runs without a user or without an RLS enforcer (system context).
+// Every target is filtered independently, so parents reached through lookups
+// and children reached through subqueries are subject to the same sharing
+// rules as the root object. USING SCOPE targets are narrowed for the same
+// user; in system context they do not filter either.
+func (e *Executor) rlsPredicate(ctx context.Context) engine.RLSPredicateFunc {
+	uc, _ := security.UserFromContext(ctx)
+	if (e.rlsEnforcer == nil && e.scopes == nil) || uc.UserID == uuid.Nil {
+		return nil
 	}
 
-	params = append(params, rlsParams...)
-	return sql, params
+	return func(ctx context.Context, target *engine.RLSTarget) (string, []any, error) {
+		objectID, err := resolveObjectID(e.cache, target.Object)
+		if err != nil {
+			return "", nil, err
+		}
+		if target.Kind == engine.RLSTargetScope {
+			if e.scopes == nil {
+				return "", nil, fmt.Errorf("USING SCOPE %s is not supported", target.Scope)
+			}
+			return e.scopes.BuildScopeClause(ctx, uc.UserID, objectID, string(target.Scope), target.Alias)
+		}
+		if e.rlsEnforcer == nil {
+			return "", nil, nil
+		}
+		clause, params, err := e.rlsEnforcer.BuildAliasedWhereClause(ctx, uc.UserID, objectID, target.Alias)
+		if err == nil && clause != "" && clause != "TRUE" {
+			querylog.MarkRLSApplied(ctx)
+		}
+		return clause, params, err
+	}
 }
@@ -0,0 +1,96 @@
+package soql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+// Explain plans a compiled query with EXPLAIN (FORMAT JSON) under the
+// caller's security context. The query is planned exactly as Execute or
+// ExecutePage would send it, but not executed.
+func (e *Executor) Explain(ctx context.Context, compiled *engine.CompiledQuery, page *Page) (*ExplainResult, error) {
+	var predicates []RLSPredicateInfo
+	rlsFn := e.rlsPredicate(ctx)
+	recordFn := func(ctx context.Context, target *engine.RLSTarget) (string, []any, error) {
+		info := RLSPredicateInfo{
+			Object:    target.Object,
+			Alias:     target.Alias,
+			Kind:      target.Kind.String(),
+			Predicate: "TRUE",
+		}
+		var clause string
+		var params []any
+		if rlsFn != nil {
+			var err error
+			clause, params, err = rlsFn(ctx, target)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		if clause != "" {
+			info.Predicate = clause
+		}
+		predicates = append(predicates, info)
+		return clause, params, nil
+	}
+
+	sql, params, err := e.render(ctx, compiled, page, recordFn)
+	if err != nil {
+		return nil, fmt.Errorf("soqlExecutor.Explain: %w", err)
+	}
+
+	q, release, err := e.begin(ctx, compiled)
+	if err != nil {
+		return nil, fmt.Errorf("soqlExecutor.Explain: %w", err)
+	}
+	defer release()
+
+	var raw []byte
+	if err := q.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+sql, params...).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("soqlExecutor.Explain: %w", err)
+	}
+	plan, err := parseQueryPlan(raw)
+	if err != nil {
+		return nil, fmt.Errorf("soqlExecutor.Explain: %w", err)
+	}
+
+	return &ExplainResult{
+		SQL:  sql,
+		RLS:  predicates,
+		Plan: plan,
+	}, nil
+}
+
+// parseQueryPlan extracts the top plan node from EXPLAIN (FORMAT JSON) output.
+func parseQueryPlan(raw []byte) (*QueryPlan, error) {
+	var statements []struct {
+		Plan json.RawMessage `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &statements); err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+	if len(statements) == 0 || len(statements[0].Plan) == 0 {
+		return nil, fmt.Errorf("parse plan: no plan in EXPLAIN output")
+	}
+
+	var node struct {
+		NodeType    string  `json:"Node Type"`
+		StartupCost float64 `json:"Startup Cost"`
+		TotalCost   float64 `json:"Total Cost"`
+		PlanRows    float64 `json:"Plan Rows"`
+	}
+	if err := json.Unmarshal(statements[0].Plan, &node); err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+
+	return &QueryPlan{
+		NodeType:    node.NodeType,
+		StartupCost: node.StartupCost,
+		TotalCost:   node.TotalCost,
+		PlanRows:    int(node.PlanRows),
+		Raw:         statements[0].Plan,
+	}, nil
+}
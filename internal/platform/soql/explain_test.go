@@ -0,0 +1,52 @@
+package soql
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+func TestParseQueryPlan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("top node estimates", func(t *testing.T) {
+		t.Parallel()
+		raw := `[{"Plan": {"Node Type": "Limit", "Startup Cost": 0.42, "Total Cost": 1234.5, "Plan Rows": 101,
+			"Plans": [{"Node Type": "Index Scan", "Total Cost": 98000.0, "Plan Rows": 40000}]}}]`
+		plan, err := parseQueryPlan([]byte(raw))
+		if err != nil {
+			t.Fatalf("parseQueryPlan() error = %v", err)
+		}
+		if plan.NodeType != "Limit" || plan.StartupCost != 0.42 || plan.TotalCost != 1234.5 || plan.PlanRows != 101 {
+			t.Errorf("plan = %+v", plan)
+		}
+		if len(plan.Raw) == 0 {
+			t.Error("Raw plan must be kept")
+		}
+	})
+
+	for _, raw := range []string{`[]`, `{"Plan": {}}`, `not json`} {
+		t.Run("rejects "+raw, func(t *testing.T) {
+			t.Parallel()
+			if _, err := parseQueryPlan([]byte(raw)); err == nil {
+				t.Errorf("parseQueryPlan(%s) expected error", raw)
+			}
+		})
+	}
+}
+
+func TestMapLimitError(t *testing.T) {
+	t.Parallel()
+
+	err := mapLimitError(engine.NewLimitError(engine.LimitTypeMaxEstimatedCost, 1000, 5000))
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || appErr.HTTPStatus != http.StatusBadRequest {
+		t.Errorf("mapLimitError() = %v, want 400", err)
+	}
+	if mapLimitError(nil) != nil {
+		t.Error("mapLimitError(nil) must be nil")
+	}
+}
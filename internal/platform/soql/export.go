@@ -0,0 +1,190 @@
+package soql
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// exportFlushInterval is the number of records written between flushes, so
+// clients receive a long export progressively instead of all at once.
+const exportFlushInterval = 500
+
+// ExportFormat is the wire format of a streamed query result.
+type ExportFormat string
+
+const (
+	// ExportFormatNDJSON writes one JSON record per line.
+	ExportFormatNDJSON ExportFormat = "ndjson"
+	// ExportFormatCSV writes RFC 4180 CSV with a header row of field names.
+	ExportFormatCSV ExportFormat = "csv"
+)
+
+// ParseExportFormat parses a format name; an empty name selects NDJSON.
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch ExportFormat(name) {
+	case "", ExportFormatNDJSON:
+		return ExportFormatNDJSON, nil
+	case ExportFormatCSV:
+		return ExportFormatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q: use ndjson or csv", name)
+	}
+}
+
+// ContentType returns the MIME type of the format.
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// RecordWriter receives the records of a streamed query.
+type RecordWriter interface {
+	// WriteHeader is called once, before the first record.
+	WriteHeader(fields []FieldInfo) error
+	WriteRecord(record map[string]any) error
+	// Flush pushes buffered output to the client.
+	Flush() error
+}
+
+// flusher is implemented by HTTP response writers that support streaming.
+type flusher interface {
+	Flush()
+}
+
+// NewRecordWriter returns a RecordWriter encoding records in format to w.
+// If w can flush (e.g. an HTTP response), output is flushed periodically.
+func NewRecordWriter(format ExportFormat, w io.Writer) RecordWriter {
+	if format == ExportFormatCSV {
+		cw := csv.NewWriter(w)
+		cw.UseCRLF = true
+		return &csvRecordWriter{w: w, csv: cw}
+	}
+	return &ndjsonRecordWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+type ndjsonRecordWriter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	written int
+}
+
+func (n *ndjsonRecordWriter) WriteHeader([]FieldInfo) error {
+	return nil
+}
+
+func (n *ndjsonRecordWriter) WriteRecord(record map[string]any) error {
+	if err := n.enc.Encode(record); err != nil {
+		return fmt.Errorf("ndjson: %w", err)
+	}
+	n.written++
+	if n.written%exportFlushInterval == 0 {
+		return n.Flush()
+	}
+	return nil
+}
+
+func (n *ndjsonRecordWriter) Flush() error {
+	if f, ok := n.w.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+type csvRecordWriter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	fields  []string
+	row     []string
+	written int
+}
+
+func (c *csvRecordWriter) WriteHeader(fields []FieldInfo) error {
+	c.fields = make([]string, len(fields))
+	for i, f := range fields {
+		c.fields[i] = f.Name
+	}
+	c.row = make([]string, len(fields))
+	if err := c.csv.Write(c.fields); err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	return nil
+}
+
+func (c *csvRecordWriter) WriteRecord(record map[string]any) error {
+	for i, name := range c.fields {
+		value, err := csvValue(record[name])
+		if err != nil {
+			return fmt.Errorf("csv: %s: %w", name, err)
+		}
+		c.row[i] = value
+	}
+	if err := c.csv.Write(c.row); err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	c.written++
+	if c.written%exportFlushInterval == 0 {
+		return c.Flush()
+	}
+	return nil
+}
+
+func (c *csvRecordWriter) Flush() error {
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	if f, ok := c.w.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// csvValue formats a column value as a CSV cell. NULL is an empty cell;
+// nested values (relationship subqueries) are written as JSON.
+func csvValue(v any) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case [16]byte:
+		return uuid.UUID(val).String(), nil
+	case uuid.UUID:
+		return val.String(), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case int16:
+		return strconv.FormatInt(int64(val), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(val), 10), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case time.Time:
+		return val.Format(time.RFC3339Nano), nil
+	case pgtype.Numeric:
+		s, err := val.Value()
+		if err != nil || s == nil {
+			return "", err
+		}
+		return fmt.Sprint(s), nil
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
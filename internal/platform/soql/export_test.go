@@ -0,0 +1,144 @@
+package soql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseExportFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		want    ExportFormat
+		wantErr bool
+	}{
+		{name: "", want: ExportFormatNDJSON},
+		{name: "ndjson", want: ExportFormatNDJSON},
+		{name: "csv", want: ExportFormatCSV},
+		{name: "xlsx", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run("format "+tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseExportFormat(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExportFormat(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseExportFormat(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// flushRecorder counts flushes like an http.Flusher response writer.
+type flushRecorder struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushes++
+}
+
+func TestRecordWriter(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+	fields := []FieldInfo{{Name: "Id", Type: "id"}, {Name: "Name", Type: "string"}, {Name: "CreatedAt", Type: "datetime"}}
+	records := []map[string]any{
+		{"Id": [16]byte(id), "Name": `Acme, "Big" Corp`, "CreatedAt": time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{"Id": [16]byte(id), "Name": nil, "CreatedAt": nil},
+	}
+
+	write := func(t *testing.T, format ExportFormat) *flushRecorder {
+		t.Helper()
+		out := &flushRecorder{}
+		w := NewRecordWriter(format, out)
+		if err := w.WriteHeader(fields); err != nil {
+			t.Fatalf("WriteHeader() error = %v", err)
+		}
+		for _, rec := range records {
+			if err := w.WriteRecord(rec); err != nil {
+				t.Fatalf("WriteRecord() error = %v", err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+		return out
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		t.Parallel()
+		out := write(t, ExportFormatCSV)
+		want := "Id,Name,CreatedAt\r\n" +
+			id.String() + `,"Acme, ""Big"" Corp",2026-03-01T10:00:00Z` + "\r\n" +
+			id.String() + ",,\r\n"
+		if out.String() != want {
+			t.Errorf("csv =\n%q\nwant\n%q", out.String(), want)
+		}
+		if out.flushes != 1 {
+			t.Errorf("flushes = %d, want 1", out.flushes)
+		}
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		t.Parallel()
+		out := write(t, ExportFormatNDJSON)
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("lines = %d, want 2:\n%s", len(lines), out.String())
+		}
+		if !strings.Contains(lines[0], `"Name":"Acme, \"Big\" Corp"`) || !strings.Contains(lines[1], `"Name":null`) {
+			t.Errorf("ndjson =\n%s", out.String())
+		}
+	})
+
+	t.Run("flushes periodically", func(t *testing.T) {
+		t.Parallel()
+		out := &flushRecorder{}
+		w := NewRecordWriter(ExportFormatNDJSON, out)
+		for i := 0; i < exportFlushInterval*2+1; i++ {
+			if err := w.WriteRecord(map[string]any{"n": i}); err != nil {
+				t.Fatalf("WriteRecord() error = %v", err)
+			}
+		}
+		if out.flushes != 2 {
+			t.Errorf("flushes = %d, want 2", out.flushes)
+		}
+	})
+}
+
+func TestCSVValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{name: "null", value: nil, want: ""},
+		{name: "boolean", value: true, want: "true"},
+		{name: "integer", value: int64(42), want: "42"},
+		{name: "float", value: 1500.25, want: "1500.25"},
+		{name: "nested records", value: []any{map[string]any{"Email": "a@b.c"}}, want: `[{"Email":"a@b.c"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := csvValue(tt.value)
+			if err != nil {
+				t.Fatalf("csvValue() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("csvValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package soql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+// RecordReader reads single records through a QueryService, with the access
+// of the user in ctx: FIELDS(ALL) leaves out the fields FLS hides, and a
+// record RLS hides reads as missing.
+type RecordReader struct {
+	service QueryService
+}
+
+// NewRecordReader creates a new RecordReader.
+func NewRecordReader(service QueryService) *RecordReader {
+	return &RecordReader{service: service}
+}
+
+// ReadRecord returns the record id of object, or nil when it does not exist
+// or the user cannot read it.
+func (r *RecordReader) ReadRecord(ctx context.Context, object, id string) (map[string]any, error) {
+	// FIELDS(ALL) requires a LIMIT.
+	result, err := r.service.Execute(ctx,
+		fmt.Sprintf("SELECT FIELDS(ALL) FROM %s WHERE Id = :id LIMIT 1", object),
+		&QueryParams{Unpaged: true, Binds: map[string]any{"id": id}})
+	if err != nil {
+		var accessErr *engine.AccessError
+		if errors.As(err, &accessErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("recordReader.ReadRecord: %w", err)
+	}
+	if len(result.Records) == 0 {
+		return nil, nil
+	}
+	return result.Records[0], nil
+}
@@ -0,0 +1,172 @@
+package soql
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+// CachedResult is a query result held by a result cache backend, with what
+// it was computed from.
+type CachedResult struct {
+	Result *QueryResult
+	// Objects are all objects the query reads: its dependencies
+	// (CompiledQuery.Dependencies) and every object RLS filters.
+	Objects []string
+}
+
+// ResultCacheBackend stores cached results. engine.NewMemoryCache provides
+// an in-process backend; a shared store implements the same interface.
+type ResultCacheBackend = engine.Cache[string, *CachedResult]
+
+// ResultCache caches complete SOQL results. Entries are keyed by the query
+// as rendered for the user: the SQL with the user's RLS predicates and its
+// params, which carry the bind values, the owners, groups and sharing inputs
+// RLS admits and the dates resolved in the user's time zone. FLS and OLS
+// shape the SQL itself. Users who see the same data therefore share an
+// entry. Entries are dropped when DML changes an object the query reads
+// (dml.ChangeListener) and when the security outbox recomputes effective
+// permissions (security.RecomputeListener); the backend TTL bounds staleness
+// from any other change.
+type ResultCache struct {
+	backend ResultCacheBackend
+	cache   metadata.MetadataReader
+	fid     engine.FIDBuilder
+	maxRows int
+
+	// generation counts invalidations. A result is stored only if none
+	// happened while its query ran, so a query racing with a write cannot
+	// put back what the write invalidated.
+	generation atomic.Uint64
+}
+
+// ResultCacheOption configures a ResultCache.
+type ResultCacheOption func(*ResultCache)
+
+// WithResultCacheMaxRows skips caching results with more than n records.
+func WithResultCacheMaxRows(n int) ResultCacheOption {
+	return func(c *ResultCache) {
+		c.maxRows = n
+	}
+}
+
+// NewResultCache creates a ResultCache over backend. The metadata cache is
+// used to find the objects a change of another object may cascade to.
+func NewResultCache(backend ResultCacheBackend, cache metadata.MetadataReader, opts ...ResultCacheOption) *ResultCache {
+	c := &ResultCache{
+		backend: backend,
+		cache:   cache,
+		fid:     &engine.DefaultFIDBuilder{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// resultSlot is where the result of one query execution is cached.
+type resultSlot struct {
+	key        string
+	generation uint64
+}
+
+// slot returns the slot of a query rendered for the current user.
+func (c *ResultCache) slot(sql string, params []any) resultSlot {
+	return resultSlot{
+		key: c.fid.BuildFID("soql-result", "", map[string]any{
+			"sql":    sql,
+			"params": params,
+		}),
+		generation: c.generation.Load(),
+	}
+}
+
+// get returns a copy of a cached result, so callers may modify its records.
+func (c *ResultCache) get(ctx context.Context, slot resultSlot) (*QueryResult, bool) {
+	entry, ok := c.backend.Get(ctx, slot.key)
+	if !ok || entry == nil {
+		return nil, false
+	}
+	return cloneResult(entry.Result), true
+}
+
+func (c *ResultCache) set(ctx context.Context, slot resultSlot, compiled *engine.CompiledQuery, result *QueryResult) {
+	if c.maxRows > 0 && len(result.Records) > c.maxRows {
+		return
+	}
+	if c.generation.Load() != slot.generation {
+		return
+	}
+	_ = c.backend.Set(ctx, slot.key, &CachedResult{
+		Result:  cloneResult(result),
+		Objects: queryObjects(compiled),
+	})
+}
+
+// queryObjects returns the objects compiled reads, each once.
+func queryObjects(compiled *engine.CompiledQuery) []string {
+	objects := slices.Clone(compiled.Dependencies)
+	for _, target := range compiled.RLSTargets {
+		if target != nil && !slices.Contains(objects, target.Object) {
+			objects = append(objects, target.Object)
+		}
+	}
+	return objects
+}
+
+// ObjectsChanged implements dml.ChangeListener. It drops the results of
+// queries reading the changed objects or any object referencing them,
+// directly or through other objects, which a delete may cascade to.
+func (c *ResultCache) ObjectsChanged(_ context.Context, objects []string) {
+	changed := make(map[string]bool, len(objects))
+	pending := slices.Clone(objects)
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if changed[strings.ToLower(name)] {
+			continue
+		}
+		changed[strings.ToLower(name)] = true
+		obj, ok := c.cache.GetObjectByAPIName(name)
+		if !ok {
+			continue
+		}
+		for _, rel := range c.cache.GetReverseRelationships(obj.ID) {
+			pending = append(pending, rel.ChildObjectAPIName)
+		}
+	}
+
+	c.generation.Add(1)
+	_ = c.backend.Remove(func(entry *CachedResult) bool {
+		return slices.ContainsFunc(entry.Objects, func(object string) bool {
+			return changed[strings.ToLower(object)]
+		})
+	})
+}
+
+// EffectiveCachesRecomputed implements security.RecomputeListener. Entries
+// are shared by the users who see the same data, and share tables are not
+// part of their keys, so any change clears the cache.
+func (c *ResultCache) EffectiveCachesRecomputed(ctx context.Context, _ security.OutboxEvent) {
+	c.generation.Add(1)
+	_ = c.backend.Clear(ctx)
+}
+
+// cloneResult copies a result down to its records.
+func cloneResult(r *QueryResult) *QueryResult {
+	clone := *r
+	clone.Fields = slices.Clone(r.Fields)
+	if r.Records != nil {
+		clone.Records = make([]map[string]any, len(r.Records))
+		for i, rec := range r.Records {
+			clone.Records[i] = maps.Clone(rec)
+		}
+	}
+	return &clone
+}
@@ -0,0 +1,241 @@
+package soql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/security/rls"
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+// treeMetadataReader serves objects and the children referencing them.
+type treeMetadataReader struct {
+	metadata.MetadataReader
+	children map[string][]string
+}
+
+// objectID derives the ID of an object from its API name.
+func objectID(apiName string) uuid.UUID {
+	return uuid.NewSHA1(uuid.Nil, []byte(strings.ToLower(apiName)))
+}
+
+func (r *treeMetadataReader) GetObjectByAPIName(apiName string) (metadata.ObjectDefinition, bool) {
+	return metadata.ObjectDefinition{ID: objectID(apiName), APIName: apiName}, true
+}
+
+func (r *treeMetadataReader) GetReverseRelationships(id uuid.UUID) []metadata.RelationshipInfo {
+	var rels []metadata.RelationshipInfo
+	for parent, children := range r.children {
+		if objectID(parent) != id {
+			continue
+		}
+		for _, child := range children {
+			rels = append(rels, metadata.RelationshipInfo{ChildObjectAPIName: child, ParentObjectAPIName: parent})
+		}
+	}
+	return rels
+}
+
+func TestResultCache(t *testing.T) {
+	t.Parallel()
+
+	reader := &treeMetadataReader{children: map[string][]string{
+		"Account": {"Contact"},
+		"Contact": {"Task"},
+	}}
+
+	type cached struct {
+		query    string
+		compiled *engine.CompiledQuery
+	}
+	entries := []cached{
+		{"SELECT COUNT(Id) FROM Account", &engine.CompiledQuery{Dependencies: []string{"Account"}}},
+		{"SELECT Name FROM Contact", &engine.CompiledQuery{Dependencies: []string{"Contact"}}},
+		{"SELECT Subject FROM Task", &engine.CompiledQuery{Dependencies: []string{"Task"}}},
+		{"SELECT Name FROM Lead", &engine.CompiledQuery{Dependencies: []string{"Lead"}}},
+		{"SELECT Name FROM Deal", &engine.CompiledQuery{
+			Dependencies: []string{"Deal"},
+			RLSTargets:   []*engine.RLSTarget{{Object: "Deal"}, {Object: "Region"}},
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		invalidate func(context.Context, *ResultCache)
+		wantKept   []int // indexes into entries
+	}{
+		{
+			name: "DML drops queries reading the object and its descendants",
+			invalidate: func(ctx context.Context, c *ResultCache) {
+				c.ObjectsChanged(ctx, []string{"account"})
+			},
+			wantKept: []int{3, 4},
+		},
+		{
+			name: "DML on an object without dependents",
+			invalidate: func(ctx context.Context, c *ResultCache) {
+				c.ObjectsChanged(ctx, []string{"Task"})
+			},
+			wantKept: []int{0, 1, 3, 4},
+		},
+		{
+			name: "DML on an object only RLS reads",
+			invalidate: func(ctx context.Context, c *ResultCache) {
+				c.ObjectsChanged(ctx, []string{"Region"})
+			},
+			wantKept: []int{0, 1, 2, 3},
+		},
+		{
+			name: "user change clears the cache",
+			invalidate: func(ctx context.Context, c *ResultCache) {
+				c.EffectiveCachesRecomputed(ctx, security.OutboxEvent{EventType: "user_changed", EntityID: uuid.New()})
+			},
+		},
+		{
+			name: "permission set change clears the cache",
+			invalidate: func(ctx context.Context, c *ResultCache) {
+				c.EffectiveCachesRecomputed(ctx, security.OutboxEvent{EventType: "permission_set_changed", EntityID: uuid.New()})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			c := NewResultCache(engine.NewMemoryCache[string, *CachedResult](0, 0), reader)
+
+			for _, e := range entries {
+				c.set(ctx, c.slot(e.query, nil), e.compiled, &QueryResult{TotalSize: 1})
+			}
+
+			tt.invalidate(ctx, c)
+
+			kept := make(map[int]bool, len(tt.wantKept))
+			for _, i := range tt.wantKept {
+				kept[i] = true
+			}
+			for i, e := range entries {
+				_, ok := c.get(ctx, c.slot(e.query, nil))
+				if ok != kept[i] {
+					t.Errorf("entry %d (%s): cached = %v, want %v", i, e.query, ok, kept[i])
+				}
+			}
+		})
+	}
+}
+
+// ownerRLS admits the records of the owners visible to each user.
+type ownerRLS struct {
+	rls.Enforcer
+	owners map[uuid.UUID][]any
+}
+
+func (e *ownerRLS) BuildAliasedWhereClause(_ context.Context, userID, _ uuid.UUID, alias string) (string, []interface{}, error) {
+	return alias + ".owner_id = ANY($1)", []interface{}{e.owners[userID]}, nil
+}
+
+func TestResultCache_KeyedByVisibility(t *testing.T) {
+	t.Parallel()
+
+	account := engine.NewObjectMeta("Account", "public", "obj_account").
+		Field("Id", "id", engine.FieldTypeID).
+		Field("Name", "name", engine.FieldTypeString).
+		Build()
+	eng := engine.NewEngine(engine.WithMetadata(
+		engine.NewStaticMetadataProvider(map[string]*engine.ObjectMeta{"Account": account})))
+	compiled, err := eng.PrepareAndResolve(context.Background(), "SELECT Name FROM Account")
+	if err != nil {
+		t.Fatalf("PrepareAndResolve() error = %v", err)
+	}
+
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	team := []any{alice, bob}
+	executor := NewExecutor(nil, &stubMetadataReader{object: metadata.ObjectDefinition{ID: uuid.New(), APIName: "Account"}},
+		&ownerRLS{owners: map[uuid.UUID][]any{alice: team, bob: team, carol: {carol}}})
+	c := NewResultCache(engine.NewMemoryCache[string, *CachedResult](0, 0), &stubMetadataReader{})
+
+	key := func(user uuid.UUID) string {
+		ctx := security.ContextWithUser(context.Background(), security.UserContext{UserID: user})
+		sql, params, err := executor.renderForUser(ctx, compiled)
+		if err != nil {
+			t.Fatalf("renderForUser() error = %v", err)
+		}
+		return c.slot(sql, params).key
+	}
+
+	if key(alice) != key(bob) {
+		t.Error("users who see the same records must share an entry")
+	}
+	if key(alice) == key(carol) {
+		t.Error("users who see different records must not share an entry")
+	}
+}
+
+func TestResultCache_KeyAndStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	reader := &stubMetadataReader{}
+	compiled := &engine.CompiledQuery{Dependencies: []string{"Account"}}
+	const query = "SELECT t0.id FROM obj_account t0 WHERE t0.name = $1 AND (t0.owner_id = ANY($2))"
+
+	t.Run("keys by SQL and params", func(t *testing.T) {
+		t.Parallel()
+		c := NewResultCache(engine.NewMemoryCache[string, *CachedResult](0, 0), reader)
+		params := []any{"Acme", []string{"u1"}}
+		c.set(ctx, c.slot(query, params), compiled, &QueryResult{TotalSize: 1})
+
+		if _, ok := c.get(ctx, c.slot(query, []any{"Acme", []string{"u1"}})); !ok {
+			t.Error("expected a hit for the same SQL and params")
+		}
+		if _, ok := c.get(ctx, c.slot(query, []any{"Other", []string{"u1"}})); ok {
+			t.Error("different bind values must not hit")
+		}
+		if _, ok := c.get(ctx, c.slot(query, []any{"Acme", []string{"u2"}})); ok {
+			t.Error("different visible owners must not hit")
+		}
+	})
+
+	t.Run("returns copies", func(t *testing.T) {
+		t.Parallel()
+		c := NewResultCache(engine.NewMemoryCache[string, *CachedResult](0, 0), reader)
+		slot := c.slot(query, nil)
+		c.set(ctx, slot, compiled, &QueryResult{Records: []map[string]any{{"Name": "Acme"}}})
+
+		first, _ := c.get(ctx, slot)
+		first.Records[0]["Name"] = "changed"
+		second, _ := c.get(ctx, slot)
+		if second.Records[0]["Name"] != "Acme" {
+			t.Errorf("cached record was modified: %v", second.Records[0])
+		}
+	})
+
+	t.Run("skips results invalidated while running", func(t *testing.T) {
+		t.Parallel()
+		c := NewResultCache(engine.NewMemoryCache[string, *CachedResult](0, 0), reader)
+		slot := c.slot(query, nil)
+		c.ObjectsChanged(ctx, []string{"Account"})
+		c.set(ctx, slot, compiled, &QueryResult{})
+
+		if _, ok := c.get(ctx, c.slot(query, nil)); ok {
+			t.Error("a result computed before an invalidation must not be stored")
+		}
+	})
+
+	t.Run("skips large results", func(t *testing.T) {
+		t.Parallel()
+		c := NewResultCache(engine.NewMemoryCache[string, *CachedResult](0, 0), reader, WithResultCacheMaxRows(1))
+		slot := c.slot(query, nil)
+		c.set(ctx, slot, compiled, &QueryResult{Records: []map[string]any{{}, {}}})
+
+		if _, ok := c.get(ctx, slot); ok {
+			t.Error("a result above the row limit must not be stored")
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package soql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+// RunAsResult is the result of a query run as another user, annotated with
+// what that user's security removed from it.
+type RunAsResult struct {
+	QueryResult
+
+	// UserID is the user the query ran as.
+	UserID uuid.UUID `json:"userId"`
+
+	// StrippedFields lists the fields, as Object.Field, that FIELDS() left
+	// out because FLS hides them from the user.
+	StrippedFields []string `json:"strippedFields"`
+
+	// RLSFilteredRows is the number of records matching the query that the
+	// user's sharing (RLS) hides.
+	RLSFilteredRows int `json:"rlsFilteredRows"`
+}
+
+// RunAsService runs SOQL queries with the security of another user, so an
+// administrator can see a query exactly as that user would.
+type RunAsService interface {
+	RunAs(ctx context.Context, userID uuid.UUID, query string, params *QueryParams) (*RunAsResult, error)
+}
+
+// RunAsUserLoader loads the user a query runs as.
+type RunAsUserLoader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*security.User, error)
+}
+
+type runAsService struct {
+	engine   *engine.Engine
+	access   engine.AccessController
+	executor *Executor
+	users    RunAsUserLoader
+	audit    security.RunAsAuditRepository
+}
+
+// NewRunAsService creates a RunAsService. Queries are compiled with the
+// metadata and limits of eng and checked by access, the OLS/FLS controller
+// of the regular SOQL engine; executor applies the user's RLS. Every run is
+// recorded in audit.
+func NewRunAsService(
+	eng *engine.Engine,
+	access engine.AccessController,
+	executor *Executor,
+	users RunAsUserLoader,
+	audit security.RunAsAuditRepository,
+) RunAsService {
+	return &runAsService{
+		engine:   eng,
+		access:   access,
+		executor: executor,
+		users:    users,
+		audit:    audit,
+	}
+}
+
+// RunAs runs query with the UserContext of userID and returns one page of
+// params.PageSize records. The run is audited whether it succeeds or not;
+// when the audit entry cannot be stored, no result is returned.
+func (s *runAsService) RunAs(ctx context.Context, userID uuid.UUID, query string, params *QueryParams) (*RunAsResult, error) {
+	result := &RunAsResult{UserID: userID}
+	object, err := s.run(ctx, result, query, params)
+
+	admin, _ := security.UserFromContext(ctx)
+	entry := &security.RunAsAuditEntry{
+		AdminUserID:     admin.UserID,
+		UserID:          userID,
+		Query:           query,
+		Object:          object,
+		RowCount:        len(result.Records),
+		RLSFilteredRows: result.RLSFilteredRows,
+		StrippedFields:  result.StrippedFields,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if auditErr := s.audit.Insert(context.WithoutCancel(ctx), entry); auditErr != nil {
+		return nil, fmt.Errorf("runAsService.RunAs: audit: %w", auditErr)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("runAsService.RunAs: %w", err)
+	}
+	return result, nil
+}
+
+// run fills result and returns the root object of the query, empty when it
+// did not compile.
+func (s *runAsService) run(ctx context.Context, result *RunAsResult, query string, params *QueryParams) (string, error) {
+	user, err := s.users.GetByID(ctx, result.UserID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", apperror.NotFound("user", result.UserID.String())
+	}
+	ctx = security.ContextWithUser(ctx, security.UserContext{
+		UserID:    user.ID,
+		ProfileID: user.ProfileID,
+		RoleID:    user.RoleID,
+		TimeZone:  user.TimeZone,
+		Locale:    user.Locale,
+	})
+
+	// A fresh engine per run, so the compiled query cache of the regular
+	// engine cannot skip the FLS checks that report stripped fields.
+	fields := &strippedFieldRecorder{AccessController: s.access, seen: make(map[string]bool)}
+	eng := engine.NewEngine(
+		engine.WithMetadata(s.engine.GetMetadata()),
+		engine.WithAccessController(fields),
+		engine.WithLimits(s.engine.GetLimits()),
+		engine.WithDateResolver(s.engine.GetDateResolver()),
+	)
+
+	var binds map[string]any
+	pageSize := DefaultPageSize
+	if params != nil {
+		binds = params.Binds
+		pageSize = clampPageSize(params.PageSize)
+	}
+
+	compiled, err := eng.PrepareAndResolve(ctx, query)
+	if err != nil {
+		return "", mapRunAsError(err)
+	}
+	result.StrippedFields = fields.list()
+	var object string
+	if compiled.Shape != nil {
+		object = compiled.Shape.Object
+	}
+	if compiled.ForUpdate {
+		return object, apperror.BadRequest("FOR UPDATE queries cannot run as another user")
+	}
+	compiled, err = compiled.Bind(binds)
+	if err != nil {
+		return object, mapRunAsError(err)
+	}
+
+	// Queries that cannot be paged (aggregates, SELECT ROW) return every
+	// record up to their LIMIT, as in Execute.
+	var records *QueryResult
+	if compiled.Pagination == nil {
+		records, err = s.executor.Execute(ctx, compiled)
+	} else {
+		records, _, err = s.executor.ExecutePage(ctx, compiled, &Page{Size: pageSize})
+	}
+	if err != nil {
+		return object, err
+	}
+	result.QueryResult = *records
+
+	filtered, err := s.executor.CountRLSFiltered(ctx, compiled)
+	if err != nil {
+		return object, err
+	}
+	result.RLSFilteredRows = filtered
+	return object, nil
+}
+
+// mapRunAsError converts SOQL engine errors to API errors. A field or
+// object the user cannot read is reported as such rather than stripped.
+func mapRunAsError(err error) error {
+	switch {
+	case engine.IsAccessError(err):
+		return apperror.Forbidden("user cannot run the query: " + err.Error())
+	case engine.IsParseError(err), engine.IsValidationError(err), engine.IsLimitError(err):
+		return apperror.BadRequest("invalid soql: " + err.Error())
+	default:
+		return err
+	}
+}
+
+// strippedFieldRecorder is an engine.AccessController that records the
+// fields FLS denies. Explicitly selected fields fail validation; the ones
+// recorded for a query that compiled are those FIELDS() left out.
+type strippedFieldRecorder struct {
+	engine.AccessController
+
+	mu     sync.Mutex
+	seen   map[string]bool
+	fields []string
+}
+
+// CanAccessField implements engine.AccessController.
+func (r *strippedFieldRecorder) CanAccessField(ctx context.Context, object, field string) error {
+	err := r.AccessController.CanAccessField(ctx, object, field)
+	var accessErr *engine.AccessError
+	if errors.As(err, &accessErr) {
+		name := object + "." + field
+		r.mu.Lock()
+		if !r.seen[name] {
+			r.seen[name] = true
+			r.fields = append(r.fields, name)
+		}
+		r.mu.Unlock()
+	}
+	return err
+}
+
+func (r *strippedFieldRecorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fields := make([]string, len(r.fields))
+	copy(fields, r.fields)
+	sort.Strings(fields)
+	return fields
+}
+
+// CountRLSFiltered returns how many records matching the query the sharing
+// predicates hide from the current user: the query is counted once as the
+// user runs it and once without RLS. Both counts ignore the query LIMIT, so
+// hidden records past it are counted too. USING SCOPE narrows both counts,
+// since it is part of what the query asks for. In system context nothing is
+// hidden.
+func (e *Executor) CountRLSFiltered(ctx context.Context, compiled *engine.CompiledQuery) (int, error) {
+	rlsFn := e.rlsPredicate(ctx)
+	if rlsFn == nil {
+		return 0, nil
+	}
+	unrestricted := func(ctx context.Context, target *engine.RLSTarget) (string, []any, error) {
+		if target.Kind == engine.RLSTargetScope {
+			return rlsFn(ctx, target)
+		}
+		return "", nil, nil
+	}
+
+	visible, err := e.count(ctx, compiled, rlsFn)
+	if err != nil {
+		return 0, fmt.Errorf("soqlExecutor.CountRLSFiltered: %w", err)
+	}
+	all, err := e.count(ctx, compiled, unrestricted)
+	if err != nil {
+		return 0, fmt.Errorf("soqlExecutor.CountRLSFiltered: %w", err)
+	}
+	return all - visible, nil
+}
+
+// count returns the number of records the query matches with the RLS
+// predicates of rlsFn, regardless of its LIMIT.
+func (e *Executor) count(ctx context.Context, compiled *engine.CompiledQuery, rlsFn engine.RLSPredicateFunc) (int, error) {
+	sql, params, err := e.render(ctx, compiled, nil, rlsFn)
+	if err != nil {
+		return 0, err
+	}
+
+	q, release, err := e.begin(ctx, compiled)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	var n int
+	if err := q.QueryRow(ctx, "SELECT count(*) FROM ("+engine.WithoutLimit(sql)+") AS q", params...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+	return n, nil
+}
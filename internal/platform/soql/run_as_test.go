@@ -0,0 +1,150 @@
+package soql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/security"
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+type stubRunAsUsers map[uuid.UUID]*security.User
+
+func (s stubRunAsUsers) GetByID(_ context.Context, id uuid.UUID) (*security.User, error) {
+	return s[id], nil
+}
+
+type stubRunAsAudit struct {
+	entries []security.RunAsAuditEntry
+	err     error
+}
+
+func (s *stubRunAsAudit) Insert(_ context.Context, entry *security.RunAsAuditEntry) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, *entry)
+	return nil
+}
+
+func TestRunAsService_RunAs(t *testing.T) {
+	t.Parallel()
+
+	meta := engine.NewStaticMetadataProvider(map[string]*engine.ObjectMeta{
+		"Deal": engine.NewObjectMeta("Deal", "", "obj_deal").
+			Field("Id", "id", engine.FieldTypeID).
+			Field("Name", "name", engine.FieldTypeString).
+			FieldFull(&engine.FieldMeta{Name: "Amount", Column: "amount", Type: engine.FieldTypeFloat, Custom: true}).
+			Build(),
+	})
+	access := &engine.FieldAccessController{AllowedFields: map[string]map[string]bool{
+		"Deal": {"Id": true, "Name": true},
+	}}
+
+	adminID := uuid.New()
+	anna := &security.User{ID: uuid.New(), ProfileID: uuid.New(), IsActive: true}
+
+	tests := []struct {
+		name         string
+		userID       uuid.UUID
+		query        string
+		auditErr     error
+		wantStatus   int
+		wantObject   string
+		wantStripped []string
+		wantAudited  bool
+	}{
+		{
+			name:        "unknown user",
+			userID:      uuid.New(),
+			query:       "SELECT Id FROM Deal",
+			wantStatus:  http.StatusNotFound,
+			wantAudited: true,
+		},
+		{
+			name:        "explicitly selected field hidden by FLS",
+			userID:      anna.ID,
+			query:       "SELECT Name, Amount FROM Deal",
+			wantStatus:  http.StatusForbidden,
+			wantAudited: true,
+		},
+		{
+			name:        "invalid query",
+			userID:      anna.ID,
+			query:       "SELECT FROM",
+			wantStatus:  http.StatusBadRequest,
+			wantAudited: true,
+		},
+		{
+			name:         "FIELDS() strips hidden fields",
+			userID:       anna.ID,
+			query:        "SELECT FIELDS(ALL) FROM Deal LIMIT 10 FOR UPDATE",
+			wantStatus:   http.StatusBadRequest, // rejected before execution
+			wantObject:   "Deal",
+			wantStripped: []string{"Deal.Amount"},
+			wantAudited:  true,
+		},
+		{
+			name:       "audit failure wins over the query error",
+			userID:     anna.ID,
+			query:      "SELECT FROM",
+			auditErr:   errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			audit := &stubRunAsAudit{err: tt.auditErr}
+			svc := NewRunAsService(engine.NewEngine(engine.WithMetadata(meta)), access, nil,
+				stubRunAsUsers{anna.ID: anna}, audit)
+
+			ctx := security.ContextWithUser(context.Background(), security.UserContext{UserID: adminID})
+			result, err := svc.RunAs(ctx, tt.userID, tt.query, nil)
+			if err == nil {
+				t.Fatalf("RunAs() = %+v, want error", result)
+			}
+			status := http.StatusInternalServerError
+			var appErr *apperror.AppError
+			if errors.As(err, &appErr) {
+				status = appErr.HTTPStatus
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%v)", status, tt.wantStatus, err)
+			}
+
+			if !tt.wantAudited {
+				if len(audit.entries) != 0 {
+					t.Errorf("audited %d entries, want none", len(audit.entries))
+				}
+				return
+			}
+			if len(audit.entries) != 1 {
+				t.Fatalf("audited %d entries, want 1", len(audit.entries))
+			}
+			entry := audit.entries[0]
+			if entry.AdminUserID != adminID || entry.UserID != tt.userID || entry.Query != tt.query {
+				t.Errorf("entry = %+v, want admin %s, user %s, query %q", entry, adminID, tt.userID, tt.query)
+			}
+			if entry.Object != tt.wantObject {
+				t.Errorf("entry.Object = %q, want %q", entry.Object, tt.wantObject)
+			}
+			if entry.Error == "" {
+				t.Error("entry.Error is empty for a failed run")
+			}
+			if len(entry.StrippedFields) != 0 || len(tt.wantStripped) != 0 {
+				if !reflect.DeepEqual(entry.StrippedFields, tt.wantStripped) {
+					t.Errorf("entry.StrippedFields = %v, want %v", entry.StrippedFields, tt.wantStripped)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package soql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/metadata"
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+// SavedQueryAnalyzer validates saved queries with the SOQL engine. It
+// implements metadata.SavedQueryAnalyzer.
+type SavedQueryAnalyzer struct {
+	cache  metadata.MetadataReader
+	limits *engine.Limits
+}
+
+// NewSavedQueryAnalyzer creates a SavedQueryAnalyzer checking queries
+// against the metadata in cache under limits (nil for the defaults).
+func NewSavedQueryAnalyzer(cache metadata.MetadataReader, limits *engine.Limits) *SavedQueryAnalyzer {
+	if limits == nil {
+		limits = &engine.DefaultLimits
+	}
+	return &SavedQueryAnalyzer{cache: cache, limits: limits}
+}
+
+// AnalyzeSavedQuery compiles the query without access checks (queries are
+// checked against the caller's permissions when they run) and reports its
+// bind variables and the fields it references.
+func (a *SavedQueryAnalyzer) AnalyzeSavedQuery(ctx context.Context, query string) (*metadata.SavedQueryAnalysis, error) {
+	refs := &fieldRecorder{cache: a.cache, seen: make(map[uuid.UUID]bool)}
+	eng := engine.NewEngine(
+		engine.WithMetadata(NewMetadataAdapter(a.cache)),
+		engine.WithAccessController(refs),
+		engine.WithLimits(a.limits),
+	)
+
+	compiled, err := eng.Prepare(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("savedQueryAnalyzer.AnalyzeSavedQuery: %w",
+			apperror.BadRequest("invalid soql: "+err.Error()))
+	}
+
+	// The validator rejects a variable used with two types, so every
+	// occurrence of a name has the same type.
+	binds := make(map[string]string, len(compiled.BindParams))
+	for _, bp := range compiled.BindParams {
+		binds[bp.Name] = bp.Type.String()
+	}
+
+	return &metadata.SavedQueryAnalysis{Binds: binds, FieldIDs: refs.fieldIDs}, nil
+}
+
+// fieldRecorder is an engine.AccessController that allows everything and
+// records the fields the validator checks, i.e. every field the query uses.
+type fieldRecorder struct {
+	cache metadata.MetadataReader
+
+	mu       sync.Mutex
+	seen     map[uuid.UUID]bool
+	fieldIDs []uuid.UUID
+}
+
+// CanAccessObject implements engine.AccessController.
+func (r *fieldRecorder) CanAccessObject(context.Context, string) error {
+	return nil
+}
+
+// CanAccessField implements engine.AccessController.
+func (r *fieldRecorder) CanAccessField(_ context.Context, object, field string) error {
+	objDef, ok := r.cache.GetObjectByAPIName(object)
+	if !ok {
+		return nil
+	}
+	for _, f := range r.cache.GetFieldsByObjectID(objDef.ID) {
+		if !strings.EqualFold(f.APIName, field) {
+			continue
+		}
+		r.mu.Lock()
+		if !r.seen[f.ID] {
+			r.seen[f.ID] = true
+			r.fieldIDs = append(r.fieldIDs, f.ID)
+		}
+		r.mu.Unlock()
+		break
+	}
+	return nil
+}
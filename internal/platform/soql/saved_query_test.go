@@ -0,0 +1,146 @@
+package soql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/platform/metadata"
+)
+
+// stubMetadataReader serves a single object with its fields, child
+// relationships, global picklists and translations; the remaining
+// MetadataReader methods are not used.
+type stubMetadataReader struct {
+	metadata.MetadataReader
+	object       metadata.ObjectDefinition
+	fields       []metadata.FieldDefinition
+	children     []metadata.RelationshipInfo
+	picklists    map[uuid.UUID][]metadata.PicklistValueRow
+	translations map[uuid.UUID]map[string]string // by resource ID, label only
+}
+
+func (r *stubMetadataReader) GetObjectByAPIName(apiName string) (metadata.ObjectDefinition, bool) {
+	if strings.EqualFold(apiName, r.object.APIName) {
+		return r.object, true
+	}
+	return metadata.ObjectDefinition{}, false
+}
+
+func (r *stubMetadataReader) GetObjectByID(id uuid.UUID) (metadata.ObjectDefinition, bool) {
+	return r.object, id == r.object.ID
+}
+
+func (r *stubMetadataReader) GetFieldsByObjectID(uuid.UUID) []metadata.FieldDefinition {
+	return r.fields
+}
+
+func (r *stubMetadataReader) GetForwardRelationships(uuid.UUID) []metadata.RelationshipInfo {
+	return nil
+}
+
+func (r *stubMetadataReader) GetReverseRelationships(uuid.UUID) []metadata.RelationshipInfo {
+	return r.children
+}
+
+func (r *stubMetadataReader) ListObjectAPINames() []string {
+	return []string{r.object.APIName}
+}
+
+func (r *stubMetadataReader) GetPicklistValues(id uuid.UUID) []metadata.PicklistValueRow {
+	return r.picklists[id]
+}
+
+func (r *stubMetadataReader) GetTranslations(_ string, id uuid.UUID, fieldName string) map[string]string {
+	if fieldName != "label" {
+		return nil
+	}
+	return r.translations[id]
+}
+
+func TestSavedQueryAnalyzer_AnalyzeSavedQuery(t *testing.T) {
+	t.Parallel()
+
+	nameID := uuid.New()
+	revenueID := uuid.New()
+	reader := &stubMetadataReader{
+		object: metadata.ObjectDefinition{ID: uuid.New(), APIName: "Account", TableName: "obj_account"},
+		fields: []metadata.FieldDefinition{
+			{ID: nameID, APIName: "Name", FieldType: metadata.FieldTypeText},
+			{ID: revenueID, APIName: "Revenue", FieldType: metadata.FieldTypeNumber},
+		},
+	}
+	analyzer := NewSavedQueryAnalyzer(reader, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantBinds  map[string]string
+		wantFields []uuid.UUID
+		wantErr    string
+	}{
+		{
+			name:       "reports binds and referenced fields",
+			query:      "SELECT Id, Name FROM Account WHERE Revenue > :min AND Id IN :ids",
+			wantBinds:  map[string]string{"min": "integer", "ids": "id[]"},
+			wantFields: []uuid.UUID{nameID, revenueID},
+		},
+		{
+			name:       "query without binds",
+			query:      "SELECT Name FROM Account",
+			wantBinds:  map[string]string{},
+			wantFields: []uuid.UUID{nameID},
+		},
+		{
+			name:    "invalid soql",
+			query:   "SELECT Missing FROM Account",
+			wantErr: "invalid soql",
+		},
+		{
+			name:    "bind variable used with two types",
+			query:   "SELECT Id FROM Account WHERE Name = :v OR Revenue > :v",
+			wantErr: "bind variable :v is used as both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			analysis, err := analyzer.AnalyzeSavedQuery(context.Background(), tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("AnalyzeSavedQuery() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnalyzeSavedQuery() unexpected error: %v", err)
+			}
+
+			if len(analysis.Binds) != len(tt.wantBinds) {
+				t.Fatalf("Binds = %v, want %v", analysis.Binds, tt.wantBinds)
+			}
+			for name, typ := range tt.wantBinds {
+				if analysis.Binds[name] != typ {
+					t.Errorf("Binds[%q] = %q, want %q", name, analysis.Binds[name], typ)
+				}
+			}
+
+			got := make(map[uuid.UUID]bool, len(analysis.FieldIDs))
+			for _, id := range analysis.FieldIDs {
+				got[id] = true
+			}
+			if len(got) != len(tt.wantFields) {
+				t.Fatalf("FieldIDs = %v, want %v", analysis.FieldIDs, tt.wantFields)
+			}
+			for _, id := range tt.wantFields {
+				if !got[id] {
+					t.Errorf("FieldIDs missing %s", id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package soql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+// SearchService executes SOSL searches with full security enforcement.
+type SearchService interface {
+	Search(ctx context.Context, search string) (*SearchResult, error)
+}
+
+type searchService struct {
+	engine   *engine.Engine
+	executor *Executor
+}
+
+// NewSearchService creates a new SearchService. The engine validates every
+// RETURNING object against OLS/FLS and the executor applies RLS, exactly as
+// for SOQL queries.
+func NewSearchService(eng *engine.Engine, executor *Executor) SearchService {
+	return &searchService{
+		engine:   eng,
+		executor: executor,
+	}
+}
+
+// Search runs a SOSL search and groups the matching records per object.
+// The search LIMIT caps the total: objects listed later get what is left.
+func (s *searchService) Search(ctx context.Context, search string) (*SearchResult, error) {
+	compiled, err := s.engine.PrepareSearch(ctx, search)
+	if err != nil {
+		return nil, fmt.Errorf("searchService.Search: %w", err)
+	}
+
+	result := &SearchResult{Groups: make([]SearchGroup, 0, len(compiled.Queries))}
+	remaining := compiled.Limit
+	for _, q := range compiled.Queries {
+		group := SearchGroup{
+			Object:  q.Shape.Object,
+			Fields:  shapeToFieldInfo(q.Shape),
+			Records: []map[string]any{},
+		}
+		if remaining > 0 {
+			res, err := s.executor.Execute(ctx, q)
+			if err != nil {
+				return nil, fmt.Errorf("searchService.Search: %s: %w", q.Shape.Object, err)
+			}
+			records := res.Records
+			if len(records) > remaining {
+				records = records[:remaining]
+			}
+			if records != nil {
+				group.Records = records
+			}
+			remaining -= len(records)
+		}
+		group.TotalSize = len(group.Records)
+		result.TotalSize += group.TotalSize
+		result.Groups = append(result.Groups, group)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package soql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/adverax/crm/internal/platform/soql/engine"
+)
+
+func TestSearchService_RejectsInvalidSearch(t *testing.T) {
+	t.Parallel()
+
+	metadata := engine.NewStaticMetadataProvider(map[string]*engine.ObjectMeta{
+		"Account": engine.NewObjectMeta("Account", "public", "obj_account").
+			Field("Id", "id", engine.FieldTypeID).
+			Field("Name", "name", engine.FieldTypeString).
+			Searchable("search_vector").
+			Build(),
+		"Invoice": engine.NewObjectMeta("Invoice", "public", "obj_invoice").
+			Field("Id", "id", engine.FieldTypeID).
+			Build(),
+	})
+	svc := NewSearchService(engine.NewEngine(engine.WithMetadata(metadata)), nil)
+
+	tests := []struct {
+		name     string
+		search   string
+		wantCode engine.ValidationErrorCode
+	}{
+		{"object without search vector", "FIND {acme} RETURNING Invoice", engine.ErrCodeObjectNotSearchable},
+		{"invalid search term", "FIND {(acme} RETURNING Account(Name)", engine.ErrCodeInvalidSearchTerm},
+		{"unknown field", "FIND {acme} RETURNING Account(Nope)", engine.ErrCodeUnknownField},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := svc.Search(context.Background(), tt.search)
+			var verr *engine.ValidationError
+			if !errors.As(err, &verr) || verr.Code != tt.wantCode {
+				t.Errorf("Search() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
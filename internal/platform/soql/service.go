@@ -2,55 +2,366 @@ package soql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/querylog"
+	"github.com/adverax/crm/internal/platform/security"
 	"github.com/adverax/crm/internal/platform/soql/engine"
 )
 
 // QueryService executes SOQL queries with full security enforcement.
 type QueryService interface {
 	Execute(ctx context.Context, query string, params *QueryParams) (*QueryResult, error)
+	ExecuteNext(ctx context.Context, cursor string) (*QueryResult, error)
 	Describe(ctx context.Context, query string) (*DescribeResult, error)
+	Explain(ctx context.Context, query string, params *QueryParams) (*ExplainResult, error)
+	Stream(ctx context.Context, query string, binds map[string]any, w RecordWriter) (int, error)
 }
 
 type queryService struct {
-	engine   *engine.Engine
-	executor *Executor
+	engine       *engine.Engine
+	exportEngine *engine.Engine
+	executor     *Executor
+	cursors      engine.CursorManager
+	fid          engine.FIDBuilder
+	writes       WriteTracker
+	results      *ResultCache
+	log          querylog.Recorder
+}
+
+// WriteTracker reports users who changed data recently. Their queries stay
+// on the primary, since a read replica may not have their changes yet.
+type WriteTracker interface {
+	WroteRecently(userID uuid.UUID) bool
+}
+
+// QueryServiceOption configures a QueryService.
+type QueryServiceOption func(*queryService)
+
+// WithCursorManager enables keyset cursors (nextRecordsUrl) for paged results.
+// Without it, Execute returns a single page and ExecuteNext is unavailable.
+func WithCursorManager(cursors engine.CursorManager) QueryServiceOption {
+	return func(s *queryService) {
+		s.cursors = cursors
+	}
+}
+
+// WithExportEngine compiles streamed queries with eng, whose limits (e.g. a
+// higher MaxRecords) apply to exports instead of the regular ones. It must
+// share the metadata and access controller of the main engine.
+func WithExportEngine(eng *engine.Engine) QueryServiceOption {
+	return func(s *queryService) {
+		s.exportEngine = eng
+	}
+}
+
+// WithWriteTracker keeps the queries of users who wrote recently off the
+// read replica, so they read their own writes. Without it every query that
+// can run on the replica does.
+func WithWriteTracker(tracker WriteTracker) QueryServiceOption {
+	return func(s *queryService) {
+		s.writes = tracker
+	}
+}
+
+// WithResultCache serves repeated complete results from cache. Paged
+// results and FOR UPDATE queries are never cached.
+func WithResultCache(cache *ResultCache) QueryServiceOption {
+	return func(s *queryService) {
+		s.results = cache
+	}
+}
+
+// WithQueryLog reports every executed query to recorder: the user, the
+// query hash, the object, the number of records and the duration.
+func WithQueryLog(recorder querylog.Recorder) QueryServiceOption {
+	return func(s *queryService) {
+		s.log = recorder
+	}
 }
 
 // NewQueryService creates a new QueryService.
-func NewQueryService(eng *engine.Engine, executor *Executor) QueryService {
-	return &queryService{
+func NewQueryService(eng *engine.Engine, executor *Executor, opts ...QueryServiceOption) QueryService {
+	s := &queryService{
 		engine:   eng,
 		executor: executor,
+		fid:      &engine.DefaultFIDBuilder{},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Execute parses, validates, compiles, and executes a SOQL query.
+// With nil params the whole result (up to the query LIMIT) is returned;
+// otherwise records are returned in pages of params.PageSize.
 func (s *queryService) Execute(ctx context.Context, query string, params *QueryParams) (*QueryResult, error) {
-	if params == nil {
-		params = &QueryParams{PageSize: DefaultPageSize}
+	ctx, started := s.startLog(ctx)
+	result, compiled, err := s.execute(ctx, query, params)
+	s.logQuery(ctx, query, compiled, resultSize(result), started, err)
+	if err != nil {
+		return nil, fmt.Errorf("queryService.Execute: %w", err)
+	}
+	return result, nil
+}
+
+func (s *queryService) execute(ctx context.Context, query string, params *QueryParams) (*QueryResult, *engine.CompiledQuery, error) {
+	var binds map[string]any
+	if params != nil {
+		binds = params.Binds
 	}
-	if params.PageSize <= 0 {
-		params.PageSize = DefaultPageSize
+
+	compiled, err := s.prepare(ctx, s.engine, query, binds)
+	if err != nil {
+		return nil, nil, err
 	}
-	if params.PageSize > MaxPageSize {
-		params.PageSize = MaxPageSize
+	ctx = s.route(ctx, compiled)
+
+	if params == nil || params.Unpaged || compiled.Pagination == nil {
+		if s.results == nil || compiled.ForUpdate {
+			if err := s.checkCost(ctx, s.engine.GetLimits(), compiled, nil); err != nil {
+				return nil, compiled, err
+			}
+			result, err := s.executor.Execute(ctx, compiled)
+			if err != nil {
+				return nil, compiled, err
+			}
+			return result, compiled, nil
+		}
+
+		// The query as rendered for the user is the cache key: users who
+		// see the same data share the cached result.
+		sql, args, err := s.executor.renderForUser(ctx, compiled)
+		if err != nil {
+			return nil, compiled, err
+		}
+		slot := s.results.slot(sql, args)
+		if result, ok := s.results.get(ctx, slot); ok {
+			return result, compiled, nil
+		}
+		if err := s.checkCost(ctx, s.engine.GetLimits(), compiled, nil); err != nil {
+			return nil, compiled, err
+		}
+		result, err := s.executor.executeRendered(ctx, compiled, sql, args)
+		if err != nil {
+			return nil, compiled, err
+		}
+		s.results.set(ctx, slot, compiled, result)
+		return result, compiled, nil
 	}
 
-	compiled, err := s.engine.PrepareAndResolve(ctx, query)
+	pageSize := clampPageSize(params.PageSize)
+	result, err := s.executePage(ctx, query, binds, compiled, pageSize, compiled.Pagination.Limit, nil)
 	if err != nil {
-		return nil, fmt.Errorf("queryService.Execute: %w", err)
+		return nil, compiled, err
 	}
+	return result, compiled, nil
+}
 
-	result, err := s.executor.Execute(ctx, compiled)
+// ExecuteNext resumes a paged query from a cursor issued by Execute or a
+// previous ExecuteNext. The query is re-run under the caller's security
+// context; cursors issued to another user or for another query are rejected.
+func (s *queryService) ExecuteNext(ctx context.Context, cursor string) (*QueryResult, error) {
+	if s.cursors == nil {
+		return nil, fmt.Errorf("queryService.ExecuteNext: %w",
+			apperror.BadRequest("cursor pagination is not enabled"))
+	}
+
+	payload, err := s.cursors.Decode(cursor)
 	if err != nil {
-		return nil, fmt.Errorf("queryService.Execute: %w", err)
+		return nil, fmt.Errorf("queryService.ExecuteNext: %w", mapCursorError(err))
+	}
+	if payload == nil || payload.Query == "" {
+		return nil, fmt.Errorf("queryService.ExecuteNext: %w", mapCursorError(engine.ErrInvalidCursor))
+	}
+
+	ctx, started := s.startLog(ctx)
+	result, compiled, err := s.executeNext(ctx, payload)
+	s.logQuery(ctx, payload.Query, compiled, resultSize(result), started, err)
+	if err != nil {
+		return nil, fmt.Errorf("queryService.ExecuteNext: %w", err)
+	}
+	return result, nil
+}
+
+func (s *queryService) executeNext(ctx context.Context, payload *engine.CursorPayload) (*QueryResult, *engine.CompiledQuery, error) {
+	compiled, err := s.prepare(ctx, s.engine, payload.Query, payload.Binds)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := compiled.Pagination
+	if p == nil {
+		return nil, compiled, mapCursorError(engine.ErrCursorMismatch)
+	}
+
+	if err := s.cursors.ValidateContext(payload, s.buildFID(ctx, payload.Query, p), p.SortKeys); err != nil {
+		return nil, compiled, mapCursorError(err)
+	}
+	ctx = s.route(ctx, compiled)
+
+	after := make([]any, len(p.SortKeySOQL))
+	for i, name := range p.SortKeySOQL {
+		v, ok := payload.LastRow[name]
+		if !ok {
+			return nil, compiled, mapCursorError(engine.ErrInvalidCursor)
+		}
+		after[i] = fromCursorValue(v)
+	}
+
+	result, err := s.executePage(ctx, payload.Query, payload.Binds, compiled, clampPageSize(payload.PageSize), payload.Remaining, after)
+	if err != nil {
+		return nil, compiled, err
+	}
+	return result, compiled, nil
+}
+
+// replicaKey marks a context whose query may run on the read replica.
+type replicaKey struct{}
+
+func routedToReplica(ctx context.Context) bool {
+	routed, _ := ctx.Value(replicaKey{}).(bool)
+	return routed
+}
+
+// route sends a query to the read replica, when there is one, unless it
+// locks rows or the user has written recently.
+func (s *queryService) route(ctx context.Context, compiled *engine.CompiledQuery) context.Context {
+	if s.executor == nil || s.executor.replica == nil || compiled.ForUpdate {
+		return ctx
+	}
+	if s.writes != nil {
+		if uc, ok := security.UserFromContext(ctx); ok && s.writes.WroteRecently(uc.UserID) {
+			return ctx
+		}
+	}
+	return context.WithValue(ctx, replicaKey{}, true)
+}
+
+// prepare compiles a query, resolves its date literals and binds its variables.
+func (s *queryService) prepare(ctx context.Context, eng *engine.Engine, query string, binds map[string]any) (*engine.CompiledQuery, error) {
+	compiled, err := eng.PrepareAndResolve(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Bind(binds)
+}
+
+// executePage runs one page and, when more records follow, issues the cursor
+// for the next one. remaining is the number of records still allowed by the
+// query LIMIT (zero when unbounded).
+func (s *queryService) executePage(
+	ctx context.Context,
+	query string,
+	binds map[string]any,
+	compiled *engine.CompiledQuery,
+	pageSize, remaining int,
+	after []any,
+) (*QueryResult, error) {
+	size := pageSize
+	if remaining > 0 && remaining < size {
+		size = remaining
+	}
+
+	page := &Page{Size: size, After: after}
+	if err := s.checkCost(ctx, s.engine.GetLimits(), compiled, page); err != nil {
+		return nil, err
+	}
+	result, next, err := s.executor.ExecutePage(ctx, compiled, page)
+	if err != nil {
+		return nil, err
 	}
 
+	if remaining > 0 {
+		remaining -= len(result.Records)
+		if remaining <= 0 {
+			next = nil
+		}
+	}
+	if next == nil || s.cursors == nil {
+		result.Done = next == nil
+		return result, nil
+	}
+
+	p := compiled.Pagination
+	lastRow := make(map[string]interface{}, len(next))
+	for i, name := range p.SortKeySOQL {
+		lastRow[name] = toCursorValue(next[i])
+	}
+
+	cursor, err := s.cursors.Encode(&engine.CursorPayload{
+		OrderBy:   p.SortKeys,
+		LastRow:   lastRow,
+		FID:       s.buildFID(ctx, query, p),
+		Query:     query,
+		PageSize:  pageSize,
+		Remaining: remaining,
+		Binds:     binds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cursor: %w", err)
+	}
+
+	result.Done = false
+	result.NextCursor = NextRecordsPath + cursor
 	return result, nil
 }
 
+// checkCost rejects a query whose planner estimates exceed the cost ceiling
+// in limits. Without a ceiling the query is not planned up front.
+func (s *queryService) checkCost(ctx context.Context, limits *engine.Limits, compiled *engine.CompiledQuery, page *Page) error {
+	if !limits.HasEstimateLimits() {
+		return nil
+	}
+	explained, err := s.executor.Explain(ctx, compiled, page)
+	if err != nil {
+		return err
+	}
+	return mapLimitError(limits.CheckEstimate(explained.Plan.TotalCost, explained.Plan.PlanRows))
+}
+
+// buildFID binds a cursor to the query text and the requesting user.
+func (s *queryService) buildFID(ctx context.Context, query string, p *engine.PaginationInfo) string {
+	uc, _ := security.UserFromContext(ctx)
+	return s.fid.BuildFID(p.Object, uc.UserID.String(), map[string]interface{}{"q": query})
+}
+
+func clampPageSize(size int) int {
+	if size <= 0 {
+		return DefaultPageSize
+	}
+	if size > MaxPageSize {
+		return MaxPageSize
+	}
+	return size
+}
+
+// mapCursorError converts cursor pagination errors to API errors.
+func mapCursorError(err error) error {
+	var pagErr *engine.PaginationError
+	if !errors.As(err, &pagErr) {
+		return err
+	}
+	if pagErr == engine.ErrCursorMismatch {
+		return apperror.Forbidden("cursor does not belong to this user or query")
+	}
+	return apperror.BadRequest(pagErr.Message)
+}
+
+// mapLimitError converts a cost ceiling violation to an API error.
+func mapLimitError(err error) error {
+	var limitErr *engine.LimitError
+	if !errors.As(err, &limitErr) {
+		return err
+	}
+	return apperror.BadRequest("query is too expensive: " + limitErr.Message)
+}
+
 // Describe analyzes a SOQL query without executing it, returning field metadata.
 func (s *queryService) Describe(ctx context.Context, query string) (*DescribeResult, error) {
 	compiled, err := s.engine.Prepare(ctx, query)
@@ -59,8 +370,104 @@ func (s *queryService) Describe(ctx context.Context, query string) (*DescribeRes
 	}
 
 	return &DescribeResult{
-		Object: compiled.Shape.Object,
-		Fields: shapeToFieldInfo(compiled.Shape),
-		IsRow:  compiled.IsRow,
+		Object:      compiled.Shape.Object,
+		ObjectLabel: compiled.Shape.Label,
+		Fields:      shapeToFieldInfo(compiled.Shape),
+		IsRow:       compiled.IsRow,
 	}, nil
 }
+
+// Explain plans a SOQL query under the caller's security context without
+// executing it. Params select the page that is planned, as in Execute.
+func (s *queryService) Explain(ctx context.Context, query string, params *QueryParams) (*ExplainResult, error) {
+	var binds map[string]any
+	if params != nil {
+		binds = params.Binds
+	}
+
+	compiled, err := s.prepare(ctx, s.engine, query, binds)
+	if err != nil {
+		return nil, fmt.Errorf("queryService.Explain: %w", err)
+	}
+	ctx = s.route(ctx, compiled)
+
+	var page *Page
+	if params != nil && !params.Unpaged && compiled.Pagination != nil {
+		page = &Page{Size: clampPageSize(params.PageSize)}
+		if limit := compiled.Pagination.Limit; limit > 0 && limit < page.Size {
+			page.Size = limit
+		}
+	}
+
+	result, err := s.executor.Explain(ctx, compiled, page)
+	if err != nil {
+		return nil, fmt.Errorf("queryService.Explain: %w", err)
+	}
+	result.Object = compiled.Shape.Object
+	result.Fields = shapeToFieldInfo(compiled.Shape)
+
+	var limitErr *engine.LimitError
+	if err := s.engine.GetLimits().CheckEstimate(result.Plan.TotalCost, result.Plan.PlanRows); errors.As(err, &limitErr) {
+		result.Rejected = true
+		result.RejectReason = limitErr.Message
+	}
+	return result, nil
+}
+
+// Stream executes a SOQL query and writes its records to w as they are read,
+// returning the number of records written. The result is never paged: every
+// record up to the query LIMIT is written, under the export engine limits
+// when one is configured. Security is enforced as in Execute.
+func (s *queryService) Stream(ctx context.Context, query string, binds map[string]any, w RecordWriter) (int, error) {
+	eng := s.exportEngine
+	if eng == nil {
+		eng = s.engine
+	}
+
+	ctx, started := s.startLog(ctx)
+	compiled, err := s.prepare(ctx, eng, query, binds)
+	if err != nil {
+		s.logQuery(ctx, query, nil, 0, started, err)
+		return 0, fmt.Errorf("queryService.Stream: %w", err)
+	}
+	ctx = s.route(ctx, compiled)
+	if err := s.checkCost(ctx, eng.GetLimits(), compiled, nil); err != nil {
+		s.logQuery(ctx, query, compiled, 0, started, err)
+		return 0, fmt.Errorf("queryService.Stream: %w", err)
+	}
+
+	count, err := s.executor.Stream(ctx, compiled, w)
+	s.logQuery(ctx, query, compiled, count, started, err)
+	if err != nil {
+		return count, fmt.Errorf("queryService.Stream: %w", err)
+	}
+	return count, nil
+}
+
+// startLog prepares ctx for an execution reported to the query log.
+func (s *queryService) startLog(ctx context.Context) (context.Context, time.Time) {
+	if s.log == nil {
+		return ctx, time.Time{}
+	}
+	return querylog.Trace(ctx), time.Now()
+}
+
+// logQuery reports an execution started by startLog to the query log.
+// compiled is nil when the query did not compile.
+func (s *queryService) logQuery(ctx context.Context, query string, compiled *engine.CompiledQuery, rows int, started time.Time, err error) {
+	if s.log == nil {
+		return
+	}
+	var object string
+	if compiled != nil && compiled.Shape != nil {
+		object = compiled.Shape.Object
+	}
+	s.log.Record(querylog.NewEntry(ctx, querylog.KindSOQL, query, object, rows, started, err))
+}
+
+func resultSize(result *QueryResult) int {
+	if result == nil {
+		return 0
+	}
+	return len(result.Records)
+}
@@ -2,8 +2,17 @@ package soql
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/adverax/crm/internal/pkg/apperror"
+	"github.com/adverax/crm/internal/platform/querylog"
+	"github.com/adverax/crm/internal/platform/security"
 	"github.com/adverax/crm/internal/platform/soql/engine"
 )
 
@@ -112,3 +121,226 @@ func TestQueryService_Describe(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryService_ExecuteNextRejectsCursor(t *testing.T) {
+	t.Parallel()
+
+	accountMeta := engine.NewObjectMeta("Account", "public", "obj_account").
+		Field("Id", "id", engine.FieldTypeID).
+		Field("Name", "name", engine.FieldTypeString).
+		Build()
+	eng := engine.NewEngine(engine.WithMetadata(engine.NewStaticMetadataProvider(map[string]*engine.ObjectMeta{
+		"Account": accountMeta,
+	})))
+
+	now := time.Now()
+	cursors := engine.NewCursorManager(engine.NewStaticSecret("secret"), engine.DefaultTieBreaker,
+		engine.WithCursorClock(func() time.Time { return now }))
+	svc := NewQueryService(eng, nil, WithCursorManager(cursors))
+
+	owner := uuid.New()
+	query := "SELECT Name FROM Account ORDER BY Name"
+	compiled, err := eng.PrepareAndResolve(context.Background(), query)
+	if err != nil {
+		t.Fatalf("PrepareAndResolve() error = %v", err)
+	}
+
+	issue := func(m *engine.HMACCursorManager, query string) string {
+		t.Helper()
+		cursor, err := m.Encode(&engine.CursorPayload{
+			OrderBy: compiled.Pagination.SortKeys,
+			LastRow: map[string]interface{}{"Name": "Acme", "Id": map[string]interface{}{cursorTagUUID: uuid.NewString()}},
+			FID:     (&engine.DefaultFIDBuilder{}).BuildFID("Account", owner.String(), map[string]interface{}{"q": query}),
+			Query:   query,
+		})
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return cursor
+	}
+
+	expired := engine.NewCursorManager(engine.NewStaticSecret("secret"), engine.DefaultTieBreaker,
+		engine.WithCursorClock(func() time.Time { return now.Add(-time.Hour) }))
+	forged := engine.NewCursorManager(engine.NewStaticSecret("guessed"), engine.DefaultTieBreaker)
+
+	tests := []struct {
+		name       string
+		svc        QueryService
+		user       uuid.UUID
+		cursor     string
+		wantStatus int
+	}{
+		{
+			name:       "cursor of another user",
+			svc:        svc,
+			user:       uuid.New(),
+			cursor:     issue(cursors, query),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "expired cursor",
+			svc:        svc,
+			user:       owner,
+			cursor:     issue(expired, query),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "forged signature",
+			svc:        svc,
+			user:       owner,
+			cursor:     issue(forged, query),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed cursor",
+			svc:        svc,
+			user:       owner,
+			cursor:     "not-a-cursor",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "cursors disabled",
+			svc:        NewQueryService(eng, nil),
+			user:       owner,
+			cursor:     issue(cursors, query),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := security.ContextWithUser(context.Background(), security.UserContext{UserID: tt.user})
+			_, err := tt.svc.ExecuteNext(ctx, tt.cursor)
+
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) {
+				t.Fatalf("ExecuteNext() error = %v, want AppError", err)
+			}
+			if appErr.HTTPStatus != tt.wantStatus {
+				t.Errorf("HTTPStatus = %d, want %d", appErr.HTTPStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+type stubWriteTracker map[uuid.UUID]bool
+
+func (s stubWriteTracker) WroteRecently(userID uuid.UUID) bool {
+	return s[userID]
+}
+
+func TestQueryService_RoutesToReplica(t *testing.T) {
+	t.Parallel()
+
+	accountMeta := engine.NewObjectMeta("Account", "public", "obj_account").
+		Field("Id", "id", engine.FieldTypeID).
+		Field("Name", "name", engine.FieldTypeString).
+		Build()
+	eng := engine.NewEngine(engine.WithMetadata(engine.NewStaticMetadataProvider(map[string]*engine.ObjectMeta{
+		"Account": accountMeta,
+	})))
+
+	writer := uuid.New()
+	reader := uuid.New()
+	withReplica := NewExecutor(nil, nil, nil, WithReplica(new(pgxpool.Pool)))
+	tracker := stubWriteTracker{writer: true}
+
+	tests := []struct {
+		name     string
+		executor *Executor
+		user     uuid.UUID
+		query    string
+		want     bool
+	}{
+		{
+			name:     "read-only query",
+			executor: withReplica,
+			user:     reader,
+			query:    "SELECT Name FROM Account",
+			want:     true,
+		},
+		{
+			name:     "FOR UPDATE stays on the primary",
+			executor: withReplica,
+			user:     reader,
+			query:    "SELECT Name FROM Account WHERE Name = 'Acme' FOR UPDATE",
+			want:     false,
+		},
+		{
+			name:     "recent writer reads own writes",
+			executor: withReplica,
+			user:     writer,
+			query:    "SELECT Name FROM Account",
+			want:     false,
+		},
+		{
+			name:     "no replica configured",
+			executor: NewExecutor(nil, nil, nil),
+			user:     reader,
+			query:    "SELECT Name FROM Account",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewQueryService(eng, tt.executor, WithWriteTracker(tracker)).(*queryService)
+			compiled, err := eng.PrepareAndResolve(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("PrepareAndResolve() error = %v", err)
+			}
+
+			ctx := security.ContextWithUser(context.Background(), security.UserContext{UserID: tt.user})
+			if got := routedToReplica(svc.route(ctx, compiled)); got != tt.want {
+				t.Errorf("routedToReplica() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type recordedEntries []querylog.Entry
+
+func (r *recordedEntries) Record(entry querylog.Entry) {
+	*r = append(*r, entry)
+}
+
+func TestQueryService_LogsFailedQuery(t *testing.T) {
+	t.Parallel()
+
+	metadata := engine.NewStaticMetadataProvider(map[string]*engine.ObjectMeta{
+		"Account": engine.NewObjectMeta("Account", "public", "obj_account").
+			Field("Id", "id", engine.FieldTypeID).
+			Build(),
+	})
+	eng := engine.NewEngine(engine.WithMetadata(metadata))
+
+	var log recordedEntries
+	svc := NewQueryService(eng, nil, WithQueryLog(&log))
+
+	user := uuid.New()
+	ctx := security.ContextWithUser(context.Background(), security.UserContext{UserID: user})
+	ctx = querylog.WithSource(ctx, "saved_query:accounts")
+	const query = "SELECT Missing FROM Account"
+
+	if _, err := svc.Execute(ctx, query, nil); err == nil {
+		t.Fatal("Execute() error = nil, want validation error")
+	}
+
+	if len(log) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(log))
+	}
+	e := log[0]
+	if e.Kind != querylog.KindSOQL || e.QueryHash != querylog.Hash(query) || e.Source != "saved_query:accounts" {
+		t.Errorf("entry = %+v, want the soql query from saved_query:accounts", e)
+	}
+	if e.UserID == nil || *e.UserID != user {
+		t.Errorf("UserID = %v, want %s", e.UserID, user)
+	}
+	if e.Error == "" || e.RLSApplied || e.RowCount != 0 {
+		t.Errorf("entry = %+v, want a failed query without rows", e)
+	}
+}
@@ -1,20 +1,24 @@
 package soql
 
 import (
+	"encoding/json"
+
 	"github.com/adverax/crm/internal/platform/soql/engine"
 )
 
 // FieldInfo describes a single field in SOQL query output.
 type FieldInfo struct {
-	Name string `json:"name"` // SOQL field name or alias
-	Type string `json:"type"` // "string", "integer", "float", "boolean", "date", "datetime", "id", "object", "array"
+	Name  string `json:"name"`            // SOQL field name or alias
+	Type  string `json:"type"`            // "string", "integer", "float", "boolean", "date", "datetime", "id", "object", "array"
+	Label string `json:"label,omitempty"` // Field label in the query locale, for plain fields and toLabel()
 }
 
 // DescribeResult contains metadata about a SOQL query without executing it.
 type DescribeResult struct {
-	Object string      `json:"object"` // Root SOQL object name
-	Fields []FieldInfo `json:"fields"` // Output fields with types
-	IsRow  bool        `json:"isRow"`  // SELECT ROW flag
+	Object      string      `json:"object"`                // Root SOQL object name
+	ObjectLabel string      `json:"objectLabel,omitempty"` // Root object label in the query locale
+	Fields      []FieldInfo `json:"fields"`                // Output fields with types
+	IsRow       bool        `json:"isRow"`                 // SELECT ROW flag
 }
 
 // QueryResult represents the result of executing a SOQL query.
@@ -49,8 +53,9 @@ func shapeToFieldInfo(shape *engine.ResultShape) []FieldInfo {
 	fields := make([]FieldInfo, len(shape.Fields))
 	for i, f := range shape.Fields {
 		fields[i] = FieldInfo{
-			Name: f.Name,
-			Type: fieldTypeToString(f.Type),
+			Name:  f.Name,
+			Type:  fieldTypeToString(f.Type),
+			Label: f.Label,
 		}
 	}
 	return fields
@@ -83,10 +88,67 @@ func fieldTypeToString(ft engine.FieldType) string {
 	}
 }
 
+// ExplainResult describes how a SOQL query would run, without running it.
+type ExplainResult struct {
+	// Object is the root SOQL object.
+	Object string `json:"object"`
+
+	// Fields describes the output fields with their types.
+	Fields []FieldInfo `json:"fields"`
+
+	// SQL is the statement sent to PostgreSQL, with RLS predicates injected.
+	SQL string `json:"sql"`
+
+	// RLS lists the row-level security predicates injected into SQL.
+	RLS []RLSPredicateInfo `json:"rls"`
+
+	// Plan holds the PostgreSQL planner estimates.
+	Plan *QueryPlan `json:"plan"`
+
+	// Rejected is true when the estimates exceed the configured cost ceiling,
+	// so executing the query would fail. RejectReason says which limit is hit.
+	Rejected     bool   `json:"rejected"`
+	RejectReason string `json:"rejectReason,omitempty"`
+}
+
+// RLSPredicateInfo describes a row-level security predicate of a query.
+type RLSPredicateInfo struct {
+	Object string `json:"object"`
+	Alias  string `json:"alias"`
+	Kind   string `json:"kind"` // "root", "lookup", "subquery" or "where_subquery"
+
+	// Predicate is the injected SQL condition, TRUE when the user sees all
+	// rows. Its $n placeholders are numbered from $1 per predicate.
+	Predicate string `json:"predicate"`
+}
+
+// QueryPlan holds the estimates of the top node of a PostgreSQL query plan.
+type QueryPlan struct {
+	NodeType    string  `json:"nodeType"`
+	StartupCost float64 `json:"startupCost"`
+	TotalCost   float64 `json:"totalCost"`
+	PlanRows    int     `json:"planRows"`
+
+	// Raw is the full plan tree as returned by EXPLAIN (FORMAT JSON).
+	Raw json.RawMessage `json:"raw"`
+}
+
 // QueryParams contains parameters for query execution.
+// Passing nil params to Execute returns all records up to the query LIMIT.
 type QueryParams struct {
-	// PageSize overrides the LIMIT in the query.
+	// Unpaged returns all records up to the query LIMIT, as with nil params;
+	// PageSize is ignored.
+	Unpaged bool
+
+	// PageSize is the maximum number of records per page; further pages are
+	// fetched with the cursor in QueryResult.NextCursor. The query LIMIT
+	// caps the total across all pages.
 	PageSize int
+
+	// Binds holds the values of :name bind variables in the query. Values
+	// are converted to the type of the field each variable is compared with;
+	// a list (or a single value) binds IN :name.
+	Binds map[string]any
 }
 
 const (
@@ -94,3 +156,21 @@ const (
 	MaxPageSize     = 2000
 	MinPageSize     = 1
 )
+
+// SearchResult represents the result of a SOSL search.
+type SearchResult struct {
+	// TotalSize is the number of records across all groups.
+	TotalSize int `json:"totalSize"`
+
+	// Groups holds the matches per searched object, in RETURNING order.
+	Groups []SearchGroup `json:"groups"`
+}
+
+// SearchGroup holds the matching records of one object, best match first.
+// Every record carries its text search rank in the search_rank field.
+type SearchGroup struct {
+	Object    string           `json:"object"`
+	Fields    []FieldInfo      `json:"fields"`
+	TotalSize int              `json:"totalSize"`
+	Records   []map[string]any `json:"records"`
+}
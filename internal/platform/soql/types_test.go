@@ -65,6 +65,18 @@ func TestShapeToFieldInfo(t *testing.T) {
 				{Name: "tags", Type: "array"},
 			},
 		},
+		{
+			name: "carries localized labels",
+			shape: &engine.ResultShape{
+				Object: "Deal",
+				Fields: []*engine.FieldShape{
+					{Name: "Stage", Column: "Stage", Type: engine.FieldTypeString, Label: "Phase"},
+				},
+			},
+			want: []FieldInfo{
+				{Name: "Stage", Type: "string", Label: "Phase"},
+			},
+		},
 		{
 			name: "unknown type defaults to string",
 			shape: &engine.ResultShape{
@@ -21,12 +21,14 @@ const (
 )
 
 // RecordService provides generic CRUD operations for any metadata-defined object.
+// Update and Delete with a non-zero version succeed only while the record
+// still has that SystemVersion; otherwise they fail with a conflict.
 type RecordService interface {
 	List(ctx context.Context, objectName string, params ListParams) (*RecordListResult, error)
 	GetByID(ctx context.Context, objectName string, recordID string) (map[string]any, error)
 	Create(ctx context.Context, objectName string, fields map[string]any) (*CreateResult, error)
-	Update(ctx context.Context, objectName string, recordID string, fields map[string]any) error
-	Delete(ctx context.Context, objectName string, recordID string) error
+	Update(ctx context.Context, objectName string, recordID string, fields map[string]any, version int64) error
+	Delete(ctx context.Context, objectName string, recordID string, version int64) error
 }
 
 type recordService struct {
@@ -163,7 +165,7 @@ func (s *recordService) Create(ctx context.Context, objectName string, fields ma
 	return &CreateResult{ID: id}, nil
 }
 
-func (s *recordService) Update(ctx context.Context, objectName string, recordID string, fields map[string]any) error {
+func (s *recordService) Update(ctx context.Context, objectName string, recordID string, fields map[string]any, version int64) error {
 	if err := validateUUID(recordID); err != nil {
 		return fmt.Errorf("recordService.Update: %w", err)
 	}
@@ -186,18 +188,21 @@ func (s *recordService) Update(ctx context.Context, objectName string, recordID
 
 	setClauses := buildSetClauses(fields)
 
-	stmt := fmt.Sprintf("UPDATE %s SET %s WHERE Id = '%s'",
-		objectName, strings.Join(setClauses, ", "), recordID)
+	stmt := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
+		objectName, strings.Join(setClauses, ", "), recordCondition(recordID, version))
 
-	_, err := s.dmlService.Execute(ctx, stmt)
+	result, err := s.dmlService.Execute(ctx, stmt)
 	if err != nil {
 		return fmt.Errorf("recordService.Update: %w", err)
 	}
+	if version != 0 && result.RowsAffected == 0 {
+		return fmt.Errorf("recordService.Update: %w", apperror.NotFound("record", recordID))
+	}
 
 	return nil
 }
 
-func (s *recordService) Delete(ctx context.Context, objectName string, recordID string) error {
+func (s *recordService) Delete(ctx context.Context, objectName string, recordID string, version int64) error {
 	if err := validateUUID(recordID); err != nil {
 		return fmt.Errorf("recordService.Delete: %w", err)
 	}
@@ -211,20 +216,32 @@ func (s *recordService) Delete(ctx context.Context, objectName string, recordID
 		return fmt.Errorf("recordService.Delete: %w", apperror.Forbidden("object is not deleteable"))
 	}
 
-	stmt := fmt.Sprintf("DELETE FROM %s WHERE Id = '%s'", objectName, recordID)
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE %s", objectName, recordCondition(recordID, version))
 
-	_, err := s.dmlService.Execute(ctx, stmt)
+	result, err := s.dmlService.Execute(ctx, stmt)
 	if err != nil {
 		return fmt.Errorf("recordService.Delete: %w", err)
 	}
+	if version != 0 && result.RowsAffected == 0 {
+		return fmt.Errorf("recordService.Delete: %w", apperror.NotFound("record", recordID))
+	}
 
 	return nil
 }
 
+// recordCondition returns the DML WHERE condition selecting recordID, at the
+// given version unless version is 0.
+func recordCondition(recordID string, version int64) string {
+	if version == 0 {
+		return fmt.Sprintf("Id = '%s'", recordID)
+	}
+	return fmt.Sprintf("Id = '%s' AND SystemVersion = %d", recordID, version)
+}
+
 // buildSelectFields returns the list of field names for SOQL SELECT.
 func (s *recordService) buildSelectFields(objDef metadata.ObjectDefinition) []string {
 	// System fields always included
-	fieldNames := []string{"Id", "OwnerId", "CreatedAt", "UpdatedAt", "CreatedById", "UpdatedById"}
+	fieldNames := []string{"Id", "OwnerId", "CreatedAt", "UpdatedAt", "CreatedById", "UpdatedById", "SystemVersion"}
 
 	fields := s.cache.GetFieldsByObjectID(objDef.ID)
 	for _, f := range fields {
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -25,6 +26,10 @@ func (m *mockQueryService) Execute(ctx context.Context, query string, params *so
 	return m.executeFunc(ctx, query, params)
 }
 
+func (m *mockQueryService) ExecuteNext(_ context.Context, _ string) (*soql.QueryResult, error) {
+	return &soql.QueryResult{Done: true}, nil
+}
+
 func (m *mockQueryService) Describe(ctx context.Context, query string) (*soql.DescribeResult, error) {
 	if m.describeFunc != nil {
 		return m.describeFunc(ctx, query)
@@ -32,6 +37,14 @@ func (m *mockQueryService) Describe(ctx context.Context, query string) (*soql.De
 	return &soql.DescribeResult{}, nil
 }
 
+func (m *mockQueryService) Explain(_ context.Context, _ string, _ *soql.QueryParams) (*soql.ExplainResult, error) {
+	return &soql.ExplainResult{}, nil
+}
+
+func (m *mockQueryService) Stream(_ context.Context, _ string, _ map[string]any, _ soql.RecordWriter) (int, error) {
+	return 0, nil
+}
+
 // --- Mock DMLService ---
 
 type mockDMLService struct {
@@ -50,6 +63,14 @@ func (m *mockDMLService) ExecuteBatch(_ context.Context, _ []string) ([]*engine.
 	return nil, nil
 }
 
+func (m *mockDMLService) ExecutePartial(_ context.Context, _ []string) (*dml.BatchResult, error) {
+	return nil, nil
+}
+
+func (m *mockDMLService) ExecuteComposite(_ context.Context, _ []dml.SubRequest) ([]dml.SubResult, error) {
+	return nil, nil
+}
+
 func (m *mockDMLService) SetPostExecuteHook(_ dml.PostExecuteHook) {}
 
 func TestRecordService_List(t *testing.T) {
@@ -298,6 +319,7 @@ func TestRecordService_Update(t *testing.T) {
 		object   string
 		recordID string
 		fields   map[string]any
+		version  int64
 		ctx      context.Context
 		dmlFunc  func(ctx context.Context, statement string) (*engine.Result, error)
 		wantErr  bool
@@ -312,6 +334,32 @@ func TestRecordService_Update(t *testing.T) {
 				return &engine.Result{RowsAffected: 1, UpdatedIds: []string{recordID}}, nil
 			},
 		},
+		{
+			name:     "guards update by version",
+			object:   "Account",
+			recordID: recordID,
+			fields:   map[string]any{"Name": "Updated"},
+			version:  4,
+			ctx:      security.ContextWithUser(context.Background(), security.UserContext{UserID: userID}),
+			dmlFunc: func(_ context.Context, statement string) (*engine.Result, error) {
+				if !strings.HasSuffix(statement, "WHERE Id = '"+recordID+"' AND SystemVersion = 4") {
+					return nil, fmt.Errorf("unexpected statement: %s", statement)
+				}
+				return &engine.Result{RowsAffected: 1, UpdatedIds: []string{recordID}}, nil
+			},
+		},
+		{
+			name:     "returns error when guarded update finds no record",
+			object:   "Account",
+			recordID: recordID,
+			fields:   map[string]any{"Name": "Updated"},
+			version:  4,
+			ctx:      security.ContextWithUser(context.Background(), security.UserContext{UserID: userID}),
+			dmlFunc: func(_ context.Context, _ string) (*engine.Result, error) {
+				return &engine.Result{}, nil
+			},
+			wantErr: true,
+		},
 		{
 			name:     "returns error for invalid UUID",
 			object:   "Account",
@@ -330,7 +378,7 @@ func TestRecordService_Update(t *testing.T) {
 			t.Parallel()
 
 			svc := NewRecordService(cache, nil, &mockDMLService{executeFunc: tt.dmlFunc})
-			err := svc.Update(tt.ctx, tt.object, tt.recordID, tt.fields)
+			err := svc.Update(tt.ctx, tt.object, tt.recordID, tt.fields, tt.version)
 
 			if tt.wantErr {
 				if err == nil {
@@ -356,6 +404,7 @@ func TestRecordService_Delete(t *testing.T) {
 		name     string
 		object   string
 		recordID string
+		version  int64
 		dmlFunc  func(ctx context.Context, statement string) (*engine.Result, error)
 		wantErr  bool
 	}{
@@ -367,6 +416,28 @@ func TestRecordService_Delete(t *testing.T) {
 				return &engine.Result{RowsAffected: 1, DeletedIds: []string{recordID}}, nil
 			},
 		},
+		{
+			name:     "guards delete by version",
+			object:   "Account",
+			recordID: recordID,
+			version:  2,
+			dmlFunc: func(_ context.Context, statement string) (*engine.Result, error) {
+				if statement != "DELETE FROM Account WHERE Id = '"+recordID+"' AND SystemVersion = 2" {
+					return nil, fmt.Errorf("unexpected statement: %s", statement)
+				}
+				return &engine.Result{RowsAffected: 1, DeletedIds: []string{recordID}}, nil
+			},
+		},
+		{
+			name:     "returns error when guarded delete finds no record",
+			object:   "Account",
+			recordID: recordID,
+			version:  2,
+			dmlFunc: func(_ context.Context, _ string) (*engine.Result, error) {
+				return &engine.Result{}, nil
+			},
+			wantErr: true,
+		},
 		{
 			name:     "returns error for invalid UUID",
 			object:   "Account",
@@ -392,7 +463,7 @@ func TestRecordService_Delete(t *testing.T) {
 			t.Parallel()
 
 			svc := NewRecordService(cache, nil, &mockDMLService{executeFunc: tt.dmlFunc})
-			err := svc.Delete(context.Background(), tt.object, tt.recordID)
+			err := svc.Delete(context.Background(), tt.object, tt.recordID, tt.version)
 
 			if tt.wantErr {
 				if err == nil {
@@ -549,3 +620,13 @@ func (s *stubCacheLoader) LoadAllLayouts(_ context.Context) ([]metadata.Layout,
 func (s *stubCacheLoader) LoadAllSharedLayouts(_ context.Context) ([]metadata.SharedLayout, error) {
 	return nil, nil
 }
+
+func (s *stubCacheLoader) LoadAllSavedQueries(_ context.Context) ([]metadata.SavedQuery, error) {
+	return nil, nil
+}
+func (s *stubCacheLoader) LoadAllPicklistValues(_ context.Context) ([]metadata.PicklistValueRow, error) {
+	return nil, nil
+}
+func (s *stubCacheLoader) LoadAllTranslations(_ context.Context) ([]metadata.Translation, error) {
+	return nil, nil
+}
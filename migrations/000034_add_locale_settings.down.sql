@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS iam.org_settings;
+
+ALTER TABLE iam.users
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS time_zone;
@@ -0,0 +1,18 @@
+-- Empty values mean "use the organization default".
+ALTER TABLE iam.users
+    ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN locale    VARCHAR(16) NOT NULL DEFAULT '';
+
+-- Single-row table: the primary key can only be TRUE.
+CREATE TABLE iam.org_settings (
+    id                      BOOLEAN      PRIMARY KEY DEFAULT true,
+    default_time_zone       VARCHAR(64)  NOT NULL DEFAULT 'UTC',
+    default_locale          VARCHAR(16)  NOT NULL DEFAULT 'en_US',
+    fiscal_year_start_month SMALLINT     NOT NULL DEFAULT 1,
+    updated_at              TIMESTAMPTZ  NOT NULL DEFAULT now(),
+
+    CONSTRAINT org_settings_singleton CHECK (id),
+    CONSTRAINT org_settings_fiscal_month_check CHECK (fiscal_year_start_month BETWEEN 1 AND 12)
+);
+
+INSERT INTO iam.org_settings (id) VALUES (true);
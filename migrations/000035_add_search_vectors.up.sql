@@ -0,0 +1,36 @@
+-- Backfill SOSL search vectors for searchable objects created before the DDL
+-- generator started maintaining them. Weights must match ddl.SearchWeight:
+-- A = name fields, B = email, C = phone, D = other text.
+DO $$
+DECLARE
+    obj  RECORD;
+    expr TEXT;
+BEGIN
+    FOR obj IN
+        SELECT id, table_name FROM metadata.object_definitions WHERE is_searchable
+    LOOP
+        SELECT string_agg(
+                   format('setweight(to_tsvector(''simple'', coalesce(%I, '''')), %L)',
+                          f.api_name,
+                          CASE
+                              WHEN f.field_subtype = 'email' THEN 'B'
+                              WHEN f.field_subtype = 'phone' THEN 'C'
+                              WHEN f.api_name = 'name' OR f.api_name LIKE '%\_name' THEN 'A'
+                              ELSE 'D'
+                          END),
+                   ' || ' ORDER BY f.sort_order, f.api_name)
+          INTO expr
+          FROM metadata.field_definitions f
+         WHERE f.object_id = obj.id
+           AND f.field_type = 'text'
+           AND coalesce(f.field_subtype, '') <> 'url';
+
+        IF expr IS NOT NULL AND to_regclass(quote_ident(obj.table_name)) IS NOT NULL THEN
+            EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS search_vector', obj.table_name);
+            EXECUTE format('ALTER TABLE %I ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (%s) STORED',
+                           obj.table_name, expr);
+            EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I USING GIN (search_vector)',
+                           'idx_' || obj.table_name || '_search_vector', obj.table_name);
+        END IF;
+    END LOOP;
+END $$;
@@ -0,0 +1,3 @@
+ALTER TABLE iam.profiles
+    DROP CONSTRAINT IF EXISTS profiles_statement_timeout_check,
+    DROP COLUMN IF EXISTS statement_timeout_ms;
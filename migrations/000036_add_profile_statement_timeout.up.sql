@@ -0,0 +1,4 @@
+-- Maximum run time of a SOQL query for users of the profile; 0 means no limit.
+ALTER TABLE iam.profiles
+    ADD COLUMN statement_timeout_ms INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT profiles_statement_timeout_check CHECK (statement_timeout_ms BETWEEN 0 AND 3600000);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS metadata.saved_query_fields;
+DROP TABLE IF EXISTS metadata.saved_query_versions;
+DROP TABLE IF EXISTS metadata.saved_queries;
@@ -0,0 +1,37 @@
+CREATE TABLE metadata.saved_queries (
+    id          UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    api_name    VARCHAR(100) NOT NULL,
+    label       VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    soql        TEXT         NOT NULL,
+    params      JSONB        NOT NULL DEFAULT '[]',
+    version     INT          NOT NULL DEFAULT 1,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+
+    CONSTRAINT saved_queries_api_name_unique UNIQUE (api_name),
+    CONSTRAINT chk_saved_query_api_name_format CHECK (api_name ~ '^[a-z][a-z0-9_]*$'),
+    CONSTRAINT chk_saved_query_soql_size CHECK (length(soql) <= 100000),
+    CONSTRAINT chk_saved_query_params_size CHECK (jsonb_array_length(params) <= 20)
+);
+
+CREATE TABLE metadata.saved_query_versions (
+    id             UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    saved_query_id UUID        NOT NULL REFERENCES metadata.saved_queries(id) ON DELETE CASCADE,
+    version        INT         NOT NULL,
+    soql           TEXT        NOT NULL,
+    params         JSONB       NOT NULL DEFAULT '[]',
+    change_summary TEXT        NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT saved_query_versions_unique UNIQUE (saved_query_id, version)
+);
+
+-- Fields referenced by the current version of each saved query; fields
+-- listed here cannot be deleted.
+CREATE TABLE metadata.saved_query_fields (
+    saved_query_id UUID NOT NULL REFERENCES metadata.saved_queries(id) ON DELETE CASCADE,
+    field_id       UUID NOT NULL REFERENCES metadata.field_definitions(id) ON DELETE CASCADE,
+    PRIMARY KEY (saved_query_id, field_id)
+);
+
+CREATE INDEX idx_saved_query_fields_field_id ON metadata.saved_query_fields(field_id);
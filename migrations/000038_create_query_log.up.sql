@@ -0,0 +1,22 @@
+-- SOQL queries and DML statements executed through the platform services.
+-- Partitioned by day; the API server creates upcoming partitions and drops
+-- those past the retention period (QUERY_LOG_RETENTION).
+CREATE TABLE metadata.query_log (
+    id          UUID         NOT NULL DEFAULT gen_random_uuid(),
+    kind        VARCHAR(10)  NOT NULL,
+    user_id     UUID,
+    source      VARCHAR(255) NOT NULL DEFAULT '',
+    query_hash  VARCHAR(64)  NOT NULL,
+    object      VARCHAR(100) NOT NULL DEFAULT '',
+    row_count   INTEGER      NOT NULL DEFAULT 0,
+    duration_ms INTEGER      NOT NULL,
+    rls_applied BOOLEAN      NOT NULL DEFAULT false,
+    error       TEXT,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (id, created_at),
+    CONSTRAINT chk_query_log_kind CHECK (kind IN ('soql', 'dml'))
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX idx_query_log_created_at ON metadata.query_log (created_at);
+CREATE INDEX idx_query_log_user_id ON metadata.query_log (user_id, created_at DESC);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS security.run_as_audit;
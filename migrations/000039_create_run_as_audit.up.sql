@@ -0,0 +1,17 @@
+-- SOQL queries administrators ran as another user (POST /admin/soql/run-as).
+-- No foreign keys: entries outlive the users they mention.
+CREATE TABLE security.run_as_audit (
+    id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_user_id     UUID        NOT NULL,
+    user_id           UUID        NOT NULL,
+    query             TEXT        NOT NULL,
+    object            VARCHAR(100) NOT NULL DEFAULT '',
+    row_count         INTEGER     NOT NULL DEFAULT 0,
+    rls_filtered_rows INTEGER     NOT NULL DEFAULT 0,
+    stripped_fields   TEXT[]      NOT NULL DEFAULT '{}',
+    error             TEXT,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_run_as_audit_admin_user_id ON security.run_as_audit (admin_user_id, created_at DESC);
+CREATE INDEX idx_run_as_audit_user_id ON security.run_as_audit (user_id, created_at DESC);
@@ -0,0 +1,13 @@
+DO $$
+DECLARE
+    obj RECORD;
+BEGIN
+    FOR obj IN SELECT table_name FROM metadata.object_definitions LOOP
+        IF to_regclass(quote_ident(obj.table_name)) IS NOT NULL THEN
+            EXECUTE format('DROP INDEX IF EXISTS %I', 'idx_' || obj.table_name || '_deleted_at');
+            EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS deleted_by', obj.table_name);
+            EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS deleted_at', obj.table_name);
+            EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS is_deleted', obj.table_name);
+        END IF;
+    END LOOP;
+END $$;
@@ -0,0 +1,16 @@
+-- Soft-delete columns for object tables created before the DDL generator
+-- started adding them (ddl.CreateObjectTable, ddl.RecycleBinIndex).
+DO $$
+DECLARE
+    obj RECORD;
+BEGIN
+    FOR obj IN SELECT table_name FROM metadata.object_definitions LOOP
+        IF to_regclass(quote_ident(obj.table_name)) IS NOT NULL THEN
+            EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN NOT NULL DEFAULT false', obj.table_name);
+            EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ', obj.table_name);
+            EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS deleted_by UUID', obj.table_name);
+            EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I (deleted_at) WHERE is_deleted',
+                           'idx_' || obj.table_name || '_deleted_at', obj.table_name);
+        END IF;
+    END LOOP;
+END $$;
@@ -0,0 +1,10 @@
+DO $$
+DECLARE
+    obj RECORD;
+BEGIN
+    FOR obj IN SELECT table_name FROM metadata.object_definitions LOOP
+        IF to_regclass(quote_ident(obj.table_name)) IS NOT NULL THEN
+            EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS system_version', obj.table_name);
+        END IF;
+    END LOOP;
+END $$;
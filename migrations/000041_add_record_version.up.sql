@@ -0,0 +1,12 @@
+-- Record version for object tables created before the DDL generator started
+-- adding it (ddl.CreateObjectTable, ddl.VersionColumn).
+DO $$
+DECLARE
+    obj RECORD;
+BEGIN
+    FOR obj IN SELECT table_name FROM metadata.object_definitions LOOP
+        IF to_regclass(quote_ident(obj.table_name)) IS NOT NULL THEN
+            EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS system_version BIGINT NOT NULL DEFAULT 1', obj.table_name);
+        END IF;
+    END LOOP;
+END $$;
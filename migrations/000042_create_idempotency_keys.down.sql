@@ -0,0 +1 @@
+DROP TABLE IF EXISTS metadata.idempotency_keys;
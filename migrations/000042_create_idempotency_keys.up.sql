@@ -0,0 +1,15 @@
+-- Responses to requests sent with an Idempotency-Key header, replayed to
+-- retries of the request. No foreign keys: entries are purged after the
+-- retention period.
+CREATE TABLE metadata.idempotency_keys (
+    user_id      UUID         NOT NULL,
+    key          VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64)  NOT NULL,
+    status_code  INTEGER      NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    body         BYTEA        NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON metadata.idempotency_keys (created_at);
@@ -0,0 +1,8 @@
+-- Claims of requests still running have no response to keep.
+DELETE FROM metadata.idempotency_keys WHERE status = 'in_progress';
+
+ALTER TABLE metadata.idempotency_keys
+    DROP CONSTRAINT IF EXISTS idempotency_keys_completed_check,
+    ALTER COLUMN status_code SET NOT NULL,
+    ALTER COLUMN body SET NOT NULL,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,13 @@
+-- A request claims its key with an in_progress row before it runs and stores
+-- its response in the row when it completes. Rows stored before are
+-- completed responses.
+ALTER TABLE metadata.idempotency_keys
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'completed'
+    CHECK (status IN ('in_progress', 'completed'));
+
+ALTER TABLE metadata.idempotency_keys
+    ALTER COLUMN status SET DEFAULT 'in_progress',
+    ALTER COLUMN status_code DROP NOT NULL,
+    ALTER COLUMN body DROP NOT NULL,
+    ADD CONSTRAINT idempotency_keys_completed_check
+    CHECK (status = 'in_progress' OR (status_code IS NOT NULL AND body IS NOT NULL));
@@ -1,5 +1,5 @@
 BEGIN;
-SELECT plan(17);
+SELECT plan(20);
 
 -- Таблица существует
 SELECT has_table('iam', 'profiles', 'table iam.profiles exists');
@@ -25,6 +25,10 @@ SELECT has_column('iam', 'profiles', 'base_permission_set_id', 'has base_permiss
 SELECT col_not_null('iam', 'profiles', 'base_permission_set_id', 'base_permission_set_id is NOT NULL');
 SELECT fk_ok('iam', 'profiles', 'base_permission_set_id', 'iam', 'permission_sets', 'id', 'FK base_permission_set_id -> permission_sets.id');
 
+SELECT has_column('iam', 'profiles', 'statement_timeout_ms', 'has statement_timeout_ms');
+SELECT col_not_null('iam', 'profiles', 'statement_timeout_ms', 'statement_timeout_ms is NOT NULL');
+SELECT col_default_is('iam', 'profiles', 'statement_timeout_ms', '0', 'statement_timeout_ms defaults to 0');
+
 SELECT has_index('iam', 'profiles', 'idx_profiles_base_permission_set_id', 'index on base_permission_set_id exists');
 
 SELECT finish();
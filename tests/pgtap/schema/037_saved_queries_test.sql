@@ -0,0 +1,37 @@
+BEGIN;
+SELECT plan(22);
+
+-- Table: metadata.saved_queries
+SELECT has_table('metadata', 'saved_queries', 'has metadata.saved_queries table');
+SELECT has_column('metadata', 'saved_queries', 'id', 'has id column');
+SELECT has_column('metadata', 'saved_queries', 'api_name', 'has api_name column');
+SELECT has_column('metadata', 'saved_queries', 'label', 'has label column');
+SELECT has_column('metadata', 'saved_queries', 'description', 'has description column');
+SELECT has_column('metadata', 'saved_queries', 'soql', 'has soql column');
+SELECT has_column('metadata', 'saved_queries', 'params', 'has params column');
+SELECT has_column('metadata', 'saved_queries', 'version', 'has version column');
+
+SELECT col_type_is('metadata', 'saved_queries', 'api_name', 'character varying(100)', 'api_name is varchar(100)');
+SELECT col_type_is('metadata', 'saved_queries', 'params', 'jsonb', 'params is jsonb');
+
+SELECT has_check('metadata', 'saved_queries', 'has check constraints');
+
+-- Table: metadata.saved_query_versions
+SELECT has_table('metadata', 'saved_query_versions', 'has metadata.saved_query_versions table');
+SELECT has_column('metadata', 'saved_query_versions', 'saved_query_id', 'has saved_query_id column');
+SELECT has_column('metadata', 'saved_query_versions', 'version', 'has version column');
+SELECT has_column('metadata', 'saved_query_versions', 'soql', 'has soql column');
+SELECT has_column('metadata', 'saved_query_versions', 'params', 'has params column');
+SELECT has_column('metadata', 'saved_query_versions', 'change_summary', 'has change_summary column');
+
+-- Table: metadata.saved_query_fields
+SELECT has_table('metadata', 'saved_query_fields', 'has metadata.saved_query_fields table');
+SELECT has_column('metadata', 'saved_query_fields', 'saved_query_id', 'has saved_query_id column');
+SELECT has_column('metadata', 'saved_query_fields', 'field_id', 'has field_id column');
+SELECT fk_ok('metadata', 'saved_query_fields', 'field_id', 'metadata', 'field_definitions', 'id', 'field_id references field_definitions');
+
+-- Indexes
+SELECT has_index('metadata', 'saved_query_fields', 'idx_saved_query_fields_field_id', 'has field_id index');
+
+SELECT * FROM finish();
+ROLLBACK;
@@ -2,16 +2,19 @@ import { reactive, computed } from 'vue'
 import type { Profile, CreateProfileRequest, UpdateProfileRequest } from '@/types/security'
 
 const API_NAME_REGEX = /^[A-Za-z][A-Za-z0-9_]*$/
+const MAX_STATEMENT_TIMEOUT_MS = 3600000
 
 export interface ProfileFormState {
   apiName: string
   label: string
   description: string
+  statementTimeoutMs: number
 }
 
 export interface ProfileFormErrors {
   apiName?: string
   label?: string
+  statementTimeoutMs?: string
 }
 
 function defaultState(): ProfileFormState {
@@ -19,6 +22,7 @@ function defaultState(): ProfileFormState {
     apiName: '',
     label: '',
     description: '',
+    statementTimeoutMs: 0,
   }
 }
 
@@ -27,6 +31,7 @@ export function useProfileForm(existing?: Profile) {
     apiName: existing.apiName,
     label: existing.label,
     description: existing.description,
+    statementTimeoutMs: existing.statementTimeoutMs ?? 0,
   } : defaultState())
 
   const errors = reactive<ProfileFormErrors>({})
@@ -34,6 +39,7 @@ export function useProfileForm(existing?: Profile) {
   function validate(): boolean {
     errors.apiName = undefined
     errors.label = undefined
+    errors.statementTimeoutMs = undefined
 
     let valid = true
 
@@ -56,6 +62,12 @@ export function useProfileForm(existing?: Profile) {
       valid = false
     }
 
+    const timeout = Number(state.statementTimeoutMs)
+    if (!Number.isInteger(timeout) || timeout < 0 || timeout > MAX_STATEMENT_TIMEOUT_MS) {
+      errors.statementTimeoutMs = `Whole milliseconds from 0 to ${MAX_STATEMENT_TIMEOUT_MS}`
+      valid = false
+    }
+
     return valid
   }
 
@@ -70,6 +82,7 @@ export function useProfileForm(existing?: Profile) {
       apiName: state.apiName,
       label: state.label,
       description: state.description || undefined,
+      statementTimeoutMs: Number(state.statementTimeoutMs) || 0,
     }
   }
 
@@ -77,6 +90,7 @@ export function useProfileForm(existing?: Profile) {
     return {
       label: state.label,
       description: state.description || undefined,
+      statementTimeoutMs: Number(state.statementTimeoutMs) || 0,
     }
   }
 
@@ -84,12 +98,14 @@ export function useProfileForm(existing?: Profile) {
     Object.assign(state, defaultState())
     errors.apiName = undefined
     errors.label = undefined
+    errors.statementTimeoutMs = undefined
   }
 
   function initFrom(profile: Profile) {
     state.apiName = profile.apiName
     state.label = profile.label
     state.description = profile.description
+    state.statementTimeoutMs = profile.statementTimeoutMs ?? 0
   }
 
   return { state, errors, validate, isValid, toCreateRequest, toUpdateRequest, reset, initFrom }
@@ -45,6 +45,9 @@ const soqlKeywords: Completion[] = [
   { label: 'TRUE', type: 'keyword' },
   { label: 'FALSE', type: 'keyword' },
   { label: 'WITH SECURITY_ENFORCED', type: 'keyword' },
+  { label: 'USING SCOPE', type: 'keyword' },
+  { label: 'EXISTS', type: 'keyword' },
+  { label: 'NOT EXISTS', type: 'keyword' },
 ]
 
 const aggregateFunctions: Completion[] = [
@@ -68,6 +71,16 @@ const scalarFunctions: Completion[] = [
   { label: 'ROUND(', type: 'function', detail: 'Round number' },
 ]
 
+const dateFunctions: Completion[] = [
+  { label: 'CALENDAR_MONTH(', type: 'function', detail: 'Month number (1-12)' },
+  { label: 'CALENDAR_QUARTER(', type: 'function', detail: 'Quarter number (1-4)' },
+  { label: 'CALENDAR_YEAR(', type: 'function', detail: 'Year' },
+  { label: 'FISCAL_QUARTER(', type: 'function', detail: 'Fiscal quarter number (1-4)' },
+  { label: 'DAY_ONLY(', type: 'function', detail: 'Date of a datetime' },
+  { label: 'WEEK_IN_YEAR(', type: 'function', detail: 'Week number (1-53)' },
+  { label: 'HOUR_IN_DAY(', type: 'function', detail: 'Hour (0-23)' },
+]
+
 const dateLiterals: Completion[] = [
   { label: 'TODAY', type: 'constant', detail: 'Current date' },
   { label: 'YESTERDAY', type: 'constant', detail: 'Previous date' },
@@ -139,6 +152,8 @@ function soqlCompletionSource(config: SoqlAutocompleteConfig) {
         options.push(...fieldCompletions)
         options.push(...aggregateFunctions)
         options.push(...scalarFunctions)
+        options.push(...dateFunctions)
+        options.push({ label: 'GROUPING(', type: 'function', detail: 'Subtotal row flag' })
         // Add FROM to move to next clause
         options.push({ label: 'FROM', type: 'keyword' })
         break
@@ -202,7 +217,10 @@ function soqlCompletionSource(config: SoqlAutocompleteConfig) {
           type: 'property' as const,
         }))
         options.push(...fieldCompletions)
+        options.push(...dateFunctions)
         options.push(
+          { label: 'ROLLUP(', type: 'function', detail: 'Subtotals and grand total' },
+          { label: 'CUBE(', type: 'function', detail: 'Subtotals for all combinations' },
           { label: 'HAVING', type: 'keyword' },
           { label: 'ORDER BY', type: 'keyword' },
           { label: 'LIMIT', type: 'keyword' },
@@ -11,6 +11,7 @@ const keywords = new Set([
   'IS', 'GROUP', 'BY', 'HAVING', 'ORDER', 'LIMIT', 'OFFSET',
   'ASC', 'DESC', 'NULLS', 'FIRST', 'LAST', 'AS', 'FOR', 'UPDATE',
   'TYPEOF', 'WHEN', 'THEN', 'ELSE', 'END', 'WITH', 'SECURITY_ENFORCED',
+  'USING', 'SCOPE', 'EXISTS',
 ])
 
 const functions = new Set([
@@ -18,6 +19,8 @@ const functions = new Set([
   'COALESCE', 'NULLIF', 'CONCAT', 'UPPER', 'LOWER', 'TRIM',
   'LENGTH', 'LEN', 'SUBSTRING', 'SUBSTR', 'ABS', 'ROUND',
   'FLOOR', 'CEIL', 'CEILING',
+  'CALENDAR_MONTH', 'CALENDAR_QUARTER', 'CALENDAR_YEAR', 'FISCAL_QUARTER',
+  'DAY_ONLY', 'WEEK_IN_YEAR', 'HOUR_IN_DAY', 'GROUPING', 'ROLLUP', 'CUBE',
 ])
 
 const dateLiterals = new Set([
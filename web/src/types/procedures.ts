@@ -25,6 +25,7 @@ export interface CommandDef {
   id?: string
   data?: Record<string, string>
   query?: string
+  binds?: Record<string, string>
   // compute fields
   value?: Record<string, string>
   condition?: string
@@ -51,6 +51,7 @@ export interface Profile {
   label: string
   description: string
   basePermissionSetId: string
+  statementTimeoutMs: number
   createdAt: string
   updatedAt: string
 }
@@ -59,11 +60,13 @@ export interface CreateProfileRequest {
   apiName: string
   label: string
   description?: string
+  statementTimeoutMs?: number
 }
 
 export interface UpdateProfileRequest {
   label: string
   description?: string
+  statementTimeoutMs?: number
 }
 
 export interface User {